@@ -24,7 +24,10 @@ package coupon
 
 import (
 	"errors"
+	"fmt"
 	"math"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -38,6 +41,10 @@ import (
 // Returns:
 //   - CalculationResult with discount amount, validity status, and applied items
 //
+// When the coupon is invalid only because of a minimum order or required items,
+// the result also carries PotentialDiscount, computed on the current items as if
+// those conditions were met, and the UnmetConditions with their shortfalls.
+//
 // Example:
 //
 //	input := CalculationInput{
@@ -51,29 +58,74 @@ import (
 //	}
 func Calculate(input CalculationInput) CalculationResult {
 	result := CalculationResult{
+		Code:    input.Coupon.Code,
 		IsValid: false,
 	}
 
 	// Validate coupon first
 	if validationErr := validateCoupon(input); validationErr != nil {
 		result.ErrorMessage = validationErr.Error()
+		result.PotentialDiscount, result.UnmetConditions = potentialDiscount(input)
 		return result
 	}
 
+	return calculateDiscount(input)
+}
+
+// calculateDiscount applies the calculation for the coupon's type to an input
+// that has already passed validation.
+//
+// Parameters:
+//   - input: CalculationInput with a validated coupon
+//
+// Returns:
+//   - CalculationResult tagged with the coupon code and whether a grace period was needed
+func calculateDiscount(input CalculationInput) CalculationResult {
+	var result CalculationResult
+
 	// Calculate discount based on coupon type
 	switch input.Coupon.Type {
 	case CouponTypePercentage:
-		return calculatePercentageDiscount(input)
+		result = calculatePercentageDiscount(input)
 	case CouponTypeFixedAmount:
-		return calculateFixedAmountDiscount(input)
+		result = calculateFixedAmountDiscount(input)
 	case CouponTypeBuyXGetY:
-		return calculateBuyXGetYDiscount(input)
+		result = calculateBuyXGetYDiscount(input)
 	case CouponTypeFreeShipping:
-		return calculateFreeShippingDiscount(input)
+		result = calculateFreeShippingDiscount(input)
 	default:
 		result.ErrorMessage = "unsupported coupon type"
-		return result
 	}
+
+	result.Code = input.Coupon.Code
+	if result.IsValid {
+		now := time.Now()
+		result.GracePeriodUsed = now.Before(input.Coupon.ValidFrom) || now.After(input.Coupon.ValidUntil)
+	}
+	return result
+}
+
+// graceWindow returns the validity window of the input's coupon widened by its
+// grace period. The start moves only when GraceBeforeValidFrom is set; a
+// negative grace period is treated as none.
+//
+// Parameters:
+//   - input: CalculationInput carrying the coupon and grace settings
+//
+// Returns:
+//   - time.Time: earliest accepted time
+//   - time.Time: latest accepted time
+func graceWindow(input CalculationInput) (time.Time, time.Time) {
+	grace := input.GracePeriod
+	if grace < 0 {
+		grace = 0
+	}
+
+	validFrom := input.Coupon.ValidFrom
+	if input.GraceBeforeValidFrom {
+		validFrom = validFrom.Add(-grace)
+	}
+	return validFrom, input.Coupon.ValidUntil.Add(grace)
 }
 
 // calculatePercentageDiscount calculates percentage-based discount for the given coupon.
@@ -143,14 +195,27 @@ func calculateFixedAmountDiscount(input CalculationInput) CalculationResult {
 // Returns:
 //   - CalculationResult with discount applied to cheapest qualifying items
 //
+// Zero-price items neither count towards X nor can be chosen as free items, so a
+// free gift in the cart never uses up the promotion.
+//
 // Example:
 //   Buy 2 Get 1 Free: customer buys 4 items, gets 2 items free (cheapest ones)
 func calculateBuyXGetYDiscount(input CalculationInput) CalculationResult {
 	result := CalculationResult{IsValid: true}
 
-	applicableItems := getApplicableItems(input)
+	if input.Coupon.BuyX <= 0 {
+		result.IsValid = false
+		result.ErrorMessage = "buy quantity must be positive"
+		return result
+	}
+
+	applicableItems := []Item{}
 	totalQuantity := 0
-	for _, item := range applicableItems {
+	for _, item := range getApplicableItems(input) {
+		if item.Price == 0 {
+			continue
+		}
+		applicableItems = append(applicableItems, item)
 		totalQuantity += item.Quantity
 	}
 
@@ -203,10 +268,16 @@ func calculateFreeShippingDiscount(input CalculationInput) CalculationResult {
 //
 // Validation checks:
 //   - Coupon is active
-//   - Current date is within validity period
+//   - Current date is within validity period, extended by any grace period
 //   - Order meets minimum amount requirement
 //   - Usage limits are not exceeded
+//   - User is allowed to redeem the coupon
+//   - Coupon may combine with automatic discounts already applied to the order
+//   - Items have a quantity of at least one and a non-negative price
+//   - Required items are present in their minimum quantities
 //   - At least one applicable item exists
+//
+// Zero-price items are valid; they simply add nothing to the discountable amount.
 func validateCoupon(input CalculationInput) error {
 	coupon := input.Coupon
 
@@ -215,12 +286,13 @@ func validateCoupon(input CalculationInput) error {
 		return errors.New("coupon is not active")
 	}
 
-	// Check date validity
+	// Check date validity, allowing the configured grace period
 	now := time.Now()
-	if now.Before(coupon.ValidFrom) {
+	validFrom, validUntil := graceWindow(input)
+	if now.Before(validFrom) {
 		return errors.New("coupon is not yet valid")
 	}
-	if now.After(coupon.ValidUntil) {
+	if now.After(validUntil) {
 		return errors.New("coupon has expired")
 	}
 
@@ -238,6 +310,31 @@ func validateCoupon(input CalculationInput) error {
 		return errors.New("user usage limit exceeded")
 	}
 
+	// Check user restriction
+	if !isUserAllowed(coupon, input.UserID) {
+		return errors.New("coupon is not valid for this user")
+	}
+
+	// Check stacking with automatic discounts
+	if input.AutoDiscountsApplied && !coupon.CombinableWithAutoDiscounts {
+		return errors.New("coupon cannot be combined with automatic discounts")
+	}
+
+	// Check item quantities and prices
+	for _, item := range input.Items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %s has invalid quantity %d", item.ID, item.Quantity)
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("item %s has negative price", item.ID)
+		}
+	}
+
+	// Check required item quantities
+	if unmet := unmetRequiredItems(input.Items, coupon.RequiredItems); len(unmet) > 0 {
+		return fmt.Errorf("required items not met: %s", strings.Join(unmet, ", "))
+	}
+
 	// Check if there are applicable items
 	if len(getApplicableItems(input)) == 0 {
 		return errors.New("no applicable items found")
@@ -246,6 +343,118 @@ func validateCoupon(input CalculationInput) error {
 	return nil
 }
 
+// potentialDiscount computes what an invalid coupon would save if the customer
+// met its minimum order and required items. Other failures, such as an expired
+// coupon or an exhausted usage limit, cannot be fixed by the customer, so no
+// potential discount is reported for them.
+//
+// Parameters:
+//   - input: CalculationInput that failed validation
+//
+// Returns:
+//   - float64: discount on the current items with the conditions met, or 0
+//   - []UnmetCondition: the conditions the order still has to meet
+func potentialDiscount(input CalculationInput) (float64, []UnmetCondition) {
+	coupon := input.Coupon
+	unmet := []UnmetCondition{}
+
+	relaxed := input
+	relaxed.Coupon.MinOrder = 0
+	relaxed.Coupon.RequiredItems = nil
+	if validateCoupon(relaxed) != nil {
+		return 0, nil
+	}
+
+	if input.OrderAmount < coupon.MinOrder {
+		shortfall := math.Round((coupon.MinOrder-input.OrderAmount)*100) / 100
+		unmet = append(unmet, UnmetCondition{
+			Condition: "min_order",
+			Message:   fmt.Sprintf("add $%.2f more to reach the $%.2f minimum order", shortfall, coupon.MinOrder),
+			Shortfall: shortfall,
+		})
+		relaxed.OrderAmount = coupon.MinOrder
+	}
+
+	if len(coupon.RequiredItems) > 0 {
+		quantities := make(map[string]int)
+		for _, item := range input.Items {
+			quantities[item.ID] += item.Quantity
+		}
+		itemIDs := make([]string, 0, len(coupon.RequiredItems))
+		for itemID := range coupon.RequiredItems {
+			itemIDs = append(itemIDs, itemID)
+		}
+		sort.Strings(itemIDs)
+		for _, itemID := range itemIDs {
+			if missing := coupon.RequiredItems[itemID] - quantities[itemID]; missing > 0 {
+				unmet = append(unmet, UnmetCondition{
+					Condition: "required_items",
+					Message:   fmt.Sprintf("add %d more of %s", missing, itemID),
+					Shortfall: float64(missing),
+				})
+			}
+		}
+	}
+
+	if len(unmet) == 0 {
+		return 0, nil
+	}
+
+	result := Calculate(relaxed)
+	if !result.IsValid {
+		return 0, nil
+	}
+	return result.DiscountAmount, unmet
+}
+
+// isUserAllowed reports whether the user may redeem the coupon. Coupons without
+// AllowedUserIDs are open to every user.
+func isUserAllowed(coupon Coupon, userID string) bool {
+	if len(coupon.AllowedUserIDs) == 0 {
+		return true
+	}
+	for _, allowedID := range coupon.AllowedUserIDs {
+		if allowedID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// unmetRequiredItems lists the required items whose quantity in the order is below the minimum.
+// Quantities of order lines sharing an item ID are added together.
+//
+// Parameters:
+//   - items: order items to check
+//   - required: map of item ID to minimum quantity
+//
+// Returns:
+//   - []string: one description per unmet requirement, sorted by item ID
+//
+// Example:
+//   unmetRequiredItems([]Item{{ID: "SKU-123", Quantity: 1}}, map[string]int{"SKU-123": 2})
+//   // Returns: ["SKU-123 (have 1, need 2)"]
+func unmetRequiredItems(items []Item, required map[string]int) []string {
+	if len(required) == 0 {
+		return nil
+	}
+
+	quantities := make(map[string]int)
+	for _, item := range items {
+		quantities[item.ID] += item.Quantity
+	}
+
+	unmet := []string{}
+	for itemID, minQuantity := range required {
+		if quantities[itemID] < minQuantity {
+			unmet = append(unmet, fmt.Sprintf("%s (have %d, need %d)", itemID, quantities[itemID], minQuantity))
+		}
+	}
+	sort.Strings(unmet)
+
+	return unmet
+}
+
 // getApplicableItems returns items that the coupon can be applied to based on
 // the coupon's category and product restrictions. If no restrictions are specified,
 // all items are considered applicable.
@@ -418,4 +627,55 @@ func CalculateMultiple(coupons []Coupon, orderAmount float64, userID string, ite
 	}
 
 	return bestResult
-}
\ No newline at end of file
+}
+
+// ScanApplicable evaluates a catalog of coupons against one cart and returns the
+// coupons that currently apply, best discount first. It is intended for
+// auto-suggesting coupons, so invalid coupons are dropped as soon as validation
+// fails, without computing a potential discount for them.
+//
+// Parameters:
+//   - coupons: catalog of coupons to evaluate
+//   - base: order details shared by every coupon; base.Coupon is ignored and
+//     base.Usage is only applied to the coupon whose code it tracks
+//
+// Returns:
+//   - []CalculationResult: valid results sorted by DiscountAmount descending;
+//     ties keep catalog order, and free shipping coupons report no amount
+//
+// Example:
+//
+//	results := ScanApplicable(catalog, CalculationInput{
+//		OrderAmount: 120.0,
+//		UserID:      "user123",
+//		Items:       items,
+//	})
+//	if len(results) > 0 {
+//		fmt.Printf("Best coupon %s saves $%.2f", results[0].Code, results[0].DiscountAmount)
+//	}
+func ScanApplicable(coupons []Coupon, base CalculationInput) []CalculationResult {
+	results := []CalculationResult{}
+
+	for _, coupon := range coupons {
+		input := base
+		input.Coupon = coupon
+		if base.Usage.CouponCode != coupon.Code {
+			input.Usage = CouponUsage{}
+		}
+
+		if validateCoupon(input) != nil {
+			continue
+		}
+
+		result := calculateDiscount(input)
+		if result.IsValid {
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].DiscountAmount > results[j].DiscountAmount
+	})
+
+	return results
+}
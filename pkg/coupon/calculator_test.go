@@ -218,6 +218,53 @@ func TestCalculate(t *testing.T) {
 		}
 	})
 	
+	t.Run("GracePeriod", func(t *testing.T) {
+		tests := []struct {
+			name          string
+			validFrom     time.Time
+			validUntil    time.Time
+			graceBefore   bool
+			expectValid   bool
+			expectedGrace bool
+		}{
+			{"within validity", time.Now().Add(-time.Hour), time.Now().Add(time.Hour), false, true, false},
+			{"expired within grace", time.Now().Add(-time.Hour), time.Now().Add(-30 * time.Second), false, true, true},
+			{"expired beyond grace", time.Now().Add(-time.Hour), time.Now().Add(-10 * time.Minute), false, false, false},
+			{"early without start grace", time.Now().Add(30 * time.Second), time.Now().Add(time.Hour), false, false, false},
+			{"early within start grace", time.Now().Add(30 * time.Second), time.Now().Add(time.Hour), true, true, true},
+		}
+		
+		for _, tt := range tests {
+			input := CalculationInput{
+				Coupon: Coupon{
+					Code:       "GRACE",
+					Type:       CouponTypePercentage,
+					Value:      10.0,
+					ValidFrom:  tt.validFrom,
+					ValidUntil: tt.validUntil,
+					IsActive:   true,
+				},
+				OrderAmount: 100.0,
+				UserID:      "user123",
+				Items:       []Item{{ID: "item1", Price: 100.0, Quantity: 1}},
+				GracePeriod: 2 * time.Minute,
+				GraceBeforeValidFrom: tt.graceBefore,
+			}
+			
+			result := Calculate(input)
+			
+			if result.IsValid != tt.expectValid {
+				t.Errorf("%s: expected valid %v, got %v (%s)", tt.name, tt.expectValid, result.IsValid, result.ErrorMessage)
+			}
+			if result.GracePeriodUsed != tt.expectedGrace {
+				t.Errorf("%s: expected grace period used %v, got %v", tt.name, tt.expectedGrace, result.GracePeriodUsed)
+			}
+			if tt.expectValid && result.DiscountAmount != 10.0 {
+				t.Errorf("%s: expected discount 10.0, got %f", tt.name, result.DiscountAmount)
+			}
+		}
+	})
+	
 	t.Run("InvalidCoupon - BelowMinOrder", func(t *testing.T) {
 		coupon := Coupon{
 			Code:       "MINORDER",
@@ -252,6 +299,76 @@ func TestCalculate(t *testing.T) {
 		}
 	})
 	
+	t.Run("InvalidCoupon - PotentialDiscount", func(t *testing.T) {
+		coupon := Coupon{
+			Code:       "SAVE15",
+			Type:       CouponTypeFixedAmount,
+			Value:      15.0,
+			MinOrder:   50.0,
+			ValidFrom:  time.Now().Add(-24 * time.Hour),
+			ValidUntil: time.Now().Add(24 * time.Hour),
+			IsActive:   true,
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 40.0,
+			UserID:      "user123",
+			Items:       []Item{{ID: "item1", Price: 40.0, Quantity: 1}},
+		}
+		
+		result := Calculate(input)
+		
+		if result.IsValid || result.DiscountAmount != 0 {
+			t.Errorf("Expected invalid coupon with no discount, got valid=%v discount=%.2f", result.IsValid, result.DiscountAmount)
+		}
+		if result.ErrorMessage != "order amount does not meet minimum requirement" {
+			t.Errorf("Expected minimum order error, got %q", result.ErrorMessage)
+		}
+		if result.PotentialDiscount != 15.0 {
+			t.Errorf("Expected potential discount 15.00, got %.2f", result.PotentialDiscount)
+		}
+		if len(result.UnmetConditions) != 1 || result.UnmetConditions[0].Condition != "min_order" || result.UnmetConditions[0].Shortfall != 10.0 {
+			t.Errorf("Expected a min_order shortfall of 10.00, got %+v", result.UnmetConditions)
+		}
+		
+		// Nothing the customer can do about an expired coupon
+		input.Coupon.ValidUntil = time.Now().Add(-time.Hour)
+		result = Calculate(input)
+		if result.PotentialDiscount != 0 || len(result.UnmetConditions) != 0 {
+			t.Errorf("Expected no potential discount for an expired coupon, got %.2f %+v", result.PotentialDiscount, result.UnmetConditions)
+		}
+	})
+	
+	t.Run("InvalidCoupon - PotentialDiscountRequiredItems", func(t *testing.T) {
+		coupon := Coupon{
+			Code:          "BUNDLE10",
+			Type:          CouponTypePercentage,
+			Value:         10.0,
+			MinOrder:      100.0,
+			RequiredItems: map[string]int{"SKU-123": 2},
+			ValidFrom:     time.Now().Add(-24 * time.Hour),
+			ValidUntil:    time.Now().Add(24 * time.Hour),
+			IsActive:      true,
+		}
+		
+		result := Calculate(CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 80.0,
+			Items:       []Item{{ID: "SKU-123", Price: 80.0, Quantity: 1}},
+		})
+		
+		if result.PotentialDiscount != 8.0 {
+			t.Errorf("Expected potential discount 8.00, got %.2f", result.PotentialDiscount)
+		}
+		if len(result.UnmetConditions) != 2 {
+			t.Fatalf("Expected 2 unmet conditions, got %+v", result.UnmetConditions)
+		}
+		if condition := result.UnmetConditions[1]; condition.Condition != "required_items" || condition.Shortfall != 1 {
+			t.Errorf("Expected one more SKU-123 required, got %+v", condition)
+		}
+	})
+	
 	t.Run("InvalidCoupon - UsageLimitExceeded", func(t *testing.T) {
 		coupon := Coupon{
 			Code:       "LIMITEXCEEDED",
@@ -285,6 +402,227 @@ func TestCalculate(t *testing.T) {
 			t.Error("Expected error message to be set")
 		}
 	})
+	
+	t.Run("RequiredItems", func(t *testing.T) {
+		coupon := Coupon{
+			Code:          "BUY2SKU123",
+			Type:          CouponTypePercentage,
+			Value:         15.0,
+			ValidFrom:     time.Now().Add(-24 * time.Hour),
+			ValidUntil:    time.Now().Add(24 * time.Hour),
+			IsActive:      true,
+			RequiredItems: map[string]int{"SKU-123": 2},
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 100.0,
+			UserID:      "user123",
+			Items: []Item{
+				{ID: "SKU-123", Price: 20.0, Quantity: 1},
+				{ID: "SKU-123", Price: 20.0, Quantity: 1},
+				{ID: "other", Price: 60.0, Quantity: 1},
+			},
+		}
+		
+		result := Calculate(input)
+		
+		if !result.IsValid {
+			t.Fatalf("Expected coupon to be valid, got: %s", result.ErrorMessage)
+		}
+		
+		if result.DiscountAmount != 15.0 {
+			t.Errorf("Expected discount amount 15.0, got %f", result.DiscountAmount)
+		}
+	})
+	
+	t.Run("RequiredItemsQuantityShort", func(t *testing.T) {
+		coupon := Coupon{
+			Code:          "BUY2SKU123",
+			Type:          CouponTypePercentage,
+			Value:         15.0,
+			ValidFrom:     time.Now().Add(-24 * time.Hour),
+			ValidUntil:    time.Now().Add(24 * time.Hour),
+			IsActive:      true,
+			RequiredItems: map[string]int{"SKU-123": 2, "SKU-456": 1},
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 80.0,
+			UserID:      "user123",
+			Items: []Item{
+				{ID: "SKU-123", Price: 20.0, Quantity: 1},
+				{ID: "other", Price: 60.0, Quantity: 1},
+			},
+		}
+		
+		result := Calculate(input)
+		
+		if result.IsValid {
+			t.Error("Expected coupon to be invalid")
+		}
+		
+		expected := "required items not met: SKU-123 (have 1, need 2), SKU-456 (have 0, need 1)"
+		if result.ErrorMessage != expected {
+			t.Errorf("Expected error message %q, got %q", expected, result.ErrorMessage)
+		}
+	})
+	
+	t.Run("InvalidCoupon - ZeroQuantityItem", func(t *testing.T) {
+		coupon := Coupon{
+			Code:       "SAVE10",
+			Type:       CouponTypePercentage,
+			Value:      10.0,
+			ValidFrom:  time.Now().Add(-24 * time.Hour),
+			ValidUntil: time.Now().Add(24 * time.Hour),
+			IsActive:   true,
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 50.0,
+			UserID:      "user123",
+			Items: []Item{
+				{ID: "item1", Price: 50.0, Quantity: 0},
+			},
+		}
+		
+		result := Calculate(input)
+		
+		if result.IsValid {
+			t.Error("Expected coupon to be invalid")
+		}
+		
+		if result.ErrorMessage != "item item1 has invalid quantity 0" {
+			t.Errorf("Unexpected error message: %q", result.ErrorMessage)
+		}
+	})
+	
+	t.Run("AutoDiscountStacking", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			combinable  bool
+			expectValid bool
+		}{
+			{name: "combinable", combinable: true, expectValid: true},
+			{name: "not combinable", combinable: false, expectValid: false},
+		}
+		
+		for _, tt := range tests {
+			coupon := Coupon{
+				Code:                        "EXTRA10",
+				Type:                        CouponTypePercentage,
+				Value:                       10.0,
+				ValidFrom:                   time.Now().Add(-24 * time.Hour),
+				ValidUntil:                  time.Now().Add(24 * time.Hour),
+				IsActive:                    true,
+				CombinableWithAutoDiscounts: tt.combinable,
+			}
+			
+			input := CalculationInput{
+				Coupon:               coupon,
+				OrderAmount:          90.0,
+				UserID:               "user123",
+				Items:                []Item{{ID: "item1", Price: 90.0, Quantity: 1}},
+				AutoDiscountsApplied: true,
+			}
+			
+			result := Calculate(input)
+			
+			if result.IsValid != tt.expectValid {
+				t.Errorf("%s: expected valid=%v, got %v (%s)", tt.name, tt.expectValid, result.IsValid, result.ErrorMessage)
+			}
+			if !tt.expectValid && result.ErrorMessage != "coupon cannot be combined with automatic discounts" {
+				t.Errorf("%s: unexpected error message %q", tt.name, result.ErrorMessage)
+			}
+			
+			input.AutoDiscountsApplied = false
+			if result := Calculate(input); !result.IsValid {
+				t.Errorf("%s: expected coupon to be valid without automatic discounts, got %q", tt.name, result.ErrorMessage)
+			}
+		}
+	})
+	
+	t.Run("BuyXGetYZeroPriceItem", func(t *testing.T) {
+		coupon := Coupon{
+			Code:       "BUY2GET1",
+			Type:       CouponTypeBuyXGetY,
+			BuyX:       2,
+			GetY:       1,
+			ValidFrom:  time.Now().Add(-24 * time.Hour),
+			ValidUntil: time.Now().Add(24 * time.Hour),
+			IsActive:   true,
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 40.0,
+			UserID:      "user123",
+			Items: []Item{
+				{ID: "shirt", Price: 20.0, Quantity: 2},
+				{ID: "sticker", Price: 0, Quantity: 1},
+			},
+		}
+		
+		result := Calculate(input)
+		
+		if !result.IsValid {
+			t.Fatalf("Expected coupon to be valid, got %q", result.ErrorMessage)
+		}
+		
+		if result.DiscountAmount != 20.0 {
+			t.Errorf("Expected discount amount 20.0, got %f", result.DiscountAmount)
+		}
+		
+		for _, item := range result.AppliedItems {
+			if item.ID == "sticker" {
+				t.Error("Expected zero-price item not to be picked as a free item")
+			}
+		}
+	})
+}
+
+func TestScanApplicable(t *testing.T) {
+	validFrom := time.Now().Add(-24 * time.Hour)
+	validUntil := time.Now().Add(24 * time.Hour)
+	coupons := []Coupon{
+		{Code: "TEN", Type: CouponTypePercentage, Value: 10.0, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: true},
+		{Code: "INACTIVE", Type: CouponTypePercentage, Value: 50.0, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: false},
+		{Code: "FLAT25", Type: CouponTypeFixedAmount, Value: 25.0, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: true},
+		{Code: "EXPIRED", Type: CouponTypeFixedAmount, Value: 40.0, ValidFrom: validFrom, ValidUntil: time.Now().Add(-time.Hour), IsActive: true},
+		{Code: "BIGSPEND", Type: CouponTypeFixedAmount, Value: 30.0, MinOrder: 500.0, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: true},
+		{Code: "USEDUP", Type: CouponTypeFixedAmount, Value: 35.0, MaxUsagePerUser: 1, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: true},
+		{Code: "SHIPFREE", Type: CouponTypeFreeShipping, ValidFrom: validFrom, ValidUntil: validUntil, IsActive: true},
+	}
+	base := CalculationInput{
+		OrderAmount: 200.0,
+		UserID:      "user123",
+		Items:       []Item{{ID: "item1", Price: 100.0, Quantity: 2, Category: "electronics"}},
+		Usage:       CouponUsage{CouponCode: "USEDUP", UserID: "user123", UsageCount: 1},
+	}
+	
+	results := ScanApplicable(coupons, base)
+	
+	expected := []struct {
+		code     string
+		discount float64
+	}{
+		{"FLAT25", 25.0},
+		{"TEN", 20.0},
+		{"SHIPFREE", 0.0},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d applicable coupons, got %+v", len(expected), results)
+	}
+	for i, want := range expected {
+		if !results[i].IsValid {
+			t.Errorf("Expected result %d to be valid", i)
+		}
+		if results[i].Code != want.code || results[i].DiscountAmount != want.discount {
+			t.Errorf("Expected result %d to be %s with %.2f, got %s with %.2f", i, want.code, want.discount, results[i].Code, results[i].DiscountAmount)
+		}
+	}
 }
 
 func BenchmarkCalculate(b *testing.B) {
@@ -2,10 +2,14 @@ package coupon
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/loyalty"
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // GenerateCode generates a single coupon code based on the provided configuration.
@@ -481,4 +485,81 @@ func ValidateCodeFormat(code string, config GeneratorConfig) bool {
 	}
 
 	return true
-}
\ No newline at end of file
+}
+
+// IssueRewardCoupon issues a single-use coupon to a loyalty customer, for example
+// when they redeem points or reach a spending milestone. The code is generated with
+// utils.CouponCodeGenerator, the discount and validity come from the reward spec,
+// and the coupon is restricted to the customer through AllowedUserIDs so it cannot
+// be shared.
+//
+// Parameters:
+//   - customer: loyalty customer receiving the reward
+//   - reward: RewardSpec describing the discount and validity window
+//
+// Returns:
+//   - Coupon: active coupon valid from now for reward.ValidFor, usable once by the customer
+//   - error: nil on success, error if the customer or reward spec is invalid or
+//     code generation fails
+//
+// Example:
+//
+//	coupon, err := IssueRewardCoupon(customer, RewardSpec{
+//		Type: CouponTypePercentage,
+//		Value: 10.0,
+//		ValidFor: 30 * 24 * time.Hour,
+//		CodePrefix: "GOLD",
+//	})
+//	// Result: coupon.Code like "GOLD-ABC23456", redeemable only by customer.ID
+func IssueRewardCoupon(customer loyalty.Customer, reward RewardSpec) (Coupon, error) {
+	if customer.ID == "" {
+		return Coupon{}, errors.New("customer ID is required")
+	}
+	if !customer.IsActive {
+		return Coupon{}, fmt.Errorf("customer %s is not active", customer.ID)
+	}
+
+	switch reward.Type {
+	case CouponTypePercentage:
+		if reward.Value <= 0 || reward.Value > 100 {
+			return Coupon{}, errors.New("percentage reward must be between 0 and 100")
+		}
+	case CouponTypeFixedAmount:
+		if reward.Value <= 0 {
+			return Coupon{}, errors.New("fixed amount reward must be positive")
+		}
+	case CouponTypeFreeShipping:
+	default:
+		return Coupon{}, fmt.Errorf("unsupported reward coupon type: %s", reward.Type)
+	}
+	if reward.ValidFor <= 0 {
+		return Coupon{}, errors.New("reward validity period must be positive")
+	}
+
+	length := reward.CodeLength
+	if length <= 0 {
+		length = 8
+	}
+	code, err := utils.NewCouponCodeGenerator(length).TryGenerateCouponCode()
+	if err != nil {
+		return Coupon{}, fmt.Errorf("failed to generate reward code: %w", err)
+	}
+	if reward.CodePrefix != "" {
+		code = strings.ToUpper(reward.CodePrefix) + "-" + code
+	}
+
+	now := time.Now()
+	return Coupon{
+		Code:            code,
+		Type:            reward.Type,
+		Value:           reward.Value,
+		MinOrder:        reward.MinOrder,
+		MaxDiscount:     reward.MaxDiscount,
+		MaxUsage:        1,
+		MaxUsagePerUser: 1,
+		ValidFrom:       now,
+		ValidUntil:      now.Add(reward.ValidFor),
+		IsActive:        true,
+		AllowedUserIDs:  []string{customer.ID},
+	}, nil
+}
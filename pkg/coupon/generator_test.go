@@ -4,6 +4,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/loyalty"
 )
 
 func TestGenerateCode(t *testing.T) {
@@ -334,6 +336,72 @@ func BenchmarkGenerateCode(b *testing.B) {
 	}
 }
 
+func TestIssueRewardCoupon(t *testing.T) {
+	customer := loyalty.Customer{ID: "cust-42", Tier: loyalty.TierGold, IsActive: true}
+	
+	t.Run("MilestoneReward", func(t *testing.T) {
+		coupon, err := IssueRewardCoupon(customer, RewardSpec{
+			Type:       CouponTypePercentage,
+			Value:      15.0,
+			ValidFor:   30 * 24 * time.Hour,
+			CodePrefix: "milestone",
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		
+		if !strings.HasPrefix(coupon.Code, "MILESTONE-") || len(coupon.Code) != len("MILESTONE-")+8 {
+			t.Errorf("Expected MILESTONE- prefixed code, got %s", coupon.Code)
+		}
+		if coupon.MaxUsage != 1 || coupon.MaxUsagePerUser != 1 {
+			t.Errorf("Expected single-use coupon, got MaxUsage=%d MaxUsagePerUser=%d", coupon.MaxUsage, coupon.MaxUsagePerUser)
+		}
+		
+		input := CalculationInput{
+			Coupon:      coupon,
+			OrderAmount: 100.0,
+			UserID:      "cust-42",
+			Items:       []Item{{ID: "item1", Price: 100.0, Quantity: 1}},
+		}
+		
+		result := Calculate(input)
+		if !result.IsValid || result.DiscountAmount != 15.0 {
+			t.Errorf("Expected valid 15.0 discount for the customer, got valid=%v discount=%f (%s)", result.IsValid, result.DiscountAmount, result.ErrorMessage)
+		}
+		
+		input.UserID = "someone-else"
+		result = Calculate(input)
+		if result.IsValid || result.ErrorMessage != "coupon is not valid for this user" {
+			t.Errorf("Expected coupon to be rejected for another user, got valid=%v (%s)", result.IsValid, result.ErrorMessage)
+		}
+		
+		input.UserID = "cust-42"
+		input.Usage = CouponUsage{UsageCount: 1, TotalUsage: 1}
+		if result := Calculate(input); result.IsValid {
+			t.Error("Expected coupon to be rejected after its single use")
+		}
+	})
+	
+	t.Run("InvalidSpec", func(t *testing.T) {
+		specs := []RewardSpec{
+			{Type: CouponTypePercentage, Value: 150, ValidFor: time.Hour},
+			{Type: CouponTypeFixedAmount, Value: 0, ValidFor: time.Hour},
+			{Type: CouponTypeBuyXGetY, ValidFor: time.Hour},
+			{Type: CouponTypePercentage, Value: 10},
+		}
+		
+		for _, spec := range specs {
+			if _, err := IssueRewardCoupon(customer, spec); err == nil {
+				t.Errorf("Expected error for spec %+v", spec)
+			}
+		}
+		
+		if _, err := IssueRewardCoupon(loyalty.Customer{ID: "cust-43"}, RewardSpec{Type: CouponTypeFreeShipping, ValidFor: time.Hour}); err == nil {
+			t.Error("Expected error for inactive customer")
+		}
+	})
+}
+
 func BenchmarkGenerateCodes(b *testing.B) {
 	config := GeneratorConfig{
 		Length: 8,
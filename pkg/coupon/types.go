@@ -69,6 +69,10 @@ const (
 //   - IsActive: manual toggle to enable/disable the coupon
 //   - BuyX/GetY: for buy-X-get-Y promotions (e.g., buy 2 get 1 free)
 //   - ApplicableCategories/Products: restrict coupon to specific items
+//   - RequiredItems: item IDs that must be in the order with at least the given quantity
+//   - CombinableWithAutoDiscounts: allow the coupon on orders where automatic discounts
+//     (e.g., bulk or tier discounts) were already applied
+//   - AllowedUserIDs: restrict the coupon to specific users (empty means any user)
 //
 // Example:
 //
@@ -99,6 +103,9 @@ type Coupon struct {
 	GetY           int        `json:"get_y,omitempty"`  // For buy_x_get_y type
 	ApplicableCategories []string `json:"applicable_categories,omitempty"`
 	ApplicableProducts   []string `json:"applicable_products,omitempty"`
+	RequiredItems        map[string]int `json:"required_items,omitempty"` // Item ID to minimum quantity
+	CombinableWithAutoDiscounts bool `json:"combinable_with_auto_discounts,omitempty"` // Stack on top of automatic discounts
+	AllowedUserIDs       []string `json:"allowed_user_ids,omitempty"` // Users allowed to redeem; empty allows everyone
 }
 
 // CouponUsage represents tracking information for coupon usage by users.
@@ -141,6 +148,10 @@ type CouponUsage struct {
 //   - UserID: identifier of the user attempting to use the coupon
 //   - Items: list of items in the order (for category/product-specific coupons)
 //   - Usage: current usage statistics for validation
+//   - AutoDiscountsApplied: OrderAmount already includes automatic discounts; coupons
+//     that are not CombinableWithAutoDiscounts are rejected
+//   - GracePeriod: how long past ValidUntil the coupon is still accepted
+//   - GraceBeforeValidFrom: also accept the coupon up to GracePeriod before ValidFrom
 //
 // Validation flow:
 //   1. Check coupon validity (active, time window)
@@ -163,6 +174,9 @@ type CalculationInput struct {
 	UserID      string  `json:"user_id"`
 	Items       []Item  `json:"items"`
 	Usage       CouponUsage `json:"usage"`
+	AutoDiscountsApplied bool `json:"auto_discounts_applied,omitempty"`
+	GracePeriod time.Duration `json:"grace_period,omitempty"`
+	GraceBeforeValidFrom bool `json:"grace_before_valid_from,omitempty"`
 }
 
 // Item represents a single item in an order with pricing and categorization information.
@@ -210,6 +224,8 @@ type Item struct {
 //   - If IsValid=true: apply DiscountAmount to the order
 //   - If IsValid=false: show ErrorMessage to user, no discount applied
 //   - AppliedItems helps track which items received the discount
+//   - PotentialDiscount and UnmetConditions are set when the coupon only fails
+//     conditions the customer can still meet (minimum order, required items)
 //
 // Example successful result:
 //
@@ -227,10 +243,34 @@ type Item struct {
 //		ErrorMessage: "Coupon has expired",
 //	}
 type CalculationResult struct {
+	Code           string  `json:"code,omitempty"` // Code of the evaluated coupon
 	DiscountAmount float64 `json:"discount_amount"`
 	IsValid        bool    `json:"is_valid"`
+	GracePeriodUsed bool   `json:"grace_period_used,omitempty"` // Accepted only because of the input's GracePeriod
 	ErrorMessage   string  `json:"error_message,omitempty"`
 	AppliedItems   []Item  `json:"applied_items,omitempty"` // Items the coupon was applied to
+	PotentialDiscount float64 `json:"potential_discount,omitempty"` // Discount if the unmet conditions were met
+	UnmetConditions []UnmetCondition `json:"unmet_conditions,omitempty"`
+}
+
+// UnmetCondition describes a coupon condition the order does not yet meet but
+// the customer can still satisfy, such as spending more to reach the minimum.
+//
+// Condition types:
+//   - "min_order": Shortfall is the amount still needed to reach MinOrder
+//   - "required_items": Shortfall is the number of units still needed
+//
+// Example:
+//
+//	condition := UnmetCondition{
+//		Condition: "min_order",
+//		Message:   "add $10.00 more to reach the $50.00 minimum order",
+//		Shortfall: 10.00,
+//	}
+type UnmetCondition struct {
+	Condition string  `json:"condition"`
+	Message   string  `json:"message"`
+	Shortfall float64 `json:"shortfall"`
 }
 
 // GeneratorConfig represents configuration parameters for automated coupon code generation.
@@ -270,6 +310,37 @@ type GeneratorConfig struct {
 	Count      int    `json:"count"`      // Number of codes to generate
 }
 
+// RewardSpec describes the coupon issued to a loyalty customer as a reward, for
+// example when they redeem points or reach a milestone. It is consumed by
+// IssueRewardCoupon, which turns it into a single-use coupon for that customer.
+//
+// Field descriptions:
+//   - Type: coupon type to issue (percentage, fixed amount, or free shipping)
+//   - Value: discount value, interpreted according to Type
+//   - MinOrder: minimum order amount required to redeem the reward
+//   - MaxDiscount: cap on the discount for percentage rewards (0 means no cap)
+//   - ValidFor: how long the coupon stays valid after issuance
+//   - CodePrefix: optional prefix for the generated code (e.g., "REWARD")
+//   - CodeLength: length of the random part of the code (default 8)
+//
+// Example:
+//
+//	reward := RewardSpec{
+//		Type: CouponTypePercentage,
+//		Value: 15.0,
+//		ValidFor: 30 * 24 * time.Hour,
+//		CodePrefix: "MILESTONE",
+//	}
+type RewardSpec struct {
+	Type        CouponType    `json:"type"`
+	Value       float64       `json:"value"`
+	MinOrder    float64       `json:"min_order,omitempty"`
+	MaxDiscount float64       `json:"max_discount,omitempty"`
+	ValidFor    time.Duration `json:"valid_for"`
+	CodePrefix  string        `json:"code_prefix,omitempty"`
+	CodeLength  int           `json:"code_length,omitempty"`
+}
+
 // ValidationRule represents a single validation constraint for coupon usage.
 // Defines specific conditions that must be met for a coupon to be considered valid.
 // Multiple rules can be combined to create complex validation logic.
@@ -0,0 +1,151 @@
+package coupon
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultIdempotencyKeyTTL is how long a UsageTracker remembers an idempotency key
+// when no TTL is configured.
+const DefaultIdempotencyKeyTTL = 24 * time.Hour
+
+// UsageTracker records coupon redemptions in memory and produces the CouponUsage
+// statistics consumed by Calculate. It is safe for concurrent use.
+//
+// Redemptions recorded through RecordRedemptionIdempotent carry an idempotency key,
+// so a checkout that retries after a timeout does not count the same redemption twice.
+// Keys are remembered for the tracker's TTL and then forgotten.
+//
+// Example:
+//
+//	tracker := NewUsageTracker(time.Hour)
+//	if tracker.RecordRedemptionIdempotent("SAVE20", "user123", "order-789") {
+//		// first time this order redeemed the coupon
+//	}
+//	usage := tracker.GetUsage("SAVE20", "user123")
+type UsageTracker struct {
+	mu              sync.Mutex
+	totalUsage      map[string]int
+	userUsage       map[string]map[string]int
+	idempotencyKeys map[string]time.Time // key -> expiry
+	ttl             time.Duration
+	now             func() time.Time
+}
+
+// NewUsageTracker creates an empty UsageTracker that remembers idempotency keys for ttl.
+// A non-positive ttl falls back to DefaultIdempotencyKeyTTL.
+//
+// Parameters:
+//   - ttl: how long a recorded idempotency key suppresses duplicates
+//
+// Returns:
+//   - *UsageTracker: ready-to-use tracker
+func NewUsageTracker(ttl time.Duration) *UsageTracker {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyKeyTTL
+	}
+
+	return &UsageTracker{
+		totalUsage:      make(map[string]int),
+		userUsage:       make(map[string]map[string]int),
+		idempotencyKeys: make(map[string]time.Time),
+		ttl:             ttl,
+		now:             time.Now,
+	}
+}
+
+// SetIdempotencyTTL changes how long newly recorded idempotency keys are remembered.
+// Keys that were already recorded keep their original expiry. Non-positive values are ignored.
+func (ut *UsageTracker) SetIdempotencyTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+	ut.ttl = ttl
+}
+
+// RecordRedemption unconditionally records one redemption of code by userID.
+//
+// Parameters:
+//   - code: coupon code that was redeemed
+//   - userID: user who redeemed the coupon
+func (ut *UsageTracker) RecordRedemption(code, userID string) {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+	ut.recordLocked(code, userID)
+}
+
+// RecordRedemptionIdempotent records one redemption of code by userID unless
+// idempotencyKey has already been recorded and has not yet expired.
+//
+// Parameters:
+//   - code: coupon code that was redeemed
+//   - userID: user who redeemed the coupon
+//   - idempotencyKey: caller-supplied key identifying this redemption attempt, e.g. an order ID
+//
+// Returns:
+//   - bool: true if the redemption was recorded, false if it was a duplicate
+//
+// Example:
+//
+//	tracker.RecordRedemptionIdempotent("SAVE20", "user123", "order-789") // true
+//	tracker.RecordRedemptionIdempotent("SAVE20", "user123", "order-789") // false (retry)
+func (ut *UsageTracker) RecordRedemptionIdempotent(code, userID, idempotencyKey string) (applied bool) {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	now := ut.now()
+	ut.pruneExpiredKeysLocked(now)
+
+	if _, seen := ut.idempotencyKeys[idempotencyKey]; seen {
+		return false
+	}
+
+	ut.idempotencyKeys[idempotencyKey] = now.Add(ut.ttl)
+	ut.recordLocked(code, userID)
+	return true
+}
+
+// GetUsage returns the current usage statistics of code for userID, suitable for
+// CalculationInput.Usage.
+//
+// Parameters:
+//   - code: coupon code to look up
+//   - userID: user whose individual usage count is returned
+//
+// Returns:
+//   - CouponUsage: per-user and total redemption counts
+func (ut *UsageTracker) GetUsage(code, userID string) CouponUsage {
+	ut.mu.Lock()
+	defer ut.mu.Unlock()
+
+	return CouponUsage{
+		CouponCode: code,
+		UserID:     userID,
+		UsageCount: ut.userUsage[code][userID],
+		TotalUsage: ut.totalUsage[code],
+	}
+}
+
+// recordLocked increments the usage counters. The caller must hold ut.mu.
+func (ut *UsageTracker) recordLocked(code, userID string) {
+	ut.totalUsage[code]++
+
+	users, exists := ut.userUsage[code]
+	if !exists {
+		users = make(map[string]int)
+		ut.userUsage[code] = users
+	}
+	users[userID]++
+}
+
+// pruneExpiredKeysLocked forgets idempotency keys whose TTL has elapsed. The caller must hold ut.mu.
+func (ut *UsageTracker) pruneExpiredKeysLocked(now time.Time) {
+	for key, expiry := range ut.idempotencyKeys {
+		if !now.Before(expiry) {
+			delete(ut.idempotencyKeys, key)
+		}
+	}
+}
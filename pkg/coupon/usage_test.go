@@ -0,0 +1,103 @@
+package coupon
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestUsageTrackerRecordRedemption tests basic usage counting
+func TestUsageTrackerRecordRedemption(t *testing.T) {
+	tracker := NewUsageTracker(time.Hour)
+
+	tracker.RecordRedemption("SAVE20", "user1")
+	tracker.RecordRedemption("SAVE20", "user1")
+	tracker.RecordRedemption("SAVE20", "user2")
+
+	usage := tracker.GetUsage("SAVE20", "user1")
+	if usage.UsageCount != 2 {
+		t.Errorf("Expected user usage 2, got %d", usage.UsageCount)
+	}
+	if usage.TotalUsage != 3 {
+		t.Errorf("Expected total usage 3, got %d", usage.TotalUsage)
+	}
+
+	if unused := tracker.GetUsage("OTHER", "user1"); unused.UsageCount != 0 || unused.TotalUsage != 0 {
+		t.Errorf("Expected no usage for unknown coupon, got %+v", unused)
+	}
+}
+
+// TestUsageTrackerDuplicateIdempotencyKey tests that retries with the same key are counted once
+func TestUsageTrackerDuplicateIdempotencyKey(t *testing.T) {
+	tracker := NewUsageTracker(time.Hour)
+
+	if !tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1") {
+		t.Error("Expected first redemption to be applied")
+	}
+	if tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1") {
+		t.Error("Expected duplicate redemption to be ignored")
+	}
+	if !tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-2") {
+		t.Error("Expected redemption with a new key to be applied")
+	}
+
+	usage := tracker.GetUsage("SAVE20", "user1")
+	if usage.UsageCount != 2 || usage.TotalUsage != 2 {
+		t.Errorf("Expected usage 2/2, got %d/%d", usage.UsageCount, usage.TotalUsage)
+	}
+}
+
+// TestUsageTrackerIdempotencyKeyExpiry tests that keys are forgotten after the TTL
+func TestUsageTrackerIdempotencyKeyExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewUsageTracker(time.Minute)
+	tracker.now = func() time.Time { return now }
+
+	tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1")
+
+	now = now.Add(30 * time.Second)
+	if tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1") {
+		t.Error("Expected duplicate within TTL to be ignored")
+	}
+
+	now = now.Add(time.Minute)
+	if !tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1") {
+		t.Error("Expected key to be accepted again after TTL")
+	}
+
+	if usage := tracker.GetUsage("SAVE20", "user1"); usage.TotalUsage != 2 {
+		t.Errorf("Expected total usage 2, got %d", usage.TotalUsage)
+	}
+
+	if NewUsageTracker(0).ttl != DefaultIdempotencyKeyTTL {
+		t.Error("Expected non-positive TTL to fall back to the default")
+	}
+}
+
+// TestUsageTrackerConcurrentRetries tests that concurrent retries apply a redemption once
+func TestUsageTrackerConcurrentRetries(t *testing.T) {
+	tracker := NewUsageTracker(time.Hour)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	applied := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tracker.RecordRedemptionIdempotent("SAVE20", "user1", "order-1") {
+				mu.Lock()
+				applied++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if applied != 1 {
+		t.Errorf("Expected exactly one applied redemption, got %d", applied)
+	}
+	if usage := tracker.GetUsage("SAVE20", "user1"); usage.TotalUsage != 1 {
+		t.Errorf("Expected total usage 1, got %d", usage.TotalUsage)
+	}
+}
@@ -760,4 +760,65 @@ func ValidateBusinessRules(coupon Coupon, input CalculationInput, businessRules
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ValidateCouponConfig checks a coupon's configuration before it is published,
+// independent of any order. Unlike validateCoupon it does not look at dates
+// relative to now or at usage, only at whether the coupon can ever work.
+//
+// Validation checks:
+//   - Code is not empty and the type is supported
+//   - Percentage values are between 0 and 100, fixed amounts are positive
+//   - Buy-X-get-Y coupons have positive BuyX and GetY
+//   - Minimum order, maximum discount and usage limits are non-negative
+//   - ValidFrom is before ValidUntil
+//
+// Parameters:
+//   - coupon: the coupon to check
+//
+// Returns:
+//   - error: nil if the configuration is valid, first problem found otherwise
+//
+// Example:
+//
+//	if err := ValidateCouponConfig(coupon); err != nil {
+//		log.Printf("coupon %s is misconfigured: %v", coupon.Code, err)
+//	}
+func ValidateCouponConfig(coupon Coupon) error {
+	if strings.TrimSpace(coupon.Code) == "" {
+		return errors.New("coupon code is required")
+	}
+
+	switch coupon.Type {
+	case CouponTypePercentage:
+		if coupon.Value <= 0 || coupon.Value > 100 {
+			return errors.New("percentage value must be between 0 and 100")
+		}
+	case CouponTypeFixedAmount:
+		if coupon.Value <= 0 {
+			return errors.New("fixed amount value must be positive")
+		}
+	case CouponTypeBuyXGetY:
+		if coupon.BuyX <= 0 || coupon.GetY <= 0 {
+			return errors.New("buy X and get Y quantities must be positive")
+		}
+	case CouponTypeFreeShipping:
+	default:
+		return fmt.Errorf("unsupported coupon type: %s", coupon.Type)
+	}
+
+	if coupon.MinOrder < 0 {
+		return errors.New("minimum order cannot be negative")
+	}
+	if coupon.MaxDiscount < 0 {
+		return errors.New("maximum discount cannot be negative")
+	}
+	if coupon.MaxUsage < 0 || coupon.MaxUsagePerUser < 0 {
+		return errors.New("usage limits cannot be negative")
+	}
+	if !coupon.ValidUntil.IsZero() && coupon.ValidFrom.After(coupon.ValidUntil) {
+		return errors.New("valid from date must be before valid until date")
+	}
+
+	return nil
+}
@@ -32,8 +32,10 @@ package currency
 import (
 	"fmt"
 	"math"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/masumrpg/ecommerce-engine/pkg/utils"
@@ -46,10 +48,16 @@ import (
 // The calculator maintains:
 //   - Currency definitions with formatting rules
 //   - Exchange rates between currency pairs
-//   - Default rounding behavior
+//   - Default rounding behavior, and the RoundingMode used for conversions and allocations
+//   - Per-currency decimal places for arithmetic and conversion results
+//   - Whether missing rates may be inverted from the opposite pair
+//   - A base currency for triangulating pairs without a rate of their own
+//   - An optional maximum rate age and what to do with older rates
 //
-// Thread safety: Calculator is not thread-safe. Use appropriate synchronization
-// when accessing from multiple goroutines.
+// Thread safety: Calculator guards its currency and exchange rate tables with a
+// read-write mutex, so conversions and formatting may run concurrently with
+// each other and with rate updates. RoundingMode is read under the same lock;
+// assign it before the calculator is shared, or use SetDefaultRounding.
 //
 // Example:
 //	calc := NewCalculator()
@@ -62,13 +70,25 @@ import (
 //		To:     EUR,
 //	})
 type Calculator struct {
+	mu           sync.RWMutex
 	currencies   map[CurrencyCode]Currency
+	added        map[CurrencyCode]bool    // codes set with AddCurrency, which win over the registry
 	exchangeRates map[string]ExchangeRate // key: "FROM/TO"
+	precisions   map[CurrencyCode]int     // decimal place overrides set with SetCurrencyPrecision
 	defaultRounding RoundingMode
+	autoInverse  bool // invert the opposite pair when a rate is missing
+	baseCurrency CurrencyCode // triangulation currency for pairs without a rate
+	maxRateAge   time.Duration   // 0 disables staleness checks
+	staleRatePolicy StaleRatePolicy
+	
+	// RoundingMode is how Convert, ConvertBatch, SumInCurrency and Allocate round
+	// amounts to the target currency's precision. The zero value is
+	// utils.RoundHalfUp; SetDefaultRounding also updates it.
+	RoundingMode utils.RoundingMode
 }
 
 // NewCalculator creates a new currency calculator with default currencies and settings.
-// Initializes the calculator with commonly used currencies (USD, EUR, IDR, JPY, GBP, SGD, MYR)
+// Initializes the calculator with commonly used currencies (USD, EUR, IDR, JPY, GBP, SGD, MYR, BHD)
 // and sets up default formatting rules for each currency.
 //
 // Returns:
@@ -82,6 +102,7 @@ type Calculator struct {
 //   - GBP (British Pound) - £100.50
 //   - SGD (Singapore Dollar) - S$100.50
 //   - MYR (Malaysian Ringgit) - RM100.50
+//   - BHD (Bahraini Dinar) - BD 100.500
 //
 // Example:
 //	calc := NewCalculator()
@@ -91,8 +112,13 @@ type Calculator struct {
 func NewCalculator() *Calculator {
 	c := &Calculator{
 		currencies:      make(map[CurrencyCode]Currency),
+		added:           make(map[CurrencyCode]bool),
 		exchangeRates:   make(map[string]ExchangeRate),
+		precisions:      make(map[CurrencyCode]int),
 		defaultRounding: RoundingModeHalfUp,
+		RoundingMode:    utils.RoundHalfUp,
+		autoInverse:     true,
+		baseCurrency:    USD,
 	}
 	
 	// Initialize with default currencies
@@ -113,6 +139,7 @@ func NewCalculator() *Calculator {
 //   - GBP: 2 decimals, comma thousands separator, pound symbol prefix
 //   - SGD: 2 decimals, comma thousands separator, S$ prefix
 //   - MYR: 2 decimals, comma thousands separator, RM prefix
+//   - BHD: 3 decimals, comma thousands separator, BD prefix with space
 //
 // This method is called automatically by NewCalculator().
 func (c *Calculator) initializeDefaultCurrencies() {
@@ -187,6 +214,16 @@ func (c *Calculator) initializeDefaultCurrencies() {
 			SymbolFirst:   true,
 			SpaceBetween:  false,
 		},
+		{
+			Code:          BHD,
+			Name:          "Bahraini Dinar",
+			Symbol:        "BD",
+			DecimalPlaces: 3, // Dinar is divided into 1000 fils
+			ThousandsSep:  ",",
+			DecimalSep:    ".",
+			SymbolFirst:   true,
+			SpaceBetween:  true,
+		},
 	}
 	
 	for _, currency := range defaultCurrencies {
@@ -225,8 +262,34 @@ func (c *Calculator) initializeDefaultCurrencies() {
 //     NegativeStyle: "parentheses",
 //   }
 //   Format(Money{-100, USD}, options) → "(100.00 USD)"
+//
+// Accounting negatives:
+//   NegativeFormat takes precedence over NegativeStyle and decorates the whole
+//   formatted amount, so the symbol stays inside the parentheses.
+//   Format(Money{-1234.56, USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}) → "($1,234.56)"
+//   Format(Money{-1234.56, USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatMinusAfter}) → "$1,234.56-"
+//
+// Symbol fallback:
+//   With FallbackToCode set, a currency without a known symbol is prefixed with its
+//   ISO code instead, and a currency that is not registered is formatted with default
+//   separators rather than returning an error.
+//   Format(Money{1500, "XYZ"}, &FormatOptions{ShowSymbol: true, FallbackToCode: true}) → "XYZ 1,500.00"
 func (c *Calculator) Format(money Money, options *FormatOptions) (string, error) {
-	currency, exists := c.currencies[money.Currency]
+	c.mu.RLock()
+	currency, exists := c.currencyLocked(money.Currency)
+	rounding := c.defaultRounding
+	c.mu.RUnlock()
+	if !exists && options != nil && options.FallbackToCode && money.Currency != "" {
+		currency = Currency{
+			Code:          money.Currency,
+			Symbol:        CurrencySymbols[money.Currency],
+			DecimalPlaces: GetCurrencyDecimalPlaces(money.Currency),
+			ThousandsSep:  DefaultThousandsSep,
+			DecimalSep:    DefaultDecimalSep,
+			SymbolFirst:   true,
+		}
+		exists = true
+	}
 	if !exists {
 		return "", &CurrencyError{
 			Type:      "unsupported_currency",
@@ -268,13 +331,16 @@ func (c *Calculator) Format(money Money, options *FormatOptions) (string, error)
 	}
 	
 	// Round the amount
-	roundedAmount := c.roundAmount(money.Amount, precision, c.defaultRounding)
+	roundedAmount := c.roundAmount(money.Amount, precision, rounding)
 	
 	// Format the number
 	numberStr := c.formatNumber(roundedAmount, precision, thousandsSep, decimalSep)
 	
-	// Handle negative amounts
-	if roundedAmount < 0 {
+	// Handle negative amounts; NegativeFormat is applied once the symbol is added
+	accountingNegative := roundedAmount < 0 && options.NegativeFormat != ""
+	if accountingNegative {
+		numberStr = strings.TrimPrefix(numberStr, "-")
+	} else if roundedAmount < 0 {
 		numberStr = strings.TrimPrefix(numberStr, "-")
 		switch options.NegativeStyle {
 		case "parentheses":
@@ -302,9 +368,12 @@ func (c *Calculator) Format(money Money, options *FormatOptions) (string, error)
 			}
 			result += string(money.Currency)
 		}
+	} else if options.ShowSymbol && currency.Symbol == "" && options.FallbackToCode {
+		// No known symbol: prefix the ISO code
+		result = string(money.Currency) + " " + numberStr
 	} else if options.ShowSymbol {
 		symbol := currency.Symbol
-		if money.Amount < 0 && options.NegativeStyle == "minus_symbol" {
+		if money.Amount < 0 && options.NegativeStyle == "minus_symbol" && !accountingNegative {
 			symbol = "-" + symbol
 		}
 		
@@ -325,6 +394,17 @@ func (c *Calculator) Format(money Money, options *FormatOptions) (string, error)
 		result = numberStr
 	}
 	
+	if accountingNegative {
+		switch options.NegativeFormat {
+		case NegativeFormatParentheses:
+			result = "(" + result + ")"
+		case NegativeFormatMinusAfter:
+			result += "-"
+		default: // NegativeFormatMinus
+			result = "-" + result
+		}
+	}
+	
 	return result, nil
 }
 
@@ -442,27 +522,36 @@ func (c *Calculator) addThousandsSeparators(numberStr, separator string) string
 // Example:
 //   - roundAmount(1.235, 2, RoundingModeHalfUp) → 1.24
 func (c *Calculator) roundAmount(amount float64, precision int, mode RoundingMode) float64 {
-	// Convert currency rounding mode to utils rounding mode
-	var utilsMode utils.RoundingMode
+	return utils.RoundWithMode(amount, precision, toUtilsRoundingMode(mode))
+}
+
+// toUtilsRoundingMode converts a currency rounding mode to the utils rounding
+// mode that implements it.
+//
+// Parameters:
+//   - mode: currency rounding mode
+//
+// Returns:
+//   - utils.RoundingMode: equivalent utils mode; RoundingModeTruncate maps to
+//     utils.RoundDown and unknown modes to utils.RoundHalfUp
+func toUtilsRoundingMode(mode RoundingMode) utils.RoundingMode {
 	switch mode {
 	case RoundingModeHalfUp:
-		utilsMode = utils.RoundHalfUp
+		return utils.RoundHalfUp
 	case RoundingModeHalfDown:
-		utilsMode = utils.RoundHalfDown
+		return utils.RoundHalfDown
 	case RoundingModeHalfEven:
-		utilsMode = utils.RoundHalfEven
+		return utils.RoundHalfEven
 	case RoundingModeUp:
-		utilsMode = utils.RoundUp
+		return utils.RoundUp
 	case RoundingModeDown:
-		utilsMode = utils.RoundDown
+		return utils.RoundDown
 	case RoundingModeTruncate:
 		// Utils package doesn't have truncate, use RoundDown as closest equivalent
-		utilsMode = utils.RoundDown
+		return utils.RoundDown
 	default:
-		utilsMode = utils.RoundHalfUp
+		return utils.RoundHalfUp
 	}
-	
-	return utils.RoundWithMode(amount, precision, utilsMode)
 }
 
 // Convert converts money from one currency to another using stored exchange rates.
@@ -477,10 +566,15 @@ func (c *Calculator) roundAmount(amount float64, precision int, mode RoundingMod
 //   - error: conversion error if exchange rate not found
 //
 // Features:
-//   - Automatic rounding to target currency decimal places
+//   - Automatic rounding to target currency decimal places with the RoundingMode field
 //   - Exchange rate tracking and source attribution
 //   - Identity conversion for same currency (rate = 1.0)
-//   - Timestamp recording for audit trails
+//   - Inverse of the opposite pair when only that direction is set (see SetAutoInverse)
+//   - Triangulation through the base currency (see SetBaseCurrency) when neither
+//     direction is set, rounded to the target precision only once
+//   - Rate, source, rate timestamp and derivation recorded for audit trails
+//   - Rate age checked against SetMaxRateAge: a warning on the result, or an
+//     error wrapping ErrStaleRate
 //
 // Example:
 //   result, err := calc.Convert(ConversionInput{
@@ -489,31 +583,96 @@ func (c *Calculator) roundAmount(amount float64, precision int, mode RoundingMod
 //     To:     EUR,
 //   })
 //   // result.ConvertedAmount.Amount = 85.0 (if rate is 0.85)
-//   // result.ExchangeRate.Rate = 0.85
+//   // result.RateUsed = 0.85, result.RateSource = "ECB"
+//   // result.RateDerivation = RateDerivationDirect
+//
+// Triangulated example (only USD/IDR and USD/EUR set):
+//   result, err := calc.Convert(ConversionInput{Amount: 150000, From: IDR, To: EUR})
+//   // result.IntermediateCurrency = USD, result.FirstLeg = IDR→USD, result.SecondLeg = USD→EUR
+//   // result.ConvertedAmount.Amount = 8.5
 func (c *Calculator) Convert(input ConversionInput) (*ConversionResult, error) {
-	if input.From == input.To {
-		return &ConversionResult{
-			OriginalAmount:  Money{Amount: input.Amount, Currency: input.From},
-			ConvertedAmount: Money{Amount: input.Amount, Currency: input.To},
-			ExchangeRate: ExchangeRate{
-				From:      input.From,
-				To:        input.To,
-				Rate:      1.0,
-				Timestamp: time.Now(),
-				Source:    "identity",
-			},
-			ConvertedAt: time.Now(),
-		}, nil
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	return c.convertLocked(input, c.resolveConversionRateLocked(input.From, input.To))
+}
+
+// conversionPair identifies a source and target currency.
+type conversionPair struct {
+	from CurrencyCode
+	to   CurrencyCode
+}
+
+// resolvedRate is the outcome of looking up the rate for a conversionPair, kept so
+// a batch can apply one lookup to every input with that pair.
+type resolvedRate struct {
+	rate ExchangeRate
+	legs []ExchangeRate
+	err  error
+}
+
+// resolveConversionRateLocked looks up the rate Convert applies to a pair: the
+// identity rate for same-currency conversions, otherwise resolveRateLocked.
+// Callers must hold c.mu.
+//
+// Parameters:
+//   - from: source currency code
+//   - to: target currency code
+//
+// Returns:
+//   - resolvedRate: rate, triangulation legs and lookup error for the pair
+func (c *Calculator) resolveConversionRateLocked(from, to CurrencyCode) resolvedRate {
+	if from == to {
+		return resolvedRate{rate: ExchangeRate{
+			From:       from,
+			To:         to,
+			Rate:       1.0,
+			Timestamp:  time.Now(),
+			Source:     "identity",
+			Derivation: RateDerivationIdentity,
+		}}
 	}
 	
-	// Get exchange rate
-	rateKey := string(input.From) + "/" + string(input.To)
-	exchangeRate, exists := c.exchangeRates[rateKey]
-	if !exists {
-		return nil, &CurrencyError{
-			Type:      "exchange_rate_not_found",
-			Message:   fmt.Sprintf("Exchange rate not found for %s to %s", input.From, input.To),
-			Timestamp: time.Now(),
+	rate, legs, err := c.resolveRateLocked(from, to)
+	return resolvedRate{rate: rate, legs: legs, err: err}
+}
+
+// convertLocked converts one amount with an already resolved rate, checking the
+// rate's age and rounding to the target currency. Callers must hold c.mu.
+//
+// Parameters:
+//   - input: conversion parameters
+//   - resolved: rate for input's currency pair from resolveConversionRateLocked
+//
+// Returns:
+//   - *ConversionResult: detailed conversion result with exchange rate info
+//   - error: the lookup error, or a stale_exchange_rate error under StaleRateError
+func (c *Calculator) convertLocked(input ConversionInput, resolved resolvedRate) (*ConversionResult, error) {
+	if resolved.err != nil {
+		return nil, resolved.err
+	}
+	exchangeRate := resolved.rate
+	precision := c.currencyPrecisionLocked(input.To)
+	
+	if exchangeRate.Derivation == RateDerivationIdentity {
+		convertedAmount := utils.RoundWithMode(input.Amount, precision, c.RoundingMode)
+		return newConversionResult(input, convertedAmount, exchangeRate), nil
+	}
+	
+	// Check the rate is recent enough
+	age := time.Since(exchangeRate.Timestamp)
+	stale := c.maxRateAge > 0 && age > c.maxRateAge
+	warning := ""
+	if stale {
+		warning = fmt.Sprintf("Exchange rate for %s to %s is %s old, older than the maximum of %s",
+			input.From, input.To, age.Round(time.Second), c.maxRateAge)
+		if c.staleRatePolicy == StaleRateError {
+			return nil, &CurrencyError{
+				Type:      "stale_exchange_rate",
+				Message:   warning,
+				Causes:    []error{ErrStaleRate},
+				Timestamp: time.Now(),
+			}
 		}
 	}
 	
@@ -521,17 +680,334 @@ func (c *Calculator) Convert(input ConversionInput) (*ConversionResult, error) {
 	convertedAmount := input.Amount * exchangeRate.Rate
 	
 	// Round according to target currency
-	targetCurrency, exists := c.currencies[input.To]
-	if exists {
-		convertedAmount = c.roundAmount(convertedAmount, targetCurrency.DecimalPlaces, c.defaultRounding)
+	convertedAmount = utils.RoundWithMode(convertedAmount, precision, c.RoundingMode)
+	
+	result := newConversionResult(input, convertedAmount, exchangeRate)
+	result.RateAge = age
+	result.StaleRate = stale
+	result.Warning = warning
+	if len(resolved.legs) == 2 {
+		result.IntermediateCurrency = resolved.legs[0].To
+		result.FirstLeg = &resolved.legs[0]
+		result.SecondLeg = &resolved.legs[1]
+	}
+	return result, nil
+}
+
+// resolveRateLocked returns the rate for a pair from lookupRateLocked, or, when
+// the pair has no usable rate and neither currency is the base currency, the
+// product of the from→base and base→to rates together with those two legs. The
+// combined rate carries the older leg's timestamp. Callers must hold c.mu.
+//
+// Parameters:
+//   - from: source currency code
+//   - to: target currency code
+//
+// Returns:
+//   - ExchangeRate: rate to apply
+//   - []ExchangeRate: the two legs for a triangulated rate, else nil
+//   - error: exchange_rate_not_found error naming the missing rates
+func (c *Calculator) resolveRateLocked(from, to CurrencyCode) (ExchangeRate, []ExchangeRate, error) {
+	rate, err := c.lookupRateLocked(from, to)
+	if err == nil {
+		return rate, nil, nil
+	}
+	base := c.baseCurrency
+	if base == "" || from == base || to == base {
+		return ExchangeRate{}, nil, err
 	}
 	
+	first, firstErr := c.lookupRateLocked(from, base)
+	second, secondErr := c.lookupRateLocked(base, to)
+	if firstErr != nil || secondErr != nil {
+		missing := []string{}
+		if firstErr != nil {
+			missing = append(missing, string(from)+"/"+string(base))
+		}
+		if secondErr != nil {
+			missing = append(missing, string(base)+"/"+string(to))
+		}
+		return ExchangeRate{}, nil, &CurrencyError{
+			Type: "exchange_rate_not_found",
+			Message: fmt.Sprintf("Exchange rate not found for %s to %s: no rate for the pair and no path through base currency %s (missing %s)",
+				from, to, base, strings.Join(missing, " and ")),
+			Timestamp: time.Now(),
+		}
+	}
+	
+	timestamp := first.Timestamp
+	if second.Timestamp.Before(timestamp) {
+		timestamp = second.Timestamp
+	}
+	source := first.Source
+	if second.Source != first.Source {
+		source += "," + second.Source
+	}
+	return ExchangeRate{
+		From:       from,
+		To:         to,
+		Rate:       first.Rate * second.Rate,
+		Timestamp:  timestamp,
+		Source:     source,
+		Derivation: RateDerivationTriangulated,
+	}, []ExchangeRate{first, second}, nil
+}
+
+// newConversionResult builds a conversion result that records the rate applied,
+// its source, when it was set and how it was derived.
+//
+// Parameters:
+//   - input: conversion parameters
+//   - convertedAmount: rounded amount in the target currency
+//   - rate: exchange rate applied
+//
+// Returns:
+//   - *ConversionResult: result with the rate audit fields filled in
+func newConversionResult(input ConversionInput, convertedAmount float64, rate ExchangeRate) *ConversionResult {
 	return &ConversionResult{
 		OriginalAmount:  Money{Amount: input.Amount, Currency: input.From},
 		ConvertedAmount: Money{Amount: convertedAmount, Currency: input.To},
-		ExchangeRate:    exchangeRate,
+		ExchangeRate:    rate,
+		RateUsed:        rate.Rate,
+		RateSource:      rate.Source,
+		RateTimestamp:   rate.Timestamp,
+		RateDerivation:  rate.Derivation,
+		Inverted:        rate.Derivation == RateDerivationInverse,
 		ConvertedAt:     time.Now(),
-	}, nil
+	}
+}
+
+// ConvertBatch converts many amounts concurrently using a pool of worker goroutines.
+// The rate for each currency pair is looked up once and reused for every input
+// with that pair, so a cart of a hundred lines in two currencies costs two
+// lookups. Results are otherwise identical to calling Convert sequentially.
+//
+// Parameters:
+//   - inputs: conversion parameters to process
+//
+// Returns:
+//   - []ConversionResult: conversion results in the same order as inputs
+//   - []error: conversion errors in the same order as inputs (nil on success)
+//
+// Both slices always have len(inputs) entries. When errs[i] is non-nil,
+// results[i] is the zero ConversionResult.
+//
+// Example:
+//   results, errs := calc.ConvertBatch([]ConversionInput{
+//     {Amount: 100, From: USD, To: EUR},
+//     {Amount: 250, From: USD, To: IDR},
+//   })
+//   for i := range results {
+//     if errs[i] != nil {
+//       continue
+//     }
+//     fmt.Println(results[i].ConvertedAmount.Amount)
+//   }
+func (c *Calculator) ConvertBatch(inputs []ConversionInput) ([]ConversionResult, []error) {
+	results := make([]ConversionResult, len(inputs))
+	errs := make([]error, len(inputs))
+	if len(inputs) == 0 {
+		return results, errs
+	}
+	
+	// Resolve each pair once; workers only read the cache
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rates := make(map[conversionPair]resolvedRate)
+	for _, input := range inputs {
+		pair := conversionPair{from: input.From, to: input.To}
+		if _, ok := rates[pair]; !ok {
+			rates[pair] = c.resolveConversionRateLocked(input.From, input.To)
+		}
+	}
+	
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(inputs) {
+		workers = len(inputs)
+	}
+	
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				pair := conversionPair{from: inputs[i].From, to: inputs[i].To}
+				result, err := c.convertLocked(inputs[i], rates[pair])
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				results[i] = *result
+			}
+		}()
+	}
+	
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	
+	return results, errs
+}
+
+// ConvertAll converts a batch of amounts like ConvertBatch, aggregates the rate
+// metadata of the conversions and reports failures as a single combined error,
+// which suits callers that only need to know whether every line converted.
+//
+// Parameters:
+//   - inputs: conversion parameters to process
+//
+// Returns:
+//   - *BatchConversionResult: results in the same order as inputs, where entries
+//     whose conversion failed are the zero ConversionResult, plus the rates,
+//     sources and stale-rate warnings across the batch
+//   - error: nil when every conversion succeeded, otherwise a *CurrencyError of
+//     type "batch_conversion_failed" whose Causes hold each failure with its index
+//
+// Example:
+//   batch, err := calc.ConvertAll(lineItems)
+//   if err != nil {
+//     log.Printf("%d lines were not converted: %v", batch.Failed, err)
+//   }
+//   for _, result := range batch.Results {
+//     fmt.Println(result.ConvertedAmount.Amount, result.ExchangeRate.Source)
+//   }
+//   fmt.Println(batch.Sources) // e.g. [ecb bi]
+func (c *Calculator) ConvertAll(inputs []ConversionInput) (*BatchConversionResult, error) {
+	results, errs := c.ConvertBatch(inputs)
+	return summarizeConversions(results, errs), combineConversionErrors(errs)
+}
+
+// summarizeConversions aggregates the rate metadata of a batch of conversions.
+//
+// Parameters:
+//   - results: conversion results indexed like the batch inputs
+//   - errs: errors indexed like the batch inputs, nil for successes
+//
+// Returns:
+//   - *BatchConversionResult: results with counts, distinct rates, sources and warnings
+func summarizeConversions(results []ConversionResult, errs []error) *BatchConversionResult {
+	batch := &BatchConversionResult{Results: results}
+	seenPairs := make(map[conversionPair]bool)
+	seenSources := make(map[string]bool)
+	addSource := func(source string) {
+		if !seenSources[source] {
+			seenSources[source] = true
+			batch.Sources = append(batch.Sources, source)
+		}
+	}
+	
+	for i, result := range results {
+		if errs[i] != nil {
+			batch.Failed++
+			continue
+		}
+		batch.Converted++
+		if result.StaleRate {
+			batch.StaleConversions++
+		}
+		if result.RateDerivation == RateDerivationIdentity {
+			continue
+		}
+		
+		pair := conversionPair{from: result.OriginalAmount.Currency, to: result.ConvertedAmount.Currency}
+		if seenPairs[pair] {
+			continue
+		}
+		seenPairs[pair] = true
+		batch.Rates = append(batch.Rates, result.ExchangeRate)
+		if result.FirstLeg != nil && result.SecondLeg != nil {
+			addSource(result.FirstLeg.Source)
+			addSource(result.SecondLeg.Source)
+		} else {
+			addSource(result.ExchangeRate.Source)
+		}
+		if batch.OldestRateTimestamp.IsZero() || result.RateTimestamp.Before(batch.OldestRateTimestamp) {
+			batch.OldestRateTimestamp = result.RateTimestamp
+		}
+		if result.Warning != "" {
+			batch.Warnings = append(batch.Warnings, result.Warning)
+		}
+	}
+	return batch
+}
+
+// SumInCurrency converts every amount to the target currency and adds them up.
+// Each leg is rounded to the target currency as it is converted, and the total is
+// rounded to the target currency's precision again.
+//
+// Parameters:
+//   - monies: amounts in any currencies with known exchange rates to target
+//   - target: currency of the total, which must be a known currency
+//
+// Returns:
+//   - Money: total in the target currency
+//   - error: currency_not_found error for an unknown target, or the combined
+//     conversion error if any amount could not be converted
+//
+// Example:
+//   total, err := calc.SumInCurrency([]Money{
+//     {Amount: 10, Currency: USD},
+//     {Amount: 5, Currency: EUR},
+//   }, IDR)
+func (c *Calculator) SumInCurrency(monies []Money, target CurrencyCode) (Money, error) {
+	if _, err := c.GetCurrency(target); err != nil {
+		return Money{}, err
+	}
+	
+	inputs := make([]ConversionInput, len(monies))
+	for i, money := range monies {
+		inputs[i] = ConversionInput{Amount: money.Amount, From: money.Currency, To: target}
+	}
+	
+	batch, err := c.ConvertAll(inputs)
+	if err != nil {
+		return Money{}, err
+	}
+	
+	total := 0.0
+	for _, result := range batch.Results {
+		total += result.ConvertedAmount.Amount
+	}
+	
+	c.mu.RLock()
+	total = utils.RoundWithMode(total, c.currencyPrecisionLocked(target), c.RoundingMode)
+	c.mu.RUnlock()
+	
+	return Money{Amount: total, Currency: target}, nil
+}
+
+// combineConversionErrors merges per-input conversion errors into one error.
+//
+// Parameters:
+//   - errs: errors indexed like the batch inputs, nil for successes
+//
+// Returns:
+//   - error: nil if all entries are nil, otherwise a *CurrencyError listing the failures
+func combineConversionErrors(errs []error) error {
+	var causes []error
+	for i, err := range errs {
+		if err != nil {
+			causes = append(causes, fmt.Errorf("conversion %d: %w", i, err))
+		}
+	}
+	if len(causes) == 0 {
+		return nil
+	}
+	
+	messages := make([]string, len(causes))
+	for i, cause := range causes {
+		messages[i] = cause.Error()
+	}
+	return &CurrencyError{
+		Type:      "batch_conversion_failed",
+		Message:   fmt.Sprintf("%d of %d conversions failed: %s", len(causes), len(errs), strings.Join(messages, "; ")),
+		Causes:    causes,
+		Timestamp: time.Now(),
+	}
 }
 
 // Add performs addition of two money amounts in the same currency.
@@ -556,7 +1032,7 @@ func (c *Calculator) Add(amount1, amount2 Money) (*ArithmeticResult, error) {
 		Amount1:   amount1,
 		Amount2:   amount2,
 		Operation: OperationAdd,
-		Rounding:  c.defaultRounding,
+		Rounding:  c.getDefaultRounding(),
 	})
 }
 
@@ -582,7 +1058,7 @@ func (c *Calculator) Subtract(amount1, amount2 Money) (*ArithmeticResult, error)
 		Amount1:   amount1,
 		Amount2:   amount2,
 		Operation: OperationSubtract,
-		Rounding:  c.defaultRounding,
+		Rounding:  c.getDefaultRounding(),
 	})
 }
 
@@ -608,7 +1084,7 @@ func (c *Calculator) Multiply(amount Money, factor float64) (*ArithmeticResult,
 		Amount1:   amount,
 		Amount2:   Money{Amount: factor, Currency: amount.Currency},
 		Operation: OperationMultiply,
-		Rounding:  c.defaultRounding,
+		Rounding:  c.getDefaultRounding(),
 	})
 }
 
@@ -642,10 +1118,113 @@ func (c *Calculator) Divide(amount Money, divisor float64) (*ArithmeticResult, e
 		Amount1:   amount,
 		Amount2:   Money{Amount: divisor, Currency: amount.Currency},
 		Operation: OperationDivide,
-		Rounding:  c.defaultRounding,
+		Rounding:  c.getDefaultRounding(),
 	})
 }
 
+// Allocate splits a money amount by ratios without losing minor units.
+// The amount is rounded to the currency's precision with the calculator's
+// RoundingMode, each share is rounded to that precision and the leftover minor
+// units are handed out with utils.AllocateRemainderWithPrecision, so the parts
+// always sum exactly to the original amount. Leftover units go to the shares
+// with the largest rounding remainders; ties go to the earlier share, so the
+// result is deterministic.
+//
+// Parameters:
+//   - money: money amount to allocate
+//   - ratios: relative size of each share (non-negative, not all zero)
+//
+// Returns:
+//   - []Money: one share per ratio, in the order of ratios
+//   - error: if ratios is empty, contains a negative value or sums to zero
+//
+// Example:
+//   parts, err := calc.Allocate(Money{Amount: 10.00, Currency: USD}, []float64{1, 1, 1})
+//   // parts = 3.34, 3.33, 3.33 USD
+//   parts, err = calc.Allocate(Money{Amount: 100, Currency: JPY}, []float64{70, 30})
+//   // parts = 70, 30 JPY
+func (c *Calculator) Allocate(money Money, ratios []float64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, &CurrencyError{
+			Type:      "empty_input",
+			Message:   "Ratios array cannot be empty",
+			Currency:  money.Currency,
+			Timestamp: time.Now(),
+		}
+	}
+	
+	var totalRatio float64
+	for _, ratio := range ratios {
+		if ratio < 0 || math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+			return nil, &CurrencyError{
+				Type:      "invalid_ratio",
+				Message:   fmt.Sprintf("Ratios must be non-negative finite numbers, got %v", ratio),
+				Currency:  money.Currency,
+				Timestamp: time.Now(),
+			}
+		}
+		totalRatio += ratio
+	}
+	if totalRatio == 0 {
+		return nil, &CurrencyError{
+			Type:      "invalid_ratio",
+			Message:   "Total ratio cannot be zero",
+			Currency:  money.Currency,
+			Timestamp: time.Now(),
+		}
+	}
+	
+	c.mu.RLock()
+	precision := c.currencyPrecisionLocked(money.Currency)
+	mode := c.RoundingMode
+	c.mu.RUnlock()
+	
+	total := utils.RoundWithMode(money.Amount, precision, mode)
+	shares := make([]float64, len(ratios))
+	for i, ratio := range ratios {
+		shares[i] = total * ratio / totalRatio
+	}
+	
+	amounts := utils.AllocateRemainderWithPrecision(total, shares, precision)
+	result := make([]Money, len(amounts))
+	for i, amount := range amounts {
+		result[i] = Money{Amount: amount, Currency: money.Currency}
+	}
+	return result, nil
+}
+
+// AllocateEqual splits a money amount into n equal shares without losing minor
+// units. It is Allocate with n equal ratios, so leftover minor units go to the
+// first shares.
+//
+// Parameters:
+//   - money: money amount to split
+//   - n: number of shares (must be > 0)
+//
+// Returns:
+//   - []Money: n shares summing exactly to the amount
+//   - error: if n is not positive
+//
+// Example:
+//   installments, err := calc.AllocateEqual(Money{Amount: 100.00, Currency: USD}, 3)
+//   // installments = 33.34, 33.33, 33.33 USD
+func (c *Calculator) AllocateEqual(money Money, n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, &CurrencyError{
+			Type:      "invalid_parts",
+			Message:   fmt.Sprintf("Number of parts must be positive, got %d", n),
+			Currency:  money.Currency,
+			Timestamp: time.Now(),
+		}
+	}
+	
+	ratios := make([]float64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return c.Allocate(money, ratios)
+}
+
 // performArithmetic is a helper function for arithmetic operations.
 // Centralizes arithmetic logic with currency validation and proper rounding.
 // Used internally by Add, Subtract, Multiply, and Divide methods.
@@ -664,11 +1243,7 @@ func (c *Calculator) Divide(amount Money, divisor float64) (*ArithmeticResult, e
 //   - Operation tracking with timestamps
 func (c *Calculator) performArithmetic(input ArithmeticInput) (*ArithmeticResult, error) {
 	if input.Amount1.Currency != input.Amount2.Currency {
-		return nil, &CurrencyError{
-			Type:      "currency_mismatch",
-			Message:   fmt.Sprintf("Cannot perform %s operation on different currencies: %s and %s", input.Operation, input.Amount1.Currency, input.Amount2.Currency),
-			Timestamp: time.Now(),
-		}
+		return nil, currencyMismatchError(fmt.Sprintf("Cannot perform %s operation on different currencies: %s and %s", input.Operation, input.Amount1.Currency, input.Amount2.Currency))
 	}
 	
 	var result float64
@@ -690,10 +1265,10 @@ func (c *Calculator) performArithmetic(input ArithmeticInput) (*ArithmeticResult
 	}
 	
 	// Round the result
-	currency, exists := c.currencies[input.Amount1.Currency]
-	if exists {
-		result = c.roundAmount(result, currency.DecimalPlaces, input.Rounding)
-	}
+	c.mu.RLock()
+	precision := c.currencyPrecisionLocked(input.Amount1.Currency)
+	c.mu.RUnlock()
+	result = c.roundAmount(result, precision, input.Rounding)
 	
 	return &ArithmeticResult{
 		Result:       Money{Amount: result, Currency: input.Amount1.Currency},
@@ -705,6 +1280,9 @@ func (c *Calculator) performArithmetic(input ArithmeticInput) (*ArithmeticResult
 
 // Compare compares two money amounts in the same currency.
 // Returns comparison result indicating relative magnitude of the amounts.
+// Amounts closer than half of the currency's smallest unit (0.005 for USD,
+// 0.5 for JPY) compare as equal, so float noise such as 0.1+0.2 vs 0.3 does
+// not make cart totals differ.
 //
 // Parameters:
 //   - amount1: first money amount
@@ -712,7 +1290,7 @@ func (c *Calculator) performArithmetic(input ArithmeticInput) (*ArithmeticResult
 //
 // Returns:
 //   - *ComparisonResult: detailed comparison with relationship and timestamp
-//   - error: comparison error if currencies don't match
+//   - error: *CurrencyError wrapping ErrCurrencyMismatch if currencies don't match
 //
 // Comparison Values:
 //   - -1: amount1 < amount2
@@ -727,29 +1305,117 @@ func (c *Calculator) performArithmetic(input ArithmeticInput) (*ArithmeticResult
 //   // result.Comparison = 1 (first amount is greater)
 func (c *Calculator) Compare(amount1, amount2 Money) (*ComparisonResult, error) {
 	if amount1.Currency != amount2.Currency {
-		return nil, &CurrencyError{
-			Type:      "currency_mismatch",
-			Message:   fmt.Sprintf("Cannot compare different currencies: %s and %s", amount1.Currency, amount2.Currency),
-			Timestamp: time.Now(),
-		}
+		return nil, currencyMismatchError(fmt.Sprintf("Cannot compare different currencies: %s and %s", amount1.Currency, amount2.Currency))
 	}
 	
 	difference := amount1.Amount - amount2.Amount
+	c.mu.RLock()
+	tolerance := math.Pow(10, -float64(c.currencyPrecisionLocked(amount1.Currency))) / 2
+	c.mu.RUnlock()
+	
+	comparison := 0
+	if difference >= tolerance {
+		comparison = 1
+	} else if difference <= -tolerance {
+		comparison = -1
+	}
 	
 	return &ComparisonResult{
 		Amount1:    amount1,
 		Amount2:    amount2,
-		IsEqual:    math.Abs(difference) < 0.001, // Small tolerance for floating point comparison
-		IsGreater:  difference > 0.001,
-		IsLess:     difference < -0.001,
+		Comparison: comparison,
+		IsEqual:    comparison == 0,
+		IsGreater:  comparison > 0,
+		IsLess:     comparison < 0,
 		Difference: Money{Amount: math.Abs(difference), Currency: amount1.Currency},
 		ComparedAt: time.Now(),
 	}, nil
 }
 
+// Equals reports whether two amounts in the same currency are equal within
+// the currency's precision.
+//
+// Parameters:
+//   - amount1: first money amount
+//   - amount2: second money amount (must be same currency)
+//
+// Returns:
+//   - bool: true if the amounts are equal
+//   - error: *CurrencyError wrapping ErrCurrencyMismatch if currencies don't match
+//
+// Example:
+//   equal, _ := calc.Equals(
+//     Money{Amount: 0.1 + 0.2, Currency: USD},
+//     Money{Amount: 0.3, Currency: USD},
+//   )
+//   // equal = true
+func (c *Calculator) Equals(amount1, amount2 Money) (bool, error) {
+	result, err := c.Compare(amount1, amount2)
+	if err != nil {
+		return false, err
+	}
+	return result.IsEqual, nil
+}
+
+// GreaterThan reports whether amount1 exceeds amount2 by at least half of the
+// currency's smallest unit.
+//
+// Parameters:
+//   - amount1: first money amount
+//   - amount2: second money amount (must be same currency)
+//
+// Returns:
+//   - bool: true if amount1 is greater
+//   - error: *CurrencyError wrapping ErrCurrencyMismatch if currencies don't match
+func (c *Calculator) GreaterThan(amount1, amount2 Money) (bool, error) {
+	result, err := c.Compare(amount1, amount2)
+	if err != nil {
+		return false, err
+	}
+	return result.IsGreater, nil
+}
+
+// LessThan reports whether amount1 is below amount2 by at least half of the
+// currency's smallest unit.
+//
+// Parameters:
+//   - amount1: first money amount
+//   - amount2: second money amount (must be same currency)
+//
+// Returns:
+//   - bool: true if amount1 is less
+//   - error: *CurrencyError wrapping ErrCurrencyMismatch if currencies don't match
+func (c *Calculator) LessThan(amount1, amount2 Money) (bool, error) {
+	result, err := c.Compare(amount1, amount2)
+	if err != nil {
+		return false, err
+	}
+	return result.IsLess, nil
+}
+
+// currencyMismatchError builds the error returned when an operation receives
+// amounts in different currencies. It has Type "currency_mismatch" and wraps
+// ErrCurrencyMismatch so callers can match it with errors.Is.
+//
+// Parameters:
+//   - message: description naming the operation and currencies
+//
+// Returns:
+//   - *CurrencyError: the mismatch error
+func currencyMismatchError(message string) *CurrencyError {
+	return &CurrencyError{
+		Type:      "currency_mismatch",
+		Message:   message,
+		Causes:    []error{ErrCurrencyMismatch},
+		Timestamp: time.Now(),
+	}
+}
+
 // SetExchangeRate sets the exchange rate between two currencies.
-// Updates the internal exchange rate table with bidirectional rates.
-// Automatically calculates and stores the inverse rate.
+// Only the given direction is stored. Lookups for the opposite direction use
+// 1/rate unless a rate for that direction is set too or automatic inversion is
+// disabled with SetAutoInverse, so separate bid and ask rates are never
+// overwritten by each other's inverse.
 //
 // Parameters:
 //   - from: source currency code
@@ -758,31 +1424,175 @@ func (c *Calculator) Compare(amount1, amount2 Money) (*ComparisonResult, error)
 //   - source: rate source identifier for tracking
 //
 // Features:
-//   - Bidirectional rate storage (from->to and to->from)
 //   - Rate validation (must be positive)
 //   - Source attribution for rate tracking
-//   - Automatic inverse rate calculation
+//   - Inverse lookups recorded with RateDerivationInverse
 //
 // Example:
 //   calc.SetExchangeRate(USD, EUR, 0.85, "ECB")
+//   calc.SetExchangeRate(EUR, USD, 1.17, "ECB") // preferred over 1/0.85 for EUR→USD
 func (c *Calculator) SetExchangeRate(from, to CurrencyCode, rate float64, source string) {
+	c.SetExchangeRateAt(from, to, rate, source, time.Now())
+}
+
+// SetExchangeRateAt sets an exchange rate like SetExchangeRate but records when
+// the rate was quoted, such as the timestamp from a rate feed, so its age is
+// measured from the quote rather than from when it was loaded.
+//
+// Parameters:
+//   - from: source currency code
+//   - to: target currency code
+//   - rate: exchange rate from source to target
+//   - source: rate source identifier for tracking
+//   - quotedAt: when the rate was published
+//
+// Example:
+//   calc.SetExchangeRateAt(USD, IDR, quote.Rate, "feed", quote.PublishedAt)
+func (c *Calculator) SetExchangeRateAt(from, to CurrencyCode, rate float64, source string, quotedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
 	rateKey := string(from) + "/" + string(to)
 	c.exchangeRates[rateKey] = ExchangeRate{
-		From:      from,
-		To:        to,
-		Rate:      rate,
-		Timestamp: time.Now(),
-		Source:    source,
+		From:       from,
+		To:         to,
+		Rate:       rate,
+		Timestamp:  quotedAt,
+		Source:     source,
+		Derivation: RateDerivationDirect,
+	}
+}
+
+// SetMaxRateAge sets how old a rate may be before Convert treats it as stale,
+// and whether a stale rate produces a warning on the result or an error. A
+// maxAge of 0 disables the check, which is the default.
+//
+// Parameters:
+//   - maxAge: maximum rate age; 0 to disable
+//   - policy: StaleRateWarn or StaleRateError
+//
+// Example:
+//   // Rates are pulled hourly; stop converting if the feed stalls for 3 hours
+//   calc.SetMaxRateAge(3*time.Hour, StaleRateError)
+func (c *Calculator) SetMaxRateAge(maxAge time.Duration, policy StaleRatePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.maxRateAge = maxAge
+	c.staleRatePolicy = policy
+}
+
+// GetRateInfo returns the rate Convert would use for a pair, with its source,
+// timestamp, age and whether it is older than the maximum rate age. Inverted and
+// triangulated rates are resolved as in Convert; a triangulated rate is as old
+// as its older leg.
+//
+// Parameters:
+//   - from: source currency code
+//   - to: target currency code
+//
+// Returns:
+//   - *RateInfo: rate, source and age
+//   - error: exchange_rate_not_found error if no rate can be resolved
+//
+// Example:
+//   info, err := calc.GetRateInfo(USD, IDR)
+//   // info.Rate = 15000, info.Source = "feed", info.Age = 12m
+func (c *Calculator) GetRateInfo(from, to CurrencyCode) (*RateInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	rate, _, err := c.resolveRateLocked(from, to)
+	if err != nil {
+		return nil, err
+	}
+	age := time.Since(rate.Timestamp)
+	return &RateInfo{
+		From:       from,
+		To:         to,
+		Rate:       rate.Rate,
+		Source:     rate.Source,
+		Timestamp:  rate.Timestamp,
+		Age:        age,
+		Stale:      c.maxRateAge > 0 && age > c.maxRateAge,
+		Derivation: rate.Derivation,
+	}, nil
+}
+
+// SetAutoInverse controls whether a missing rate is derived by inverting the
+// rate set for the opposite pair. It is enabled by default; disable it when bid
+// and ask rates differ and every direction must be set explicitly.
+//
+// Parameters:
+//   - enabled: true to derive missing directions as 1/rate
+//
+// Example:
+//   calc.SetAutoInverse(false)
+//   calc.SetExchangeRate(USD, IDR, 15000, "bank")
+//   _, err := calc.Convert(ConversionInput{Amount: 150000, From: IDR, To: USD})
+//   // err: the IDR/USD rate is not set
+func (c *Calculator) SetAutoInverse(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.autoInverse = enabled
+}
+
+// SetBaseCurrency sets the currency that Convert triangulates through when a
+// pair has no rate in either direction. The default is USD; an empty code
+// disables triangulation.
+//
+// Parameters:
+//   - code: base currency that rates are maintained against
+//
+// Example:
+//   calc.SetBaseCurrency(EUR)
+//   calc.SetExchangeRate(EUR, IDR, 17000, "ECB")
+//   calc.SetExchangeRate(EUR, JPY, 160, "ECB")
+//   // IDR→JPY now converts through EUR
+func (c *Calculator) SetBaseCurrency(code CurrencyCode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	c.baseCurrency = code
+}
+
+// lookupRateLocked returns the rate for a pair: the rate set for it, else the
+// inverse of the opposite pair when automatic inversion is enabled. The error
+// names the missing direction. Callers must hold c.mu.
+//
+// Parameters:
+//   - from: source currency code
+//   - to: target currency code
+//
+// Returns:
+//   - ExchangeRate: rate with its derivation recorded
+//   - error: exchange_rate_not_found error if neither direction can be used
+func (c *Calculator) lookupRateLocked(from, to CurrencyCode) (ExchangeRate, error) {
+	if rate, exists := c.exchangeRates[string(from)+"/"+string(to)]; exists {
+		return rate, nil
 	}
 	
-	// Also set the inverse rate
-	inverseKey := string(to) + "/" + string(from)
-	c.exchangeRates[inverseKey] = ExchangeRate{
-		From:      to,
-		To:        from,
-		Rate:      1.0 / rate,
+	opposite, oppositeExists := c.exchangeRates[string(to)+"/"+string(from)]
+	if oppositeExists && c.autoInverse && opposite.Rate != 0 {
+		return ExchangeRate{
+			From:       from,
+			To:         to,
+			Rate:       1.0 / opposite.Rate,
+			Timestamp:  opposite.Timestamp,
+			Source:     opposite.Source,
+			Derivation: RateDerivationInverse,
+		}, nil
+	}
+	
+	message := fmt.Sprintf("Exchange rate not found for %s to %s: neither %s/%s nor %s/%s is set", from, to, from, to, to, from)
+	if oppositeExists {
+		message = fmt.Sprintf("Exchange rate not found for %s to %s: %s/%s is not set and automatic inversion of %s/%s is disabled", from, to, from, to, to, from)
+	}
+	return ExchangeRate{}, &CurrencyError{
+		Type:      "exchange_rate_not_found",
+		Message:   message,
 		Timestamp: time.Now(),
-		Source:    source,
 	}
 }
 
@@ -798,7 +1608,7 @@ func (c *Calculator) SetExchangeRate(from, to CurrencyCode, rate float64, source
 //   - error: rate not found error
 //
 // Features:
-//   - Identity rate for same currency (rate = 1.0)
+//   - Inverse of the opposite pair when only that direction is set
 //   - Source attribution and timestamp tracking
 //   - Thread-safe rate retrieval
 //
@@ -806,14 +1616,12 @@ func (c *Calculator) SetExchangeRate(from, to CurrencyCode, rate float64, source
 //   rate, err := calc.GetExchangeRate(USD, EUR)
 //   // rate.Rate = 0.85, rate.Source = "ECB"
 func (c *Calculator) GetExchangeRate(from, to CurrencyCode) (*ExchangeRate, error) {
-	rateKey := string(from) + "/" + string(to)
-	rate, exists := c.exchangeRates[rateKey]
-	if !exists {
-		return nil, &CurrencyError{
-			Type:      "exchange_rate_not_found",
-			Message:   fmt.Sprintf("Exchange rate not found for %s to %s", from, to),
-			Timestamp: time.Now(),
-		}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	rate, err := c.lookupRateLocked(from, to)
+	if err != nil {
+		return nil, err
 	}
 	return &rate, nil
 }
@@ -839,7 +1647,11 @@ func (c *Calculator) GetExchangeRate(from, to CurrencyCode) (*ExchangeRate, erro
 //     DecimalSep:     ".",
 //   })
 func (c *Calculator) AddCurrency(currency Currency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
 	c.currencies[currency.Code] = currency
+	c.added[currency.Code] = true
 }
 
 // GetCurrency retrieves currency information by currency code.
@@ -856,7 +1668,10 @@ func (c *Calculator) AddCurrency(currency Currency) {
 //   currency, err := calc.GetCurrency(USD)
 //   // currency.Symbol = "$", currency.DecimalPlaces = 2
 func (c *Calculator) GetCurrency(code CurrencyCode) (*Currency, error) {
-	currency, exists := c.currencies[code]
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	currency, exists := c.currencyLocked(code)
 	if !exists {
 		return nil, &CurrencyError{
 			Type:      "currency_not_found",
@@ -883,10 +1698,20 @@ func (c *Calculator) GetCurrency(code CurrencyCode) (*Currency, error) {
 //   list := calc.GetSupportedCurrencies()
 //   // list.Total = 7, list.Currencies contains all registered currencies
 func (c *Calculator) GetSupportedCurrencies() *CurrencyList {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
 	currencies := make([]Currency, 0, len(c.currencies))
-	for _, currency := range c.currencies {
+	for code := range c.currencies {
+		currency, _ := c.currencyLocked(code)
 		currencies = append(currencies, currency)
 	}
+	for _, code := range registeredCurrencyCodes() {
+		if _, added := c.currencies[code]; !added {
+			registered, _ := LookupRegisteredCurrency(code)
+			currencies = append(currencies, registered)
+		}
+	}
 	
 	return &CurrencyList{
 		Currencies: currencies,
@@ -896,7 +1721,8 @@ func (c *Calculator) GetSupportedCurrencies() *CurrencyList {
 }
 
 // SetDefaultRounding sets the default rounding mode for the calculator.
-// Changes the rounding behavior for all subsequent calculations.
+// Changes the rounding behavior for all subsequent calculations, including the
+// RoundingMode field used by conversions and allocations.
 //
 // Parameters:
 //   - mode: rounding mode to use as default
@@ -911,7 +1737,92 @@ func (c *Calculator) GetSupportedCurrencies() *CurrencyList {
 // Example:
 //   calc.SetDefaultRounding(RoundingModeHalfEven)
 func (c *Calculator) SetDefaultRounding(mode RoundingMode) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
 	c.defaultRounding = mode
+	c.RoundingMode = toUtilsRoundingMode(mode)
+}
+
+// SetCurrencyPrecision overrides the number of decimal places that arithmetic and
+// conversion results in the given currency are rounded to. Formatting keeps using
+// the currency's own DecimalPlaces.
+//
+// Parameters:
+//   - currency: currency code to configure
+//   - decimals: decimal places to round to; negative values are treated as 0
+//
+// Default precision comes from the registered currency, then from
+// CurrencyDecimalPlaces, so JPY and IDR round to 0 decimals, USD and EUR to 2,
+// and BHD to 3 without any configuration.
+//
+// Example:
+//   calc.SetCurrencyPrecision(IDR, 0)
+//   calc.SetCurrencyPrecision(USD, 4) // keep sub-cent precision for unit prices
+func (c *Calculator) SetCurrencyPrecision(currency CurrencyCode, decimals int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	if decimals < 0 {
+		decimals = 0
+	}
+	c.precisions[currency] = decimals
+}
+
+// currencyPrecisionLocked returns the decimal places results in a currency are
+// rounded to: an override from SetCurrencyPrecision, else the DecimalPlaces of
+// the currency from currencyLocked, else the standard table via
+// GetCurrencyDecimalPlaces.
+// Callers must hold c.mu.
+//
+// Parameters:
+//   - code: currency code to look up
+//
+// Returns:
+//   - int: number of decimal places
+func (c *Calculator) currencyPrecisionLocked(code CurrencyCode) int {
+	if precision, exists := c.precisions[code]; exists {
+		return precision
+	}
+	if currency, exists := c.currencyLocked(code); exists {
+		return currency.DecimalPlaces
+	}
+	return GetCurrencyDecimalPlaces(code)
+}
+
+// currencyLocked returns a currency added to this calculator with AddCurrency,
+// else one defined package-wide with RegisterCurrency, else a default currency.
+// A registration therefore overrides a default currency in every calculator but
+// not a currency the calculator was given explicitly. Callers must hold c.mu.
+//
+// Parameters:
+//   - code: currency code to look up
+//
+// Returns:
+//   - Currency: currency definition
+//   - bool: true if the currency is known
+func (c *Calculator) currencyLocked(code CurrencyCode) (Currency, bool) {
+	if c.added[code] {
+		return c.currencies[code], true
+	}
+	if currency, exists := LookupRegisteredCurrency(code); exists {
+		return currency, true
+	}
+	currency, exists := c.currencies[code]
+	return currency, exists
+}
+
+// getDefaultRounding returns the calculator's default rounding mode.
+// Reads the mode under the calculator's lock so it is safe to call while
+// SetDefaultRounding runs on another goroutine.
+//
+// Returns:
+//   - RoundingMode: the current default rounding mode
+func (c *Calculator) getDefaultRounding() RoundingMode {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	
+	return c.defaultRounding
 }
 
 // Parse parses a formatted currency string into Money.
@@ -936,7 +1847,9 @@ func (c *Calculator) SetDefaultRounding(mode RoundingMode) {
 //   money, err := calc.Parse("$1,234.56", USD)
 //   // money.Amount = 1234.56, money.Currency = USD
 func (c *Calculator) Parse(input string, currency CurrencyCode) (*Money, error) {
-	currencyInfo, exists := c.currencies[currency]
+	c.mu.RLock()
+	currencyInfo, exists := c.currencyLocked(currency)
+	c.mu.RUnlock()
 	if !exists {
 		return nil, &CurrencyError{
 			Type:      "unsupported_currency",
@@ -1,7 +1,13 @@
 package currency
 
 import (
+	"errors"
+	"math"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 func TestNewCalculator(t *testing.T) {
@@ -61,6 +67,13 @@ func TestFormat(t *testing.T) {
 			expected: "999,99 €",
 			wantErr:  false,
 		},
+		{
+			name:     "BHD with three decimal places",
+			money:    Money{Amount: 1234.567, Currency: BHD},
+			options:  &FormatOptions{ShowSymbol: true},
+			expected: "BD 1,234.567",
+			wantErr:  false,
+		},
 		{
 			name:     "Negative amount with parentheses",
 			money:    Money{Amount: -100.50, Currency: USD},
@@ -106,6 +119,101 @@ func TestFormat(t *testing.T) {
 	}
 }
 
+func TestFormatNegativeFormat(t *testing.T) {
+	calc := NewCalculator()
+	
+	tests := []struct {
+		name     string
+		money    Money
+		options  *FormatOptions
+		expected string
+	}{
+		{"Parentheses keep the symbol inside", Money{Amount: -1234.56, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "($1,234.56)"},
+		{"Minus before the symbol", Money{Amount: -1234.56, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatMinus}, "-$1,234.56"},
+		{"Minus after the amount", Money{Amount: -1234.56, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatMinusAfter}, "$1,234.56-"},
+		{"Small negative", Money{Amount: -0.01, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "($0.01)"},
+		{"Negative that rounds to zero", Money{Amount: -0.004, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "$0.00"},
+		{"Zero", Money{Amount: 0, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "$0.00"},
+		{"Large IDR amount with IDR separators", Money{Amount: -1234567890, Currency: IDR}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "(Rp 1.234.567.890)"},
+		{"Separators overridden per locale", Money{Amount: -1234567.89, Currency: USD}, &FormatOptions{ThousandsSep: ".", DecimalSep: ",", NegativeFormat: NegativeFormatParentheses}, "(1.234.567,89)"},
+		{"Suffix symbol inside parentheses", Money{Amount: -50.25, Currency: EUR}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "(50,25 €)"},
+		{"Positive amounts are unchanged", Money{Amount: 1234.56, Currency: USD}, &FormatOptions{ShowSymbol: true, NegativeFormat: NegativeFormatParentheses}, "$1,234.56"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Format(tt.money, tt.options)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected '%s', got '%s'", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatFallbackToCode(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddCurrency(Currency{
+		Code:          "TST",
+		Name:          "Test Dollar",
+		DecimalPlaces: 2,
+		ThousandsSep:  ",",
+		DecimalSep:    ".",
+		SymbolFirst:   true,
+	})
+	
+	tests := []struct {
+		name     string
+		money    Money
+		expected string
+	}{
+		{
+			name:     "registered currency keeps its symbol",
+			money:    Money{Amount: 1500, Currency: USD},
+			expected: "$1,500.00",
+		},
+		{
+			name:     "registered currency without symbol",
+			money:    Money{Amount: 1500, Currency: "TST"},
+			expected: "TST 1,500.00",
+		},
+		{
+			name:     "unregistered currency",
+			money:    Money{Amount: 1500, Currency: "XYZ"},
+			expected: "XYZ 1,500.00",
+		},
+		{
+			name:     "unregistered currency with known symbol",
+			money:    Money{Amount: 1500, Currency: THB},
+			expected: "฿1,500.00",
+		},
+		{
+			name:     "negative unregistered amount",
+			money:    Money{Amount: -25.5, Currency: "XYZ"},
+			expected: "XYZ -25.50",
+		},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Format(tt.money, &FormatOptions{ShowSymbol: true, FallbackToCode: true})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+	
+	// Without the option, unregistered currencies still fail
+	if _, err := calc.Format(Money{Amount: 1500, Currency: "XYZ"}, &FormatOptions{ShowSymbol: true}); err == nil {
+		t.Error("Expected error for unregistered currency without FallbackToCode")
+	}
+}
+
 func TestConvert(t *testing.T) {
 	calc := NewCalculator()
 	
@@ -171,6 +279,364 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+func TestConvertRecordsRateUsed(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, EUR, 0.8, "ECB")
+	configured, err := calc.GetExchangeRate(USD, EUR)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	tests := []struct {
+		name       string
+		input      ConversionInput
+		rate       float64
+		source     string
+		derivation RateDerivation
+	}{
+		{"Direct rate", ConversionInput{Amount: 100, From: USD, To: EUR}, 0.8, "ECB", RateDerivationDirect},
+		{"Inverse rate", ConversionInput{Amount: 80, From: EUR, To: USD}, 1.25, "ECB", RateDerivationInverse},
+		{"Identity", ConversionInput{Amount: 10, From: EUR, To: EUR}, 1.0, "identity", RateDerivationIdentity},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Convert(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.RateUsed != tt.rate || result.RateSource != tt.source || result.RateDerivation != tt.derivation {
+				t.Errorf("Expected rate %v from %q (%s), got %v from %q (%s)",
+					tt.rate, tt.source, tt.derivation, result.RateUsed, result.RateSource, result.RateDerivation)
+			}
+			if tt.derivation != RateDerivationIdentity && !result.RateTimestamp.Equal(configured.Timestamp) {
+				t.Errorf("Expected rate timestamp %v, got %v", configured.Timestamp, result.RateTimestamp)
+			}
+			
+			// The recorded rate reproduces the converted amount
+			if math.Abs(tt.input.Amount*result.RateUsed-result.ConvertedAmount.Amount) > 0.005 {
+				t.Errorf("Recorded rate %v does not reproduce %v from %v", result.RateUsed, result.ConvertedAmount.Amount, tt.input.Amount)
+			}
+		})
+	}
+}
+
+func TestConvertAutoInverse(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, IDR, 15000, "feed")
+	
+	// Only USD/IDR is set, so IDR→USD uses its inverse
+	result, err := calc.Convert(ConversionInput{Amount: 150000, From: IDR, To: USD})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConvertedAmount.Amount != 10 || !result.Inverted || result.RateDerivation != RateDerivationInverse {
+		t.Errorf("Expected 10.00 USD from the inverted rate, got %v (inverted %v)", result.ConvertedAmount.Amount, result.Inverted)
+	}
+	
+	// An explicitly set rate wins over the inverse, whichever is set first
+	calc.SetExchangeRate(IDR, USD, 0.00006, "bank")
+	calc.SetExchangeRate(USD, IDR, 15000, "feed")
+	result, err = calc.Convert(ConversionInput{Amount: 150000, From: IDR, To: USD})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConvertedAmount.Amount != 9 || result.Inverted || result.RateSource != "bank" {
+		t.Errorf("Expected 9.00 USD from the bank rate, got %v from %q", result.ConvertedAmount.Amount, result.RateSource)
+	}
+	
+	// With inversion disabled the missing direction is reported
+	calc.SetAutoInverse(false)
+	calc.SetExchangeRate(USD, EUR, 0.85, "ECB")
+	_, err = calc.Convert(ConversionInput{Amount: 85, From: EUR, To: USD})
+	var currencyErr *CurrencyError
+	if !errors.As(err, &currencyErr) || currencyErr.Type != "exchange_rate_not_found" {
+		t.Fatalf("Expected exchange_rate_not_found error, got %v", err)
+	}
+	if !strings.Contains(currencyErr.Message, "EUR/USD is not set") {
+		t.Errorf("Expected error to name the missing EUR/USD direction, got %q", currencyErr.Message)
+	}
+	if _, err := calc.Convert(ConversionInput{Amount: 100, From: USD, To: EUR}); err != nil {
+		t.Errorf("Expected the set direction to keep working, got %v", err)
+	}
+}
+
+func TestConvertTriangulated(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, IDR, 15000, "feed")
+	calc.SetExchangeRate(USD, EUR, 0.85, "feed")
+	
+	// IDR→USD is inverted, then USD→EUR applied, with one final rounding
+	result, err := calc.Convert(ConversionInput{Amount: 150000, From: IDR, To: EUR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConvertedAmount.Amount != 8.5 || result.RateDerivation != RateDerivationTriangulated {
+		t.Errorf("Expected 8.50 EUR through USD, got %v (%s)", result.ConvertedAmount.Amount, result.RateDerivation)
+	}
+	if result.IntermediateCurrency != USD || result.FirstLeg == nil || result.SecondLeg == nil {
+		t.Fatalf("Expected two legs through USD, got %s %+v %+v", result.IntermediateCurrency, result.FirstLeg, result.SecondLeg)
+	}
+	if math.Abs(result.FirstLeg.Rate-1.0/15000) > 1e-12 || result.SecondLeg.Rate != 0.85 {
+		t.Errorf("Unexpected leg rates %v and %v", result.FirstLeg.Rate, result.SecondLeg.Rate)
+	}
+	
+	// 1 IDR is 0.0000567 EUR; rounding each leg to cents would give 0.00
+	result, err = calc.Convert(ConversionInput{Amount: 1000, From: IDR, To: EUR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.ConvertedAmount.Amount != 0.06 {
+		t.Errorf("Expected 0.06 EUR without double rounding, got %v", result.ConvertedAmount.Amount)
+	}
+	
+	// A missing leg is named in the error
+	_, err = calc.Convert(ConversionInput{Amount: 100, From: IDR, To: JPY})
+	if err == nil || !strings.Contains(err.Error(), "USD/JPY") {
+		t.Errorf("Expected error naming the missing USD/JPY leg, got %v", err)
+	}
+	
+	// Another base currency can be configured
+	calc.SetBaseCurrency(EUR)
+	calc.SetExchangeRate(EUR, JPY, 160, "ECB")
+	result, err = calc.Convert(ConversionInput{Amount: 100, From: USD, To: JPY})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.IntermediateCurrency != EUR || result.ConvertedAmount.Amount != 13600 {
+		t.Errorf("Expected 13600 JPY through EUR, got %v through %s", result.ConvertedAmount.Amount, result.IntermediateCurrency)
+	}
+}
+
+func TestConvertStaleRates(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRateAt(USD, IDR, 15000, "feed", time.Now().Add(-5*time.Hour))
+	calc.SetExchangeRate(USD, EUR, 0.85, "feed")
+	
+	info, err := calc.GetRateInfo(USD, IDR)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if info.Rate != 15000 || info.Source != "feed" || info.Age < 5*time.Hour || info.Stale {
+		t.Errorf("Expected a 5 hour old rate from feed that is not stale without a maximum, got %+v", info)
+	}
+	
+	// Warn policy converts but flags the result
+	calc.SetMaxRateAge(3*time.Hour, StaleRateWarn)
+	result, err := calc.Convert(ConversionInput{Amount: 10, From: USD, To: IDR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !result.StaleRate || result.Warning == "" || result.ConvertedAmount.Amount != 150000 {
+		t.Errorf("Expected a converted amount with a stale rate warning, got %+v", result)
+	}
+	result, err = calc.Convert(ConversionInput{Amount: 10, From: USD, To: EUR})
+	if err != nil || result.StaleRate || result.Warning != "" {
+		t.Errorf("Expected a fresh rate without warning, got %+v (%v)", result, err)
+	}
+	
+	// Error policy blocks the conversion, including through a stale leg
+	calc.SetMaxRateAge(3*time.Hour, StaleRateError)
+	if _, err := calc.Convert(ConversionInput{Amount: 10, From: USD, To: IDR}); !errors.Is(err, ErrStaleRate) {
+		t.Errorf("Expected ErrStaleRate, got %v", err)
+	}
+	if _, err := calc.Convert(ConversionInput{Amount: 10000, From: IDR, To: EUR}); !errors.Is(err, ErrStaleRate) {
+		t.Errorf("Expected ErrStaleRate for a triangulated conversion with a stale leg, got %v", err)
+	}
+	info, err = calc.GetRateInfo(IDR, USD)
+	if err != nil || !info.Stale || info.Derivation != RateDerivationInverse {
+		t.Errorf("Expected the inverted rate to be reported stale, got %+v (%v)", info, err)
+	}
+	
+	// A fresh quote clears the block
+	calc.SetExchangeRate(USD, IDR, 15100, "feed")
+	if _, err := calc.Convert(ConversionInput{Amount: 10, From: USD, To: IDR}); err != nil {
+		t.Errorf("Expected a fresh rate to convert, got %v", err)
+	}
+}
+
+func TestConvertBatch(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, IDR, 15000, "test")
+	calc.SetExchangeRate(USD, EUR, 0.85, "test")
+	
+	inputs := make([]ConversionInput, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		amount := float64(i) + 0.37
+		switch i % 4 {
+		case 0:
+			inputs = append(inputs, ConversionInput{Amount: amount, From: USD, To: IDR})
+		case 1:
+			inputs = append(inputs, ConversionInput{Amount: amount, From: EUR, To: USD})
+		case 2:
+			inputs = append(inputs, ConversionInput{Amount: amount, From: USD, To: USD})
+		default:
+			inputs = append(inputs, ConversionInput{Amount: amount, From: USD, To: JPY})
+		}
+	}
+	
+	results, errs := calc.ConvertBatch(inputs)
+	
+	if len(results) != len(inputs) || len(errs) != len(inputs) {
+		t.Fatalf("Expected %d results and errors, got %d and %d", len(inputs), len(results), len(errs))
+	}
+	
+	for i, input := range inputs {
+		expected, err := calc.Convert(input)
+		if err != nil {
+			if errs[i] == nil {
+				t.Errorf("Input %d: expected error, got none", i)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("Input %d: unexpected error: %v", i, errs[i])
+			continue
+		}
+		if results[i].OriginalAmount != expected.OriginalAmount {
+			t.Errorf("Input %d: expected original %v, got %v", i, expected.OriginalAmount, results[i].OriginalAmount)
+		}
+		if results[i].ConvertedAmount != expected.ConvertedAmount {
+			t.Errorf("Input %d: expected converted %v, got %v", i, expected.ConvertedAmount, results[i].ConvertedAmount)
+		}
+	}
+	
+	results, errs = calc.ConvertBatch(nil)
+	if len(results) != 0 || len(errs) != 0 {
+		t.Errorf("Expected empty output for empty input, got %d results and %d errors", len(results), len(errs))
+	}
+}
+
+func TestConvertAll(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(EUR, USD, 1.1, "ecb")
+	calc.SetExchangeRate(IDR, USD, 0.000065, "bi")
+	
+	inputs := []ConversionInput{
+		{Amount: 10, From: EUR, To: USD},
+		{Amount: 5, From: GBP, To: USD}, // no GBP rate
+		{Amount: 100000, From: IDR, To: USD},
+		{Amount: 7.5, From: USD, To: USD},
+	}
+	
+	batch, err := calc.ConvertAll(inputs)
+	results := batch.Results
+	if len(results) != len(inputs) {
+		t.Fatalf("Expected %d results, got %d", len(inputs), len(results))
+	}
+	
+	var currencyErr *CurrencyError
+	if !errors.As(err, &currencyErr) || currencyErr.Type != "batch_conversion_failed" {
+		t.Fatalf("Expected batch_conversion_failed error, got %v", err)
+	}
+	if len(currencyErr.Causes) != 1 || !strings.Contains(currencyErr.Causes[0].Error(), "conversion 1") {
+		t.Errorf("Expected one failure for input 1, got %v", currencyErr.Causes)
+	}
+	var rateErr *CurrencyError
+	if !errors.As(currencyErr.Causes[0], &rateErr) || rateErr.Type != "exchange_rate_not_found" {
+		t.Errorf("Expected cause to wrap exchange_rate_not_found, got %v", currencyErr.Causes[0])
+	}
+	
+	if results[0].ConvertedAmount.Amount != 11 || results[0].ExchangeRate.Source != "ecb" {
+		t.Errorf("Expected 11 USD via ecb, got %v via %s", results[0].ConvertedAmount.Amount, results[0].ExchangeRate.Source)
+	}
+	if results[1] != (ConversionResult{}) {
+		t.Errorf("Expected zero result for failed conversion, got %+v", results[1])
+	}
+	if results[2].ConvertedAmount.Amount != 6.5 || results[2].ExchangeRate.Source != "bi" {
+		t.Errorf("Expected 6.5 USD via bi, got %v via %s", results[2].ConvertedAmount.Amount, results[2].ExchangeRate.Source)
+	}
+	
+	if batch.Converted != 3 || batch.Failed != 1 {
+		t.Errorf("Expected 3 converted and 1 failed, got %d and %d", batch.Converted, batch.Failed)
+	}
+	if len(batch.Rates) != 2 || batch.Rates[0].From != EUR || batch.Rates[1].From != IDR {
+		t.Errorf("Expected the EUR and IDR rates in input order, got %+v", batch.Rates)
+	}
+	if strings.Join(batch.Sources, ",") != "ecb,bi" {
+		t.Errorf("Expected sources ecb,bi, got %v", batch.Sources)
+	}
+	if batch.OldestRateTimestamp != batch.Rates[0].Timestamp {
+		t.Errorf("Expected oldest rate timestamp %v, got %v", batch.Rates[0].Timestamp, batch.OldestRateTimestamp)
+	}
+	
+	if _, err := calc.ConvertAll(inputs[:1]); err != nil {
+		t.Errorf("Expected no error when all conversions succeed, got %v", err)
+	}
+}
+
+func TestConvertAllReusesRatePerPair(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetBaseCurrency(USD)
+	calc.SetExchangeRate(USD, IDR, 15000, "bi")
+	calc.SetExchangeRateAt(USD, EUR, 0.85, "ecb", time.Now().Add(-2*time.Hour))
+	calc.SetMaxRateAge(time.Hour, StaleRateWarn)
+	
+	inputs := make([]ConversionInput, 0, 100)
+	for i := 0; i < 100; i++ {
+		inputs = append(inputs, ConversionInput{Amount: float64(i + 1), From: IDR, To: EUR})
+	}
+	
+	batch, err := calc.ConvertAll(inputs)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	// Every line shares the single triangulated lookup for IDR→EUR
+	if len(batch.Rates) != 1 || batch.Rates[0].Derivation != RateDerivationTriangulated {
+		t.Fatalf("Expected one triangulated rate, got %+v", batch.Rates)
+	}
+	for i, result := range batch.Results {
+		if result.ExchangeRate != batch.Rates[0] || result.FirstLeg == nil || result.SecondLeg == nil {
+			t.Errorf("Result %d: expected the shared triangulated rate, got %+v", i, result.ExchangeRate)
+		}
+	}
+	if strings.Join(batch.Sources, ",") != "bi,ecb" {
+		t.Errorf("Expected both leg sources, got %v", batch.Sources)
+	}
+	if batch.StaleConversions != 100 || len(batch.Warnings) != 1 {
+		t.Errorf("Expected 100 stale conversions with one warning, got %d and %v", batch.StaleConversions, batch.Warnings)
+	}
+}
+
+func TestSumInCurrency(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(EUR, USD, 1.1, "test")
+	calc.SetExchangeRate(IDR, USD, 0.000065, "test")
+	
+	total, err := calc.SumInCurrency([]Money{
+		{Amount: 10, Currency: EUR},
+		{Amount: 100000, Currency: IDR},
+		{Amount: 0.015, Currency: USD},
+	}, USD)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 11.00 + 6.50 + 0.02
+	if math.Abs(total.Amount-17.52) > 1e-9 || total.Currency != USD {
+		t.Errorf("Expected 17.52 USD, got %v %s", total.Amount, total.Currency)
+	}
+	
+	if _, err := calc.SumInCurrency([]Money{{Amount: 1, Currency: GBP}}, USD); err == nil {
+		t.Error("Expected error when an amount has no exchange rate")
+	}
+	
+	total, err = calc.SumInCurrency(nil, JPY)
+	if err != nil || total.Amount != 0 || total.Currency != JPY {
+		t.Errorf("Expected zero JPY for no amounts, got %v %s (%v)", total.Amount, total.Currency, err)
+	}
+	
+	// A rate alone does not make an unknown target currency summable
+	calc.SetExchangeRate(USD, CurrencyCode("XTS"), 2, "test")
+	var currencyErr *CurrencyError
+	if _, err := calc.SumInCurrency([]Money{{Amount: 1, Currency: USD}}, CurrencyCode("XTS")); !errors.As(err, &currencyErr) || currencyErr.Type != "currency_not_found" {
+		t.Errorf("Expected currency_not_found for an unknown target, got %v", err)
+	}
+	if _, err := NewBatchConverter(calc).SumInCurrency([]Money{{Amount: 1, Currency: USD}}, CurrencyCode("XTS")); err == nil {
+		t.Error("Expected BatchConverter.SumInCurrency to reject an unknown target")
+	}
+}
+
 func TestArithmeticOperations(t *testing.T) {
 	calc := NewCalculator()
 	
@@ -343,6 +809,62 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCompareWithinPrecision(t *testing.T) {
+	calc := NewCalculator()
+	
+	sum := Money{Amount: 0.1 + 0.2, Currency: USD}
+	total := Money{Amount: 0.3, Currency: USD}
+	if equal, err := calc.Equals(sum, total); err != nil || !equal {
+		t.Errorf("Expected 0.1+0.2 to equal 0.3 USD, got %v (err %v)", equal, err)
+	}
+	
+	tests := []struct {
+		name       string
+		amount1    Money
+		amount2    Money
+		comparison int
+	}{
+		{"Sub-cent difference is equal", Money{Amount: 10.004, Currency: USD}, Money{Amount: 10.00, Currency: USD}, 0},
+		{"One cent greater", Money{Amount: 10.01, Currency: USD}, Money{Amount: 10.00, Currency: USD}, 1},
+		{"One cent less", Money{Amount: 9.99, Currency: USD}, Money{Amount: 10.00, Currency: USD}, -1},
+		{"Fractional yen is equal", Money{Amount: 100.4, Currency: JPY}, Money{Amount: 100, Currency: JPY}, 0},
+		{"One fils greater", Money{Amount: 1.001, Currency: BHD}, Money{Amount: 1.000, Currency: BHD}, 1},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Compare(tt.amount1, tt.amount2)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.Comparison != tt.comparison {
+				t.Errorf("Expected comparison %d, got %d", tt.comparison, result.Comparison)
+			}
+			
+			greater, _ := calc.GreaterThan(tt.amount1, tt.amount2)
+			less, _ := calc.LessThan(tt.amount1, tt.amount2)
+			equal, _ := calc.Equals(tt.amount1, tt.amount2)
+			if greater != (tt.comparison > 0) || less != (tt.comparison < 0) || equal != (tt.comparison == 0) {
+				t.Errorf("Helpers disagree with comparison %d: greater=%v less=%v equal=%v", tt.comparison, greater, less, equal)
+			}
+		})
+	}
+	
+	_, err := calc.LessThan(Money{Amount: 1, Currency: USD}, Money{Amount: 1, Currency: EUR})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch, got %v", err)
+	}
+	var currencyErr *CurrencyError
+	if !errors.As(err, &currencyErr) || currencyErr.Type != "currency_mismatch" {
+		t.Errorf("Expected *CurrencyError of type currency_mismatch, got %v", err)
+	}
+	
+	_, err = calc.Add(Money{Amount: 1, Currency: USD}, Money{Amount: 1, Currency: EUR})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Expected ErrCurrencyMismatch from Add, got %v", err)
+	}
+}
+
 func TestExchangeRateManagement(t *testing.T) {
 	calc := NewCalculator()
 	
@@ -597,6 +1119,135 @@ func TestDefaultRounding(t *testing.T) {
 	}
 }
 
+func TestRoundingModeField(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      utils.RoundingMode
+		converted float64
+		allocated []float64
+	}{
+		{"half up", utils.RoundHalfUp, 3, []float64{0.07, 0.06}},
+		{"half even", utils.RoundHalfEven, 2, []float64{0.06, 0.06}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := NewCalculator()
+			calc.RoundingMode = tt.mode
+			calc.SetExchangeRate(USD, JPY, 1, "test")
+			
+			// 2.5 JPY sits exactly between 2 and 3
+			result, err := calc.Convert(ConversionInput{Amount: 2.5, From: USD, To: JPY})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result.ConvertedAmount.Amount != tt.converted {
+				t.Errorf("Expected %v JPY, got %v", tt.converted, result.ConvertedAmount.Amount)
+			}
+			
+			// 0.125 USD rounds to 0.13 or 0.12 before it is split
+			parts, err := calc.Allocate(Money{Amount: 0.125, Currency: USD}, []float64{1, 1})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i, part := range parts {
+				if math.Abs(part.Amount-tt.allocated[i]) > 1e-9 {
+					t.Errorf("Part %d: expected %v, got %v", i, tt.allocated[i], part.Amount)
+				}
+			}
+		})
+	}
+	
+	// SetDefaultRounding keeps the field in step
+	calc := NewCalculator()
+	calc.SetDefaultRounding(RoundingModeHalfEven)
+	if calc.RoundingMode != utils.RoundHalfEven {
+		t.Errorf("Expected SetDefaultRounding to set RoundingMode to half even, got %v", calc.RoundingMode)
+	}
+}
+
+func TestCurrencyPrecision(t *testing.T) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, IDR, 15432.1, "test")
+	calc.SetExchangeRate(USD, BHD, 0.37654, "test")
+	
+	tests := []struct {
+		name     string
+		amount   Money
+		factor   float64
+		expected float64
+	}{
+		{"IDR rounds to whole rupiah", Money{Amount: 10000, Currency: IDR}, 1.115, 11150},
+		{"JPY rounds to whole yen", Money{Amount: 333, Currency: JPY}, 1.1, 366},
+		{"USD rounds to cents", Money{Amount: 10.00, Currency: USD}, 1.0756, 10.76},
+		{"EUR rounds to cents", Money{Amount: 3.34, Currency: EUR}, 0.501, 1.67},
+		{"BHD rounds to fils", Money{Amount: 1.23456, Currency: BHD}, 1.0, 1.235},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Multiply(tt.amount, tt.factor)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result.Result.Amount-tt.expected) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.expected, result.Result.Amount)
+			}
+		})
+	}
+	
+	// Add and Subtract round to the same precision
+	sum, err := calc.Add(Money{Amount: 0.4, Currency: IDR}, Money{Amount: 0.4, Currency: IDR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum.Result.Amount != 1 {
+		t.Errorf("Expected IDR sum 1, got %v", sum.Result.Amount)
+	}
+	difference, err := calc.Subtract(Money{Amount: 5.0, Currency: BHD}, Money{Amount: 1.23456, Currency: BHD})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(difference.Result.Amount-3.765) > 1e-9 {
+		t.Errorf("Expected BHD difference 3.765, got %v", difference.Result.Amount)
+	}
+	
+	// Convert rounds to the destination currency after applying the rate
+	converted, err := calc.Convert(ConversionInput{Amount: 12.34, From: USD, To: IDR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if converted.ConvertedAmount.Amount != 190432 {
+		t.Errorf("Expected 190432 IDR, got %v", converted.ConvertedAmount.Amount)
+	}
+	converted, err = calc.Convert(ConversionInput{Amount: 12.34, From: USD, To: BHD})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(converted.ConvertedAmount.Amount-4.647) > 1e-9 {
+		t.Errorf("Expected 4.647 BHD, got %v", converted.ConvertedAmount.Amount)
+	}
+	
+	// Overrides take precedence over the currency defaults
+	calc.SetCurrencyPrecision(USD, 4)
+	result, err := calc.Multiply(Money{Amount: 10.00, Currency: USD}, 1.075551)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(result.Result.Amount-10.7555) > 1e-9 {
+		t.Errorf("Expected 10.7555 with 4 decimal override, got %v", result.Result.Amount)
+	}
+	
+	calc.SetCurrencyPrecision(IDR, -2)
+	converted, err = calc.Convert(ConversionInput{Amount: 1.5, From: IDR, To: IDR})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if converted.ConvertedAmount.Amount != 2 {
+		t.Errorf("Expected identity conversion rounded to 2 IDR, got %v", converted.ConvertedAmount.Amount)
+	}
+}
+
 func BenchmarkFormat(b *testing.B) {
 	calc := NewCalculator()
 	money := Money{Amount: 1234.56, Currency: USD}
@@ -619,6 +1270,20 @@ func BenchmarkConvert(b *testing.B) {
 	}
 }
 
+func BenchmarkConvertBatch(b *testing.B) {
+	calc := NewCalculator()
+	calc.SetExchangeRate(USD, IDR, 15000, "test")
+	inputs := make([]ConversionInput, 10000)
+	for i := range inputs {
+		inputs[i] = ConversionInput{Amount: float64(i), From: USD, To: IDR}
+	}
+	
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = calc.ConvertBatch(inputs)
+	}
+}
+
 func BenchmarkArithmetic(b *testing.B) {
 	calc := NewCalculator()
 	amount1 := Money{Amount: 100.50, Currency: USD}
@@ -628,4 +1293,69 @@ func BenchmarkArithmetic(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = calc.Add(amount1, amount2)
 	}
-}
\ No newline at end of file
+}
+func TestCalculatorAllocate(t *testing.T) {
+	calc := NewCalculator()
+	
+	tests := []struct {
+		name     string
+		money    Money
+		ratios   []float64
+		expected []float64
+	}{
+		{"ten dollars three ways", Money{Amount: 10, Currency: USD}, []float64{1, 1, 1}, []float64{3.34, 3.33, 3.33}},
+		{"weighted", Money{Amount: 100, Currency: USD}, []float64{3, 2, 1}, []float64{50, 33.33, 16.67}},
+		{"zero ratio", Money{Amount: 5, Currency: USD}, []float64{1, 0, 1}, []float64{2.5, 0, 2.5}},
+		{"yen", Money{Amount: 100, Currency: JPY}, []float64{1, 1, 1}, []float64{34, 33, 33}},
+		{"three decimals", Money{Amount: 1, Currency: BHD}, []float64{1, 1, 1}, []float64{0.334, 0.333, 0.333}},
+		{"negative amount", Money{Amount: -10, Currency: USD}, []float64{1, 1, 1}, []float64{-3.33, -3.33, -3.34}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := calc.Allocate(tt.money, tt.ratios)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(parts) != len(tt.expected) {
+				t.Fatalf("Expected %d parts, got %d", len(tt.expected), len(parts))
+			}
+			for i, part := range parts {
+				if math.Abs(part.Amount-tt.expected[i]) > 1e-9 || part.Currency != tt.money.Currency {
+					t.Errorf("Part %d: expected %v %s, got %v %s", i, tt.expected[i], tt.money.Currency, part.Amount, part.Currency)
+				}
+			}
+		})
+	}
+	
+	// Sum invariant across awkward amounts and split counts
+	for _, amount := range []float64{0.01, 0.05, 1, 9.99, 10, 33.33, 100, 1234.56} {
+		for n := 1; n <= 7; n++ {
+			parts, err := calc.AllocateEqual(Money{Amount: amount, Currency: USD}, n)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var sumCents int64
+			for _, part := range parts {
+				sumCents += int64(math.Round(part.Amount * 100))
+			}
+			if sumCents != int64(math.Round(amount*100)) {
+				t.Errorf("AllocateEqual(%v, %d) parts sum to %d cents, expected %v", amount, n, sumCents, amount*100)
+			}
+		}
+	}
+	
+	// Invalid input
+	if _, err := calc.Allocate(Money{Amount: 10, Currency: USD}, nil); err == nil {
+		t.Error("Expected error for empty ratios")
+	}
+	if _, err := calc.Allocate(Money{Amount: 10, Currency: USD}, []float64{1, -1}); err == nil {
+		t.Error("Expected error for negative ratio")
+	}
+	if _, err := calc.Allocate(Money{Amount: 10, Currency: USD}, []float64{0, 0}); err == nil {
+		t.Error("Expected error for zero total ratio")
+	}
+	if _, err := calc.AllocateEqual(Money{Amount: 10, Currency: USD}, 0); err == nil {
+		t.Error("Expected error for zero parts")
+	}
+}
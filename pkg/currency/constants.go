@@ -134,7 +134,7 @@ var (
 	// MiddleEastCurrencies contains currencies from Middle Eastern and North African countries.
 	// Includes currencies from the MENA region for regional operations.
 	MiddleEastCurrencies = []CurrencyCode{
-		SAR, AED, TRY,
+		SAR, AED, TRY, BHD,
 	}
 	
 	// ZeroDecimalCurrencies contains currencies that don't use fractional units.
@@ -146,7 +146,7 @@ var (
 	// HighPrecisionCurrencies contains currencies that use more than 2 decimal places.
 	// These currencies require 3 decimal places for accurate representation.
 	HighPrecisionCurrencies = []CurrencyCode{
-		// Add currencies that need more than 2 decimal places
+		BHD,
 	}
 )
 
@@ -183,6 +183,7 @@ var CurrencySymbols = map[CurrencyCode]string{
 	TRY: "₺",
 	SAR: "﷼",
 	AED: "د.إ",
+	BHD: "BD",
 }
 
 // CurrencyNames maps currency codes to their full English names.
@@ -218,6 +219,7 @@ var CurrencyNames = map[CurrencyCode]string{
 	TRY: "Turkish Lira",
 	SAR: "Saudi Riyal",
 	AED: "UAE Dirham",
+	BHD: "Bahraini Dinar",
 }
 
 // CurrencyDecimalPlaces maps currency codes to their standard number of decimal places.
@@ -254,6 +256,7 @@ var CurrencyDecimalPlaces = map[CurrencyCode]int{
 	TRY: 2,
 	SAR: 2,
 	AED: 2,
+	BHD: 3,
 }
 
 // Helper functions for currency groups
@@ -334,7 +337,8 @@ func IsZeroDecimalCurrency(code CurrencyCode) bool {
 	return false
 }
 
-// GetCurrencySymbol returns the display symbol for the given currency code.
+// GetCurrencySymbol returns the display symbol for the given currency code,
+// preferring a currency defined with RegisterCurrency over the built-in table.
 // If the currency code is not found, it returns the code itself as fallback.
 //
 // Parameters:
@@ -347,13 +351,17 @@ func IsZeroDecimalCurrency(code CurrencyCode) bool {
 //	symbol := GetCurrencySymbol(USD) // Returns "$"
 //	symbol := GetCurrencySymbol("XYZ") // Returns "XYZ" (fallback)
 func GetCurrencySymbol(code CurrencyCode) string {
+	if currency, exists := LookupRegisteredCurrency(code); exists {
+		return currency.Symbol
+	}
 	if symbol, exists := CurrencySymbols[code]; exists {
 		return symbol
 	}
 	return string(code) // Fallback to currency code
 }
 
-// GetCurrencyName returns the full English name for the given currency code.
+// GetCurrencyName returns the full English name for the given currency code,
+// preferring a currency defined with RegisterCurrency over the built-in table.
 // If the currency code is not found, it returns the code itself as fallback.
 //
 // Parameters:
@@ -365,13 +373,17 @@ func GetCurrencySymbol(code CurrencyCode) string {
 // Example:
 //	name := GetCurrencyName(EUR) // Returns "Euro"
 func GetCurrencyName(code CurrencyCode) string {
+	if currency, exists := LookupRegisteredCurrency(code); exists {
+		return currency.Name
+	}
 	if name, exists := CurrencyNames[code]; exists {
 		return name
 	}
 	return string(code) // Fallback to currency code
 }
 
-// GetCurrencyDecimalPlaces returns the standard number of decimal places for the given currency.
+// GetCurrencyDecimalPlaces returns the standard number of decimal places for the given currency,
+// preferring a currency defined with RegisterCurrency over the built-in table.
 // If the currency code is not found, it returns the default precision (2 decimal places).
 //
 // Parameters:
@@ -384,6 +396,9 @@ func GetCurrencyName(code CurrencyCode) string {
 //	places := GetCurrencyDecimalPlaces(USD) // Returns 2
 //	places := GetCurrencyDecimalPlaces(JPY) // Returns 0
 func GetCurrencyDecimalPlaces(code CurrencyCode) int {
+	if currency, exists := LookupRegisteredCurrency(code); exists {
+		return currency.DecimalPlaces
+	}
 	if places, exists := CurrencyDecimalPlaces[code]; exists {
 		return places
 	}
@@ -391,7 +406,8 @@ func GetCurrencyDecimalPlaces(code CurrencyCode) int {
 }
 
 // IsValidCurrencyCode checks if the given currency code is supported.
-// A currency is considered valid if it exists in the CurrencyNames mapping.
+// A currency is considered valid if it exists in the CurrencyNames mapping or
+// was defined with RegisterCurrency.
 //
 // Parameters:
 //   - code: The currency code to validate (e.g., USD, EUR)
@@ -404,12 +420,16 @@ func GetCurrencyDecimalPlaces(code CurrencyCode) int {
 //		fmt.Println("USD is supported")
 //	}
 func IsValidCurrencyCode(code CurrencyCode) bool {
+	if _, exists := LookupRegisteredCurrency(code); exists {
+		return true
+	}
 	_, exists := CurrencyNames[code]
 	return exists
 }
 
 // GetSupportedCurrencyCodes returns a slice of all supported currency codes.
-// The returned slice contains all currency codes that have names defined.
+// The returned slice contains all currency codes that have names defined,
+// followed by currencies defined with RegisterCurrency.
 //
 // Returns:
 //   - []CurrencyCode: A slice of supported currency codes
@@ -422,6 +442,11 @@ func GetSupportedCurrencyCodes() []CurrencyCode {
 	for code := range CurrencyNames {
 		codes = append(codes, code)
 	}
+	for _, code := range registeredCurrencyCodes() {
+		if _, builtIn := CurrencyNames[code]; !builtIn {
+			codes = append(codes, code)
+		}
+	}
 	return codes
 }
 
@@ -0,0 +1,113 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MinorUnitMoney is Money whose JSON amount is an integer in the currency's
+// minor unit (cents for USD, whole yen for JPY), which payment gateways commonly
+// expect and which avoids float drift. Money itself keeps its plain JSON form;
+// use MinorUnitMoney for the fields or values that should be sent in minor units.
+//
+// Example:
+//   type ChargeRequest struct {
+//     Total MinorUnitMoney `json:"total"`
+//   }
+//   data, _ := json.Marshal(ChargeRequest{Total: Money{Amount: 100.00, Currency: USD}.MinorUnits()})
+//   // {"total":{"amount":10000,"currency":"USD"}}
+type MinorUnitMoney Money
+
+// MinorUnits returns the money as a MinorUnitMoney so it is encoded in minor units.
+//
+// Returns:
+//   - MinorUnitMoney: Same amount and currency
+//
+// Example:
+//   data, _ := json.Marshal(Money{Amount: 0.29, Currency: USD}.MinorUnits())
+//   // {"amount":29,"currency":"USD"}
+func (m Money) MinorUnits() MinorUnitMoney {
+	return MinorUnitMoney(m)
+}
+
+// Money returns the value as plain Money.
+//
+// Returns:
+//   - Money: Same amount and currency in major units
+func (m MinorUnitMoney) Money() Money {
+	return Money(m)
+}
+
+// moneyJSON is the wire form of MinorUnitMoney; Amount is kept as a raw number
+// so decoding can tell integer literals from decimal ones.
+type moneyJSON struct {
+	Amount   json.Number  `json:"amount"`
+	Currency CurrencyCode `json:"currency"`
+}
+
+// MarshalJSON implements json.Marshaler for MinorUnitMoney. The amount is
+// rounded to the currency's decimal places from CurrencyDecimalPlaces and
+// written as an integer.
+//
+// Returns:
+//   - []byte: JSON object with "amount" and "currency"
+//   - error: Encoding error, if any
+//
+// Example:
+//   json.Marshal(MinorUnitMoney{Amount: 100.00, Currency: USD}) // {"amount":10000,"currency":"USD"}
+//   json.Marshal(MinorUnitMoney{Amount: 1500, Currency: JPY})   // {"amount":1500,"currency":"JPY"}
+func (m MinorUnitMoney) MarshalJSON() ([]byte, error) {
+	amount := json.Number(fmt.Sprint(int64(math.Round(m.Amount * minorUnitFactor(m.Currency)))))
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON implements json.Unmarshaler for MinorUnitMoney. Integer amounts
+// are read as minor units. Decimal amounts such as 100.5 or 100.0 are read as
+// major units, so payloads written from plain Money decode to the same value.
+//
+// Parameters:
+//   - data: JSON object with "amount" and "currency"
+//
+// Returns:
+//   - error: Decoding error if the object or amount is malformed
+//
+// Example:
+//   var m MinorUnitMoney
+//   json.Unmarshal([]byte(`{"amount":10000,"currency":"USD"}`), &m)  // 100.00 USD
+//   json.Unmarshal([]byte(`{"amount":100.50,"currency":"USD"}`), &m) // 100.50 USD
+func (m *MinorUnitMoney) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	amount := 0.0
+	if raw.Amount != "" {
+		value, err := raw.Amount.Float64()
+		if err != nil {
+			return fmt.Errorf("invalid money amount %q: %w", raw.Amount, err)
+		}
+		amount = value
+		if !strings.ContainsAny(string(raw.Amount), ".eE") {
+			amount = value / minorUnitFactor(raw.Currency)
+		}
+	}
+
+	m.Amount = amount
+	m.Currency = raw.Currency
+	return nil
+}
+
+// minorUnitFactor returns the number of minor units in one major unit of a
+// currency, e.g. 100 for USD, 1 for JPY and 1000 for BHD.
+//
+// Parameters:
+//   - code: Currency code
+//
+// Returns:
+//   - float64: Power of ten matching the currency's decimal places
+func minorUnitFactor(code CurrencyCode) float64 {
+	return math.Pow(10, float64(GetCurrencyDecimalPlaces(code)))
+}
@@ -0,0 +1,95 @@
+package currency
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestMinorUnitMoneyJSON(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{Money{Amount: 100.00, Currency: USD}, `{"amount":10000,"currency":"USD"}`},
+		{Money{Amount: 0.29, Currency: USD}, `{"amount":29,"currency":"USD"}`},
+		{Money{Amount: -12.5, Currency: EUR}, `{"amount":-1250,"currency":"EUR"}`},
+		{Money{Amount: 1500, Currency: JPY}, `{"amount":1500,"currency":"JPY"}`},
+		{Money{Amount: 1.234, Currency: BHD}, `{"amount":1234,"currency":"BHD"}`},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.money.MinorUnits())
+		if err != nil {
+			t.Fatalf("Marshal %v failed: %v", tt.money, err)
+		}
+		if string(data) != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, data)
+		}
+
+		var decoded MinorUnitMoney
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("Unmarshal %s failed: %v", data, err)
+		}
+		if decoded.Currency != tt.money.Currency || math.Abs(decoded.Amount-tt.money.Amount) > 1e-9 {
+			t.Errorf("Expected round trip to %v, got %v", tt.money, decoded.Money())
+		}
+	}
+
+	// Decimal input is read as major units
+	var decoded MinorUnitMoney
+	if err := json.Unmarshal([]byte(`{"amount":100.50,"currency":"USD"}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Amount != 100.50 {
+		t.Errorf("Expected 100.50 from decimal input, got %v", decoded.Amount)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":"abc","currency":"USD"}`), &decoded); err == nil {
+		t.Error("Expected error for a non-numeric amount")
+	}
+}
+
+func TestMoneyJSONUnchanged(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{Money{Amount: 100.5, Currency: USD}, `{"amount":100.5,"currency":"USD"}`},
+		{Money{Amount: 100, Currency: USD}, `{"amount":100,"currency":"USD"}`},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.money)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if string(data) != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, data)
+		}
+	}
+
+	// Plain Money always reads amounts as major units, even when a field of
+	// another type is encoded in minor units
+	payload := struct {
+		Price Money          `json:"price"`
+		Total MinorUnitMoney `json:"total"`
+	}{
+		Price: Money{Amount: 100, Currency: USD},
+		Total: Money{Amount: 100, Currency: USD}.MinorUnits(),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"price":{"amount":100,"currency":"USD"},"total":{"amount":10000,"currency":"USD"}}` {
+		t.Errorf("Unexpected payload %s", data)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal([]byte(`{"amount":100,"currency":"USD"}`), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Amount != 100 {
+		t.Errorf("Expected 100 from integer input, got %v", decoded.Amount)
+	}
+}
@@ -0,0 +1,141 @@
+package currency
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registry holds currencies defined at runtime with RegisterCurrency. It is
+// consulted before the built-in CurrencySymbols, CurrencyNames and
+// CurrencyDecimalPlaces tables, and by every Calculator for currencies it has
+// not been given with AddCurrency.
+var registry = struct {
+	mu         sync.RWMutex
+	currencies map[CurrencyCode]Currency
+}{currencies: make(map[CurrencyCode]Currency)}
+
+// RegisterCurrency defines a currency the package does not know about, such as
+// loyalty points or a regional voucher, so formatting, precision and validation
+// work for it in every Calculator. The currency is formatted with the default
+// separators and the symbol before the amount; use RegisterCurrencyDefinition
+// for full control. An empty symbol falls back to the code.
+//
+// Parameters:
+//   - code: Currency code, e.g. "PTS"
+//   - symbol: Display symbol; "" to display the code
+//   - decimals: Decimal places of the minor unit (0 to CryptoPrecision)
+//
+// Returns:
+//   - error: If the code is empty or decimals is out of range
+//
+// Example:
+//	currency.RegisterCurrency("PTS", "", 0)
+//	calc := currency.NewCalculator()
+//	formatted, _ := calc.Format(currency.Money{Amount: 1500, Currency: "PTS"}, &currency.FormatOptions{ShowSymbol: true})
+//	// formatted = "PTS 1,500"
+func RegisterCurrency(code CurrencyCode, symbol string, decimals int) error {
+	return RegisterCurrencyDefinition(Currency{
+		Code:          code,
+		Symbol:        symbol,
+		DecimalPlaces: decimals,
+		SymbolFirst:   true,
+	})
+}
+
+// RegisterCurrencyDefinition registers a complete currency definition, replacing
+// any earlier registration of the same code. Built-in currencies can be
+// overridden this way: the registered definition replaces the default in every
+// Calculator, though a currency given to a Calculator with AddCurrency still
+// wins there, as does SetCurrencyPrecision for precision. Missing separators default to DefaultThousandsSep and
+// DefaultDecimalSep, a missing name to the code, and a missing symbol to the code
+// separated from the amount by a space.
+//
+// Parameters:
+//   - currency: Currency definition; Code is required
+//
+// Returns:
+//   - error: If the code is empty or DecimalPlaces is out of range
+//
+// Example:
+//	currency.RegisterCurrencyDefinition(currency.Currency{
+//		Code:          "VCH",
+//		Name:          "Regional Voucher",
+//		Symbol:        "V",
+//		DecimalPlaces: 2,
+//		ThousandsSep:  ".",
+//		DecimalSep:    ",",
+//		SymbolFirst:   true,
+//		SpaceBetween:  true,
+//	})
+func RegisterCurrencyDefinition(currency Currency) error {
+	currency.Code = CurrencyCode(strings.TrimSpace(string(currency.Code)))
+	if currency.Code == "" {
+		return &CurrencyError{
+			Type:      "validation",
+			Message:   "Currency code is required",
+			Timestamp: time.Now(),
+		}
+	}
+	if currency.DecimalPlaces < 0 || currency.DecimalPlaces > CryptoPrecision {
+		return &CurrencyError{
+			Type:      "validation",
+			Message:   fmt.Sprintf("Currency %s has invalid decimal places %d; expected 0 to %d", currency.Code, currency.DecimalPlaces, CryptoPrecision),
+			Currency:  currency.Code,
+			Timestamp: time.Now(),
+		}
+	}
+
+	if currency.Name == "" {
+		currency.Name = string(currency.Code)
+	}
+	if currency.Symbol == "" {
+		currency.Symbol = string(currency.Code)
+		currency.SpaceBetween = true
+	}
+	if currency.ThousandsSep == "" {
+		currency.ThousandsSep = DefaultThousandsSep
+	}
+	if currency.DecimalSep == "" {
+		currency.DecimalSep = DefaultDecimalSep
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.currencies[currency.Code] = currency
+	return nil
+}
+
+// LookupRegisteredCurrency returns a currency defined with RegisterCurrency or
+// RegisterCurrencyDefinition.
+//
+// Parameters:
+//   - code: Currency code to look up
+//
+// Returns:
+//   - Currency: The registered definition
+//   - bool: True if the code is registered
+func LookupRegisteredCurrency(code CurrencyCode) (Currency, bool) {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	currency, exists := registry.currencies[code]
+	return currency, exists
+}
+
+// registeredCurrencyCodes returns the codes of all registered currencies in
+// sorted order.
+func registeredCurrencyCodes() []CurrencyCode {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	codes := make([]CurrencyCode, 0, len(registry.currencies))
+	for code := range registry.currencies {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+	return codes
+}
@@ -0,0 +1,105 @@
+package currency
+
+import "testing"
+
+func TestRegisterCurrency(t *testing.T) {
+	if err := RegisterCurrency("PTS", "", 0); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := RegisterCurrency("VCH", "V", 2); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	calc := NewCalculator()
+	
+	// Format falls back to the code when no symbol is registered
+	formatted, err := calc.Format(Money{Amount: 1500.4, Currency: "PTS"}, &FormatOptions{ShowSymbol: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if formatted != "PTS 1,500" {
+		t.Errorf("Expected 'PTS 1,500', got '%s'", formatted)
+	}
+	formatted, err = calc.Format(Money{Amount: 12.5, Currency: "VCH"}, &FormatOptions{ShowSymbol: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if formatted != "V12.50" {
+		t.Errorf("Expected 'V12.50', got '%s'", formatted)
+	}
+	
+	// Precision and arithmetic use the registered decimals
+	sum, err := calc.Add(Money{Amount: 10.4, Currency: "PTS"}, Money{Amount: 20.3, Currency: "PTS"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sum.Result.Amount != 31 || sum.Result.Currency != "PTS" {
+		t.Errorf("Expected 31 PTS rounded to whole points, got %+v", sum.Result)
+	}
+	if _, err := calc.Add(Money{Amount: 1, Currency: "PTS"}, Money{Amount: 1, Currency: "VCH"}); err == nil {
+		t.Error("Expected an error adding different custom currencies")
+	}
+	
+	// Lookups and validation see registered currencies
+	if GetCurrencyDecimalPlaces("PTS") != 0 || GetCurrencySymbol("VCH") != "V" || !IsValidCurrencyCode("PTS") {
+		t.Error("Expected package lookups to use the registry")
+	}
+	if err := NewValidator(calc).ValidateMoney(Money{Amount: 5, Currency: "VCH"}); err != nil {
+		t.Errorf("Expected registered currency to validate, got %v", err)
+	}
+	
+	// Invalid definitions are rejected
+	if err := RegisterCurrency("", "X", 2); err == nil {
+		t.Error("Expected an error for an empty code")
+	}
+	if err := RegisterCurrency("BAD", "B", -1); err == nil {
+		t.Error("Expected an error for negative decimals")
+	}
+	if IsValidCurrencyCode("BAD") {
+		t.Error("Expected a rejected currency not to be registered")
+	}
+}
+
+func TestRegisterCurrencyDefinitionOverridesBuiltIn(t *testing.T) {
+	t.Cleanup(func() {
+		registry.mu.Lock()
+		delete(registry.currencies, MYR)
+		registry.mu.Unlock()
+	})
+	calc := NewCalculator()
+	
+	if err := RegisterCurrencyDefinition(Currency{Code: MYR, Name: "Malaysian Ringgit", Symbol: "RM", DecimalPlaces: 0, SymbolFirst: true}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	
+	// Existing and new calculators round MYR to the registered whole ringgit
+	for _, c := range []*Calculator{calc, NewCalculator()} {
+		sum, err := c.Add(Money{Amount: 10.4, Currency: MYR}, Money{Amount: 20.3, Currency: MYR})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if sum.Result.Amount != 31 {
+			t.Errorf("Expected 31 MYR with the overridden precision, got %v", sum.Result.Amount)
+		}
+		currency, err := c.GetCurrency(MYR)
+		if err != nil || currency.DecimalPlaces != 0 {
+			t.Errorf("Expected the registered MYR definition, got %+v (%v)", currency, err)
+		}
+	}
+	formatted, err := calc.Format(Money{Amount: 1234.56, Currency: MYR}, &FormatOptions{ShowSymbol: true})
+	if err != nil || formatted != "RM1,235" {
+		t.Errorf("Expected 'RM1,235', got '%s' (%v)", formatted, err)
+	}
+	
+	// A currency added to the calculator and an explicit precision still win
+	calc.AddCurrency(Currency{Code: MYR, Symbol: "RM", DecimalPlaces: 2, ThousandsSep: ",", DecimalSep: ".", SymbolFirst: true})
+	sum, err := calc.Add(Money{Amount: 10.4, Currency: MYR}, Money{Amount: 20.3, Currency: MYR})
+	if err != nil || sum.Result.Amount != 30.7 {
+		t.Errorf("Expected 30.7 MYR from the added currency, got %v (%v)", sum.Result.Amount, err)
+	}
+	other := NewCalculator()
+	other.SetCurrencyPrecision(MYR, 1)
+	sum, err = other.Add(Money{Amount: 10.44, Currency: MYR}, Money{Amount: 20.3, Currency: MYR})
+	if err != nil || sum.Result.Amount != 30.7 {
+		t.Errorf("Expected 30.7 MYR from SetCurrencyPrecision, got %v (%v)", sum.Result.Amount, err)
+	}
+}
@@ -38,7 +38,10 @@
 //   // Output: "$100.50"
 package currency
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // CurrencyCode represents ISO 4217 standard three-letter currency codes.
 // Used throughout the system to identify currencies in a standardized format.
@@ -57,7 +60,7 @@ type CurrencyCode string
 //   - North America: USD, CAD, MXN
 //   - Europe: EUR, GBP, CHF, SEK, NOK, DKK, RUB, TRY
 //   - Asia-Pacific: JPY, CNY, SGD, MYR, THB, PHP, VND, KRW, INR, IDR, AUD
-//   - Middle East & Africa: SAR, AED, ZAR
+//   - Middle East & Africa: SAR, AED, BHD, ZAR
 //   - South America: BRL
 const (
 	USD CurrencyCode = "USD" // US Dollar - Primary global reserve currency
@@ -86,6 +89,7 @@ const (
 	TRY CurrencyCode = "TRY" // Turkish Lira
 	SAR CurrencyCode = "SAR" // Saudi Riyal
 	AED CurrencyCode = "AED" // UAE Dirham
+	BHD CurrencyCode = "BHD" // Bahraini Dinar - Three decimal places
 )
 
 // Currency represents a complete currency definition with formatting rules.
@@ -156,6 +160,7 @@ type Money struct {
 //   - Rate: Exchange rate multiplier (From * Rate = To)
 //   - Timestamp: When the rate was set or last updated
 //   - Source: Rate provider or source identifier
+//   - Derivation: How the rate was obtained (direct, inverse or identity)
 //
 // Rate Calculation:
 //   - 1 unit of From currency = Rate units of To currency
@@ -175,6 +180,7 @@ type ExchangeRate struct {
 	Rate      float64      `json:"rate"`
 	Timestamp time.Time    `json:"timestamp"`
 	Source    string       `json:"source"`
+	Derivation RateDerivation `json:"derivation,omitempty"`
 }
 
 // ConversionInput represents input parameters for currency conversion.
@@ -214,6 +220,16 @@ type ConversionInput struct {
 //   - OriginalAmount: Input money amount before conversion
 //   - ConvertedAmount: Output money amount after conversion
 //   - ExchangeRate: Exchange rate used for the conversion
+//   - RateUsed: Rate the amount was multiplied by
+//   - RateSource: Source passed to SetExchangeRate ("identity" for same-currency conversions)
+//   - RateTimestamp: When the rate was set
+//   - RateDerivation: Whether the rate was set directly, inverted from the opposite pair, or identity
+//   - Inverted: Whether RateUsed is 1/rate of the opposite pair
+//   - IntermediateCurrency: Base currency a triangulated conversion went through
+//   - FirstLeg, SecondLeg: The from→base and base→to rates of a triangulated conversion
+//   - RateAge: How old the rate was at conversion time
+//   - StaleRate: Whether RateAge exceeded the calculator's maximum rate age
+//   - Warning: Explanation when the conversion used a stale rate
 //   - ConvertedAt: Timestamp when conversion was performed
 //
 // Features:
@@ -227,15 +243,75 @@ type ConversionInput struct {
 //     OriginalAmount:  Money{Amount: 100.00, Currency: USD},
 //     ConvertedAmount: Money{Amount: 85.42, Currency: EUR},
 //     ExchangeRate:    ExchangeRate{From: USD, To: EUR, Rate: 0.8542},
+//     RateUsed:        0.8542,
+//     RateSource:      "ECB",
+//     RateTimestamp:   rateSetAt,
+//     RateDerivation:  RateDerivationDirect,
 //     ConvertedAt:     time.Now(),
 //   }
 type ConversionResult struct {
 	OriginalAmount Money        `json:"original_amount"`
 	ConvertedAmount Money       `json:"converted_amount"`
 	ExchangeRate   ExchangeRate `json:"exchange_rate"`
+	RateUsed       float64      `json:"rate_used"`
+	RateSource     string       `json:"rate_source"`
+	RateTimestamp  time.Time    `json:"rate_timestamp"`
+	RateDerivation RateDerivation `json:"rate_derivation"`
+	Inverted       bool         `json:"inverted"`
+	IntermediateCurrency CurrencyCode `json:"intermediate_currency,omitempty"`
+	FirstLeg       *ExchangeRate `json:"first_leg,omitempty"`
+	SecondLeg      *ExchangeRate `json:"second_leg,omitempty"`
+	RateAge        time.Duration `json:"rate_age"`
+	StaleRate      bool         `json:"stale_rate,omitempty"`
+	Warning        string       `json:"warning,omitempty"`
 	ConvertedAt    time.Time    `json:"converted_at"`
 }
 
+// BatchConversionResult holds the results of Calculator.ConvertAll together with
+// metadata aggregated across the batch, so callers can show or audit which rates
+// a multi-currency cart was converted with without walking every line.
+//
+// Fields:
+//   - Results: Conversion results in input order (zero value for failed inputs)
+//   - Converted: Number of inputs that converted
+//   - Failed: Number of inputs that could not be converted
+//   - Rates: Each distinct exchange rate applied, one per currency pair, in input order
+//   - Sources: Distinct rate sources used, including both legs of triangulated rates
+//   - StaleConversions: Number of conversions that used a stale rate
+//   - OldestRateTimestamp: When the oldest applied rate was set (zero if none)
+//   - Warnings: Stale-rate warnings, one per currency pair
+//
+// Example:
+//   batch, err := calc.ConvertAll(inputs)
+//   // batch.Converted = 3, batch.Rates = [EUR→USD, IDR→USD], batch.Sources = [ecb bi]
+type BatchConversionResult struct {
+	Results             []ConversionResult `json:"results"`
+	Converted           int                `json:"converted"`
+	Failed              int                `json:"failed"`
+	Rates               []ExchangeRate     `json:"rates"`
+	Sources             []string           `json:"sources"`
+	StaleConversions    int                `json:"stale_conversions"`
+	OldestRateTimestamp time.Time          `json:"oldest_rate_timestamp"`
+	Warnings            []string           `json:"warnings,omitempty"`
+}
+
+// RateDerivation records how the exchange rate applied to a conversion was
+// obtained, so auditors can reproduce a converted amount from the configured rates.
+//
+// Derivations:
+//   - RateDerivationDirect: Rate set with SetExchangeRate for this pair
+//   - RateDerivationInverse: 1 / the rate set for the opposite pair
+//   - RateDerivationIdentity: Same-currency conversion at 1.0
+//   - RateDerivationTriangulated: Product of the rates to and from the base currency
+type RateDerivation string
+
+const (
+	RateDerivationDirect   RateDerivation = "direct"   // Set for the pair as given
+	RateDerivationInverse  RateDerivation = "inverse"  // Inverted from the opposite pair
+	RateDerivationIdentity RateDerivation = "identity" // Same currency, rate 1.0
+	RateDerivationTriangulated RateDerivation = "triangulated" // Through the base currency
+)
+
 // FormatOptions represents customizable options for currency formatting.
 // Allows fine-grained control over currency display appearance,
 // overriding default currency formatting rules when specified.
@@ -249,6 +325,10 @@ type ConversionResult struct {
 //   - SymbolFirst: Override symbol position (nil uses currency default)
 //   - SpaceBetween: Override spacing (nil uses currency default)
 //   - NegativeStyle: How to display negative amounts
+//   - NegativeFormat: Accounting-style negatives around the whole amount, symbol
+//     included; takes precedence over NegativeStyle when set
+//   - FallbackToCode: Prefix the ISO code (e.g., "SGD 1,500.00") when ShowSymbol is set
+//     but no symbol is known, and format unregistered currencies instead of failing
 //
 // Negative Styles:
 //   - "parentheses": ($100.00)
@@ -271,8 +351,57 @@ type FormatOptions struct {
 	SymbolFirst   *bool  `json:"symbol_first,omitempty"`
 	SpaceBetween  *bool  `json:"space_between,omitempty"`
 	NegativeStyle string `json:"negative_style,omitempty"` // "parentheses", "minus", "minus_symbol"
+	NegativeFormat NegativeFormat `json:"negative_format,omitempty"`
+	FallbackToCode bool  `json:"fallback_to_code,omitempty"`
 }
 
+// StaleRatePolicy selects what Convert does when the rate it would use is older
+// than the maximum age set with SetMaxRateAge.
+//
+// Policies:
+//   - StaleRateWarn: Convert anyway and flag the result with StaleRate and Warning
+//   - StaleRateError: Refuse to convert and return an error wrapping ErrStaleRate
+type StaleRatePolicy string
+
+const (
+	StaleRateWarn  StaleRatePolicy = "warn"  // Convert and report a warning
+	StaleRateError StaleRatePolicy = "error" // Fail the conversion
+)
+
+// RateInfo describes the rate Convert would use for a pair and how old it is.
+//
+// Example:
+//   info, _ := calc.GetRateInfo(USD, IDR)
+//   if info.Stale {
+//     // block checkout until the feed recovers
+//   }
+type RateInfo struct {
+	From       CurrencyCode   `json:"from"`
+	To         CurrencyCode   `json:"to"`
+	Rate       float64        `json:"rate"`
+	Source     string         `json:"source"`
+	Timestamp  time.Time      `json:"timestamp"`
+	Age        time.Duration  `json:"age"`
+	Stale      bool           `json:"stale"` // Older than the calculator's maximum rate age
+	Derivation RateDerivation `json:"derivation"`
+}
+
+// NegativeFormat selects how FormatOptions.NegativeFormat marks negative
+// amounts. Unlike NegativeStyle, the marker surrounds the complete formatted
+// amount including any symbol or code, as finance exports expect.
+//
+// Formats (for -1234.56 USD with ShowSymbol):
+//   - NegativeFormatMinus: -$1,234.56
+//   - NegativeFormatParentheses: ($1,234.56)
+//   - NegativeFormatMinusAfter: $1,234.56-
+type NegativeFormat string
+
+const (
+	NegativeFormatMinus       NegativeFormat = "minus"       // Leading minus sign
+	NegativeFormatParentheses NegativeFormat = "parentheses" // Amount wrapped in parentheses
+	NegativeFormatMinusAfter  NegativeFormat = "minus_after" // Trailing minus sign
+)
+
 // RoundingMode represents different rounding strategies for currency calculations.
 // Provides precise control over how fractional currency amounts are rounded
 // to match currency-specific decimal place requirements.
@@ -391,7 +520,8 @@ type ArithmeticResult struct {
 // Fields:
 //   - Amount1: First money amount in comparison
 //   - Amount2: Second money amount in comparison
-//   - IsEqual: Whether amounts are exactly equal
+//   - Comparison: -1, 0 or 1 as Amount1 is less than, equal to or greater than Amount2
+//   - IsEqual: Whether amounts are equal within the currency's precision
 //   - IsGreater: Whether Amount1 > Amount2
 //   - IsLess: Whether Amount1 < Amount2
 //   - Difference: Absolute difference between amounts
@@ -399,13 +529,14 @@ type ArithmeticResult struct {
 //
 // Comparison Logic:
 //   - Requires same currency for both amounts
-//   - Uses precise decimal comparison
+//   - Amounts within half of the currency's smallest unit are equal
 //   - Difference is always positive (absolute value)
 //
 // Example:
 //   result := ComparisonResult{
 //     Amount1:    Money{Amount: 100.50, Currency: USD},
 //     Amount2:    Money{Amount: 75.25, Currency: USD},
+//     Comparison: 1,
 //     IsEqual:    false,
 //     IsGreater:  true,
 //     IsLess:     false,
@@ -415,6 +546,7 @@ type ArithmeticResult struct {
 type ComparisonResult struct {
 	Amount1     Money     `json:"amount1"`
 	Amount2     Money     `json:"amount2"`
+	Comparison  int       `json:"comparison"` // -1, 0 or 1 as amount1 is less than, equal to or greater than amount2
 	IsEqual     bool      `json:"is_equal"`
 	IsGreater   bool      `json:"is_greater"`
 	IsLess      bool      `json:"is_less"`
@@ -506,15 +638,32 @@ type CurrencyError struct {
 	Message     string             `json:"message"`
 	Currency    CurrencyCode       `json:"currency,omitempty"`
 	Validations []ValidationError  `json:"validations,omitempty"`
+	Causes      []error            `json:"-"` // Underlying errors of a combined batch failure
 	Timestamp   time.Time          `json:"timestamp"`
 }
 
+// ErrCurrencyMismatch is wrapped by the *CurrencyError returned when an
+// operation receives amounts in different currencies; check for it with
+// errors.Is(err, ErrCurrencyMismatch).
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrStaleRate is wrapped by the *CurrencyError Convert returns when the rate
+// it would use is older than the calculator's maximum rate age and the
+// StaleRateError policy is set; check for it with errors.Is(err, ErrStaleRate).
+var ErrStaleRate = errors.New("stale exchange rate")
+
 // Error implements the error interface for CurrencyError.
 // Returns the primary error message for standard error handling.
 func (e *CurrencyError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying errors of a combined error, so errors.Is and
+// errors.As can reach the individual conversion failures.
+func (e *CurrencyError) Unwrap() []error {
+	return e.Causes
+}
+
 // LocaleInfo represents locale-specific currency information.
 // Provides localization context for currency display and formatting
 // according to regional preferences and standards.
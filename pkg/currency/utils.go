@@ -233,7 +233,7 @@ func (v *Validator) ValidateExchangeRate(rate ExchangeRate) *ValidationError {
 //   - Error handling for individual conversion failures
 //   - Sum calculation across different currencies
 //   - Automatic rounding according to target currency rules
-//   - Efficient processing with minimal calculator calls
+//   - One rate lookup per currency pair via Calculator.ConvertBatch
 //
 // Use Cases:
 //   - Converting shopping cart items to display currency
@@ -296,22 +296,25 @@ func NewBatchConverter(calculator *Calculator) *BatchConverter {
 //     fmt.Printf("Some conversions failed: %v", errors)
 //   }
 func (bc *BatchConverter) ConvertBatch(amounts []Money, targetCurrency CurrencyCode) ([]ConversionResult, []error) {
-	results := make([]ConversionResult, 0, len(amounts))
-	errors := make([]error, 0)
-	
+	inputs := make([]ConversionInput, len(amounts))
 	for i, amount := range amounts {
-		result, err := bc.calculator.Convert(ConversionInput{
+		inputs[i] = ConversionInput{
 			Amount: amount.Amount,
 			From:   amount.Currency,
 			To:     targetCurrency,
-		})
-		
+		}
+	}
+	converted, errs := bc.calculator.ConvertBatch(inputs)
+	
+	results := make([]ConversionResult, 0, len(amounts))
+	errors := make([]error, 0)
+	for i, err := range errs {
 		if err != nil {
 			errors = append(errors, fmt.Errorf("conversion %d failed: %w", i, err))
 			continue
 		}
 		
-		results = append(results, *result)
+		results = append(results, converted[i])
 	}
 	
 	return results, errors
@@ -322,7 +325,7 @@ func (bc *BatchConverter) ConvertBatch(amounts []Money, targetCurrency CurrencyC
 // applying proper rounding according to the target currency's decimal places.
 //
 // Process:
-//   1. Convert all amounts to target currency using ConvertBatch
+//   1. Convert all amounts to target currency using Calculator.SumInCurrency
 //   2. Sum all converted amounts
 //   3. Apply target currency rounding rules
 //   4. Return total as Money in target currency
@@ -333,7 +336,7 @@ func (bc *BatchConverter) ConvertBatch(amounts []Money, targetCurrency CurrencyC
 //
 // Returns:
 //   - *Money: Total sum in target currency
-//   - error: Error if any conversion fails
+//   - error: Error if the target currency is unknown or any conversion fails
 //
 // Example:
 //   amounts := []Money{
@@ -344,29 +347,12 @@ func (bc *BatchConverter) ConvertBatch(amounts []Money, targetCurrency CurrencyC
 //   total, err := converter.SumInCurrency(amounts, USD)
 //   // Returns total in USD, e.g., $250.75
 func (bc *BatchConverter) SumInCurrency(amounts []Money, targetCurrency CurrencyCode) (*Money, error) {
-	conversions, errors := bc.ConvertBatch(amounts, targetCurrency)
-	
-	if len(errors) > 0 {
-		return nil, fmt.Errorf("conversion errors: %v", errors)
-	}
-	
-	var total float64
-	for _, conversion := range conversions {
-		total += conversion.ConvertedAmount.Amount
-	}
-	
-	// Round according to target currency
-	currency, err := bc.calculator.GetCurrency(targetCurrency)
+	total, err := bc.calculator.SumInCurrency(amounts, targetCurrency)
 	if err != nil {
 		return nil, err
 	}
 	
-	total = bc.calculator.roundAmount(total, currency.DecimalPlaces, bc.calculator.defaultRounding)
-	
-	return &Money{
-		Amount:   total,
-		Currency: targetCurrency,
-	}, nil
+	return &total, nil
 }
 
 // CurrencyDetector provides intelligent currency detection and extraction from text.
@@ -0,0 +1,293 @@
+package currency
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// currencyWords holds the spoken names of a currency's major and minor units.
+// Plural forms are only used by languages that inflect nouns (English).
+type currencyWords struct {
+	Major       string
+	MajorPlural string
+	Minor       string
+	MinorPlural string
+}
+
+// amountWordsLanguages lists the languages supported by AmountToWords.
+var amountWordsLanguages = map[string]bool{
+	"en": true,
+	"id": true,
+}
+
+// currencyWordNames maps language codes to the spoken unit names of common currencies.
+// Currencies not listed fall back to their configured Name.
+var currencyWordNames = map[string]map[CurrencyCode]currencyWords{
+	"en": {
+		USD: {Major: "dollar", MajorPlural: "dollars", Minor: "cent", MinorPlural: "cents"},
+		EUR: {Major: "euro", MajorPlural: "euros", Minor: "cent", MinorPlural: "cents"},
+		GBP: {Major: "pound", MajorPlural: "pounds", Minor: "penny", MinorPlural: "pence"},
+		JPY: {Major: "yen", MajorPlural: "yen"},
+		IDR: {Major: "rupiah", MajorPlural: "rupiah", Minor: "sen", MinorPlural: "sen"},
+		SGD: {Major: "Singapore dollar", MajorPlural: "Singapore dollars", Minor: "cent", MinorPlural: "cents"},
+		MYR: {Major: "ringgit", MajorPlural: "ringgit", Minor: "sen", MinorPlural: "sen"},
+		AUD: {Major: "Australian dollar", MajorPlural: "Australian dollars", Minor: "cent", MinorPlural: "cents"},
+	},
+	"id": {
+		USD: {Major: "dolar Amerika", Minor: "sen"},
+		EUR: {Major: "euro", Minor: "sen"},
+		GBP: {Major: "pound sterling", Minor: "penny"},
+		JPY: {Major: "yen"},
+		IDR: {Major: "rupiah", Minor: "sen"},
+		SGD: {Major: "dolar Singapura", Minor: "sen"},
+		MYR: {Major: "ringgit", Minor: "sen"},
+		AUD: {Major: "dolar Australia", Minor: "sen"},
+	},
+}
+
+// maxAmountInWords is the largest whole amount AmountToWords can spell out
+// (just under one quadrillion).
+const maxAmountInWords = 999999999999999
+
+// maxMinorUnitsInWords bounds the amount in minor units: a float64 holds every
+// whole number only up to 2^53, so larger counts of cents would be misspelled.
+const maxMinorUnitsInWords = 1 << 53
+
+var (
+	englishOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+		"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []string{"", "thousand", "million", "billion", "trillion"}
+
+	indonesianOnes   = []string{"nol", "satu", "dua", "tiga", "empat", "lima", "enam", "tujuh", "delapan", "sembilan"}
+	indonesianScales = []string{"", "ribu", "juta", "miliar", "triliun"}
+)
+
+// AmountToWords spells out a monetary amount for formal documents such as printed invoices.
+// Both the major unit and, for currencies with decimal places, the minor unit
+// (cents, sen) are written out. Minor units are omitted when zero.
+//
+// Supported Locales:
+//   - "en" (also "en-US", "en_GB", ...): English
+//   - "id" (also "id-ID"): Indonesian
+//
+// Parameters:
+//   - amount: Amount to spell out; negative amounts are prefixed with "minus"
+//   - currency: Currency providing the code, name, and decimal places
+//   - locale: Language of the output
+//
+// Returns:
+//   - string: Amount in words
+//   - error: Error if the locale is unsupported, the amount is not finite, its whole
+//     part exceeds 999,999,999,999,999 or it has more than 2^53 minor units (about
+//     90 trillion with 2 decimal places, 90 million with 8), or the currency has
+//     no usable name
+//
+// Example:
+//   idr := Currency{Code: IDR, Name: "Indonesian Rupiah", DecimalPlaces: 0}
+//   words, _ := AmountToWords(1500000, idr, "id")
+//   // Returns: "satu juta lima ratus ribu rupiah"
+//
+//   usd := Currency{Code: USD, Name: "US Dollar", DecimalPlaces: 2}
+//   words, _ = AmountToWords(1234.56, usd, "en")
+//   // Returns: "one thousand two hundred thirty-four dollars and fifty-six cents"
+func AmountToWords(amount float64, currency Currency, locale string) (string, error) {
+	language := strings.ToLower(locale)
+	if i := strings.IndexAny(language, "-_"); i >= 0 {
+		language = language[:i]
+	}
+	if !amountWordsLanguages[language] {
+		return "", &CurrencyError{
+			Type:      "unsupported_locale",
+			Message:   fmt.Sprintf("Locale %s is not supported for amounts in words", locale),
+			Currency:  currency.Code,
+			Timestamp: time.Now(),
+		}
+	}
+
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return "", &CurrencyError{
+			Type:      "invalid_amount",
+			Message:   fmt.Sprintf("Amount %v cannot be written in words", amount),
+			Currency:  currency.Code,
+			Timestamp: time.Now(),
+		}
+	}
+
+	units, exists := currencyWordNames[language][currency.Code]
+	if !exists {
+		if currency.Name == "" {
+			return "", &CurrencyError{
+				Type:      "invalid_currency",
+				Message:   fmt.Sprintf("Currency %s has no name to write in words", currency.Code),
+				Currency:  currency.Code,
+				Timestamp: time.Now(),
+			}
+		}
+		units = currencyWords{Major: currency.Name, Minor: "sen"}
+		if language == "en" {
+			units.Minor, units.MinorPlural = "cent", "cents"
+		}
+	}
+
+	decimalPlaces := currency.DecimalPlaces
+	if decimalPlaces < 0 {
+		decimalPlaces = 0
+	}
+	// Check the range before converting, since an out-of-range float64 to int64
+	// conversion does not fail but yields a meaningless number
+	scaledAmount := math.Round(math.Abs(amount) * math.Pow(10, float64(decimalPlaces)))
+	if decimalPlaces > CryptoPrecision || scaledAmount > maxMinorUnitsInWords ||
+		math.Floor(scaledAmount/math.Pow(10, float64(decimalPlaces))) > maxAmountInWords {
+		return "", &CurrencyError{
+			Type:      "invalid_amount",
+			Message:   fmt.Sprintf("Amount %v is outside the range that can be written in words with %d decimal places", amount, decimalPlaces),
+			Currency:  currency.Code,
+			Timestamp: time.Now(),
+		}
+	}
+	scale := int64(math.Pow(10, float64(decimalPlaces)))
+	totalMinor := int64(scaledAmount)
+	major := totalMinor / scale
+	minor := totalMinor % scale
+
+	toWords := numberToEnglishWords
+	conjunction := "and"
+	if language == "id" {
+		toWords = numberToIndonesianWords
+		conjunction = "dan"
+	}
+
+	parts := make([]string, 0, 5)
+	if amount < 0 && totalMinor > 0 {
+		parts = append(parts, "minus")
+	}
+	if major > 0 || minor == 0 || units.Minor == "" {
+		parts = append(parts, toWords(major), unitName(units.Major, units.MajorPlural, major))
+	}
+	if minor > 0 && units.Minor != "" {
+		if major > 0 {
+			parts = append(parts, conjunction)
+		}
+		parts = append(parts, toWords(minor), unitName(units.Minor, units.MinorPlural, minor))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// unitName picks the singular or plural unit name for a count.
+// Languages without plural forms leave plural empty.
+func unitName(singular, plural string, count int64) string {
+	if count != 1 && plural != "" {
+		return plural
+	}
+	return singular
+}
+
+// numberToEnglishWords spells out a non-negative whole number in English,
+// e.g. 1234 becomes "one thousand two hundred thirty-four".
+func numberToEnglishWords(n int64) string {
+	if n == 0 {
+		return englishOnes[0]
+	}
+
+	groups := make([]string, 0, len(englishScales))
+	for scale := 0; n > 0; scale++ {
+		group := n % 1000
+		n /= 1000
+		if group == 0 {
+			continue
+		}
+
+		words := englishGroupWords(group)
+		if englishScales[scale] != "" {
+			words += " " + englishScales[scale]
+		}
+		groups = append([]string{words}, groups...)
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// englishGroupWords spells out a number from 1 to 999 in English.
+func englishGroupWords(n int64) string {
+	words := make([]string, 0, 3)
+	if n >= 100 {
+		words = append(words, englishOnes[n/100], "hundred")
+		n %= 100
+	}
+	if n >= 20 {
+		tens := englishTens[n/10]
+		if n%10 > 0 {
+			tens += "-" + englishOnes[n%10]
+		}
+		words = append(words, tens)
+	} else if n > 0 {
+		words = append(words, englishOnes[n])
+	}
+	return strings.Join(words, " ")
+}
+
+// numberToIndonesianWords spells out a non-negative whole number in Indonesian,
+// e.g. 1500000 becomes "satu juta lima ratus ribu". One thousand is "seribu".
+func numberToIndonesianWords(n int64) string {
+	if n == 0 {
+		return indonesianOnes[0]
+	}
+
+	groups := make([]string, 0, len(indonesianScales))
+	for scale := 0; n > 0; scale++ {
+		group := n % 1000
+		n /= 1000
+		if group == 0 {
+			continue
+		}
+
+		var words string
+		switch {
+		case scale == 1 && group == 1:
+			words = "seribu"
+		case scale == 0:
+			words = indonesianGroupWords(group)
+		default:
+			words = indonesianGroupWords(group) + " " + indonesianScales[scale]
+		}
+		groups = append([]string{words}, groups...)
+	}
+
+	return strings.Join(groups, " ")
+}
+
+// indonesianGroupWords spells out a number from 1 to 999 in Indonesian.
+func indonesianGroupWords(n int64) string {
+	words := make([]string, 0, 3)
+
+	hundreds := n / 100
+	switch {
+	case hundreds == 1:
+		words = append(words, "seratus")
+	case hundreds > 1:
+		words = append(words, indonesianOnes[hundreds]+" ratus")
+	}
+
+	n %= 100
+	switch {
+	case n == 10:
+		words = append(words, "sepuluh")
+	case n == 11:
+		words = append(words, "sebelas")
+	case n > 11 && n < 20:
+		words = append(words, indonesianOnes[n%10]+" belas")
+	case n >= 20:
+		words = append(words, indonesianOnes[n/10]+" puluh")
+		if n%10 > 0 {
+			words = append(words, indonesianOnes[n%10])
+		}
+	case n > 0:
+		words = append(words, indonesianOnes[n])
+	}
+
+	return strings.Join(words, " ")
+}
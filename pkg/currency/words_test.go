@@ -0,0 +1,121 @@
+package currency
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAmountToWords(t *testing.T) {
+	usd := Currency{Code: USD, Name: "US Dollar", DecimalPlaces: 2}
+	idr := Currency{Code: IDR, Name: "Indonesian Rupiah", DecimalPlaces: 2}
+	jpy := Currency{Code: JPY, Name: "Japanese Yen", DecimalPlaces: 0}
+	thb := Currency{Code: THB, Name: "Thai Baht", DecimalPlaces: 2}
+
+	tests := []struct {
+		name     string
+		amount   float64
+		currency Currency
+		locale   string
+		expected string
+	}{
+		{"en zero", 0, usd, "en", "zero dollars"},
+		{"en one", 1, usd, "en", "one dollar"},
+		{"en cents only", 0.45, usd, "en", "forty-five cents"},
+		{"en one cent", 2.01, usd, "en", "two dollars and one cent"},
+		{"en with cents", 1234.56, usd, "en-US", "one thousand two hundred thirty-four dollars and fifty-six cents"},
+		{"en millions", 1500000, usd, "en", "one million five hundred thousand dollars"},
+		{"en skipped groups", 2000000017, usd, "en_GB", "two billion seventeen dollars"},
+		{"en negative", -12.5, usd, "en", "minus twelve dollars and fifty cents"},
+		{"en rupiah", 1500000, idr, "en", "one million five hundred thousand rupiah"},
+		{"en zero decimal currency", 1999.6, jpy, "en", "two thousand yen"},
+		{"en fallback name", 3, thb, "en", "three Thai Baht"},
+		{"id invoice amount", 1500000, idr, "id", "satu juta lima ratus ribu rupiah"},
+		{"id seribu", 1000, idr, "id-ID", "seribu rupiah"},
+		{"id seratus sebelas", 111, idr, "id", "seratus sebelas rupiah"},
+		{"id belas and puluh", 19.75, idr, "id", "sembilan belas rupiah dan tujuh puluh lima sen"},
+		{"id sepuluh ribu", 10000, idr, "id", "sepuluh ribu rupiah"},
+		{"id large", 2345678901, idr, "id", "dua miliar tiga ratus empat puluh lima juta enam ratus tujuh puluh delapan ribu sembilan ratus satu rupiah"},
+		{"id one thousand thousands", 1001000, idr, "id", "satu juta seribu rupiah"},
+		{"id dollars", 25.5, usd, "id", "dua puluh lima dolar Amerika dan lima puluh sen"},
+		{"id zero", 0, idr, "id", "nol rupiah"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AmountToWords(tt.amount, tt.currency, tt.locale)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("AmountToWords(%v, %s, %s) = %q, want %q", tt.amount, tt.currency.Code, tt.locale, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAmountToWordsErrors(t *testing.T) {
+	usd := Currency{Code: USD, Name: "US Dollar", DecimalPlaces: 2}
+
+	if _, err := AmountToWords(10, usd, "fr"); err == nil {
+		t.Error("Expected error for unsupported locale")
+	}
+	if _, err := AmountToWords(1e16, usd, "en"); err == nil {
+		t.Error("Expected error for amount too large")
+	}
+	if _, err := AmountToWords(10, Currency{Code: "XXX", DecimalPlaces: 2}, "en"); err == nil {
+		t.Error("Expected error for currency without a name")
+	}
+}
+
+func TestAmountToWordsRange(t *testing.T) {
+	usd := Currency{Code: USD, Name: "US Dollar", DecimalPlaces: 2}
+	jpy := Currency{Code: JPY, Name: "Japanese Yen", DecimalPlaces: 0}
+	btc := Currency{Code: "BTC", Name: "Bitcoin", DecimalPlaces: 8}
+
+	largest := "nine hundred ninety-nine trillion nine hundred ninety-nine billion nine hundred ninety-nine million nine hundred ninety-nine thousand nine hundred ninety-nine"
+	tests := []struct {
+		name     string
+		amount   float64
+		currency Currency
+		expected string
+	}{
+		{"largest whole amount", 999999999999999, jpy, largest + " yen"},
+		{"rounds down to the largest", 999999999999999.4, jpy, largest + " yen"},
+		{"largest negative cents", -90071992547409.91, usd, "minus ninety trillion seventy-one billion nine hundred ninety-two million five hundred forty-seven thousand four hundred nine dollars and ninety-one cents"},
+		{"most satoshis", 90000000.12345678, btc, "ninety million Bitcoin and twelve million three hundred forty-five thousand six hundred seventy-eight cents"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AmountToWords(tt.amount, tt.currency, "en")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("AmountToWords(%v, %s) = %q, want %q", tt.amount, tt.currency.Code, got, tt.expected)
+			}
+		})
+	}
+
+	outOfRange := []struct {
+		name     string
+		amount   float64
+		currency Currency
+	}{
+		{"one quadrillion", 1e15, jpy},
+		{"rounds up to one quadrillion", 999999999999999.6, jpy},
+		{"cents beyond 2^53", 999999999999999, usd},
+		{"negative cents beyond 2^53", -90071992547410, usd},
+		{"satoshis beyond 2^53", 1e8, btc},
+		{"beyond int64", 1e300, usd},
+		{"too many decimal places", 1, Currency{Code: "XTS", Name: "Test", DecimalPlaces: 19}},
+	}
+	for _, tt := range outOfRange {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AmountToWords(tt.amount, tt.currency, "en")
+			var currencyErr *CurrencyError
+			if !errors.As(err, &currencyErr) || currencyErr.Type != "invalid_amount" {
+				t.Errorf("AmountToWords(%v, %s) = %q, %v; want an invalid_amount error", tt.amount, tt.currency.Code, got, err)
+			}
+		})
+	}
+}
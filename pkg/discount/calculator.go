@@ -27,8 +27,12 @@
 package discount
 
 import (
+	"fmt"
 	"math"
+	"sort"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // Calculate calculates all applicable discounts for the given input.
@@ -42,14 +46,34 @@ import (
 //   - Final amount and savings percentage calculation
 //   - Precision rounding to 2 decimal places
 //   - Comprehensive error handling and validation
+//   - Optional skipped rule reasons via input.Explain
+//
+// With input.CustomerFavorableRounding, fractions of a cent resolve in the
+// customer's favor: the total discount is rounded up (never beyond the original
+// amount) and the final amount is rounded down.
+//
+// Items must have a quantity of at least one and a non-negative price; otherwise
+// the result is invalid. Zero-price items are allowed and contribute nothing.
 //
 // Discount Application Order (when stacking):
 //   1. Tier pricing (changes base price)
-//   2. Bulk discounts
-//   3. Bundle discounts
-//   4. Category discounts
-//   5. Progressive discounts
-//   6. Loyalty discounts (applied last)
+//   2. Bulk discounts (combined according to input.ApplicationOrder)
+//   3. Tiered bulk discounts
+//   4. Bundle discounts
+//   5. Mix for fixed price discounts
+//   6. Buy X get Y discounts
+//   7. Category discounts
+//   8. Progressive discounts
+//   9. Formula discounts
+//   10. Loyalty discounts
+//   11. Order threshold discounts (applied last, on the post-item-discount subtotal)
+//
+// Item price floors are enforced after the loyalty discounts, and the stacking
+// cap after the order threshold discounts.
+//
+// The result splits TotalDiscount into ItemDiscount and OrderDiscount. When the
+// stacking cap trims the total, the order-level discount is reduced first.
+// ItemBreakdown attributes TotalDiscount to the individual items.
 //
 // Parameters:
 //   - input: DiscountCalculationInput containing items, rules, and configuration
@@ -78,6 +102,12 @@ func Calculate(input DiscountCalculationInput) DiscountCalculationResult {
 		AppliedDiscounts: []DiscountApplication{},
 	}
 
+	if err := validateItems(input.Items); err != nil {
+		result.IsValid = false
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
 	// Calculate original amount
 	result.OriginalAmount = calculateOriginalAmount(input.Items)
 
@@ -101,13 +131,379 @@ func Calculate(input DiscountCalculationInput) DiscountCalculationResult {
 	}
 
 	// Round to 2 decimal places
-	result.TotalDiscount = math.Round(result.TotalDiscount*100) / 100
-	result.FinalAmount = math.Round(result.FinalAmount*100) / 100
-	result.SavingsPercent = math.Round(result.SavingsPercent*100) / 100
+	if input.CustomerFavorableRounding {
+		result.TotalDiscount = math.Min(utils.RoundWithMode(result.TotalDiscount, 2, utils.RoundUp), result.OriginalAmount)
+		result.FinalAmount = utils.RoundWithMode(result.OriginalAmount-result.TotalDiscount, 2, utils.RoundDown)
+	} else {
+		result.TotalDiscount = math.Round(result.TotalDiscount*100) / 100
+		result.FinalAmount = math.Round(result.FinalAmount*100) / 100
+	}
+	result.SavingsPercent = utils.RoundToPercent(result.SavingsPercent)
+	roundAppliedDiscounts(&result, input.CustomerFavorableRounding)
+	splitOrderDiscount(&result)
+	reconcileDiscountedUnits(&result)
+	result.ItemBreakdown = attributeItemDiscounts(input, result)
 
 	return result
 }
 
+// attributeItemDiscounts breaks the rounded TotalDiscount down by item. Each
+// application's discount is spread over its applied items in proportion to
+// their value; order threshold discounts are spread by each line's value after
+// item-level discounts, since that is the subtotal they were taken from. Items
+// never take more than their line total, or (Price - MinPrice) × Quantity when
+// they have a price floor, while other items can take the rest. The item-level
+// and order-level shares are then rounded with utils.AllocateProportional so they
+// sum exactly to ItemDiscount and OrderDiscount, which also applies any trimming
+// by the stacking cap. Items with the same ID are combined.
+//
+// Parameters:
+//   - input: DiscountCalculationInput whose items are broken down
+//   - result: Result with rounded totals and applied discounts
+//
+// Returns:
+//   - []ItemDiscountBreakdown: One entry per item ID, in input order
+//
+// Example:
+//   // $200 + $50 cart, $20 bulk discount on the first item, then 10% off $230
+//   // First item: 20 + 18 = $38 off; second item: $5 off
+func attributeItemDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) []ItemDiscountBreakdown {
+	ids := []string{}
+	lineTotals := map[string]float64{}
+	capacity := map[string]float64{}
+	for _, item := range input.Items {
+		if _, seen := lineTotals[item.ID]; !seen {
+			ids = append(ids, item.ID)
+		}
+		lineTotals[item.ID] += item.Price * float64(item.Quantity)
+		capacity[item.ID] += math.Max(item.Price-item.MinPrice, 0) * float64(item.Quantity)
+	}
+
+	itemShares := map[string]float64{}
+	orderShares := map[string]float64{}
+	ruleIDs := map[string][]string{}
+	for _, application := range result.AppliedDiscounts {
+		if application.Type == DiscountTypeOrderThreshold {
+			continue
+		}
+		weights := map[string]float64{}
+		order := []string{}
+		for _, item := range application.AppliedItems {
+			if _, seen := weights[item.ID]; !seen {
+				order = append(order, item.ID)
+			}
+			weights[item.ID] += item.Price * float64(item.Quantity)
+		}
+		shares := distributeDiscount(application.DiscountAmount, order, weights, capacity)
+		for _, id := range order {
+			itemShares[id] += shares[id]
+			ruleIDs[id] = appendRuleID(ruleIDs[id], application.RuleID, shares[id])
+		}
+	}
+
+	// Order-level discounts come off what is left of each line
+	for _, application := range result.AppliedDiscounts {
+		if application.Type != DiscountTypeOrderThreshold {
+			continue
+		}
+		weights := map[string]float64{}
+		for _, id := range ids {
+			weights[id] = math.Max(lineTotals[id]-itemShares[id]-orderShares[id], 0)
+		}
+		shares := distributeDiscount(application.DiscountAmount, ids, weights, capacity)
+		for _, id := range ids {
+			orderShares[id] += shares[id]
+			ruleIDs[id] = appendRuleID(ruleIDs[id], application.RuleID, shares[id])
+		}
+	}
+
+	itemParts := make([]float64, len(ids))
+	orderParts := make([]float64, len(ids))
+	for i, id := range ids {
+		itemParts[i] = itemShares[id]
+		orderParts[i] = orderShares[id]
+	}
+	if input.ReconcileLargestLine {
+		lineAmounts := make([]float64, len(ids))
+		for i, id := range ids {
+			lineAmounts[i] = lineTotals[id]
+		}
+		itemParts = reconcileLargestLine(result.ItemDiscount, scaleParts(itemParts, result.ItemDiscount), lineAmounts)
+		orderParts = reconcileLargestLine(result.OrderDiscount, scaleParts(orderParts, result.OrderDiscount), lineAmounts)
+	} else {
+		itemParts = utils.AllocateProportional(result.ItemDiscount, itemParts, 2)
+		orderParts = utils.AllocateProportional(result.OrderDiscount, orderParts, 2)
+	}
+
+	breakdown := make([]ItemDiscountBreakdown, len(ids))
+	for i, id := range ids {
+		original := math.Round(lineTotals[id]*100) / 100
+		discount := math.Round((itemParts[i]+orderParts[i])*100) / 100
+		breakdown[i] = ItemDiscountBreakdown{
+			ItemID: id,
+			OriginalAmount: original,
+			DiscountAmount: discount,
+			FinalAmount: math.Round((original-discount)*100) / 100,
+			RuleIDs: ruleIDs[id],
+		}
+	}
+	return breakdown
+}
+
+// scaleParts rescales unrounded parts so they sum to total, keeping their
+// proportions. This carries trimming by the stacking cap into the parts before
+// they are rounded. Parts that sum to zero are returned unchanged.
+//
+// Parameters:
+//   - parts: Unrounded parts
+//   - total: Amount the parts should sum to
+//
+// Returns:
+//   - []float64: Scaled parts
+func scaleParts(parts []float64, total float64) []float64 {
+	sum := utils.Sum(parts)
+	if sum == 0 {
+		return parts
+	}
+	scaled := make([]float64, len(parts))
+	for i, part := range parts {
+		scaled[i] = part * total / sum
+	}
+	return scaled
+}
+
+// reconcileLargestLine rounds each part to cents on its own and then moves the
+// difference between their sum and total, rounded to cents, onto the part with
+// the largest line amount (the first one on ties). The parts then add up to
+// total exactly while every other line keeps its independently rounded value.
+//
+// Parameters:
+//   - total: Amount the rounded parts must add up to
+//   - parts: Unrounded parts
+//   - lineAmounts: Original amount of each line, used to pick the largest line
+//
+// Returns:
+//   - []float64: Parts rounded to cents that sum to total
+//
+// Example:
+//   // 20% off three $3.33 lines: 0.666 each rounds to 0.67, 2.01 in total,
+//   // but 20% of $9.99 is 2.00, so the first line becomes 0.66
+//   parts := reconcileLargestLine(2.00, []float64{0.666, 0.666, 0.666}, []float64{3.33, 3.33, 3.33})
+func reconcileLargestLine(total float64, parts []float64, lineAmounts []float64) []float64 {
+	rounded := make([]float64, len(parts))
+	largest := -1
+	for i, part := range parts {
+		rounded[i] = math.Round(part*100) / 100
+		if part > 0 && (largest < 0 || lineAmounts[i] > lineAmounts[largest]) {
+			largest = i
+		}
+	}
+	if largest < 0 {
+		return rounded
+	}
+
+	drift := math.Round((total-utils.Sum(rounded))*100) / 100
+	rounded[largest] = math.Round((rounded[largest]+drift)*100) / 100
+	return rounded
+}
+
+// distributeDiscount spreads an amount over items in proportion to their
+// weights without giving any item more than its remaining capacity; what a full
+// item cannot take is passed on to the others. Only if every item is full is the
+// rest spread by weight regardless of capacity. Capacity is reduced by the
+// shares handed out.
+//
+// Parameters:
+//   - amount: Discount to spread
+//   - ids: Item IDs in a stable order
+//   - weights: Relative share of each item; items without weight get nothing
+//   - capacity: Remaining discount each item can take, updated in place
+//
+// Returns:
+//   - map[string]float64: Unrounded share of each item
+//
+// Example:
+//   // $15 over two $50 items, the first of which can take only $5 more
+//   // shares = {"a": 5, "b": 10}
+func distributeDiscount(amount float64, ids []string, weights map[string]float64, capacity map[string]float64) map[string]float64 {
+	shares := map[string]float64{}
+	open := []string{}
+	for _, id := range ids {
+		if weights[id] > 0 {
+			open = append(open, id)
+		}
+	}
+
+	remaining := amount
+	for remaining > percentCapTolerance && len(open) > 0 {
+		weightSum := 0.0
+		for _, id := range open {
+			weightSum += weights[id]
+		}
+
+		next := []string{}
+		overflow := 0.0
+		for _, id := range open {
+			share := remaining * weights[id] / weightSum
+			room := math.Max(capacity[id]-shares[id], 0)
+			if share >= room {
+				shares[id] += room
+				overflow += share - room
+				continue
+			}
+			shares[id] += share
+			next = append(next, id)
+		}
+		remaining, open = overflow, next
+	}
+
+	if remaining > percentCapTolerance {
+		weightSum := 0.0
+		for _, id := range ids {
+			weightSum += math.Max(weights[id], 0)
+		}
+		for _, id := range ids {
+			if weightSum > 0 {
+				shares[id] += remaining * math.Max(weights[id], 0) / weightSum
+			}
+		}
+	}
+
+	for id, share := range shares {
+		capacity[id] -= share
+	}
+	return shares
+}
+
+// appendRuleID adds a rule ID to an item's list of contributing rules when the
+// rule gave the item a share of its discount, skipping duplicates.
+//
+// Parameters:
+//   - ruleIDs: Rules recorded for the item so far
+//   - ruleID: Rule to add
+//   - share: Discount the rule gave the item
+//
+// Returns:
+//   - []string: Updated rule IDs
+func appendRuleID(ruleIDs []string, ruleID string, share float64) []string {
+	if share <= percentCapTolerance {
+		return ruleIDs
+	}
+	for _, existing := range ruleIDs {
+		if existing == ruleID {
+			return ruleIDs
+		}
+	}
+	return append(ruleIDs, ruleID)
+}
+
+// splitOrderDiscount divides the rounded TotalDiscount into ItemDiscount and
+// OrderDiscount. Order threshold discounts are applied last, so when the
+// stacking cap trims the total they are the part that is reduced.
+//
+// Parameters:
+//   - result: Calculation result with rounded TotalDiscount and applications
+func splitOrderDiscount(result *DiscountCalculationResult) {
+	itemDiscount := 0.0
+	for _, application := range result.AppliedDiscounts {
+		if application.Type != DiscountTypeOrderThreshold {
+			itemDiscount += application.DiscountAmount
+		}
+	}
+
+	result.ItemDiscount = math.Min(math.Round(itemDiscount*100)/100, result.TotalDiscount)
+	result.OrderDiscount = math.Round((result.TotalDiscount-result.ItemDiscount)*100) / 100
+}
+
+// reconcileDiscountedUnits rescales each application's DiscountedUnits to the
+// discount the application finally keeps. Units are priced when the rule is
+// applied, before price floors shrink the application, the stacking cap trims
+// item-level discounts and the applications are rounded, so their amounts are
+// spread again with utils.AllocateProportional and sum exactly to the
+// application's DiscountAmount, scaled down by the share of item-level discount
+// the stacking cap left.
+//
+// Parameters:
+//   - result: Calculation result with rounded applications and ItemDiscount set
+//
+// Example:
+//   // Buy 1 get 1 free on two $10 shirts with a $6 floor: only $8 may come off
+//   // the line, so the free shirt's unit drops from $10 to $8 with the application
+func reconcileDiscountedUnits(result *DiscountCalculationResult) {
+	itemApplied := 0.0
+	for _, application := range result.AppliedDiscounts {
+		if application.Type != DiscountTypeOrderThreshold {
+			itemApplied += application.DiscountAmount
+		}
+	}
+	scale := 1.0
+	if itemApplied > result.ItemDiscount && itemApplied > 0 {
+		scale = result.ItemDiscount / itemApplied
+	}
+
+	for i, application := range result.AppliedDiscounts {
+		if len(application.DiscountedUnits) == 0 {
+			continue
+		}
+		units := append([]DiscountedUnit(nil), application.DiscountedUnits...)
+		weights := make([]float64, len(units))
+		for j, unit := range units {
+			weights[j] = unit.DiscountAmount
+		}
+		target := math.Round(application.DiscountAmount*scale*100) / 100
+		for j, amount := range utils.AllocateProportional(target, weights, 2) {
+			units[j].DiscountAmount = amount
+		}
+		result.AppliedDiscounts[i].DiscountedUnits = units
+	}
+}
+
+// roundAppliedDiscounts rounds each applied discount to cents so that the
+// breakdown sums exactly to the rounded total of the applications, assigning any
+// leftover cent with utils.AllocateRemainder. Without a stacking cap that total
+// equals TotalDiscount.
+//
+// Parameters:
+//   - result: Calculation result whose applied discounts are rounded
+//   - roundUp: Round the applications' total up instead of to nearest
+func roundAppliedDiscounts(result *DiscountCalculationResult, roundUp bool) {
+	if len(result.AppliedDiscounts) == 0 {
+		return
+	}
+
+	amounts := make([]float64, len(result.AppliedDiscounts))
+	for i, application := range result.AppliedDiscounts {
+		amounts[i] = application.DiscountAmount
+	}
+	total := math.Round(utils.Sum(amounts)*100) / 100
+	if roundUp {
+		total = utils.RoundWithMode(utils.Sum(amounts), 2, utils.RoundUp)
+	}
+	for i, amount := range utils.AllocateRemainder(total, amounts) {
+		result.AppliedDiscounts[i].DiscountAmount = amount
+	}
+}
+
+// validateItems rejects items with a quantity below one or a negative price.
+// Zero-price items are valid.
+//
+// Parameters:
+//   - items: Slice of DiscountItem to validate
+//
+// Returns:
+//   - error: Description of the first invalid item, or nil
+func validateItems(items []DiscountItem) error {
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %s has invalid quantity %d", item.ID, item.Quantity)
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("item %s has negative price", item.ID)
+		}
+	}
+	return nil
+}
+
 // calculateOriginalAmount calculates the total original amount before discounts.
 // Computes the sum of all item prices multiplied by their quantities,
 // providing the baseline amount for discount calculations.
@@ -140,16 +536,22 @@ func calculateOriginalAmount(items []DiscountItem) float64 {
 // Features:
 //   - Sequential application in priority order
 //   - Cumulative discount calculation
+//   - Item price floors (MinPrice) applied before the stacking cap
 //   - Maximum stacked discount percentage enforcement
 //   - Preserves all applied discount details
 //
 // Application Priority:
 //   1. Tier pricing (affects base prices)
-//   2. Bulk discounts
-//   3. Bundle discounts
-//   4. Category discounts
-//   5. Progressive discounts
-//   6. Loyalty discounts
+//   2. Bulk discounts (combined according to input.ApplicationOrder)
+//   3. Tiered bulk discounts
+//   4. Bundle discounts
+//   5. Mix for fixed price discounts
+//   6. Buy X get Y discounts
+//   7. Category discounts
+//   8. Progressive discounts
+//   9. Formula discounts
+//   10. Loyalty discounts
+//   11. Order threshold discounts (on the subtotal after item-level discounts)
 //
 // Parameters:
 //   - input: DiscountCalculationInput with rules and configuration
@@ -171,29 +573,179 @@ func calculateStackedDiscounts(input DiscountCalculationInput, result DiscountCa
 	// 2. Bulk discounts
 	result = applyBulkDiscounts(input, result)
 
-	// 3. Bundle discounts
+	// 3. Tiered bulk discounts
+	result = applyTieredBulkDiscounts(input, result)
+
+	// 4. Bundle discounts
 	result = applyBundleDiscounts(input, result)
 
-	// 4. Category discounts
+	// 5. Mix for fixed price discounts
+	result = applyMixForFixedPriceDiscounts(input, result)
+
+	// 6. Buy X get Y discounts
+	result = applyBOGODiscounts(input, result)
+
+	// 7. Category discounts
 	result = applyCategoryDiscounts(input, result)
 
-	// 5. Progressive discounts
+	// 8. Progressive discounts
 	result = applyProgressiveDiscounts(input, result)
 
-	// 6. Loyalty discounts (applied last)
+	// 9. Formula discounts
+	result = applyFormulaDiscounts(input, result)
+
+	// 10. Loyalty discounts
 	result = applyLoyaltyDiscounts(input, result)
 
+	// Keep discounted items at or above their price floors
+	result = applyPriceFloors(input, result)
+
+	// 11. Order threshold discounts on the post-item-discount subtotal
+	result = applyOrderThresholdDiscounts(input, result)
+
 	// Check maximum stacked discount limit
 	if input.MaxStackedDiscountPercent > 0 {
-		maxDiscount := result.OriginalAmount * (input.MaxStackedDiscountPercent / 100)
-		if result.TotalDiscount > maxDiscount {
+		maxDiscount := discountCapAmount(result.OriginalAmount, input.MaxStackedDiscountPercent)
+		if exceedsDiscountCap(result.TotalDiscount, maxDiscount) {
 			result.TotalDiscount = maxDiscount
+
+			// Report applications that start after the cap was already reached
+			cumulative := 0.0
+			for _, application := range result.AppliedDiscounts {
+				if utils.RoundToCurrency(cumulative) >= maxDiscount {
+					result = skipRule(input, result, application.Type, application.RuleID, SkipReasonCapReached,
+						fmt.Sprintf("maximum stacked discount of %.2f%% already reached", input.MaxStackedDiscountPercent))
+				}
+				cumulative += application.DiscountAmount
+			}
+		}
+	}
+
+	return result
+}
+
+// applyPriceFloors limits the discount on items with a MinPrice so their
+// discounted unit price does not fall below it. Each application's discount is
+// attributed to its applied items in proportion to their value; where an item's
+// attributed discount exceeds (Price - MinPrice) × Quantity, the excess is
+// removed from TotalDiscount and from the contributing applications pro rata,
+// and a FloorAdjustment is recorded.
+//
+// Parameters:
+//   - input: DiscountCalculationInput whose items carry the floors
+//   - result: Result with the applied discounts to limit
+//
+// Returns:
+//   - DiscountCalculationResult: Result with floors enforced
+//
+// Example:
+//   // Jacket $100 with MinPrice $70; 20% category + 15% loyalty stack to $35 off
+//   // Only $30 may be taken, so TotalDiscount drops by $5 and the
+//   // applications shrink by $2.86 and $2.14
+func applyPriceFloors(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	allowed := map[string]float64{}
+	floors := map[string]float64{}
+	order := []string{}
+	for _, item := range input.Items {
+		if item.MinPrice <= 0 {
+			continue
+		}
+		if _, seen := allowed[item.ID]; !seen {
+			order = append(order, item.ID)
+		}
+		allowed[item.ID] += math.Max(item.Price-item.MinPrice, 0) * float64(item.Quantity)
+		floors[item.ID] = item.MinPrice
+	}
+	if len(order) == 0 {
+		return result
+	}
+
+	// Attribute each application's discount to its items by value
+	shares := make([]map[string]float64, len(result.AppliedDiscounts))
+	attributed := map[string]float64{}
+	for i, application := range result.AppliedDiscounts {
+		shares[i] = map[string]float64{}
+		itemsAmount := calculateItemsAmount(application.AppliedItems)
+		if itemsAmount <= 0 {
+			continue
+		}
+		for _, item := range application.AppliedItems {
+			share := application.DiscountAmount * item.Price * float64(item.Quantity) / itemsAmount
+			shares[i][item.ID] += share
+			attributed[item.ID] += share
+		}
+	}
+
+	for _, id := range order {
+		excess := attributed[id] - allowed[id]
+		if excess <= percentCapTolerance {
+			continue
 		}
+
+		for i := range result.AppliedDiscounts {
+			if share := shares[i][id]; share > 0 {
+				result.AppliedDiscounts[i].DiscountAmount -= excess * share / attributed[id]
+			}
+		}
+		result.TotalDiscount -= excess
+		result.FloorAdjustments = append(result.FloorAdjustments, FloorAdjustment{
+			ItemID: id,
+			MinPrice: floors[id],
+			Reduction: math.Round(excess*100) / 100,
+		})
 	}
 
 	return result
 }
 
+// percentCapTolerance absorbs float noise when comparing discount amounts that
+// should be equal, far below a cent.
+const percentCapTolerance = 1e-9
+
+// discountPercentOf returns a discount as a percentage of the amount it was taken
+// from, rounded with utils.RoundToPercent for reporting. Caps are checked on
+// amounts with exceedsDiscountCap, not on this percentage.
+//
+// Parameters:
+//   - discount: Discount amount
+//   - amount: Amount the discount applies to
+//
+// Returns:
+//   - float64: Rounded percentage, or 0 when amount is not positive
+func discountPercentOf(discount, amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	return utils.RoundToPercent(discount / amount * 100)
+}
+
+// discountCapAmount returns the most that may be taken off an amount under a
+// percentage cap, amount × capPercent / 100 rounded to cents.
+//
+// Parameters:
+//   - amount: Amount the cap applies to
+//   - capPercent: Maximum allowed percentage
+//
+// Returns:
+//   - float64: Cap amount in cents precision
+func discountCapAmount(amount, capPercent float64) float64 {
+	return utils.RoundToCurrency(amount * capPercent / 100)
+}
+
+// exceedsDiscountCap reports whether a discount is above a cap amount once the
+// discount is rounded to cents, so float noise such as 50.0000001 on a 50.00 cap
+// does not count as exceeding it, while a whole cent over does.
+//
+// Parameters:
+//   - discount: Discount amount to check
+//   - capAmount: Maximum allowed discount, typically from discountCapAmount
+//
+// Returns:
+//   - bool: True if the discount in cents is strictly above the cap
+func exceedsDiscountCap(discount, capAmount float64) bool {
+	return utils.RoundToCurrency(discount) > capAmount
+}
+
 // calculateBestSingleDiscount finds the best single discount to apply.
 // Tests each discount type individually and returns the one that provides
 // the highest discount amount, ensuring customers get the best possible deal
@@ -201,7 +753,7 @@ func calculateStackedDiscounts(input DiscountCalculationInput, result DiscountCa
 //
 // Features:
 //   - Tests all discount types independently
-//   - Compares discount amounts to find maximum savings
+//   - Compares discount amounts after item price floors to find maximum savings
 //   - Returns complete discount application details
 //   - Ensures only one discount type is applied
 //
@@ -209,9 +761,12 @@ func calculateStackedDiscounts(input DiscountCalculationInput, result DiscountCa
 //   - Tier pricing
 //   - Bulk discounts
 //   - Bundle discounts
+//   - Mix for fixed price discounts
 //   - Category discounts
 //   - Progressive discounts
+//   - Formula discounts
 //   - Loyalty discounts
+//   - Order threshold discounts (on the full subtotal)
 //
 // Parameters:
 //   - input: DiscountCalculationInput with rules and configuration
@@ -231,18 +786,26 @@ func calculateBestSingleDiscount(input DiscountCalculationInput, result Discount
 	discountTypes := []func(DiscountCalculationInput, DiscountCalculationResult) DiscountCalculationResult{
 		applyTierPricing,
 		applyBulkDiscounts,
+		applyTieredBulkDiscounts,
 		applyBundleDiscounts,
+		applyMixForFixedPriceDiscounts,
+		applyBOGODiscounts,
 		applyCategoryDiscounts,
 		applyProgressiveDiscounts,
+		applyFormulaDiscounts,
 		applyLoyaltyDiscounts,
+		applyOrderThresholdDiscounts,
 	}
 
+	skippedRules := []SkippedRule{}
 	for _, discountFunc := range discountTypes {
 		testResult := discountFunc(input, DiscountCalculationResult{
 			OriginalAmount: result.OriginalAmount,
 			IsValid: true,
 			AppliedDiscounts: []DiscountApplication{},
 		})
+		testResult = applyPriceFloors(input, testResult)
+		skippedRules = append(skippedRules, testResult.SkippedRules...)
 
 		if testResult.TotalDiscount > bestDiscount {
 			bestResult = testResult
@@ -250,6 +813,10 @@ func calculateBestSingleDiscount(input DiscountCalculationInput, result Discount
 		}
 	}
 
+	if input.Explain {
+		bestResult.SkippedRules = skippedRules
+	}
+
 	return bestResult
 }
 
@@ -277,17 +844,25 @@ func calculateBestSingleDiscount(input DiscountCalculationInput, result Discount
 //   // 12 items: discount = (10-8) × 12 = $24
 func applyTierPricing(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
 	for _, rule := range input.TierRules {
+		categoryMatched, inRange, aboveMax, discounted := false, false, false, false
+
 		for _, item := range input.Items {
 			if rule.Category != "" && item.Category != rule.Category {
 				continue
 			}
+			categoryMatched = true
+			if rule.MaxQuantity > 0 && item.Quantity > rule.MaxQuantity {
+				aboveMax = true
+			}
 
 		if item.Quantity >= rule.MinQuantity && (rule.MaxQuantity == 0 || item.Quantity <= rule.MaxQuantity) {
+				inRange = true
 				originalItemTotal := item.Price * float64(item.Quantity)
 				newItemTotal := rule.PricePerItem * float64(item.Quantity)
 				discount := originalItemTotal - newItemTotal
 
 				if discount > 0 {
+					discounted = true
 					result.TotalDiscount += discount
 					result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
 						Type: DiscountTypeTier,
@@ -300,6 +875,21 @@ func applyTierPricing(input DiscountCalculationInput, result DiscountCalculation
 				}
 			}
 		}
+
+		switch {
+		case !categoryMatched:
+			result = skipRule(input, result, DiscountTypeTier, "tier_pricing", SkipReasonCategoryMismatch,
+				fmt.Sprintf("no items in category %s", rule.Category))
+		case !inRange && aboveMax:
+			result = skipRule(input, result, DiscountTypeTier, "tier_pricing", SkipReasonAboveMaxQuantity,
+				fmt.Sprintf("item quantities exceed maximum %d", rule.MaxQuantity))
+		case !inRange:
+			result = skipRule(input, result, DiscountTypeTier, "tier_pricing", SkipReasonBelowMinQuantity,
+				fmt.Sprintf("no item reaches minimum quantity %d", rule.MinQuantity))
+		case !discounted:
+			result = skipRule(input, result, DiscountTypeTier, "tier_pricing", SkipReasonNoDiscount,
+				fmt.Sprintf("tier price %.2f is not below the item price", rule.PricePerItem))
+		}
 	}
 
 	return result
@@ -321,6 +911,10 @@ func applyTierPricing(input DiscountCalculationInput, result DiscountCalculation
 //   - fixed_amount: Fixed dollar amount off
 //   - fixed_price: Fixed price per item
 //
+// Rules are ordered and combined according to input.ApplicationOrder. With a
+// sequential order each rule is computed on the amount its items have left
+// after the earlier bulk rules, and applications are appended in that order.
+//
 // Parameters:
 //   - input: DiscountCalculationInput containing bulk rules and items
 //   - result: Current DiscountCalculationResult to update
@@ -332,12 +926,42 @@ func applyTierPricing(input DiscountCalculationInput, result DiscountCalculation
 //   // Rule: 5+ items get 15% off
 //   // 6 items totaling $120: discount = $18 (15%)
 func applyBulkDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
-	for _, rule := range input.BulkRules {
+	sequential := input.ApplicationOrder != ApplicationOrderIndependent
+	taken := map[string]float64{} // Bulk discount already applied per item ID
+
+	for _, rule := range orderBulkRules(input.BulkRules, input.ApplicationOrder) {
+		if !isBulkRuleApplicableToCustomer(rule, input.Customer) {
+			result = skipRule(input, result, DiscountTypeBulk, "bulk_discount", SkipReasonCustomerNotEligible,
+				fmt.Sprintf("customer type %q or tier %q is not targeted", input.Customer.Type, input.Customer.LoyaltyTier))
+			continue
+		}
+
 		applicableItems := getApplicableItems(input.Items, rule.ApplicableCategories, rule.ApplicableProducts)
 		totalQuantity := getTotalQuantity(applicableItems)
 
+		switch {
+		case len(applicableItems) == 0:
+			result = skipRule(input, result, DiscountTypeBulk, "bulk_discount", SkipReasonCategoryMismatch,
+				"no items match the rule's categories or products")
+		case totalQuantity < rule.MinQuantity:
+			result = skipRule(input, result, DiscountTypeBulk, "bulk_discount", SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below minimum %d", totalQuantity, rule.MinQuantity))
+		case rule.MaxQuantity > 0 && totalQuantity > rule.MaxQuantity:
+			result = skipRule(input, result, DiscountTypeBulk, "bulk_discount", SkipReasonAboveMaxQuantity,
+				fmt.Sprintf("quantity %d exceeds maximum %d", totalQuantity, rule.MaxQuantity))
+		}
+
 		if totalQuantity >= rule.MinQuantity && (rule.MaxQuantity == 0 || totalQuantity <= rule.MaxQuantity) {
-			discount := calculateBulkDiscount(applicableItems, rule)
+			var discount float64
+			if sequential {
+				discount = calculateSequentialBulkDiscount(applicableItems, rule, taken)
+			} else {
+				discount = calculateBulkDiscount(applicableItems, rule)
+			}
+			if discount <= 0 && len(applicableItems) > 0 {
+				result = skipRule(input, result, DiscountTypeBulk, "bulk_discount", SkipReasonNoDiscount,
+					"rule produced no savings")
+			}
 
 			if discount > 0 {
 				result.TotalDiscount += discount
@@ -356,6 +980,143 @@ func applyBulkDiscounts(input DiscountCalculationInput, result DiscountCalculati
 	return result
 }
 
+// applyTieredBulkDiscounts applies graduated bulk rules. Matching items are
+// grouped per item or per category according to the rule's Scope, and each
+// group gets the percentage of the highest bracket its quantity reaches.
+// Every discounted group becomes its own application carrying the matched
+// bracket, so callers can show "You unlocked the 12+ tier".
+//
+// Features:
+//   - Highest qualifying bracket wins; boundaries are inclusive
+//   - Quantities above the top bracket keep the top rate
+//   - Per-item or per-category quantity counting
+//   - Category and product filtering
+//
+// Parameters:
+//   - input: DiscountCalculationInput containing tiered bulk rules and items
+//   - result: Current DiscountCalculationResult to update
+//
+// Returns:
+//   - DiscountCalculationResult: Updated result with tiered bulk discounts applied
+//
+// Example:
+//   // Brackets: 5% at 3, 10% at 6, 15% at 12 (per item)
+//   // 6 mugs at $10: 10% = $6 off; 2 plates: below the first bracket
+func applyTieredBulkDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	for _, rule := range input.TieredBulkRules {
+		if len(rule.Brackets) == 0 {
+			result = skipRule(input, result, DiscountTypeTieredBulk, rule.ID, SkipReasonInvalidRule,
+				"rule has no brackets")
+			continue
+		}
+
+		applicableItems := getApplicableItems(input.Items, rule.ApplicableCategories, rule.ApplicableProducts)
+		if len(applicableItems) == 0 {
+			result = skipRule(input, result, DiscountTypeTieredBulk, rule.ID, SkipReasonCategoryMismatch,
+				"no items match the rule's categories or products")
+			continue
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = "Tiered Bulk Discount"
+		}
+
+		for _, group := range groupTieredBulkItems(applicableItems, rule.Scope) {
+			quantity := getTotalQuantity(group.items)
+			bracket, ok := matchTieredBracket(rule.Brackets, quantity)
+			if !ok {
+				result = skipRule(input, result, DiscountTypeTieredBulk, rule.ID, SkipReasonBelowMinQuantity,
+					fmt.Sprintf("%s quantity %d is below the first bracket", group.key, quantity))
+				continue
+			}
+
+			discount := calculateItemsAmount(group.items) * (bracket.DiscountValue / 100)
+			if discount <= 0 {
+				result = skipRule(input, result, DiscountTypeTieredBulk, rule.ID, SkipReasonNoDiscount,
+					fmt.Sprintf("%s bracket produced no savings", group.key))
+				continue
+			}
+
+			result.TotalDiscount += discount
+			result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
+				Type: DiscountTypeTieredBulk,
+				RuleID: rule.ID,
+				Name: name,
+				DiscountAmount: discount,
+				AppliedItems: group.items,
+				Description: fmt.Sprintf("%.2f%% off %s for reaching the %d+ tier", bracket.DiscountValue, group.key, bracket.MinQuantity),
+				Bracket: &bracket,
+			})
+		}
+	}
+
+	return result
+}
+
+// tieredBulkGroup is a set of items whose quantities count together toward a
+// tiered bulk bracket.
+type tieredBulkGroup struct {
+	key   string
+	items []DiscountItem
+}
+
+// groupTieredBulkItems groups items by ID or by category, keeping the order in
+// which each group first appears.
+//
+// Parameters:
+//   - items: Items the rule applies to
+//   - scope: Whether quantities count per item or per category
+//
+// Returns:
+//   - []tieredBulkGroup: Groups in first-appearance order
+func groupTieredBulkItems(items []DiscountItem, scope TieredBulkScope) []tieredBulkGroup {
+	groups := []tieredBulkGroup{}
+	index := map[string]int{}
+	for _, item := range items {
+		key := "item " + item.ID
+		if scope == TieredBulkScopeCategory {
+			key = "category " + item.Category
+		}
+
+		i, exists := index[key]
+		if !exists {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, tieredBulkGroup{key: key})
+		}
+		groups[i].items = append(groups[i].items, item)
+	}
+	return groups
+}
+
+// matchTieredBracket returns the bracket with the highest MinQuantity that the
+// quantity reaches. Brackets do not need to be sorted.
+//
+// Parameters:
+//   - brackets: Brackets of a tiered bulk rule
+//   - quantity: Quantity to match
+//
+// Returns:
+//   - TieredBulkBracket: The matched bracket
+//   - bool: False when the quantity is below every bracket
+//
+// Example:
+//   brackets := []TieredBulkBracket{{3, 5}, {6, 10}, {12, 15}}
+//   bracket, _ := matchTieredBracket(brackets, 6)  // {6, 10}
+//   bracket, _ = matchTieredBracket(brackets, 40)  // {12, 15}
+func matchTieredBracket(brackets []TieredBulkBracket, quantity int) (TieredBulkBracket, bool) {
+	var best TieredBulkBracket
+	found := false
+	for _, bracket := range brackets {
+		if quantity >= bracket.MinQuantity && (!found || bracket.MinQuantity > best.MinQuantity) {
+			best = bracket
+			found = true
+		}
+	}
+	return best, found
+}
+
 // applyBundleDiscounts applies bundle discount rules for product combinations.
 // Provides discounts when customers purchase specific combinations of products
 // or categories together, encouraging cross-selling and upselling.
@@ -386,6 +1147,10 @@ func applyBulkDiscounts(input DiscountCalculationInput, result DiscountCalculati
 func applyBundleDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
 	for _, rule := range input.BundleRules {
 		bundleMatches := findBundleMatches(input.Items, rule)
+		if len(bundleMatches) == 0 {
+			result = skipRule(input, result, DiscountTypeBundle, rule.ID, SkipReasonBundleIncomplete,
+				"cart does not contain the bundle's required items")
+		}
 
 		for _, match := range bundleMatches {
 			discount := calculateBundleDiscount(match.MatchedItems, rule)
@@ -407,6 +1172,234 @@ func applyBundleDiscounts(input DiscountCalculationInput, result DiscountCalcula
 	return result
 }
 
+// applyMixForFixedPriceDiscounts applies multi-buy fixed price promotions.
+// Groups eligible units into sets of the rule's required quantity and charges the
+// group price per complete set, leaving the remaining units at their normal price.
+//
+// Features:
+//   - Category and product-specific eligibility
+//   - Per-unit set building across different items
+//   - Most or least expensive unit selection
+//   - Sets priced above their normal value are not discounted
+//
+// Parameters:
+//   - input: DiscountCalculationInput containing mix for fixed price rules and items
+//   - result: Current DiscountCalculationResult to update
+//
+// Returns:
+//   - DiscountCalculationResult: Updated result with mix for fixed price discounts applied
+//
+// Example:
+//   // Rule: any 3 for $10, 7 eligible units priced $6, $5, $5, $4, $4, $3, $3
+//   // Sets: ($6+$5+$5) and ($4+$4+$3), one $3 unit left at normal price
+//   // Discount: ($16-$10) + ($11-$10) = $7
+func applyMixForFixedPriceDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	for _, rule := range input.MixFixedPriceRules {
+		if rule.RequiredQuantity <= 0 {
+			result = skipRule(input, result, DiscountTypeMixFixedPrice, rule.ID, SkipReasonInvalidRule,
+				"required quantity must be positive")
+			continue
+		}
+
+		applicableItems := getApplicableItems(input.Items, rule.EligibleCategories, rule.EligibleProducts)
+		if len(applicableItems) == 0 {
+			result = skipRule(input, result, DiscountTypeMixFixedPrice, rule.ID, SkipReasonCategoryMismatch,
+				"no items match the rule's categories or products")
+			continue
+		}
+
+		// Expand items into individual units so sets can mix different items
+		units := []DiscountItem{}
+		for _, item := range applicableItems {
+			for i := 0; i < item.Quantity; i++ {
+				unit := item
+				unit.Quantity = 1
+				units = append(units, unit)
+			}
+		}
+
+		sets := len(units) / rule.RequiredQuantity
+		if sets == 0 {
+			result = skipRule(input, result, DiscountTypeMixFixedPrice, rule.ID, SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below required %d", len(units), rule.RequiredQuantity))
+			continue
+		}
+
+		sort.SliceStable(units, func(i, j int) bool {
+			if rule.Selection == "least_expensive" {
+				return units[i].Price < units[j].Price
+			}
+			return units[i].Price > units[j].Price
+		})
+
+		discount := 0.0
+		appliedItems := []DiscountItem{}
+		for set := 0; set < sets; set++ {
+			setUnits := units[set*rule.RequiredQuantity : (set+1)*rule.RequiredQuantity]
+			setDiscount := calculateItemsAmount(setUnits) - rule.GroupPrice
+			if setDiscount <= 0 {
+				continue
+			}
+
+			discount += setDiscount
+			appliedItems = mergeDiscountUnits(appliedItems, setUnits)
+		}
+
+		if discount > 0 {
+			result.TotalDiscount += discount
+			result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
+				Type: DiscountTypeMixFixedPrice,
+				RuleID: rule.ID,
+				Name: rule.Name,
+				DiscountAmount: discount,
+				AppliedItems: appliedItems,
+				Description: fmt.Sprintf("Any %d for %.2f", rule.RequiredQuantity, rule.GroupPrice),
+			})
+		} else {
+			result = skipRule(input, result, DiscountTypeMixFixedPrice, rule.ID, SkipReasonNoDiscount,
+				fmt.Sprintf("group price %.2f is not below the set price", rule.GroupPrice))
+		}
+	}
+
+	return result
+}
+
+// mergeDiscountUnits adds single units back onto a list of items, combining
+// units of the same item into one entry with the summed quantity.
+//
+// Parameters:
+//   - items: Items collected so far
+//   - units: Single-quantity units to add
+//
+// Returns:
+//   - []DiscountItem: Items with the units merged in
+func mergeDiscountUnits(items []DiscountItem, units []DiscountItem) []DiscountItem {
+	for _, unit := range units {
+		merged := false
+		for i := range items {
+			if items[i].ID == unit.ID {
+				items[i].Quantity += unit.Quantity
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			items = append(items, unit)
+		}
+	}
+	return items
+}
+
+// applyBOGODiscounts applies buy-X-get-Y rules. Eligible items are expanded
+// into individual units; every complete set of BuyQuantity + GetQuantity units
+// earns GetQuantity discounted units, up to MaxApplications sets. The
+// discounted units are the cheapest eligible units, or the most expensive when
+// the rule's Selection is "most_expensive", and are listed in DiscountedUnits.
+// Calculate rescales the unit amounts once price floors, the stacking cap and
+// rounding have settled the application's final discount.
+//
+// Parameters:
+//   - input: DiscountCalculationInput containing BOGO rules and items
+//   - result: Current DiscountCalculationResult to update
+//
+// Returns:
+//   - DiscountCalculationResult: Updated result with BOGO discounts applied
+//
+// Example:
+//   // Buy 2 get 1 free; socks at $8, $10, $12, $6, $9, $11
+//   // 2 complete sets: the $6 and $8 pairs are free, discount = $14
+func applyBOGODiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	for _, rule := range input.BOGORules {
+		if rule.BuyQuantity <= 0 || rule.GetQuantity <= 0 {
+			result = skipRule(input, result, DiscountTypeBOGO, rule.ID, SkipReasonInvalidRule,
+				"buy and get quantities must be positive")
+			continue
+		}
+
+		applicableItems := getApplicableItems(input.Items, rule.EligibleCategories, rule.EligibleProducts)
+		if len(applicableItems) == 0 {
+			result = skipRule(input, result, DiscountTypeBOGO, rule.ID, SkipReasonCategoryMismatch,
+				"no items match the rule's categories or products")
+			continue
+		}
+
+		// Expand items into numbered units so the discount can be traced to each one
+		units := []DiscountedUnit{}
+		for _, item := range applicableItems {
+			for i := 1; i <= item.Quantity; i++ {
+				units = append(units, DiscountedUnit{ItemID: item.ID, Unit: i, Price: item.Price})
+			}
+		}
+
+		setSize := rule.BuyQuantity + rule.GetQuantity
+		sets := len(units) / setSize
+		if rule.MaxApplications > 0 && sets > rule.MaxApplications {
+			sets = rule.MaxApplications
+		}
+		if sets == 0 {
+			result = skipRule(input, result, DiscountTypeBOGO, rule.ID, SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below required %d", len(units), setSize))
+			continue
+		}
+
+		sort.SliceStable(units, func(i, j int) bool {
+			if rule.Selection == "most_expensive" {
+				return units[i].Price > units[j].Price
+			}
+			return units[i].Price < units[j].Price
+		})
+
+		percent := math.Max(0, math.Min(rule.DiscountPercent, 100))
+		discounted := append([]DiscountedUnit(nil), units[:sets*rule.GetQuantity]...)
+		parts := make([]float64, len(discounted))
+		rawDiscount := 0.0
+		for i, unit := range discounted {
+			parts[i] = unit.Price * percent / 100
+			rawDiscount += parts[i]
+		}
+
+		// Round per unit so the invoice lines add up to the application's discount
+		discount := 0.0
+		for i, amount := range utils.AllocateRemainder(rawDiscount, parts) {
+			discounted[i].DiscountAmount = amount
+			discount += amount
+		}
+		if discount <= 0 {
+			result = skipRule(input, result, DiscountTypeBOGO, rule.ID, SkipReasonNoDiscount,
+				"rule produced no savings")
+			continue
+		}
+
+		itemsByID := map[string]DiscountItem{}
+		for _, item := range applicableItems {
+			itemsByID[item.ID] = item
+		}
+		appliedItems := []DiscountItem{}
+		for _, unit := range discounted {
+			single := itemsByID[unit.ItemID]
+			single.Quantity = 1
+			appliedItems = mergeDiscountUnits(appliedItems, []DiscountItem{single})
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = "Buy X Get Y"
+		}
+		result.TotalDiscount += discount
+		result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
+			Type: DiscountTypeBOGO,
+			RuleID: rule.ID,
+			Name: name,
+			DiscountAmount: discount,
+			AppliedItems: appliedItems,
+			Description: fmt.Sprintf("Buy %d get %d at %.0f%% off, applied %d time(s)", rule.BuyQuantity, rule.GetQuantity, percent, sets),
+			DiscountedUnits: discounted,
+		})
+	}
+
+	return result
+}
+
 // applyCategoryDiscounts applies category-specific discounts with time validation.
 // Provides percentage-based discounts for items in specific categories,
 // with support for time-based validity periods and maximum discount limits.
@@ -440,13 +1433,24 @@ func applyCategoryDiscounts(input DiscountCalculationInput, result DiscountCalcu
 	for _, rule := range input.CategoryRules {
 		// Check if rule is currently valid
 		if now.Before(rule.ValidFrom) || now.After(rule.ValidUntil) {
+			result = skipRule(input, result, DiscountTypeCategory, "category_"+rule.Category, SkipReasonInactive,
+				"rule is outside its validity period")
 			continue
 		}
 
 		categoryItems := getItemsByCategory(input.Items, rule.Category)
 		totalQuantity := getTotalQuantity(categoryItems)
 
-		if totalQuantity >= rule.MinQuantity {
+		switch {
+		case len(categoryItems) == 0:
+			result = skipRule(input, result, DiscountTypeCategory, "category_"+rule.Category, SkipReasonCategoryMismatch,
+				fmt.Sprintf("no items in category %s", rule.Category))
+		case totalQuantity < rule.MinQuantity:
+			result = skipRule(input, result, DiscountTypeCategory, "category_"+rule.Category, SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below minimum %d", totalQuantity, rule.MinQuantity))
+		}
+
+		if len(categoryItems) > 0 && totalQuantity >= rule.MinQuantity {
 			categoryAmount := calculateItemsAmount(categoryItems)
 			discount := categoryAmount * (rule.DiscountPercent / 100)
 
@@ -506,9 +1510,24 @@ func applyProgressiveDiscounts(input DiscountCalculationInput, result DiscountCa
 			applicableItems = getItemsByCategory(input.Items, rule.Category)
 		}
 
+		if rule.QuantityStep <= 0 {
+			result = skipRule(input, result, DiscountTypeProgressive, "progressive", SkipReasonInvalidRule,
+				fmt.Sprintf("quantity step %d must be positive", rule.QuantityStep))
+			continue
+		}
+
 		totalQuantity := getTotalQuantity(applicableItems)
 		steps := totalQuantity / rule.QuantityStep
 
+		switch {
+		case len(applicableItems) == 0:
+			result = skipRule(input, result, DiscountTypeProgressive, "progressive", SkipReasonCategoryMismatch,
+				fmt.Sprintf("no items in category %s", rule.Category))
+		case steps == 0:
+			result = skipRule(input, result, DiscountTypeProgressive, "progressive", SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below step %d", totalQuantity, rule.QuantityStep))
+		}
+
 		if steps > 0 {
 			progressivePercent := float64(steps) * rule.DiscountPercent
 			if progressivePercent > rule.MaxDiscount {
@@ -535,6 +1554,196 @@ func applyProgressiveDiscounts(input DiscountCalculationInput, result DiscountCa
 	return result
 }
 
+// applyFormulaDiscounts applies formula discount rules whose percentage follows a
+// continuous curve of the eligible quantity rather than discrete tiers.
+//
+// Features:
+//   - Linear, logarithmic, and capped curves (see FormulaCurve)
+//   - Percentage clamped between 0 and the rule's MaxPercent
+//   - Minimum quantity requirement
+//   - Category-specific or global application
+//
+// Parameters:
+//   - input: DiscountCalculationInput containing formula rules and items
+//   - result: Current DiscountCalculationResult to update
+//
+// Returns:
+//   - DiscountCalculationResult: Updated result with formula discounts applied
+//
+// Example:
+//   // Rule: linear 2% per item, max 30%
+//   // 8 items totaling $80: 16% discount = $12.80
+//   // 20 items totaling $200: capped at 30% = $60
+func applyFormulaDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	for _, rule := range input.FormulaRules {
+		applicableItems := input.Items
+		if rule.Category != "" {
+			applicableItems = getItemsByCategory(input.Items, rule.Category)
+		}
+		totalQuantity := getTotalQuantity(applicableItems)
+
+		if len(applicableItems) == 0 {
+			result = skipRule(input, result, DiscountTypeFormula, rule.ID, SkipReasonCategoryMismatch,
+				fmt.Sprintf("no items in category %s", rule.Category))
+			continue
+		}
+		if totalQuantity < rule.MinQuantity {
+			result = skipRule(input, result, DiscountTypeFormula, rule.ID, SkipReasonBelowMinQuantity,
+				fmt.Sprintf("quantity %d is below minimum %d", totalQuantity, rule.MinQuantity))
+			continue
+		}
+
+		percent, ok := formulaDiscountPercent(rule, totalQuantity)
+		if !ok {
+			result = skipRule(input, result, DiscountTypeFormula, rule.ID, SkipReasonInvalidRule,
+				fmt.Sprintf("unknown formula curve %q", rule.Curve))
+			continue
+		}
+
+		discount := calculateItemsAmount(applicableItems) * (percent / 100)
+		if discount <= 0 {
+			result = skipRule(input, result, DiscountTypeFormula, rule.ID, SkipReasonNoDiscount,
+				"rule produced no savings")
+			continue
+		}
+
+		name := rule.Name
+		if name == "" {
+			name = "Formula Discount"
+		}
+		result.TotalDiscount += discount
+		result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
+			Type: DiscountTypeFormula,
+			RuleID: rule.ID,
+			Name: name,
+			DiscountAmount: discount,
+			AppliedItems: applicableItems,
+			Description: fmt.Sprintf("%.2f%% %s quantity discount", percent, rule.Curve),
+		})
+	}
+
+	return result
+}
+
+// applyOrderThresholdDiscounts applies the best qualifying order threshold rule
+// to the order subtotal left after the discounts already in the result.
+// Rules whose MinSpend is not reached, or that are beaten by a better
+// threshold, are reported as skipped.
+//
+// Discount Types:
+//   - percentage: DiscountValue percent of the subtotal, capped at MaxDiscountAmount if set
+//   - fixed_amount: DiscountValue off, never more than the subtotal
+//
+// Parameters:
+//   - input: DiscountCalculationInput containing order threshold rules and items
+//   - result: Current DiscountCalculationResult to update
+//
+// Returns:
+//   - DiscountCalculationResult: Updated result with the order discount applied
+//
+// Example:
+//   // Rules: spend $100 get $15 off, spend $200 get 10% off
+//   // $250 cart with $30 item discounts: subtotal $220, 10% = $22 off
+func applyOrderThresholdDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
+	subtotal := result.OriginalAmount - result.TotalDiscount
+	best := -1
+	bestDiscount := 0.0
+
+	for i, rule := range input.OrderThresholdRules {
+		if subtotal < rule.MinSpend {
+			result = skipRule(input, result, DiscountTypeOrderThreshold, rule.ID, SkipReasonBelowMinOrderAmount,
+				fmt.Sprintf("subtotal %.2f is below minimum spend %.2f", subtotal, rule.MinSpend))
+			continue
+		}
+
+		var discount float64
+		switch rule.DiscountType {
+		case "percentage":
+			discount = subtotal * (rule.DiscountValue / 100)
+			if rule.MaxDiscountAmount > 0 && discount > rule.MaxDiscountAmount {
+				discount = rule.MaxDiscountAmount
+			}
+		case "fixed_amount":
+			discount = rule.DiscountValue
+		default:
+			result = skipRule(input, result, DiscountTypeOrderThreshold, rule.ID, SkipReasonInvalidRule,
+				fmt.Sprintf("unknown discount type %q", rule.DiscountType))
+			continue
+		}
+		discount = math.Min(discount, subtotal)
+
+		if discount <= 0 {
+			result = skipRule(input, result, DiscountTypeOrderThreshold, rule.ID, SkipReasonNoDiscount,
+				"rule produced no savings")
+			continue
+		}
+		if discount > bestDiscount {
+			if best >= 0 {
+				result = skipRule(input, result, DiscountTypeOrderThreshold, input.OrderThresholdRules[best].ID, SkipReasonNoDiscount,
+					fmt.Sprintf("a better order threshold rule applies: %s", rule.ID))
+			}
+			best, bestDiscount = i, discount
+		} else {
+			result = skipRule(input, result, DiscountTypeOrderThreshold, rule.ID, SkipReasonNoDiscount,
+				fmt.Sprintf("a better order threshold rule applies: %s", input.OrderThresholdRules[best].ID))
+		}
+	}
+
+	if best < 0 {
+		return result
+	}
+
+	rule := input.OrderThresholdRules[best]
+	name := rule.Name
+	if name == "" {
+		name = "Order Discount"
+	}
+	result.TotalDiscount += bestDiscount
+	result.AppliedDiscounts = append(result.AppliedDiscounts, DiscountApplication{
+		Type: DiscountTypeOrderThreshold,
+		RuleID: rule.ID,
+		Name: name,
+		DiscountAmount: bestDiscount,
+		AppliedItems: input.Items,
+		Description: fmt.Sprintf("Order discount for spending %.2f or more", rule.MinSpend),
+	})
+
+	return result
+}
+
+// formulaDiscountPercent evaluates a formula rule's curve at the given quantity and
+// clamps the result between 0 and MaxPercent (100 when unset). Reports false for an
+// unknown curve.
+//
+// Example:
+//   rule := FormulaDiscountRule{Curve: FormulaCurveLinear, Coefficient: 2, MaxPercent: 30}
+//   percent, _ := formulaDiscountPercent(rule, 20)
+//   // percent = 30 (40 clamped to the cap)
+func formulaDiscountPercent(rule FormulaDiscountRule, quantity int) (float64, bool) {
+	maxPercent := rule.MaxPercent
+	if maxPercent <= 0 || maxPercent > 100 {
+		maxPercent = 100
+	}
+	if quantity <= 0 {
+		return 0, true
+	}
+
+	q := float64(quantity)
+	var percent float64
+	switch rule.Curve {
+	case FormulaCurveLinear:
+		percent = rule.Offset + rule.Coefficient*q
+	case FormulaCurveLogarithmic:
+		percent = rule.Offset + rule.Coefficient*math.Log(q)
+	case FormulaCurveCapped:
+		percent = maxPercent - utils.ExponentialDecay(maxPercent, rule.Coefficient, q)
+	default:
+		return 0, false
+	}
+
+	return utils.Clamp(percent, 0, maxPercent), true
+}
+
 // applyLoyaltyDiscounts applies loyalty-based discounts for customer tiers.
 // Provides exclusive discounts based on customer loyalty tier status,
 // rewarding long-term customers with special pricing benefits.
@@ -565,6 +1774,8 @@ func applyProgressiveDiscounts(input DiscountCalculationInput, result DiscountCa
 func applyLoyaltyDiscounts(input DiscountCalculationInput, result DiscountCalculationResult) DiscountCalculationResult {
 	for _, rule := range input.LoyaltyRules {
 		if input.Customer.LoyaltyTier != rule.Tier {
+			result = skipRule(input, result, DiscountTypeLoyalty, "loyalty_"+rule.Tier, SkipReasonCustomerNotEligible,
+				fmt.Sprintf("customer tier %q does not match %q", input.Customer.LoyaltyTier, rule.Tier))
 			continue
 		}
 
@@ -575,7 +1786,16 @@ func applyLoyaltyDiscounts(input DiscountCalculationInput, result DiscountCalcul
 
 		itemAmount := calculateItemsAmount(applicableItems)
 
-		if itemAmount >= rule.MinOrderAmount {
+		switch {
+		case len(applicableItems) == 0:
+			result = skipRule(input, result, DiscountTypeLoyalty, "loyalty_"+rule.Tier, SkipReasonCategoryMismatch,
+				"no items match the rule's categories")
+		case itemAmount < rule.MinOrderAmount:
+			result = skipRule(input, result, DiscountTypeLoyalty, "loyalty_"+rule.Tier, SkipReasonBelowMinOrderAmount,
+				fmt.Sprintf("amount %.2f is below minimum %.2f", itemAmount, rule.MinOrderAmount))
+		}
+
+		if len(applicableItems) > 0 && itemAmount >= rule.MinOrderAmount {
 			discount := itemAmount * (rule.DiscountPercent / 100)
 
 			// Apply maximum discount limit
@@ -600,6 +1820,37 @@ func applyLoyaltyDiscounts(input DiscountCalculationInput, result DiscountCalcul
 	return result
 }
 
+// skipRule records why a discount rule was not applied when Explain is enabled.
+// Without Explain the result is returned unchanged.
+//
+// Parameters:
+//   - input: DiscountCalculationInput whose Explain flag is checked
+//   - result: Current DiscountCalculationResult to update
+//   - discountType: Type of the skipped rule
+//   - ruleID: Rule identifier, matching the RuleID used when the rule applies
+//   - reason: Why the rule was skipped
+//   - details: Human-readable explanation
+//
+// Returns:
+//   - DiscountCalculationResult: Result with the skipped rule recorded
+//
+// Example:
+//   result = skipRule(input, result, DiscountTypeBulk, "bulk_discount",
+//       SkipReasonBelowMinQuantity, "quantity 3 is below minimum 5")
+func skipRule(input DiscountCalculationInput, result DiscountCalculationResult, discountType DiscountType, ruleID string, reason SkipReason, details string) DiscountCalculationResult {
+	if !input.Explain {
+		return result
+	}
+
+	result.SkippedRules = append(result.SkippedRules, SkippedRule{
+		Type: discountType,
+		RuleID: ruleID,
+		Reason: reason,
+		Details: details,
+	})
+	return result
+}
+
 // Helper functions for discount calculations and item filtering.
 // These functions provide utilities for item selection, quantity calculations,
 // amount computations, and specific discount type calculations.
@@ -663,6 +1914,53 @@ func getApplicableItems(items []DiscountItem, categories []string, products []st
 	return applicable
 }
 
+// isBulkRuleApplicableToCustomer checks whether a bulk rule targets the customer's segment.
+// A rule with no customer types and no tiers applies to every customer; otherwise the
+// customer must match each non-empty list.
+//
+// Parameters:
+//   - rule: BulkDiscountRule with optional customer type and tier restrictions
+//   - customer: Customer whose Type and LoyaltyTier are checked
+//
+// Returns:
+//   - bool: True if the rule may be applied for this customer
+//
+// Example:
+//   rule := BulkDiscountRule{ApplicableCustomerTypes: []string{"premium"}}
+//   isBulkRuleApplicableToCustomer(rule, Customer{Type: "premium"}) // true
+//   isBulkRuleApplicableToCustomer(rule, Customer{Type: "regular"}) // false
+func isBulkRuleApplicableToCustomer(rule BulkDiscountRule, customer Customer) bool {
+	// Check customer types
+	if len(rule.ApplicableCustomerTypes) > 0 {
+		found := false
+		for _, customerType := range rule.ApplicableCustomerTypes {
+			if customerType == customer.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	// Check loyalty tiers
+	if len(rule.ApplicableTiers) > 0 {
+		found := false
+		for _, tier := range rule.ApplicableTiers {
+			if tier == customer.LoyaltyTier {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
 // getItemsByCategory filters items by a specific category.
 // Returns all items that belong to the specified category,
 // useful for category-specific discount calculations.
@@ -772,6 +2070,69 @@ func calculateBulkDiscount(items []DiscountItem, rule BulkDiscountRule) float64
 	}
 }
 
+// orderBulkRules returns the bulk rules in the order they should be applied.
+// Percentage-first and fixed-first orders are stable, so rules of the same
+// kind keep their listed order.
+//
+// Parameters:
+//   - rules: Bulk rules as listed in the input
+//   - order: Requested application order
+//
+// Returns:
+//   - []BulkDiscountRule: Rules in application order
+func orderBulkRules(rules []BulkDiscountRule, order ApplicationOrder) []BulkDiscountRule {
+	if order != ApplicationOrderPercentageFirst && order != ApplicationOrderFixedFirst {
+		return rules
+	}
+
+	ordered := append([]BulkDiscountRule(nil), rules...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		iPercent := ordered[i].DiscountType == "percentage"
+		jPercent := ordered[j].DiscountType == "percentage"
+		if order == ApplicationOrderPercentageFirst {
+			return iPercent && !jPercent
+		}
+		return !iPercent && jPercent
+	})
+	return ordered
+}
+
+// calculateSequentialBulkDiscount calculates a bulk discount on the amount the
+// items have left after earlier bulk rules, then records the new discount
+// against the items in proportion to their remaining amounts.
+//
+// Parameters:
+//   - items: Items the rule applies to
+//   - rule: BulkDiscountRule to calculate
+//   - taken: Discount already applied per item ID; updated in place
+//
+// Returns:
+//   - float64: Discount for this rule
+//
+// Example:
+//   // $100 item already discounted $5 by a fixed rule
+//   discount := calculateSequentialBulkDiscount(items, percentRule10, taken) // 9.50
+func calculateSequentialBulkDiscount(items []DiscountItem, rule BulkDiscountRule, taken map[string]float64) float64 {
+	remaining := make([]DiscountItem, len(items))
+	remainingAmounts := make([]float64, len(items))
+	totalRemaining := 0.0
+	for i, item := range items {
+		amount := math.Max(0, item.Price*float64(item.Quantity)-taken[item.ID])
+		remaining[i] = item
+		remaining[i].Price = amount / float64(item.Quantity)
+		remainingAmounts[i] = amount
+		totalRemaining += amount
+	}
+
+	discount := calculateBulkDiscount(remaining, rule)
+	if discount > 0 && totalRemaining > 0 {
+		for i, item := range items {
+			taken[item.ID] += discount * remainingAmounts[i] / totalRemaining
+		}
+	}
+	return discount
+}
+
 // findBundleMatches finds items that match bundle rules.
 // Determines which items form valid bundles based on required products
 // and categories, calculating how many complete bundles can be formed.
@@ -925,4 +2286,86 @@ func CalculateBestDiscount(inputs []DiscountCalculationInput) DiscountCalculatio
 	}
 
 	return bestResult
-}
\ No newline at end of file
+}
+
+// UnattributedStageName is the stage name EffectiveDiscountRate uses for any part of
+// the total discount not covered by the supplied stages.
+const UnattributedStageName = "unattributed"
+
+// EffectiveDiscountRate calculates the overall discount percentage of a promotion stack.
+// Reports a single effective rate for a chain of discounts (e.g. bulk discount, coupon,
+// loyalty redemption) and attributes it to each stage against the original amount,
+// so the stage percentages add up to the overall rate.
+//
+// Features:
+//   - Overall discount percentage from original and final amounts
+//   - Optional per-stage attribution
+//   - Reconciliation entry for any gap between stage deltas and the total
+//   - Zero original amount handling (reports a 0% rate)
+//
+// Parameters:
+//   - originalAmount: Amount before any discount
+//   - finalAmount: Amount after all discounts
+//   - stages: Optional per-stage discount amounts, in application order
+//
+// Returns:
+//   - EffectiveDiscount: Overall rate with per-stage attribution
+//
+// Example:
+//   effective := EffectiveDiscountRate(200.0, 150.0, []DiscountStage{
+//     {Name: "bulk", Amount: 30.0},
+//     {Name: "coupon", Amount: 20.0},
+//   })
+//   // effective.EffectivePercent == 25.0; stages attribute 15% and 10%
+func EffectiveDiscountRate(originalAmount, finalAmount float64, stages []DiscountStage) EffectiveDiscount {
+	result := EffectiveDiscount{
+		OriginalAmount: originalAmount,
+		FinalAmount:    finalAmount,
+		TotalDiscount:  originalAmount - finalAmount,
+	}
+
+	percentOf := func(amount float64) float64 {
+		if originalAmount == 0 {
+			return 0
+		}
+		return amount / originalAmount * 100
+	}
+
+	result.EffectivePercent = percentOf(result.TotalDiscount)
+
+	if len(stages) == 0 {
+		result.EffectivePercent = utils.RoundToPercent(result.EffectivePercent)
+		return result
+	}
+
+	attributed := 0.0
+	for _, stage := range stages {
+		attributed += stage.Amount
+		result.Stages = append(result.Stages, StageAttribution{
+			Name:    stage.Name,
+			Amount:  stage.Amount,
+			Percent: percentOf(stage.Amount),
+		})
+	}
+
+	// Reconcile stage deltas with the overall discount
+	if remainder := result.TotalDiscount - attributed; math.Abs(remainder) > 1e-9 {
+		result.Stages = append(result.Stages, StageAttribution{
+			Name:    UnattributedStageName,
+			Amount:  remainder,
+			Percent: percentOf(remainder),
+		})
+	}
+
+	// Round percentages last, keeping stage shares summing to the overall rate
+	percents := make([]float64, len(result.Stages))
+	for i, stage := range result.Stages {
+		percents[i] = stage.Percent
+	}
+	for i, percent := range utils.AllocateRemainderWithPrecision(result.EffectivePercent, percents, 4) {
+		result.Stages[i].Percent = percent
+	}
+	result.EffectivePercent = utils.RoundToPercent(result.EffectivePercent)
+
+	return result
+}
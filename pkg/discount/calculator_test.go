@@ -1,6 +1,8 @@
 package discount
 
 import (
+	"fmt"
+	"math"
 	"testing"
 	"time"
 )
@@ -305,6 +307,681 @@ func TestCalculate(t *testing.T) {
 			t.Error("Expected multiple discounts to be applied")
 		}
 	})
+
+	t.Run("BulkDiscountCustomerSegment", func(t *testing.T) {
+		items := []DiscountItem{
+			{ID: "item1", Price: 100, Quantity: 5, Category: "electronics"},
+		}
+		
+		bulkRules := []BulkDiscountRule{
+			{
+				MinQuantity: 3,
+				DiscountType: "percentage",
+				DiscountValue: 10,
+				ApplicableCustomerTypes: []string{"premium"},
+			},
+		}
+		
+		premium := Calculate(DiscountCalculationInput{
+			Items: items,
+			Customer: Customer{ID: "customer1", Type: "premium"},
+			BulkRules: bulkRules,
+		})
+		if premium.TotalDiscount != 50.0 {
+			t.Errorf("Expected premium customer discount 50.0, got %f", premium.TotalDiscount)
+		}
+		
+		regular := Calculate(DiscountCalculationInput{
+			Items: items,
+			Customer: Customer{ID: "customer2", Type: "regular"},
+			BulkRules: bulkRules,
+		})
+		if regular.TotalDiscount != 0 {
+			t.Errorf("Expected no discount for regular customer, got %f", regular.TotalDiscount)
+		}
+		
+		anonymous := Calculate(DiscountCalculationInput{
+			Items: items,
+			BulkRules: bulkRules,
+		})
+		if anonymous.TotalDiscount != 0 {
+			t.Errorf("Expected no discount without customer type, got %f", anonymous.TotalDiscount)
+		}
+	})
+	
+	t.Run("BulkDiscountLoyaltyTier", func(t *testing.T) {
+		items := []DiscountItem{
+			{ID: "item1", Price: 100, Quantity: 5, Category: "electronics"},
+		}
+		
+		bulkRules := []BulkDiscountRule{
+			{
+				MinQuantity: 3,
+				DiscountType: "percentage",
+				DiscountValue: 10,
+				ApplicableCustomerTypes: []string{"premium"},
+				ApplicableTiers: []string{"gold", "platinum"},
+			},
+		}
+		
+		gold := Calculate(DiscountCalculationInput{
+			Items: items,
+			Customer: Customer{Type: "premium", LoyaltyTier: "gold"},
+			BulkRules: bulkRules,
+		})
+		if gold.TotalDiscount != 50.0 {
+			t.Errorf("Expected gold premium discount 50.0, got %f", gold.TotalDiscount)
+		}
+		
+		silver := Calculate(DiscountCalculationInput{
+			Items: items,
+			Customer: Customer{Type: "premium", LoyaltyTier: "silver"},
+			BulkRules: bulkRules,
+		})
+		if silver.TotalDiscount != 0 {
+			t.Errorf("Expected no discount for silver tier, got %f", silver.TotalDiscount)
+		}
+	})
+	
+	t.Run("MixForFixedPriceMostExpensive", func(t *testing.T) {
+		items := []DiscountItem{
+			{ID: "chips", Price: 6, Quantity: 1, Category: "snacks"},
+			{ID: "cookies", Price: 5, Quantity: 2, Category: "snacks"},
+			{ID: "nuts", Price: 4, Quantity: 2, Category: "snacks"},
+			{ID: "candy", Price: 3, Quantity: 2, Category: "snacks"},
+			{ID: "soda", Price: 8, Quantity: 1, Category: "drinks"},
+		}
+		
+		result := Calculate(DiscountCalculationInput{
+			Items: items,
+			MixFixedPriceRules: []MixForFixedPriceRule{
+				{ID: "any3for10", Name: "Any 3 for $10", EligibleCategories: []string{"snacks"}, RequiredQuantity: 3, GroupPrice: 10},
+			},
+			AllowStacking: true,
+		})
+		
+		// Sets: 6+5+5 and 4+4+3, one candy left at normal price
+		if result.TotalDiscount != 7.0 {
+			t.Errorf("Expected discount 7.0, got %f", result.TotalDiscount)
+		}
+		if result.FinalAmount != 31.0 {
+			t.Errorf("Expected final amount 31.0, got %f", result.FinalAmount)
+		}
+		if len(result.AppliedDiscounts) != 1 || result.AppliedDiscounts[0].Type != DiscountTypeMixFixedPrice {
+			t.Fatalf("Expected one mix for fixed price discount, got %+v", result.AppliedDiscounts)
+		}
+		
+		appliedUnits := 0
+		for _, item := range result.AppliedDiscounts[0].AppliedItems {
+			appliedUnits += item.Quantity
+			if item.Category != "snacks" {
+				t.Errorf("Expected only eligible items in sets, got %s", item.ID)
+			}
+		}
+		if appliedUnits != 6 {
+			t.Errorf("Expected 6 units in sets, got %d", appliedUnits)
+		}
+	})
+	
+	t.Run("MixForFixedPriceLeastExpensive", func(t *testing.T) {
+		items := []DiscountItem{
+			{ID: "chips", Price: 6, Quantity: 1, Category: "snacks"},
+			{ID: "cookies", Price: 5, Quantity: 2, Category: "snacks"},
+			{ID: "nuts", Price: 4, Quantity: 2, Category: "snacks"},
+			{ID: "candy", Price: 3, Quantity: 2, Category: "snacks"},
+		}
+		
+		result := Calculate(DiscountCalculationInput{
+			Items: items,
+			MixFixedPriceRules: []MixForFixedPriceRule{
+				{ID: "any3for10", EligibleCategories: []string{"snacks"}, RequiredQuantity: 3, GroupPrice: 10, Selection: "least_expensive"},
+			},
+		})
+		
+		// Sets: 3+3+4 (no saving) and 4+5+5, chips left at normal price
+		if result.TotalDiscount != 4.0 {
+			t.Errorf("Expected discount 4.0, got %f", result.TotalDiscount)
+		}
+	})
+	
+	t.Run("MixForFixedPriceIncompleteSet", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "chips", Price: 6, Quantity: 2, Category: "snacks"}},
+			MixFixedPriceRules: []MixForFixedPriceRule{
+				{ID: "any3for10", EligibleCategories: []string{"snacks"}, RequiredQuantity: 3, GroupPrice: 10},
+			},
+		})
+		
+		if result.TotalDiscount != 0 {
+			t.Errorf("Expected no discount without a complete set, got %f", result.TotalDiscount)
+		}
+	})
+	
+	t.Run("ExplainSkippedRules", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "mouse", Price: 50, Quantity: 2, Category: "accessories"}},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 5, DiscountType: "percentage", DiscountValue: 10},
+			},
+			CategoryRules: []CategoryDiscountRule{
+				{
+					Category: "electronics",
+					DiscountPercent: 20,
+					ValidFrom: time.Now().Add(-time.Hour),
+					ValidUntil: time.Now().Add(time.Hour),
+				},
+			},
+			AllowStacking: true,
+			Explain: true,
+		}
+		
+		result := Calculate(input)
+		
+		if result.TotalDiscount != 0 {
+			t.Errorf("Expected no discount, got %f", result.TotalDiscount)
+		}
+		if len(result.SkippedRules) != 2 {
+			t.Fatalf("Expected 2 skipped rules, got %d: %+v", len(result.SkippedRules), result.SkippedRules)
+		}
+		
+		bulk := result.SkippedRules[0]
+		if bulk.Type != DiscountTypeBulk || bulk.Reason != SkipReasonBelowMinQuantity {
+			t.Errorf("Expected bulk rule skipped below min quantity, got %+v", bulk)
+		}
+		
+		category := result.SkippedRules[1]
+		if category.Type != DiscountTypeCategory || category.RuleID != "category_electronics" || category.Reason != SkipReasonCategoryMismatch {
+			t.Errorf("Expected category rule skipped for category mismatch, got %+v", category)
+		}
+		
+		input.AllowStacking = false
+		if result := Calculate(input); len(result.SkippedRules) != 2 {
+			t.Errorf("Expected 2 skipped rules without stacking, got %d", len(result.SkippedRules))
+		}
+		
+		input.Explain = false
+		if result := Calculate(input); len(result.SkippedRules) != 0 {
+			t.Errorf("Expected no skipped rules without explain, got %d", len(result.SkippedRules))
+		}
+	})
+	
+	t.Run("ExplainCapReached", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "item1", Price: 100, Quantity: 1, Category: "electronics"}},
+			Customer: Customer{LoyaltyTier: "gold"},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 20},
+			},
+			LoyaltyRules: []LoyaltyDiscountRule{
+				{Tier: "gold", DiscountPercent: 10},
+			},
+			AllowStacking: true,
+			MaxStackedDiscountPercent: 20,
+			Explain: true,
+		})
+		
+		if result.TotalDiscount != 20 {
+			t.Errorf("Expected capped discount 20, got %f", result.TotalDiscount)
+		}
+		if len(result.SkippedRules) != 1 || result.SkippedRules[0].Reason != SkipReasonCapReached || result.SkippedRules[0].Type != DiscountTypeLoyalty {
+			t.Errorf("Expected loyalty rule skipped for cap reached, got %+v", result.SkippedRules)
+		}
+	})
+	
+	t.Run("StackingCapBoundary", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "item1", Price: 100, Quantity: 1, Category: "electronics"}},
+			Customer: Customer{LoyaltyTier: "gold"},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 20},
+			},
+			LoyaltyRules: []LoyaltyDiscountRule{
+				{Tier: "gold", DiscountPercent: 10},
+			},
+			AllowStacking: true,
+			MaxStackedDiscountPercent: 28, // 20 bulk + 8 loyalty lands exactly on the cap
+			Explain: true,
+		}
+		
+		result := Calculate(input)
+		if result.TotalDiscount != 28 {
+			t.Errorf("Expected discount 28 at the cap, got %f", result.TotalDiscount)
+		}
+		if len(result.SkippedRules) != 0 {
+			t.Errorf("Expected no rules skipped at the cap, got %+v", result.SkippedRules)
+		}
+		
+		if exceedsDiscountCap(50.0000001, discountCapAmount(100, 50)) {
+			t.Error("Expected 50.0000001 to count as exactly the 50.00 cap")
+		}
+		if !exceedsDiscountCap(50.01, discountCapAmount(100, 50)) {
+			t.Error("Expected 50.01 to exceed the 50.00 cap")
+		}
+		// 15% of 33.33 is 4.9995, a 5.00 cap once rounded to cents
+		if exceedsDiscountCap(5.00, discountCapAmount(33.33, 15)) || !exceedsDiscountCap(5.01, discountCapAmount(33.33, 15)) {
+			t.Error("Expected a 5.00 discount on 33.33 to sit exactly on the 15% cap")
+		}
+	})
+	
+	t.Run("FormulaDiscountLinear", func(t *testing.T) {
+		rule := FormulaDiscountRule{ID: "smooth", Curve: FormulaCurveLinear, Coefficient: 2, MaxPercent: 30}
+		tests := []struct {
+			quantity         int
+			expectedDiscount float64
+		}{
+			{quantity: 1, expectedDiscount: 0.2},   // 2%
+			{quantity: 5, expectedDiscount: 5.0},   // 10%
+			{quantity: 15, expectedDiscount: 45.0}, // 30%, exactly at the cap
+			{quantity: 40, expectedDiscount: 120.0}, // 80% clamped to 30%
+		}
+		
+		for _, tt := range tests {
+			result := Calculate(DiscountCalculationInput{
+				Items: []DiscountItem{{ID: "widget", Price: 10, Quantity: tt.quantity}},
+				FormulaRules: []FormulaDiscountRule{rule},
+			})
+			
+			if math.Abs(result.TotalDiscount-tt.expectedDiscount) > 0.001 {
+				t.Errorf("Quantity %d: expected discount %f, got %f", tt.quantity, tt.expectedDiscount, result.TotalDiscount)
+			}
+		}
+	})
+	
+	t.Run("FormulaDiscountCurves", func(t *testing.T) {
+		tests := []struct {
+			name            string
+			rule            FormulaDiscountRule
+			quantity        int
+			expectedPercent float64
+		}{
+			{"logarithmic", FormulaDiscountRule{Curve: FormulaCurveLogarithmic, Coefficient: 5}, 10, 5 * math.Log(10)},
+			{"logarithmic with offset", FormulaDiscountRule{Curve: FormulaCurveLogarithmic, Coefficient: 5, Offset: 2}, 1, 2},
+			{"logarithmic capped", FormulaDiscountRule{Curve: FormulaCurveLogarithmic, Coefficient: 10, MaxPercent: 25}, 100, 25},
+			{"capped early", FormulaDiscountRule{Curve: FormulaCurveCapped, Coefficient: 0.1, MaxPercent: 30}, 5, 30 * (1 - math.Exp(-0.5))},
+			{"capped approaches max", FormulaDiscountRule{Curve: FormulaCurveCapped, Coefficient: 0.1, MaxPercent: 30}, 100, 30 * (1 - math.Exp(-10))},
+			{"negative clamped to zero", FormulaDiscountRule{Curve: FormulaCurveLinear, Coefficient: 1, Offset: -10}, 4, 0},
+		}
+		
+		for _, tt := range tests {
+			percent, ok := formulaDiscountPercent(tt.rule, tt.quantity)
+			if !ok {
+				t.Errorf("%s: expected curve to be valid", tt.name)
+			}
+			if math.Abs(percent-tt.expectedPercent) > 0.0001 {
+				t.Errorf("%s: expected percent %f, got %f", tt.name, tt.expectedPercent, percent)
+			}
+		}
+	})
+	
+	t.Run("FormulaDiscountSkipped", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "widget", Price: 10, Quantity: 3, Category: "tools"}},
+			FormulaRules: []FormulaDiscountRule{
+				{ID: "min-qty", Curve: FormulaCurveLinear, Coefficient: 2, MinQuantity: 5},
+				{ID: "unknown", Curve: "cubic", Coefficient: 2},
+			},
+			AllowStacking: true,
+			Explain: true,
+		})
+		
+		if result.TotalDiscount != 0 {
+			t.Errorf("Expected no discount, got %f", result.TotalDiscount)
+		}
+		if len(result.SkippedRules) != 2 || result.SkippedRules[0].Reason != SkipReasonBelowMinQuantity || result.SkippedRules[1].Reason != SkipReasonInvalidRule {
+			t.Errorf("Expected min quantity and invalid rule skips, got %+v", result.SkippedRules)
+		}
+	})
+	
+	t.Run("SavingsPercentRounded", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "pen", Price: 1.0, Quantity: 3}},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 3, DiscountType: "fixed_amount", DiscountValue: 1},
+			},
+			AllowStacking: true,
+		})
+		
+		if result.SavingsPercent != 33.3333 {
+			t.Errorf("Expected savings percent 33.3333, got %v", result.SavingsPercent)
+		}
+		
+		effective := EffectiveDiscountRate(3.0, 2.0, []DiscountStage{{Name: "bulk", Amount: 0.5}, {Name: "coupon", Amount: 0.5}})
+		if effective.EffectivePercent != 33.3333 {
+			t.Errorf("Expected effective percent 33.3333, got %v", effective.EffectivePercent)
+		}
+		if sum := effective.Stages[0].Percent + effective.Stages[1].Percent; math.Abs(sum-effective.EffectivePercent) > 1e-9 {
+			t.Errorf("Expected stage percents to sum to %v, got %v", effective.EffectivePercent, sum)
+		}
+	})
+	
+	t.Run("CustomerFavorableRounding", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "pen", Price: 1.01, Quantity: 1}},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 11},
+			},
+			AllowStacking: true,
+		}
+		
+		result := Calculate(input)
+		if result.TotalDiscount != 0.11 || result.FinalAmount != 0.90 {
+			t.Errorf("Expected discount 0.11 and final 0.90, got %v and %v", result.TotalDiscount, result.FinalAmount)
+		}
+		
+		// 0.1111 of discount resolves in the customer's favor
+		input.CustomerFavorableRounding = true
+		result = Calculate(input)
+		if result.TotalDiscount != 0.12 || result.FinalAmount != 0.89 {
+			t.Errorf("Expected discount 0.12 and final 0.89, got %v and %v", result.TotalDiscount, result.FinalAmount)
+		}
+		if result.AppliedDiscounts[0].DiscountAmount != 0.12 {
+			t.Errorf("Expected applied discount 0.12, got %v", result.AppliedDiscounts[0].DiscountAmount)
+		}
+	})
+	
+	t.Run("AppliedDiscountsReconcile", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "pen", Price: 0.10, Quantity: 1, Category: "pens"},
+				{ID: "pad", Price: 0.10, Quantity: 1, Category: "pads"},
+			},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 15, ApplicableCategories: []string{"pens"}},
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 15, ApplicableCategories: []string{"pads"}},
+			},
+			AllowStacking: true,
+		})
+		
+		if len(result.AppliedDiscounts) != 2 {
+			t.Fatalf("Expected 2 applied discounts, got %d", len(result.AppliedDiscounts))
+		}
+		if result.AppliedDiscounts[0].DiscountAmount != 0.02 || result.AppliedDiscounts[1].DiscountAmount != 0.01 {
+			t.Errorf("Expected cent amounts 0.02 and 0.01, got %f and %f",
+				result.AppliedDiscounts[0].DiscountAmount, result.AppliedDiscounts[1].DiscountAmount)
+		}
+		if sum := result.AppliedDiscounts[0].DiscountAmount + result.AppliedDiscounts[1].DiscountAmount; math.Abs(sum-result.TotalDiscount) > 1e-9 {
+			t.Errorf("Expected applied discounts to sum to %f, got %f", result.TotalDiscount, sum)
+		}
+	})
+	
+	t.Run("ZeroQuantityItem", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "widget", Price: 10, Quantity: 0}},
+		})
+		
+		if result.IsValid {
+			t.Error("Expected zero-quantity item to be rejected")
+		}
+		if result.ErrorMessage != "item widget has invalid quantity 0" {
+			t.Errorf("Unexpected error message: %q", result.ErrorMessage)
+		}
+	})
+	
+	t.Run("ZeroPriceItem", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "widget", Price: 10, Quantity: 5},
+				{ID: "sample", Price: 0, Quantity: 1},
+			},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 3, DiscountType: "percentage", DiscountValue: 10},
+			},
+			AllowStacking: true,
+		})
+		
+		if !result.IsValid {
+			t.Fatalf("Expected zero-price item to be valid, got %q", result.ErrorMessage)
+		}
+		if result.OriginalAmount != 50 {
+			t.Errorf("Expected original amount 50, got %f", result.OriginalAmount)
+		}
+		if result.TotalDiscount != 5 {
+			t.Errorf("Expected discount 5, got %f", result.TotalDiscount)
+		}
+	})
+	
+	t.Run("MinPriceFloor", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "jacket", Price: 100, Quantity: 1, Category: "apparel", MinPrice: 70},
+				{ID: "shirt", Price: 50, Quantity: 1, Category: "apparel"},
+			},
+			Customer: Customer{ID: "customer1", LoyaltyTier: "gold"},
+			CategoryRules: []CategoryDiscountRule{
+				{
+					Category: "apparel",
+					DiscountPercent: 20,
+					ValidFrom: time.Now().Add(-time.Hour),
+					ValidUntil: time.Now().Add(time.Hour),
+				},
+			},
+			LoyaltyRules: []LoyaltyDiscountRule{
+				{Tier: "gold", DiscountPercent: 15},
+			},
+			AllowStacking: true,
+		}
+		
+		// Stacked 35% would take the jacket to $65, below its $70 floor
+		result := Calculate(input)
+		
+		if !result.IsValid {
+			t.Fatalf("Expected valid result, got error: %s", result.ErrorMessage)
+		}
+		if result.TotalDiscount != 47.5 || result.FinalAmount != 102.5 {
+			t.Errorf("Expected discount 47.50 and final 102.50, got %.2f and %.2f", result.TotalDiscount, result.FinalAmount)
+		}
+		if len(result.FloorAdjustments) != 1 {
+			t.Fatalf("Expected 1 floor adjustment, got %+v", result.FloorAdjustments)
+		}
+		adjustment := result.FloorAdjustments[0]
+		if adjustment.ItemID != "jacket" || adjustment.MinPrice != 70 || adjustment.Reduction != 5 {
+			t.Errorf("Unexpected floor adjustment: %+v", adjustment)
+		}
+		applied := 0.0
+		for _, application := range result.AppliedDiscounts {
+			applied += application.DiscountAmount
+		}
+		if math.Abs(applied-result.TotalDiscount) > 0.001 {
+			t.Errorf("Expected applied discounts to sum to %.2f, got %.2f", result.TotalDiscount, applied)
+		}
+		
+		// Without stacking the best single discount is also held at the floor
+		input.AllowStacking = false
+		input.Items[0].MinPrice = 90
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 20 {
+			t.Errorf("Expected category discount limited to 20.00, got %.2f", result.TotalDiscount)
+		}
+		if len(result.FloorAdjustments) != 1 || result.FloorAdjustments[0].Reduction != 10 {
+			t.Errorf("Expected a 10.00 floor adjustment, got %+v", result.FloorAdjustments)
+		}
+		
+		// Floors not reached leave the discount untouched
+		input.AllowStacking = true
+		input.Items[0].MinPrice = 50
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 52.5 || len(result.FloorAdjustments) != 0 {
+			t.Errorf("Expected full discount 52.50 without adjustments, got %.2f and %+v", result.TotalDiscount, result.FloorAdjustments)
+		}
+	})
+	
+	t.Run("OrderThresholdDiscount", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "item1", Price: 50, Quantity: 4, Category: "electronics"},
+				{ID: "item2", Price: 25, Quantity: 2, Category: "books"},
+			},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 4, DiscountType: "percentage", DiscountValue: 10, ApplicableCategories: []string{"electronics"}},
+			},
+			OrderThresholdRules: []OrderThresholdRule{
+				{ID: "spend-100", MinSpend: 100, DiscountType: "fixed_amount", DiscountValue: 15},
+				{ID: "spend-200", MinSpend: 200, DiscountType: "percentage", DiscountValue: 10},
+				{ID: "spend-500", MinSpend: 500, DiscountType: "fixed_amount", DiscountValue: 100},
+			},
+			AllowStacking: true,
+			Explain: true,
+		}
+		
+		// $250 cart, $20 bulk discount: the $230 subtotal earns 10% = $23 off
+		result := Calculate(input)
+		
+		if !result.IsValid {
+			t.Fatalf("Expected valid result, got error: %s", result.ErrorMessage)
+		}
+		if result.ItemDiscount != 20 || result.OrderDiscount != 23 || result.TotalDiscount != 43 {
+			t.Errorf("Expected item 20.00 + order 23.00 = 43.00, got %.2f + %.2f = %.2f", result.ItemDiscount, result.OrderDiscount, result.TotalDiscount)
+		}
+		if result.FinalAmount != 207 {
+			t.Errorf("Expected final amount 207.00, got %.2f", result.FinalAmount)
+		}
+		last := result.AppliedDiscounts[len(result.AppliedDiscounts)-1]
+		if last.Type != DiscountTypeOrderThreshold || last.RuleID != "spend-200" {
+			t.Errorf("Expected spend-200 applied last, got %+v", last)
+		}
+		skipped := map[string]SkipReason{}
+		for _, rule := range result.SkippedRules {
+			if rule.Type == DiscountTypeOrderThreshold {
+				skipped[rule.RuleID] = rule.Reason
+			}
+		}
+		if skipped["spend-100"] != SkipReasonNoDiscount || skipped["spend-500"] != SkipReasonBelowMinOrderAmount {
+			t.Errorf("Unexpected skipped order rules: %v", skipped)
+		}
+		
+		// Item discounts can drop the subtotal below a threshold
+		input.BulkRules[0].DiscountValue = 30
+		result = Calculate(input)
+		
+		if result.ItemDiscount != 60 || result.OrderDiscount != 15 {
+			t.Errorf("Expected item 60.00 and order 15.00 below the $200 threshold, got %.2f and %.2f", result.ItemDiscount, result.OrderDiscount)
+		}
+		
+		// The stacking cap trims the order-level discount first
+		input.MaxStackedDiscountPercent = 30
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 75 || result.ItemDiscount != 60 || result.OrderDiscount != 15 {
+			t.Errorf("Expected 75.00 (60.00 + 15.00) at the cap, got %.2f (%.2f + %.2f)", result.TotalDiscount, result.ItemDiscount, result.OrderDiscount)
+		}
+		input.MaxStackedDiscountPercent = 26
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 65 || result.ItemDiscount != 60 || result.OrderDiscount != 5 {
+			t.Errorf("Expected 65.00 (60.00 + 5.00) at the cap, got %.2f (%.2f + %.2f)", result.TotalDiscount, result.ItemDiscount, result.OrderDiscount)
+		}
+	})
+
+	t.Run("ItemBreakdown", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "item1", Price: 50, Quantity: 4, Category: "electronics"},
+				{ID: "item2", Price: 25, Quantity: 2, Category: "books"},
+			},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 4, DiscountType: "percentage", DiscountValue: 10, ApplicableCategories: []string{"electronics"}},
+			},
+			OrderThresholdRules: []OrderThresholdRule{
+				{ID: "spend-200", MinSpend: 200, DiscountType: "percentage", DiscountValue: 10},
+			},
+			AllowStacking: true,
+		}
+		
+		// $20 bulk on item1, then 10% of the $180 + $50 that is left of each line
+		result := Calculate(input)
+		
+		if len(result.ItemBreakdown) != 2 {
+			t.Fatalf("Expected 2 item breakdowns, got %+v", result.ItemBreakdown)
+		}
+		first, second := result.ItemBreakdown[0], result.ItemBreakdown[1]
+		if first.ItemID != "item1" || first.OriginalAmount != 200 || first.DiscountAmount != 38 || first.FinalAmount != 162 {
+			t.Errorf("Unexpected item1 breakdown: %+v", first)
+		}
+		if len(first.RuleIDs) != 2 || first.RuleIDs[0] != "bulk_discount" || first.RuleIDs[1] != "spend-200" {
+			t.Errorf("Expected item1 touched by bulk_discount and spend-200, got %v", first.RuleIDs)
+		}
+		if second.ItemID != "item2" || second.DiscountAmount != 5 || len(second.RuleIDs) != 1 || second.RuleIDs[0] != "spend-200" {
+			t.Errorf("Unexpected item2 breakdown: %+v", second)
+		}
+		
+		// A capped total is still fully attributed
+		input.BulkRules[0].DiscountValue = 30
+		input.OrderThresholdRules[0].MinSpend = 100
+		input.MaxStackedDiscountPercent = 26
+		result = Calculate(input)
+		
+		attributed := 0.0
+		for _, breakdown := range result.ItemBreakdown {
+			attributed += breakdown.DiscountAmount
+		}
+		if result.TotalDiscount != 65 || math.Abs(attributed-result.TotalDiscount) > 0.001 {
+			t.Errorf("Expected breakdown to sum to capped 65.00, got %.2f of %.2f", attributed, result.TotalDiscount)
+		}
+		if result.ItemBreakdown[1].DiscountAmount != 1.32 {
+			t.Errorf("Expected item2 to carry 1.32 of the trimmed order discount, got %.2f", result.ItemBreakdown[1].DiscountAmount)
+		}
+	})
+
+	t.Run("ItemBreakdownNoLostCents", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "a", Price: 10, Quantity: 1},
+				{ID: "b", Price: 10, Quantity: 1},
+				{ID: "c", Price: 10, Quantity: 1},
+			},
+			OrderThresholdRules: []OrderThresholdRule{
+				{ID: "spend-30", MinSpend: 30, DiscountType: "fixed_amount", DiscountValue: 10},
+			},
+		}
+		
+		result := Calculate(input)
+		
+		attributed := 0.0
+		for _, breakdown := range result.ItemBreakdown {
+			if breakdown.DiscountAmount != 3.33 && breakdown.DiscountAmount != 3.34 {
+				t.Errorf("Expected a 3.33 or 3.34 share, got %+v", breakdown)
+			}
+			attributed += breakdown.DiscountAmount
+		}
+		if math.Abs(attributed-10) > 0.001 {
+			t.Errorf("Expected shares to sum to 10.00, got %.2f", attributed)
+		}
+	})
+
+	t.Run("ItemBreakdownRespectsFloors", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "jacket", Price: 100, Quantity: 1, Category: "apparel", MinPrice: 70},
+				{ID: "shirt", Price: 50, Quantity: 1, Category: "apparel"},
+			},
+			Customer: Customer{ID: "customer1", LoyaltyTier: "gold"},
+			CategoryRules: []CategoryDiscountRule{
+				{
+					Category: "apparel",
+					DiscountPercent: 20,
+					ValidFrom: time.Now().Add(-time.Hour),
+					ValidUntil: time.Now().Add(time.Hour),
+				},
+			},
+			LoyaltyRules: []LoyaltyDiscountRule{
+				{Tier: "gold", DiscountPercent: 15},
+			},
+			AllowStacking: true,
+		}
+		
+		// The jacket keeps its $70 floor; the shirt carries the rest of the $47.50
+		result := Calculate(input)
+		
+		jacket, shirt := result.ItemBreakdown[0], result.ItemBreakdown[1]
+		if jacket.DiscountAmount != 30 || jacket.FinalAmount != 70 {
+			t.Errorf("Expected jacket discounted to its 70.00 floor, got %+v", jacket)
+		}
+		if shirt.DiscountAmount != 17.5 {
+			t.Errorf("Expected shirt discount 17.50, got %+v", shirt)
+		}
+	})
 }
 
 func TestCalculateBestDiscount(t *testing.T) {
@@ -355,6 +1032,536 @@ func TestCalculateBestDiscount(t *testing.T) {
 	})
 }
 
+func TestBulkApplicationOrder(t *testing.T) {
+	newInput := func(order ApplicationOrder) DiscountCalculationInput {
+		return DiscountCalculationInput{
+			Items: []DiscountItem{
+				{ID: "item1", Price: 100, Quantity: 1, Category: "electronics"},
+			},
+			BulkRules: []BulkDiscountRule{
+				{MinQuantity: 1, DiscountType: "fixed_amount", DiscountValue: 5},
+				{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 10},
+			},
+			AllowStacking: true,
+			ApplicationOrder: order,
+		}
+	}
+	
+	tests := []struct {
+		name      string
+		order     ApplicationOrder
+		final     float64
+		discounts []float64 // Bulk discounts in the order they were applied
+	}{
+		{"10% then -$5", ApplicationOrderPercentageFirst, 85, []float64{10, 5}},
+		{"-$5 then 10%", ApplicationOrderFixedFirst, 85.5, []float64{5, 9.5}},
+		{"listed order", ApplicationOrderSequential, 85.5, []float64{5, 9.5}},
+		{"independent", ApplicationOrderIndependent, 85, []float64{5, 10}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Calculate(newInput(tt.order))
+			
+			if !result.IsValid {
+				t.Fatalf("Expected valid result, got error: %s", result.ErrorMessage)
+			}
+			if result.FinalAmount != tt.final {
+				t.Errorf("Expected final amount %.2f, got %.2f", tt.final, result.FinalAmount)
+			}
+			if len(result.AppliedDiscounts) != len(tt.discounts) {
+				t.Fatalf("Expected %d applied discounts, got %d", len(tt.discounts), len(result.AppliedDiscounts))
+			}
+			for i, application := range result.AppliedDiscounts {
+				if application.DiscountAmount != tt.discounts[i] {
+					t.Errorf("Expected discount %d to be %.2f, got %.2f", i, tt.discounts[i], application.DiscountAmount)
+				}
+			}
+		})
+	}
+	
+	// A fixed price rule counts as fixed and sees the amount left by percentage rules
+	input := newInput(ApplicationOrderPercentageFirst)
+	input.BulkRules[0] = BulkDiscountRule{MinQuantity: 1, DiscountType: "fixed_price", DiscountValue: 80}
+	result := Calculate(input)
+	
+	if result.FinalAmount != 80 || result.AppliedDiscounts[0].DiscountAmount != 10 || result.AppliedDiscounts[1].DiscountAmount != 10 {
+		t.Errorf("Expected 10.00 then 10.00 off to a final 80.00, got %+v", result.AppliedDiscounts)
+	}
+}
+
+func TestTieredBulkDiscounts(t *testing.T) {
+	rule := TieredBulkRule{
+		ID: "graduated",
+		Brackets: []TieredBulkBracket{
+			{MinQuantity: 12, DiscountValue: 15},
+			{MinQuantity: 3, DiscountValue: 5},
+			{MinQuantity: 6, DiscountValue: 10},
+		},
+	}
+	
+	t.Run("Brackets", func(t *testing.T) {
+		tests := []struct {
+			quantity int
+			percent  float64
+			min      int
+		}{
+			{2, 0, 0},
+			{3, 5, 3},
+			{5, 5, 3},
+			{6, 10, 6},
+			{11, 10, 6},
+			{12, 15, 12},
+			{40, 15, 12},
+		}
+		
+		for _, tt := range tests {
+			result := Calculate(DiscountCalculationInput{
+				Items: []DiscountItem{{ID: "mug", Price: 10, Quantity: tt.quantity, Category: "kitchen"}},
+				TieredBulkRules: []TieredBulkRule{rule},
+				AllowStacking: true,
+				Explain: true,
+			})
+			
+			expected := float64(tt.quantity) * 10 * tt.percent / 100
+			if result.TotalDiscount != expected {
+				t.Errorf("Quantity %d: expected discount %.2f, got %.2f", tt.quantity, expected, result.TotalDiscount)
+			}
+			if tt.percent == 0 {
+				if len(result.AppliedDiscounts) != 0 || len(result.SkippedRules) != 1 || result.SkippedRules[0].Reason != SkipReasonBelowMinQuantity {
+					t.Errorf("Quantity %d: expected the rule skipped below the first bracket, got %+v", tt.quantity, result.SkippedRules)
+				}
+				continue
+			}
+			if len(result.AppliedDiscounts) != 1 || result.AppliedDiscounts[0].Bracket == nil {
+				t.Fatalf("Quantity %d: expected one application with a bracket, got %+v", tt.quantity, result.AppliedDiscounts)
+			}
+			if bracket := result.AppliedDiscounts[0].Bracket; bracket.MinQuantity != tt.min || bracket.DiscountValue != tt.percent {
+				t.Errorf("Quantity %d: expected the %d+ bracket, got %+v", tt.quantity, tt.min, *bracket)
+			}
+		}
+	})
+	
+	t.Run("Scope", func(t *testing.T) {
+		items := []DiscountItem{
+			{ID: "mug", Price: 10, Quantity: 4, Category: "kitchen"},
+			{ID: "plate", Price: 20, Quantity: 2, Category: "kitchen"},
+			{ID: "towel", Price: 5, Quantity: 3, Category: "bath"},
+		}
+		
+		// Per item: mugs reach 3+ (5%), plates miss the first bracket, towels reach 3+ (5%)
+		result := Calculate(DiscountCalculationInput{Items: items, TieredBulkRules: []TieredBulkRule{rule}, AllowStacking: true})
+		
+		if result.TotalDiscount != 2.75 || len(result.AppliedDiscounts) != 2 {
+			t.Errorf("Expected 2.00 + 0.75 from two item groups, got %.2f from %d", result.TotalDiscount, len(result.AppliedDiscounts))
+		}
+		
+		// Per category: 6 kitchen items reach 6+ (10% of $80), 3 bath items reach 3+ (5% of $15)
+		categoryRule := rule
+		categoryRule.Scope = TieredBulkScopeCategory
+		result = Calculate(DiscountCalculationInput{Items: items, TieredBulkRules: []TieredBulkRule{categoryRule}, AllowStacking: true})
+		
+		if result.TotalDiscount != 8.75 || len(result.AppliedDiscounts) != 2 {
+			t.Fatalf("Expected 8.00 + 0.75 from two category groups, got %.2f from %d", result.TotalDiscount, len(result.AppliedDiscounts))
+		}
+		if result.AppliedDiscounts[0].Bracket.MinQuantity != 6 || result.AppliedDiscounts[1].Bracket.MinQuantity != 3 {
+			t.Errorf("Expected kitchen at 6+ and bath at 3+, got %+v and %+v", *result.AppliedDiscounts[0].Bracket, *result.AppliedDiscounts[1].Bracket)
+		}
+	})
+	
+	t.Run("StackingAndCap", func(t *testing.T) {
+		input := DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "mug", Price: 10, Quantity: 12, Category: "kitchen"}},
+			BulkRules: []BulkDiscountRule{{MinQuantity: 10, DiscountType: "percentage", DiscountValue: 10}},
+			TieredBulkRules: []TieredBulkRule{rule},
+			AllowStacking: true,
+			Explain: true,
+		}
+		
+		// $120: bulk $12 + tiered 15% $18
+		result := Calculate(input)
+		
+		if result.TotalDiscount != 30 || len(result.AppliedDiscounts) != 2 {
+			t.Errorf("Expected stacked 30.00 from two applications, got %.2f from %d", result.TotalDiscount, len(result.AppliedDiscounts))
+		}
+		
+		// The cap trims the combined discount and the tiered rule still reports its bracket
+		input.MaxStackedDiscountPercent = 20
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 24 {
+			t.Errorf("Expected 24.00 at the 20%% cap, got %.2f", result.TotalDiscount)
+		}
+		if last := result.AppliedDiscounts[len(result.AppliedDiscounts)-1]; last.Type != DiscountTypeTieredBulk || last.Bracket.MinQuantity != 12 {
+			t.Errorf("Expected the tiered application with the 12+ bracket last, got %+v", last)
+		}
+		
+		// Without stacking the better of the two applies
+		input.AllowStacking = false
+		input.MaxStackedDiscountPercent = 0
+		result = Calculate(input)
+		
+		if result.TotalDiscount != 18 || result.AppliedDiscounts[0].Type != DiscountTypeTieredBulk {
+			t.Errorf("Expected the tiered 18.00 as the best single discount, got %.2f", result.TotalDiscount)
+		}
+	})
+	
+	t.Run("NoBrackets", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "mug", Price: 10, Quantity: 12}},
+			TieredBulkRules: []TieredBulkRule{{ID: "empty"}},
+			AllowStacking: true,
+			Explain: true,
+		})
+		
+		if result.TotalDiscount != 0 || len(result.SkippedRules) != 1 || result.SkippedRules[0].Reason != SkipReasonInvalidRule {
+			t.Errorf("Expected the empty rule skipped as invalid, got %+v", result.SkippedRules)
+		}
+	})
+}
+
+func TestBOGODiscounts(t *testing.T) {
+	socks := []DiscountItem{
+		{ID: "socks-a", Price: 8, Quantity: 1, Category: "socks"},
+		{ID: "socks-b", Price: 10, Quantity: 1, Category: "socks"},
+		{ID: "socks-c", Price: 12, Quantity: 1, Category: "socks"},
+		{ID: "socks-d", Price: 6, Quantity: 1, Category: "socks"},
+		{ID: "socks-e", Price: 9, Quantity: 1, Category: "socks"},
+		{ID: "socks-f", Price: 11, Quantity: 1, Category: "socks"},
+		{ID: "hat", Price: 25, Quantity: 1, Category: "hats"},
+	}
+	buy2Get1 := BOGORule{ID: "b2g1", EligibleCategories: []string{"socks"}, BuyQuantity: 2, GetQuantity: 1, DiscountPercent: 100}
+	
+	unitIDs := func(units []DiscountedUnit) []string {
+		ids := []string{}
+		for _, unit := range units {
+			ids = append(ids, fmt.Sprintf("%s#%d", unit.ItemID, unit.Unit))
+		}
+		return ids
+	}
+	
+	t.Run("CheapestFree", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{Items: socks, BOGORules: []BOGORule{buy2Get1}, AllowStacking: true})
+		
+		if result.TotalDiscount != 14 || len(result.AppliedDiscounts) != 1 {
+			t.Fatalf("Expected the $6 and $8 socks free for 14.00, got %.2f", result.TotalDiscount)
+		}
+		application := result.AppliedDiscounts[0]
+		if ids := unitIDs(application.DiscountedUnits); len(ids) != 2 || ids[0] != "socks-d#1" || ids[1] != "socks-a#1" {
+			t.Errorf("Expected socks-d#1 and socks-a#1 discounted, got %v", ids)
+		}
+		if application.Type != DiscountTypeBOGO || application.DiscountedUnits[0].DiscountAmount != 6 {
+			t.Errorf("Unexpected application: %+v", application)
+		}
+	})
+	
+	t.Run("MostExpensive", func(t *testing.T) {
+		rule := buy2Get1
+		rule.Selection = "most_expensive"
+		result := Calculate(DiscountCalculationInput{Items: socks, BOGORules: []BOGORule{rule}, AllowStacking: true})
+		
+		if result.TotalDiscount != 23 {
+			t.Errorf("Expected the $12 and $11 socks free for 23.00, got %.2f", result.TotalDiscount)
+		}
+		if ids := unitIDs(result.AppliedDiscounts[0].DiscountedUnits); len(ids) != 2 || ids[0] != "socks-c#1" || ids[1] != "socks-f#1" {
+			t.Errorf("Expected socks-c#1 and socks-f#1 discounted, got %v", ids)
+		}
+	})
+	
+	t.Run("HalfOffAndUnits", func(t *testing.T) {
+		rule := BOGORule{ID: "b1g1-half", BuyQuantity: 1, GetQuantity: 1, DiscountPercent: 50}
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "shirt", Price: 20, Quantity: 3}},
+			BOGORules: []BOGORule{rule},
+			AllowStacking: true,
+		})
+		
+		// 3 units make one complete set; the odd unit pays full price
+		if result.TotalDiscount != 10 {
+			t.Errorf("Expected one shirt at half price for 10.00, got %.2f", result.TotalDiscount)
+		}
+		if ids := unitIDs(result.AppliedDiscounts[0].DiscountedUnits); len(ids) != 1 || ids[0] != "shirt#1" {
+			t.Errorf("Expected shirt#1 discounted, got %v", ids)
+		}
+	})
+	
+	t.Run("UnitAmountsSumToDiscount", func(t *testing.T) {
+		rule := BOGORule{ID: "b1g1-third", BuyQuantity: 1, GetQuantity: 1, DiscountPercent: 33}
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "candle", Price: 9.99, Quantity: 4}},
+			BOGORules: []BOGORule{rule},
+			AllowStacking: true,
+		})
+		
+		application := result.AppliedDiscounts[0]
+		sum := 0.0
+		for _, unit := range application.DiscountedUnits {
+			sum += unit.DiscountAmount
+		}
+		if math.Abs(sum-application.DiscountAmount) > 1e-9 || result.TotalDiscount != 6.59 {
+			t.Errorf("Expected unit discounts to sum to 6.59, got %.4f of %.2f", sum, result.TotalDiscount)
+		}
+	})
+	
+	t.Run("UnitAmountsFollowFloorsAndCap", func(t *testing.T) {
+		rule := BOGORule{ID: "b1g1", BuyQuantity: 1, GetQuantity: 1, DiscountPercent: 100}
+		unitSum := func(application DiscountApplication) float64 {
+			sum := 0.0
+			for _, unit := range application.DiscountedUnits {
+				sum += unit.DiscountAmount
+			}
+			return sum
+		}
+		
+		// The $6 floor leaves only $8 to take off two $10 shirts
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "shirt", Price: 10, Quantity: 2, MinPrice: 6}},
+			BOGORules: []BOGORule{rule},
+			AllowStacking: true,
+		})
+		application := result.AppliedDiscounts[0]
+		if result.TotalDiscount != 8 || application.DiscountAmount != 8 || unitSum(application) != 8 {
+			t.Errorf("Expected the free unit to carry the floored 8.00, got units %+v of %.2f", application.DiscountedUnits, result.TotalDiscount)
+		}
+		
+		// A 25% stacking cap leaves 5.00 of the 10.00 free shirt
+		result = Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "shirt", Price: 10, Quantity: 2}},
+			BOGORules: []BOGORule{rule},
+			AllowStacking: true,
+			MaxStackedDiscountPercent: 25,
+		})
+		if result.TotalDiscount != 5 || unitSum(result.AppliedDiscounts[0]) != 5 {
+			t.Errorf("Expected the free unit trimmed to the 5.00 cap, got units %+v of %.2f", result.AppliedDiscounts[0].DiscountedUnits, result.TotalDiscount)
+		}
+	})
+	
+	t.Run("MaxApplications", func(t *testing.T) {
+		rule := BOGORule{ID: "b2g1-capped", BuyQuantity: 2, GetQuantity: 1, DiscountPercent: 100, MaxApplications: 2}
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "pen", Price: 5, Quantity: 9}},
+			BOGORules: []BOGORule{rule},
+			AllowStacking: true,
+		})
+		
+		if result.TotalDiscount != 10 || len(result.AppliedDiscounts[0].DiscountedUnits) != 2 {
+			t.Errorf("Expected 2 free pens for 10.00 under the cap, got %.2f", result.TotalDiscount)
+		}
+	})
+	
+	t.Run("Skipped", func(t *testing.T) {
+		result := Calculate(DiscountCalculationInput{
+			Items: []DiscountItem{{ID: "socks-a", Price: 8, Quantity: 2, Category: "socks"}},
+			BOGORules: []BOGORule{buy2Get1, {ID: "broken", BuyQuantity: 1}},
+			AllowStacking: true,
+			Explain: true,
+		})
+		
+		skipped := map[string]SkipReason{}
+		for _, rule := range result.SkippedRules {
+			skipped[rule.RuleID] = rule.Reason
+		}
+		if result.TotalDiscount != 0 || skipped["b2g1"] != SkipReasonBelowMinQuantity || skipped["broken"] != SkipReasonInvalidRule {
+			t.Errorf("Expected both rules skipped, got %v", skipped)
+		}
+	})
+}
+
+func TestReconcileLargestLine(t *testing.T) {
+	newInput := func(reconcile bool, prices ...float64) DiscountCalculationInput {
+		input := DiscountCalculationInput{
+			BulkRules: []BulkDiscountRule{{MinQuantity: 1, DiscountType: "percentage", DiscountValue: 20}},
+			AllowStacking: true,
+			ReconcileLargestLine: reconcile,
+		}
+		for i, price := range prices {
+			input.Items = append(input.Items, DiscountItem{ID: fmt.Sprintf("item%d", i+1), Price: price, Quantity: 1})
+		}
+		return input
+	}
+	lineDiscounts := func(result DiscountCalculationResult) []float64 {
+		discounts := []float64{}
+		for _, line := range result.ItemBreakdown {
+			discounts = append(discounts, line.DiscountAmount)
+		}
+		return discounts
+	}
+	
+	t.Run("DriftOnLargestLine", func(t *testing.T) {
+		// 20% of three $3.33 lines rounds to 0.67 each; 20% of $19.99 is 4.00, not 4.01
+		result := Calculate(newInput(true, 3.33, 3.33, 3.33, 10))
+		
+		expected := []float64{0.67, 0.67, 0.67, 1.99}
+		got := lineDiscounts(result)
+		for i := range expected {
+			if got[i] != expected[i] {
+				t.Fatalf("Expected lines %v, got %v", expected, got)
+			}
+		}
+		if result.TotalDiscount != 4 {
+			t.Errorf("Expected total discount 4.00, got %.2f", result.TotalDiscount)
+		}
+		
+		// The default spreads the cents by remainder instead
+		result = Calculate(newInput(false, 3.33, 3.33, 3.33, 10))
+		
+		if got := lineDiscounts(result); got[2] != 0.66 || got[3] != 2 {
+			t.Errorf("Expected largest-remainder lines without the option, got %v", got)
+		}
+	})
+	
+	t.Run("RealizedPercent", func(t *testing.T) {
+		carts := [][]float64{
+			{3.33, 3.33, 3.33},
+			{0.99, 1.49, 2.29, 7.77},
+			{19.99, 0.05, 0.05, 0.05},
+			{1.11, 2.22, 3.33, 4.44, 5.55, 6.66},
+		}
+		for _, prices := range carts {
+			result := Calculate(newInput(true, prices...))
+			
+			subtotal, sum := 0.0, 0.0
+			for _, price := range prices {
+				subtotal += price
+			}
+			for _, discount := range lineDiscounts(result) {
+				sum += discount
+			}
+			advertised := subtotal * 0.20
+			if math.Abs(sum-result.TotalDiscount) > 1e-9 || math.Abs(sum-advertised) > 0.005+1e-9 {
+				t.Errorf("Cart %v: lines sum to %.2f, total %.2f, advertised %.4f", prices, sum, result.TotalDiscount, advertised)
+			}
+			
+			// Every line but the largest keeps its own rounded 20%
+			largest := 0
+			for i, price := range prices {
+				if price > prices[largest] {
+					largest = i
+				}
+			}
+			for i, discount := range lineDiscounts(result) {
+				if own := math.Round(prices[i]*20) / 100; i != largest && discount != own {
+					t.Errorf("Cart %v: expected line %d at %.2f, got %.2f", prices, i, own, discount)
+				}
+			}
+		}
+	})
+}
+
+func TestReconcileLargestLineParts(t *testing.T) {
+	tests := []struct {
+		name        string
+		total       float64
+		parts       []float64
+		lineAmounts []float64
+		expected    []float64
+	}{
+		{"DriftOnLargestLine", 4.00, []float64{0.666, 0.666, 0.666, 2}, []float64{3.33, 3.33, 3.33, 10}, []float64{0.67, 0.67, 0.67, 1.99}},
+		{"FirstLineOnTies", 2.00, []float64{0.666, 0.666, 0.666}, []float64{3.33, 3.33, 3.33}, []float64{0.66, 0.67, 0.67}},
+		{"NoDrift", 3.00, []float64{1, 2}, []float64{5, 10}, []float64{1, 2}},
+		{"NoDiscount", 0, []float64{0, 0}, []float64{5, 10}, []float64{0, 0}},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileLargestLine(tt.total, tt.parts, tt.lineAmounts)
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("Expected parts %v, got %v", tt.expected, got)
+				}
+			}
+			
+			sum := 0.0
+			for _, part := range got {
+				sum += part
+			}
+			if math.Abs(sum-tt.total) > 1e-9 {
+				t.Errorf("Expected parts to add up to %.2f, got %.2f", tt.total, sum)
+			}
+		})
+	}
+}
+
+func TestEffectiveDiscountRate(t *testing.T) {
+	sumPercents := func(stages []StageAttribution) float64 {
+		total := 0.0
+		for _, stage := range stages {
+			total += stage.Percent
+		}
+		return total
+	}
+	
+	t.Run("MixedPromotionStack", func(t *testing.T) {
+		stages := []DiscountStage{
+			{Name: "bulk", Amount: 30.0},
+			{Name: "coupon", Amount: 20.0},
+			{Name: "loyalty", Amount: 10.0},
+		}
+		
+		result := EffectiveDiscountRate(200.0, 140.0, stages)
+		
+		if result.TotalDiscount != 60.0 {
+			t.Errorf("Expected total discount 60.0, got %f", result.TotalDiscount)
+		}
+		
+		if result.EffectivePercent != 30.0 {
+			t.Errorf("Expected effective rate 30%%, got %f", result.EffectivePercent)
+		}
+		
+		if len(result.Stages) != 3 {
+			t.Fatalf("Expected 3 stage attributions, got %d", len(result.Stages))
+		}
+		
+		if result.Stages[0].Percent != 15.0 || result.Stages[1].Percent != 10.0 || result.Stages[2].Percent != 5.0 {
+			t.Errorf("Unexpected stage attributions: %+v", result.Stages)
+		}
+		
+		if math.Abs(sumPercents(result.Stages)-result.EffectivePercent) > 1e-9 {
+			t.Errorf("Stage attributions %f do not reconcile to %f", sumPercents(result.Stages), result.EffectivePercent)
+		}
+	})
+	
+	t.Run("UnattributedRemainder", func(t *testing.T) {
+		result := EffectiveDiscountRate(100.0, 75.0, []DiscountStage{{Name: "coupon", Amount: 20.0}})
+		
+		if len(result.Stages) != 2 {
+			t.Fatalf("Expected coupon and unattributed stages, got %+v", result.Stages)
+		}
+		
+		if result.Stages[1].Name != UnattributedStageName || result.Stages[1].Amount != 5.0 {
+			t.Errorf("Expected unattributed remainder of 5.0, got %+v", result.Stages[1])
+		}
+		
+		if math.Abs(sumPercents(result.Stages)-result.EffectivePercent) > 1e-9 {
+			t.Errorf("Stage attributions %f do not reconcile to %f", sumPercents(result.Stages), result.EffectivePercent)
+		}
+	})
+	
+	t.Run("NoStages", func(t *testing.T) {
+		result := EffectiveDiscountRate(80.0, 60.0, nil)
+		
+		if result.EffectivePercent != 25.0 {
+			t.Errorf("Expected effective rate 25%%, got %f", result.EffectivePercent)
+		}
+		
+		if len(result.Stages) != 0 {
+			t.Errorf("Expected no stage attributions, got %+v", result.Stages)
+		}
+	})
+	
+	t.Run("ZeroOriginalAmount", func(t *testing.T) {
+		result := EffectiveDiscountRate(0, 0, []DiscountStage{{Name: "coupon", Amount: 0}})
+		
+		if result.EffectivePercent != 0 {
+			t.Errorf("Expected 0%% for zero original amount, got %f", result.EffectivePercent)
+		}
+		
+		if math.IsNaN(result.Stages[0].Percent) || result.Stages[0].Percent != 0 {
+			t.Errorf("Expected 0%% stage attribution, got %f", result.Stages[0].Percent)
+		}
+	})
+}
+
 func TestHelperFunctions(t *testing.T) {
 	t.Run("GetApplicableItems", func(t *testing.T) {
 		items := []DiscountItem{
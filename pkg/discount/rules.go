@@ -33,6 +33,8 @@ import (
 	"math"
 	"strings"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // RuleEngine manages and applies discount rules.
@@ -391,7 +393,7 @@ func (re *RuleEngine) ApplyFrequencyDiscounts(items []DiscountItem, customer Cus
 
 	result.FinalAmount = result.OriginalAmount - result.TotalDiscount
 	if result.OriginalAmount > 0 {
-		result.SavingsPercent = (result.TotalDiscount / result.OriginalAmount) * 100
+		result.SavingsPercent = utils.RoundToPercent((result.TotalDiscount / result.OriginalAmount) * 100)
 	}
 
 	return result
@@ -469,7 +471,7 @@ func (re *RuleEngine) ApplySeasonalDiscounts(items []DiscountItem, customer Cust
 
 	result.FinalAmount = result.OriginalAmount - result.TotalDiscount
 	if result.OriginalAmount > 0 {
-		result.SavingsPercent = (result.TotalDiscount / result.OriginalAmount) * 100
+		result.SavingsPercent = utils.RoundToPercent((result.TotalDiscount / result.OriginalAmount) * 100)
 	}
 
 	return result
@@ -543,7 +545,7 @@ func (re *RuleEngine) ApplyCrossSellDiscounts(items []DiscountItem) DiscountCalc
 
 	result.FinalAmount = result.OriginalAmount - result.TotalDiscount
 	if result.OriginalAmount > 0 {
-		result.SavingsPercent = (result.TotalDiscount / result.OriginalAmount) * 100
+		result.SavingsPercent = utils.RoundToPercent((result.TotalDiscount / result.OriginalAmount) * 100)
 	}
 
 	return result
@@ -615,7 +617,7 @@ func (re *RuleEngine) ApplyMixAndMatchDiscounts(items []DiscountItem) DiscountCa
 
 	result.FinalAmount = result.OriginalAmount - result.TotalDiscount
 	if result.OriginalAmount > 0 {
-		result.SavingsPercent = (result.TotalDiscount / result.OriginalAmount) * 100
+		result.SavingsPercent = utils.RoundToPercent((result.TotalDiscount / result.OriginalAmount) * 100)
 	}
 
 	return result
@@ -891,6 +893,10 @@ func (re *RuleEngine) GetApplicableRules(items []DiscountItem, customer Customer
 
 	// Check bulk rules
 	for _, rule := range re.BulkRules {
+		if !isBulkRuleApplicableToCustomer(rule, customer) {
+			continue
+		}
+
 		applicableItems := getApplicableItems(items, rule.ApplicableCategories, rule.ApplicableProducts)
 		if getTotalQuantity(applicableItems) >= rule.MinQuantity {
 			applicableRules["bulk"] = append(applicableRules["bulk"].([]BulkDiscountRule), rule)
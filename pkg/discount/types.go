@@ -66,6 +66,95 @@ const (
 	// DiscountTypeProgressive represents progressive discounts
 	// Applied with increasing discount rates based on quantity
 	DiscountTypeProgressive DiscountType = "progressive"
+
+	// DiscountTypeMixFixedPrice represents multi-buy fixed price discounts
+	// Applied when sets of eligible items are sold for a fixed group price
+	DiscountTypeMixFixedPrice DiscountType = "mix_fixed_price"
+
+	// DiscountTypeFormula represents formula-based discounts
+	// Applied with a percentage that scales smoothly with quantity
+	DiscountTypeFormula DiscountType = "formula"
+
+	// DiscountTypeOrderThreshold represents spend-threshold order discounts
+	// Applied to the order subtotal once it reaches a minimum spend
+	DiscountTypeOrderThreshold DiscountType = "order_threshold"
+
+	// DiscountTypeTieredBulk represents graduated quantity discounts
+	// Applied at the rate of the highest quantity bracket reached
+	DiscountTypeTieredBulk DiscountType = "tiered_bulk"
+
+	// DiscountTypeBOGO represents buy-X-get-Y discounts
+	// Applied to the "get" units of each complete buy-and-get set
+	DiscountTypeBOGO DiscountType = "bogo"
+)
+
+// SkipReason explains why a configured discount rule was not applied.
+// Reported in DiscountCalculationResult.SkippedRules when Explain is enabled.
+type SkipReason string
+
+const (
+	// SkipReasonBelowMinQuantity means the eligible quantity is below the rule's minimum
+	SkipReasonBelowMinQuantity SkipReason = "below_min_quantity"
+
+	// SkipReasonAboveMaxQuantity means the eligible quantity exceeds the rule's maximum
+	SkipReasonAboveMaxQuantity SkipReason = "above_max_quantity"
+
+	// SkipReasonCategoryMismatch means no item matches the rule's categories or products
+	SkipReasonCategoryMismatch SkipReason = "category_mismatch"
+
+	// SkipReasonCustomerNotEligible means the customer's type or loyalty tier is not targeted
+	SkipReasonCustomerNotEligible SkipReason = "customer_not_eligible"
+
+	// SkipReasonBelowMinOrderAmount means the eligible amount is below the rule's minimum order
+	SkipReasonBelowMinOrderAmount SkipReason = "below_min_order_amount"
+
+	// SkipReasonBundleIncomplete means the cart does not contain the bundle's required items
+	SkipReasonBundleIncomplete SkipReason = "bundle_incomplete"
+
+	// SkipReasonInactive means the rule is outside its validity period
+	SkipReasonInactive SkipReason = "inactive"
+
+	// SkipReasonInvalidRule means the rule configuration cannot be applied
+	SkipReasonInvalidRule SkipReason = "invalid_rule"
+
+	// SkipReasonNoDiscount means the rule matched but produced no savings
+	SkipReasonNoDiscount SkipReason = "no_discount"
+
+	// SkipReasonCapReached means the maximum stacked discount was already reached
+	// before the rule was applied, so it did not add any savings
+	SkipReasonCapReached SkipReason = "cap_reached"
+)
+
+// ApplicationOrder controls how bulk discount rules combine when several of
+// them match the same items. By default every rule is computed on the full
+// price of its items, so the order does not change the total. The other orders
+// apply the rules one after another, each to the amount left by the rules
+// before it, which makes percentage and fixed-amount rules order-dependent:
+//
+//   // $100 item, 10% rule and $5 rule
+//   // ApplicationOrderPercentageFirst: $10 off, then $5 off   = $85.00
+//   // ApplicationOrderFixedFirst:      $5 off, then 10% of $95 = $85.50
+//
+// DiscountCalculationResult.AppliedDiscounts lists bulk discounts in the order
+// they were applied.
+type ApplicationOrder string
+
+const (
+	// ApplicationOrderIndependent computes every bulk rule on the full price of
+	// its items (the default)
+	ApplicationOrderIndependent ApplicationOrder = ""
+
+	// ApplicationOrderSequential applies bulk rules in the order they are listed,
+	// each to the amount remaining after the previous ones
+	ApplicationOrderSequential ApplicationOrder = "sequential"
+
+	// ApplicationOrderPercentageFirst applies percentage rules before fixed_amount
+	// and fixed_price rules, each to the amount remaining after the previous ones
+	ApplicationOrderPercentageFirst ApplicationOrder = "percentage_first"
+
+	// ApplicationOrderFixedFirst applies fixed_amount and fixed_price rules before
+	// percentage rules, each to the amount remaining after the previous ones
+	ApplicationOrderFixedFirst ApplicationOrder = "fixed_first"
 )
 
 // BulkDiscountRule represents bulk discount configuration.
@@ -76,6 +165,7 @@ const (
 //   - Minimum and maximum quantity thresholds
 //   - Multiple discount types (percentage, fixed amount, fixed price)
 //   - Category and product-specific targeting
+//   - Customer type and loyalty tier targeting (empty lists apply to everyone)
 //   - Flexible quantity range configuration
 //
 // Example:
@@ -84,6 +174,7 @@ const (
 //       DiscountType: "percentage",
 //       DiscountValue: 15.0, // 15% off
 //       ApplicableCategories: []string{"electronics"},
+//       ApplicableCustomerTypes: []string{"premium"},
 //   }
 type BulkDiscountRule struct {
 	MinQuantity    int     `json:"min_quantity"`
@@ -92,6 +183,8 @@ type BulkDiscountRule struct {
 	DiscountValue  float64 `json:"discount_value"`
 	ApplicableCategories []string `json:"applicable_categories,omitempty"`
 	ApplicableProducts   []string `json:"applicable_products,omitempty"`
+	ApplicableCustomerTypes []string `json:"applicable_customer_types,omitempty"` // Matched against Customer.Type
+	ApplicableTiers      []string `json:"applicable_tiers,omitempty"`      // Matched against Customer.LoyaltyTier
 }
 
 // TierPricingRule represents tier-based pricing configuration.
@@ -199,6 +292,106 @@ type ProgressiveDiscountRule struct {
 	Category        string  `json:"category,omitempty"`
 }
 
+// TieredBulkScope selects how a TieredBulkRule counts quantities when choosing
+// a bracket.
+type TieredBulkScope string
+
+const (
+	// TieredBulkScopeItem picks a bracket for each item from its own quantity
+	// (the default)
+	TieredBulkScopeItem TieredBulkScope = "item"
+
+	// TieredBulkScopeCategory picks a bracket for each category from the total
+	// quantity of its items
+	TieredBulkScopeCategory TieredBulkScope = "category"
+)
+
+// TieredBulkBracket is one step of a TieredBulkRule: from MinQuantity units on,
+// DiscountValue percent comes off.
+type TieredBulkBracket struct {
+	MinQuantity   int     `json:"min_quantity"`
+	DiscountValue float64 `json:"discount_value"` // Percent off
+}
+
+// TieredBulkRule represents a graduated bulk discount with several quantity
+// brackets, such as 5% at 3 units, 10% at 6 and 15% at 12. Only the highest
+// bracket the quantity reaches applies; quantities above the top bracket keep
+// its rate. Quantities are counted per item or per category depending on
+// Scope, and each item or category gets its own discount application with the
+// matched bracket.
+//
+// Features:
+//   - Any number of brackets, in any order
+//   - Inclusive boundaries (exactly MinQuantity units qualify)
+//   - Per-item or per-category quantity counting
+//   - Category and product targeting
+//
+// Example:
+//   rule := TieredBulkRule{
+//       ID: "graduated-volume",
+//       Name: "Buy More, Save More",
+//       Brackets: []TieredBulkBracket{
+//           {MinQuantity: 3, DiscountValue: 5},
+//           {MinQuantity: 6, DiscountValue: 10},
+//           {MinQuantity: 12, DiscountValue: 15},
+//       },
+//       Scope: TieredBulkScopeCategory,
+//   }
+type TieredBulkRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	Brackets             []TieredBulkBracket `json:"brackets"`
+	Scope                TieredBulkScope     `json:"scope,omitempty"` // Empty means TieredBulkScopeItem
+	ApplicableCategories []string            `json:"applicable_categories,omitempty"`
+	ApplicableProducts   []string            `json:"applicable_products,omitempty"`
+}
+
+// FormulaCurve identifies the curve a FormulaDiscountRule uses to turn quantity
+// into a discount percentage.
+type FormulaCurve string
+
+const (
+	// FormulaCurveLinear grows the discount by a fixed amount per unit:
+	// percent = Offset + Coefficient × quantity
+	FormulaCurveLinear FormulaCurve = "linear"
+
+	// FormulaCurveLogarithmic grows the discount quickly at first, then slowly:
+	// percent = Offset + Coefficient × ln(quantity)
+	FormulaCurveLogarithmic FormulaCurve = "logarithmic"
+
+	// FormulaCurveCapped approaches MaxPercent smoothly without a hard kink:
+	// percent = MaxPercent × (1 − e^(−Coefficient × quantity))
+	FormulaCurveCapped FormulaCurve = "capped"
+)
+
+// FormulaDiscountRule represents a discount whose percentage follows a continuous
+// curve of the eligible quantity instead of discrete tiers.
+//
+// Features:
+//   - Linear, logarithmic, and capped curves
+//   - Result clamped between 0 and MaxPercent (100 when unset)
+//   - Optional minimum quantity before the curve applies
+//   - Category-specific or global application
+//
+// Example:
+//   // discount% = min(30, 2 × quantity)
+//   rule := FormulaDiscountRule{
+//       ID: "smooth-volume",
+//       Curve: FormulaCurveLinear,
+//       Coefficient: 2.0,
+//       MaxPercent: 30.0,
+//   }
+type FormulaDiscountRule struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Curve       FormulaCurve `json:"curve"`
+	Coefficient float64      `json:"coefficient"`
+	Offset      float64      `json:"offset,omitempty"`      // Added to linear and logarithmic curves
+	MaxPercent  float64      `json:"max_percent,omitempty"` // Upper clamp; 0 means 100%
+	MinQuantity int          `json:"min_quantity,omitempty"`
+	Category    string       `json:"category,omitempty"`
+}
+
 // CategoryDiscountRule represents category-specific discount configuration.
 // Provides targeted discounts for specific product categories,
 // enabling category-based promotional campaigns.
@@ -227,6 +420,33 @@ type CategoryDiscountRule struct {
 	ValidUntil      time.Time `json:"valid_until"`
 }
 
+// OrderThresholdRule represents a cart-level "spend X, get Y off" discount.
+// The discount applies to the order subtotal after item-level discounts
+// rather than to individual items.
+//
+// Features:
+//   - Minimum spend measured on the post-item-discount subtotal
+//   - Percentage or fixed amount off the order
+//   - Optional maximum discount amount for percentage rules
+//   - Only the best qualifying threshold rule applies
+//
+// Example:
+//   rule := OrderThresholdRule{
+//       ID: "spend-100-save-15",
+//       Name: "Spend $100, get $15 off",
+//       MinSpend: 100.0,
+//       DiscountType: "fixed_amount",
+//       DiscountValue: 15.0,
+//   }
+type OrderThresholdRule struct {
+	ID                string  `json:"id"`
+	Name              string  `json:"name"`
+	MinSpend          float64 `json:"min_spend"`
+	DiscountType      string  `json:"discount_type"` // "percentage" or "fixed_amount"
+	DiscountValue     float64 `json:"discount_value"`
+	MaxDiscountAmount float64 `json:"max_discount_amount,omitempty"`
+}
+
 // DiscountItem represents an item for discount calculation.
 // Contains all necessary information about a product item
 // required for discount calculations and rule applications.
@@ -236,6 +456,7 @@ type CategoryDiscountRule struct {
 //   - Price and quantity information
 //   - Category classification
 //   - Optional weight and sale status
+//   - Optional minimum unit price the discounts may not go below
 //   - Flexible item attributes
 //
 // Example:
@@ -245,6 +466,7 @@ type CategoryDiscountRule struct {
 //       Quantity: 2,
 //       Category: "electronics",
 //       Weight: 2.5,
+//       MinPrice: 750.00, // unit cost
 //   }
 type DiscountItem struct {
 	ID       string  `json:"id"`
@@ -253,6 +475,7 @@ type DiscountItem struct {
 	Category string  `json:"category"`
 	Weight   float64 `json:"weight,omitempty"`
 	IsSale   bool    `json:"is_sale,omitempty"`
+	MinPrice float64 `json:"min_price,omitempty"` // Floor for the discounted unit price, e.g. unit cost; 0 means no floor
 }
 
 // Customer represents customer information for discount calculation.
@@ -261,7 +484,7 @@ type DiscountItem struct {
 //
 // Features:
 //   - Unique customer identification
-//   - Loyalty tier classification
+//   - Customer type and loyalty tier classification
 //   - Purchase history tracking
 //   - Membership duration information
 //   - Repeat customer identification
@@ -269,6 +492,7 @@ type DiscountItem struct {
 // Example:
 //   customer := Customer{
 //       ID: "customer-123",
+//       Type: "premium",
 //       LoyaltyTier: "gold",
 //       MemberSince: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
 //       TotalPurchases: 5000.0,
@@ -277,6 +501,7 @@ type DiscountItem struct {
 //   }
 type Customer struct {
 	ID              string    `json:"id"`
+	Type            string    `json:"type,omitempty"` // Customer segment, e.g. "regular", "premium", "wholesale"
 	LoyaltyTier     string    `json:"loyalty_tier"`
 	MemberSince     time.Time `json:"member_since"`
 	TotalPurchases  float64   `json:"total_purchases"`
@@ -302,6 +527,17 @@ type Customer struct {
 //       AllowStacking: true,
 //       MaxStackedDiscountPercent: 50.0,
 //   }
+//
+// ApplicationOrder decides whether bulk rules are computed independently or
+// one after another (see ApplicationOrder).
+//
+// ReconcileLargestLine changes how ItemBreakdown is rounded. By default leftover
+// cents are spread over the lines with the largest remainders. With the option
+// set, each line's discount is rounded to cents on its own, as a receipt would
+// print it, and the difference from the rounded total is moved onto the line
+// with the largest original amount. Either way the lines add up to
+// TotalDiscount, which stays within a cent of the advertised percentage of the
+// eligible subtotal; the option only fixes which line absorbs the drift.
 type DiscountCalculationInput struct {
 	Items                   []DiscountItem          `json:"items"`
 	Customer                Customer                `json:"customer"`
@@ -311,8 +547,17 @@ type DiscountCalculationInput struct {
 	LoyaltyRules           []LoyaltyDiscountRule   `json:"loyalty_rules,omitempty"`
 	ProgressiveRules       []ProgressiveDiscountRule `json:"progressive_rules,omitempty"`
 	CategoryRules          []CategoryDiscountRule  `json:"category_rules,omitempty"`
+	MixFixedPriceRules     []MixForFixedPriceRule  `json:"mix_fixed_price_rules,omitempty"`
+	FormulaRules           []FormulaDiscountRule   `json:"formula_rules,omitempty"`
+	BOGORules              []BOGORule              `json:"bogo_rules,omitempty"`
+	TieredBulkRules        []TieredBulkRule        `json:"tiered_bulk_rules,omitempty"`
+	OrderThresholdRules    []OrderThresholdRule    `json:"order_threshold_rules,omitempty"`
 	AllowStacking          bool                    `json:"allow_stacking"`
 	MaxStackedDiscountPercent float64             `json:"max_stacked_discount_percent,omitempty"`
+	Explain                bool                    `json:"explain,omitempty"` // Report skipped rules in the result
+	CustomerFavorableRounding bool                 `json:"customer_favorable_rounding,omitempty"` // Round the discount up and the final amount down
+	ApplicationOrder       ApplicationOrder        `json:"application_order,omitempty"` // How matching bulk rules combine
+	ReconcileLargestLine   bool                    `json:"reconcile_largest_line,omitempty"` // Round lines independently and put the drift on the largest line
 }
 
 // DiscountApplication represents a single discount application.
@@ -324,6 +569,8 @@ type DiscountCalculationInput struct {
 //   - Applied discount amount tracking
 //   - Item-specific application details
 //   - Human-readable descriptions
+//   - Matched bracket for tiered bulk rules
+//   - Individual discounted units for BOGO rules
 //   - Comprehensive audit trail
 //
 // Example:
@@ -341,6 +588,8 @@ type DiscountApplication struct {
 	DiscountAmount float64      `json:"discount_amount"`
 	AppliedItems   []DiscountItem `json:"applied_items"`
 	Description    string       `json:"description"`
+	Bracket        *TieredBulkBracket `json:"bracket,omitempty"` // Matched bracket of a tiered bulk rule
+	DiscountedUnits []DiscountedUnit  `json:"discounted_units,omitempty"` // Units discounted by a BOGO rule
 }
 
 // DiscountCalculationResult represents the result of discount calculation.
@@ -350,7 +599,11 @@ type DiscountApplication struct {
 // Features:
 //   - Original and final amount tracking
 //   - Total discount calculation
+//   - Item-level and order-level discount split for separate invoice lines
 //   - Applied discount details
+//   - Skipped rule reasons (when Explain is enabled)
+//   - Price floor adjustments for items with a MinPrice
+//   - Per-item discount breakdown for refunds and tax bases
 //   - Savings percentage calculation
 //   - Validation status and error handling
 //
@@ -358,6 +611,8 @@ type DiscountApplication struct {
 //   result := DiscountCalculationResult{
 //       OriginalAmount: 200.0,
 //       TotalDiscount: 30.0,
+//       ItemDiscount: 15.0,
+//       OrderDiscount: 15.0,
 //       FinalAmount: 170.0,
 //       SavingsPercent: 15.0,
 //       IsValid: true,
@@ -365,11 +620,68 @@ type DiscountApplication struct {
 type DiscountCalculationResult struct {
 	OriginalAmount    float64               `json:"original_amount"`
 	TotalDiscount     float64               `json:"total_discount"`
+	ItemDiscount      float64               `json:"item_discount"`  // Part of TotalDiscount from item-level rules
+	OrderDiscount     float64               `json:"order_discount"` // Part of TotalDiscount from order threshold rules
 	FinalAmount       float64               `json:"final_amount"`
 	AppliedDiscounts  []DiscountApplication `json:"applied_discounts"`
 	SavingsPercent    float64               `json:"savings_percent"`
 	IsValid           bool                  `json:"is_valid"`
 	ErrorMessage      string                `json:"error_message,omitempty"`
+	SkippedRules      []SkippedRule         `json:"skipped_rules,omitempty"`
+	FloorAdjustments  []FloorAdjustment     `json:"floor_adjustments,omitempty"`
+	ItemBreakdown     []ItemDiscountBreakdown `json:"item_breakdown,omitempty"`
+}
+
+// ItemDiscountBreakdown records how much of a calculation's TotalDiscount landed
+// on one item, so a partial refund can reverse exactly that item's share. The
+// breakdowns of a result sum to its TotalDiscount.
+//
+// Example:
+//   breakdown := ItemDiscountBreakdown{
+//       ItemID: "item1",
+//       OriginalAmount: 200.0,
+//       DiscountAmount: 38.0,
+//       FinalAmount: 162.0,
+//       RuleIDs: []string{"bulk_discount", "spend-200"},
+//   }
+type ItemDiscountBreakdown struct {
+	ItemID         string   `json:"item_id"`
+	OriginalAmount float64  `json:"original_amount"` // Line total before discounts
+	DiscountAmount float64  `json:"discount_amount"`
+	FinalAmount    float64  `json:"final_amount"`
+	RuleIDs        []string `json:"rule_ids,omitempty"` // Rules whose discount landed on the item
+}
+
+// FloorAdjustment records discount removed from an item so its discounted unit
+// price does not fall below its MinPrice.
+//
+// Example:
+//   adjustment := FloorAdjustment{
+//       ItemID: "jacket",
+//       MinPrice: 70.0,
+//       Reduction: 5.0,
+//   }
+type FloorAdjustment struct {
+	ItemID    string  `json:"item_id"`
+	MinPrice  float64 `json:"min_price"`
+	Reduction float64 `json:"reduction"` // Discount removed from the item's line
+}
+
+// SkippedRule records a configured discount rule that was not applied and why.
+// Used to debug promotions that do not trigger as expected.
+//
+// Example:
+//   skipped := SkippedRule{
+//       Type: DiscountTypeBulk,
+//       RuleID: "bulk_discount",
+//       Reason: SkipReasonBelowMinQuantity,
+//       Details: "quantity 3 is below minimum 5",
+//   }
+type SkippedRule struct {
+	Type    DiscountType `json:"type"`
+	RuleID  string       `json:"rule_id"`
+	Reason  SkipReason   `json:"reason"`
+	Details string       `json:"details,omitempty"`
 }
 
 // BundleMatch represents a matched bundle configuration.
@@ -495,4 +807,144 @@ type MixAndMatchRule struct {
 	DiscountType    string   `json:"discount_type"` // "flat_discount", "percentage"
 	DiscountValue   float64  `json:"discount_value"`
 	MaxApplications int      `json:"max_applications,omitempty"`
-}
\ No newline at end of file
+}
+
+// MixForFixedPriceRule represents a multi-buy fixed price promotion such as "any 3 for $10".
+// Eligible units are grouped into sets of RequiredQuantity and each complete set
+// is charged GroupPrice; leftover units stay at their normal price.
+//
+// Features:
+//   - Category and product-specific eligibility (empty lists make every item eligible)
+//   - Fixed price per complete set
+//   - Configurable unit selection for the sets
+//   - Sets that would cost more than their normal price are left undiscounted
+//
+// Selection:
+//   - "most_expensive": Fill sets with the most expensive units first (default, best for the customer)
+//   - "least_expensive": Fill sets with the cheapest units first
+//
+// Example:
+//   rule := MixForFixedPriceRule{
+//       ID: "any-3-for-10",
+//       Name: "Any 3 for $10",
+//       EligibleCategories: []string{"snacks"},
+//       RequiredQuantity: 3,
+//       GroupPrice: 10.0,
+//   }
+type MixForFixedPriceRule struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	EligibleCategories []string `json:"eligible_categories,omitempty"`
+	EligibleProducts   []string `json:"eligible_products,omitempty"`
+	RequiredQuantity   int      `json:"required_quantity"`
+	GroupPrice         float64  `json:"group_price"`
+	Selection          string   `json:"selection,omitempty"` // "most_expensive" (default), "least_expensive"
+}
+
+// BOGORule represents a buy-X-get-Y promotion such as "buy 2 get 1 free" or
+// "buy 1 get 1 50% off". Eligible units are counted in sets of
+// BuyQuantity + GetQuantity, and GetQuantity units per complete set are
+// discounted by DiscountPercent.
+//
+// Features:
+//   - Free (100%) or partially discounted "get" units
+//   - Category and product-specific eligibility (empty lists make every item eligible)
+//   - Optional cap on the number of sets per order
+//   - Configurable choice of which units are discounted
+//   - Discounted units reported individually for invoices
+//
+// Selection:
+//   - "least_expensive": Discount the cheapest eligible units (default)
+//   - "most_expensive": Discount the most expensive eligible units
+//
+// Example:
+//   rule := BOGORule{
+//       ID: "b2g1-socks",
+//       Name: "Buy 2 socks, get 1 free",
+//       EligibleCategories: []string{"socks"},
+//       BuyQuantity: 2,
+//       GetQuantity: 1,
+//       DiscountPercent: 100,
+//       MaxApplications: 3,
+//   }
+type BOGORule struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	EligibleCategories []string `json:"eligible_categories,omitempty"`
+	EligibleProducts   []string `json:"eligible_products,omitempty"`
+	BuyQuantity        int      `json:"buy_quantity"`
+	GetQuantity        int      `json:"get_quantity"`
+	DiscountPercent    float64  `json:"discount_percent"`            // Percent off each "get" unit; 100 makes it free
+	MaxApplications    int      `json:"max_applications,omitempty"` // Sets per order; 0 means no limit
+	Selection          string   `json:"selection,omitempty"`        // "least_expensive" (default), "most_expensive"
+}
+
+// DiscountedUnit identifies a single unit of a line item that a discount was
+// applied to. Unit numbers run from 1 to the item's Quantity, so an invoice can
+// show "2nd pair free" against the exact unit.
+//
+// Example:
+//   unit := DiscountedUnit{ItemID: "socks-blue", Unit: 3, Price: 8.0, DiscountAmount: 8.0}
+type DiscountedUnit struct {
+	ItemID         string  `json:"item_id"`
+	Unit           int     `json:"unit"`
+	Price          float64 `json:"price"`
+	DiscountAmount float64 `json:"discount_amount"`
+}
+
+// DiscountStage represents the amount taken off by one stage of a promotion stack.
+// Used as input to EffectiveDiscountRate for reporting.
+//
+// Example:
+//   stage := DiscountStage{
+//       Name: "coupon",
+//       Amount: 20.0,
+//   }
+type DiscountStage struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// StageAttribution represents a stage's share of the overall effective discount.
+// Percent is expressed against the original amount, so the stage percents sum to
+// EffectiveDiscount.EffectivePercent.
+//
+// Example:
+//   attribution := StageAttribution{
+//       Name: "coupon",
+//       Amount: 20.0,
+//       Percent: 10.0,
+//   }
+type StageAttribution struct {
+	Name    string  `json:"name"`
+	Amount  float64 `json:"amount"`
+	Percent float64 `json:"percent"`
+}
+
+// EffectiveDiscount represents the combined effect of a promotion stack.
+// Summarizes how much of the original amount was discounted overall and
+// how that discount is attributed to each stage.
+//
+// Features:
+//   - Single effective discount percentage for reporting
+//   - Per-stage attribution reconciling to the total
+//   - Unattributed remainder when stage deltas do not cover the full discount
+//
+// Example:
+//   effective := EffectiveDiscount{
+//       OriginalAmount: 200.0,
+//       FinalAmount: 150.0,
+//       TotalDiscount: 50.0,
+//       EffectivePercent: 25.0,
+//       Stages: []StageAttribution{
+//           {Name: "bulk", Amount: 30.0, Percent: 15.0},
+//           {Name: "coupon", Amount: 20.0, Percent: 10.0},
+//       },
+//   }
+type EffectiveDiscount struct {
+	OriginalAmount   float64            `json:"original_amount"`
+	FinalAmount      float64            `json:"final_amount"`
+	TotalDiscount    float64            `json:"total_discount"`
+	EffectivePercent float64            `json:"effective_percent"`
+	Stages           []StageAttribution `json:"stages,omitempty"`
+}
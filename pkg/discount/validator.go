@@ -136,10 +136,9 @@ func (dv *DiscountValidator) ValidateDiscountApplication(discount DiscountApplic
 
 	// Validate single discount percentage limit
 	if itemsTotal > 0 {
-		discountPercent := (discount.DiscountAmount / itemsTotal) * 100
-		if discountPercent > dv.MaxSingleDiscountPercent {
+		if exceedsDiscountCap(discount.DiscountAmount, discountCapAmount(itemsTotal, dv.MaxSingleDiscountPercent)) {
 			return fmt.Errorf("single discount percentage (%.2f%%) exceeds maximum allowed (%.2f%%)",
-				discountPercent, dv.MaxSingleDiscountPercent)
+				discountPercentOf(discount.DiscountAmount, itemsTotal), dv.MaxSingleDiscountPercent)
 		}
 	}
 
@@ -188,10 +187,9 @@ func (dv *DiscountValidator) ValidateStackedDiscounts(discounts []DiscountApplic
 
 	// Validate stacked discount percentage limit
 	if originalAmount > 0 {
-		totalDiscountPercent := (totalDiscount / originalAmount) * 100
-		if totalDiscountPercent > dv.MaxStackedDiscountPercent {
+		if exceedsDiscountCap(totalDiscount, discountCapAmount(originalAmount, dv.MaxStackedDiscountPercent)) {
 			return fmt.Errorf("stacked discount percentage (%.2f%%) exceeds maximum allowed (%.2f%%)",
-				totalDiscountPercent, dv.MaxStackedDiscountPercent)
+				discountPercentOf(totalDiscount, originalAmount), dv.MaxStackedDiscountPercent)
 		}
 	}
 
@@ -187,6 +187,42 @@ func TestValidateStackedDiscounts(t *testing.T) {
 		}
 	})
 	
+	t.Run("ExactlyAtMaxStackedPercent", func(t *testing.T) {
+		discounts := []DiscountApplication{
+			{
+				Type: DiscountTypeBulk,
+				DiscountAmount: 0.1,
+			},
+			{
+				Type: DiscountTypeLoyalty,
+				DiscountAmount: 0.2, // Sums to 0.30000000000000004, a hair over 50% of 0.6
+			},
+		}
+		
+		err := validator.ValidateStackedDiscounts(discounts, 0.6)
+		if err != nil {
+			t.Errorf("Expected discount at exactly the cap to be valid, got: %v", err)
+		}
+	})
+	
+	t.Run("JustOverMaxStackedPercent", func(t *testing.T) {
+		discounts := []DiscountApplication{
+			{
+				Type: DiscountTypeBulk,
+				DiscountAmount: 30,
+			},
+			{
+				Type: DiscountTypeLoyalty,
+				DiscountAmount: 20.01, // 50.01%
+			},
+		}
+		
+		err := validator.ValidateStackedDiscounts(discounts, 100.0)
+		if err == nil {
+			t.Error("Expected error for discount just over the cap")
+		}
+	})
+	
 	t.Run("InvalidCombination", func(t *testing.T) {
 		discounts := []DiscountApplication{
 			{
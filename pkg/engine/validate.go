@@ -0,0 +1,236 @@
+// Package engine ties the engine's packages together for checks that span them.
+//
+// This package includes:
+//   - EngineConfig bundling the pricing, tax, shipping, coupon and loyalty configuration
+//   - ValidateAll, a preflight that reports every configuration problem at once
+//
+// Basic usage:
+//
+//	issues := engine.ValidateAll(engine.EngineConfig{
+//		PricingRules:  pricingRules,
+//		TaxRules:      taxRules,
+//		ShippingRules: shippingRules,
+//		Coupons:       coupons,
+//		Loyalty:       &loyaltyConfig,
+//	})
+//	for _, issue := range issues {
+//		log.Printf("%s %s: %s", issue.Severity, issue.Path, issue.Message)
+//	}
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/coupon"
+	"github.com/masumrpg/ecommerce-engine/pkg/loyalty"
+	"github.com/masumrpg/ecommerce-engine/pkg/pricing"
+	"github.com/masumrpg/ecommerce-engine/pkg/shipping"
+	"github.com/masumrpg/ecommerce-engine/pkg/tax"
+)
+
+// Severity classifies a validation issue.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The configuration is wrong and must be fixed
+	SeverityWarning Severity = "warning" // The configuration works but is likely a mistake
+)
+
+// EngineConfig bundles the configuration of every package for validation.
+// Empty sections are skipped.
+//
+// Example:
+//
+//	cfg := engine.EngineConfig{
+//		TaxConfiguration: tax.TaxConfiguration{DefaultCurrency: "USD"},
+//		TaxRules:         []tax.TaxRule{salesTax},
+//		Coupons:          []coupon.Coupon{welcome10},
+//	}
+type EngineConfig struct {
+	PricingRules     []pricing.PricingRule         `json:"pricing_rules,omitempty"`
+	TaxConfiguration tax.TaxConfiguration          `json:"tax_configuration"`
+	TaxRules         []tax.TaxRule                 `json:"tax_rules,omitempty"`
+	ShippingRules    []shipping.ShippingRule       `json:"shipping_rules,omitempty"`
+	Coupons          []coupon.Coupon               `json:"coupons,omitempty"`
+	Loyalty          *loyalty.LoyaltyConfiguration `json:"loyalty,omitempty"`
+}
+
+// ValidationIssue describes one configuration problem. Path identifies the
+// offending element using the EngineConfig JSON field names, such as
+// "tax_rules[2]" or "loyalty.default_rules[0]".
+//
+// Example:
+//
+//	issue := ValidationIssue{
+//		Severity: SeverityError,
+//		Path:     "coupons[1]",
+//		Message:  "percentage value must be between 0 and 100",
+//	}
+type ValidationIssue struct {
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+// ValidateAll runs every package's validators over the configuration and
+// reports all problems found instead of stopping at the first one.
+//
+// Errors come from pricing.ValidateRule, tax.TaxRuleEngine.ValidateRule (with
+// the configuration's custom validation rules), shipping.ValidateShippingRule,
+// coupon.ValidateCouponConfig, loyalty.ValidateConfiguration and
+// loyalty.RuleEngine.ValidateRule, plus duplicate IDs or codes within a section.
+// Warnings flag elements that validate but will never apply, such as active
+// rules or coupons that have already expired, and the shipping engine's
+// configuration warnings.
+//
+// Parameters:
+//   - cfg: EngineConfig to validate
+//
+// Returns:
+//   - []ValidationIssue: Issues in section order, empty if the configuration is clean
+//
+// Example:
+//
+//	issues := engine.ValidateAll(cfg)
+//	for _, issue := range issues {
+//		if issue.Severity == engine.SeverityError {
+//			return fmt.Errorf("%s: %s", issue.Path, issue.Message)
+//		}
+//	}
+func ValidateAll(cfg EngineConfig) []ValidationIssue {
+	issues := []ValidationIssue{}
+	now := time.Now()
+
+	issues = append(issues, validatePricingRules(cfg.PricingRules, now)...)
+	issues = append(issues, validateTaxRules(cfg.TaxConfiguration, cfg.TaxRules, now)...)
+	issues = append(issues, validateShippingRules(cfg.ShippingRules, now)...)
+	issues = append(issues, validateCoupons(cfg.Coupons, now)...)
+	if cfg.Loyalty != nil {
+		issues = append(issues, validateLoyalty(*cfg.Loyalty)...)
+	}
+
+	return issues
+}
+
+func validatePricingRules(rules []pricing.PricingRule, now time.Time) []ValidationIssue {
+	issues := []ValidationIssue{}
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		path := fmt.Sprintf("pricing_rules[%d]", i)
+		if err := pricing.ValidateRule(rule); err != nil {
+			issues = append(issues, newError(path, err))
+		}
+		issues = append(issues, checkDuplicate(seen, path, "rule ID", rule.ID)...)
+
+		// The calculator skips rules outside their validity window
+		if rule.IsActive && rule.ValidUntil.IsZero() {
+			issues = append(issues, newWarning(path, "rule has no valid until date and is never applied"))
+		} else if rule.IsActive && now.After(rule.ValidUntil) {
+			issues = append(issues, newWarning(path, "active rule has expired"))
+		}
+	}
+	return issues
+}
+
+func validateTaxRules(config tax.TaxConfiguration, rules []tax.TaxRule, now time.Time) []ValidationIssue {
+	issues := []ValidationIssue{}
+	ruleEngine := tax.NewTaxRuleEngine(config)
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		path := fmt.Sprintf("tax_rules[%d]", i)
+		if err := ruleEngine.ValidateRule(rule); err != nil {
+			issues = append(issues, newError(path, err))
+		}
+		issues = append(issues, checkDuplicate(seen, path, "rule ID", rule.ID)...)
+
+		if rule.IsActive && !rule.ValidUntil.IsZero() && now.After(rule.ValidUntil) {
+			issues = append(issues, newWarning(path, "active rule has expired"))
+		}
+	}
+	return issues
+}
+
+func validateShippingRules(rules []shipping.ShippingRule, now time.Time) []ValidationIssue {
+	issues := []ValidationIssue{}
+	if len(rules) == 0 {
+		return issues
+	}
+
+	seen := make(map[string]bool)
+	for i, rule := range rules {
+		path := fmt.Sprintf("shipping_rules[%d]", i)
+		if err := shipping.ValidateShippingRule(rule); err != nil {
+			issues = append(issues, newError(path, err))
+		}
+		issues = append(issues, checkDuplicate(seen, path, "rule ID", rule.ID)...)
+	}
+
+	// The shipping engine also reports expired rules
+	ruleEngine := shipping.NewShippingRuleEngine()
+	ruleEngine.ShippingRules = rules
+	for _, warning := range ruleEngine.ValidateRuleConfiguration() {
+		issues = append(issues, newWarning("shipping_rules", warning))
+	}
+	return issues
+}
+
+func validateCoupons(coupons []coupon.Coupon, now time.Time) []ValidationIssue {
+	issues := []ValidationIssue{}
+	seen := make(map[string]bool)
+	for i, c := range coupons {
+		path := fmt.Sprintf("coupons[%d]", i)
+		if err := coupon.ValidateCouponConfig(c); err != nil {
+			issues = append(issues, newError(path, err))
+		}
+		issues = append(issues, checkDuplicate(seen, path, "coupon code", c.Code)...)
+
+		if c.IsActive && now.After(c.ValidUntil) {
+			issues = append(issues, newWarning(path, "active coupon has expired"))
+		}
+	}
+	return issues
+}
+
+func validateLoyalty(config loyalty.LoyaltyConfiguration) []ValidationIssue {
+	issues := []ValidationIssue{}
+	for _, err := range loyalty.ValidateConfiguration(config) {
+		issues = append(issues, newError("loyalty", err))
+	}
+
+	ruleEngine := loyalty.NewRuleEngine(&config)
+	seen := make(map[string]bool)
+	for i, rule := range config.DefaultRules {
+		path := fmt.Sprintf("loyalty.default_rules[%d]", i)
+		if err := ruleEngine.ValidateRule(rule); err != nil {
+			issues = append(issues, newError(path, err))
+		}
+		issues = append(issues, checkDuplicate(seen, path, "rule ID", rule.ID)...)
+	}
+	return issues
+}
+
+// checkDuplicate reports an ID already seen in the same section. Empty IDs are
+// left to the package validators.
+func checkDuplicate(seen map[string]bool, path, label, id string) []ValidationIssue {
+	if id == "" {
+		return nil
+	}
+	if seen[id] {
+		return []ValidationIssue{newIssue(SeverityError, path, fmt.Sprintf("duplicate %s %s", label, id))}
+	}
+	seen[id] = true
+	return nil
+}
+
+func newError(path string, err error) ValidationIssue {
+	return newIssue(SeverityError, path, err.Error())
+}
+
+func newWarning(path, message string) ValidationIssue {
+	return newIssue(SeverityWarning, path, message)
+}
+
+func newIssue(severity Severity, path, message string) ValidationIssue {
+	return ValidationIssue{Severity: severity, Path: path, Message: message}
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/coupon"
+	"github.com/masumrpg/ecommerce-engine/pkg/loyalty"
+	"github.com/masumrpg/ecommerce-engine/pkg/pricing"
+	"github.com/masumrpg/ecommerce-engine/pkg/shipping"
+	"github.com/masumrpg/ecommerce-engine/pkg/tax"
+)
+
+func createValidConfig() EngineConfig {
+	from := time.Now().Add(-24 * time.Hour)
+	until := time.Now().Add(24 * time.Hour)
+
+	return EngineConfig{
+		PricingRules: []pricing.PricingRule{
+			{
+				ID: "promo", Name: "Promo", IsActive: true, ValidFrom: from, ValidUntil: until,
+				Adjustments: []pricing.PriceAdjustment{{Type: "percentage", Value: 10}},
+			},
+		},
+		TaxRules: []tax.TaxRule{
+			{ID: "ny", Name: "NY Sales Tax", Method: tax.TaxMethodPercentage, Rate: 8.0, IsActive: true, ValidFrom: from, ValidUntil: until},
+		},
+		ShippingRules: []shipping.ShippingRule{
+			{ID: "standard", Name: "Standard", Method: shipping.ShippingMethodStandard, BaseCost: 5.0, IsActive: true, ValidFrom: from, ValidUntil: until},
+		},
+		Coupons: []coupon.Coupon{
+			{Code: "SAVE10", Type: coupon.CouponTypePercentage, Value: 10, IsActive: true, ValidFrom: from, ValidUntil: until},
+		},
+		Loyalty: &loyalty.LoyaltyConfiguration{
+			BasePointsRate:       1.0,
+			RedemptionRate:       0.01,
+			MaxRedemptionPercent: 50,
+			DefaultRules: []loyalty.LoyaltyRule{
+				{ID: "base", Name: "Base Points", Type: "earning", Actions: []loyalty.LoyaltyAction{{Type: "add_points", Value: 1}}},
+			},
+		},
+	}
+}
+
+func TestValidateAllValidConfig(t *testing.T) {
+	if issues := ValidateAll(createValidConfig()); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %+v", issues)
+	}
+}
+
+func TestValidateAllReportsEveryProblem(t *testing.T) {
+	cfg := createValidConfig()
+	cfg.PricingRules[0].Name = ""
+	cfg.TaxRules[0].Rate = -1
+	cfg.ShippingRules[0].BaseCost = -5
+	cfg.Coupons = append(cfg.Coupons,
+		coupon.Coupon{Code: "HUGE", Type: coupon.CouponTypePercentage, Value: 150, IsActive: true, ValidUntil: time.Now().Add(time.Hour)},
+		coupon.Coupon{Code: "SAVE10", Type: coupon.CouponTypeFixedAmount, Value: 5, IsActive: true, ValidUntil: time.Now().Add(-time.Hour)},
+	)
+	cfg.Loyalty.MaxRedemptionPercent = 150
+	cfg.Loyalty.DefaultRules[0].Actions = nil
+
+	issues := ValidateAll(cfg)
+
+	expected := []ValidationIssue{
+		{Severity: SeverityError, Path: "pricing_rules[0]", Message: "rule name is required"},
+		{Severity: SeverityError, Path: "tax_rules[0]", Message: "tax rate cannot be negative"},
+		{Severity: SeverityError, Path: "shipping_rules[0]", Message: "base cost cannot be negative"},
+		{Severity: SeverityError, Path: "coupons[1]", Message: "percentage value must be between 0 and 100"},
+		{Severity: SeverityError, Path: "coupons[2]", Message: "duplicate coupon code SAVE10"},
+		{Severity: SeverityWarning, Path: "coupons[2]", Message: "active coupon has expired"},
+		{Severity: SeverityError, Path: "loyalty", Message: "maximum redemption percent must be between 0 and 100"},
+		{Severity: SeverityError, Path: "loyalty.default_rules[0]", Message: "rule must have at least one action"},
+	}
+	for _, want := range expected {
+		found := false
+		for _, issue := range issues {
+			if issue == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected issue %+v, got %+v", want, issues)
+		}
+	}
+	if len(issues) != len(expected) {
+		t.Errorf("Expected %d issues, got %d: %+v", len(expected), len(issues), issues)
+	}
+}
+
+func TestValidateAllPricingRuleWithoutWindow(t *testing.T) {
+	cfg := EngineConfig{
+		PricingRules: []pricing.PricingRule{
+			{ID: "promo", Name: "Promo", IsActive: true, Adjustments: []pricing.PriceAdjustment{{Type: "fixed", Value: 5}}},
+		},
+	}
+
+	issues := ValidateAll(cfg)
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning || issues[0].Path != "pricing_rules[0]" {
+		t.Errorf("Expected one warning for the rule without a validity window, got %+v", issues)
+	}
+}
@@ -44,6 +44,7 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -78,6 +79,9 @@ type Calculator struct {
 	config *LoyaltyConfiguration
 	rules  []LoyaltyRule
 	tierBenefits map[LoyaltyTier]TierBenefit
+
+	actionMu     sync.Mutex
+	actionAwards map[string]int // award counts keyed by customer, action, and scope
 }
 
 // NewCalculator creates a new loyalty calculator with the provided configuration.
@@ -239,6 +243,12 @@ func (c *Calculator) Calculate(input PointsCalculationInput) (*PointsCalculation
 //   - Generation of redemption code and transaction
 //   - Point balance updates
 //
+// Discount and cashback rewards without a fixed Value are worth their points at
+// the program's RedemptionRate, so their value follows PointsValue. The redeemed
+// value is capped to input.OrderAmount when one is given; the customer is then
+// charged only the points needed to cover the capped value, rounded up to a
+// whole point, so PointsRedeemed, NewBalance and the transaction agree.
+//
 // Parameters:
 //   - input: RedemptionInput containing customer and redemption details
 //   - reward: Reward being redeemed with cost and value information
@@ -291,10 +301,24 @@ func (c *Calculator) RedeemPoints(input RedemptionInput, reward Reward) (*Redemp
 	}
 
 	// Apply tier redemption bonus
-	tierBenefit := c.getTierBenefit(input.Customer.Tier)
-	discountAmount := reward.Value * float64(quantity)
-	if tierBenefit.RedemptionBonus > 0 {
-		discountAmount *= (1.0 + tierBenefit.RedemptionBonus)
+	var discountAmount float64
+	if reward.Value == 0 && (reward.Type == RewardTypeDiscount || reward.Type == RewardTypeCashback) {
+		discountAmount = c.PointsValue(totalPointsCost, input.Customer.Tier)
+	} else {
+		discountAmount = c.applyRedemptionBonus(reward.Value*float64(quantity), input.Customer.Tier)
+	}
+
+	// Points can never pay for more than the order, and only the points that
+	// cover the capped value are spent
+	var warnings []string
+	if input.OrderAmount > 0 && discountAmount > input.OrderAmount {
+		pointValue := discountAmount / float64(totalPointsCost)
+		neededPoints := int(math.Ceil(input.OrderAmount/pointValue - 1e-9))
+		if neededPoints < totalPointsCost {
+			totalPointsCost = neededPoints
+		}
+		discountAmount = input.OrderAmount
+		warnings = append(warnings, fmt.Sprintf("Redeemed value capped to order total; %d points used", totalPointsCost))
 	}
 
 	// Create redemption transaction
@@ -324,11 +348,44 @@ func (c *Calculator) RedeemPoints(input RedemptionInput, reward Reward) (*Redemp
 		ValidUntil:     reward.ValidUntil,
 		Transaction:    transaction,
 		IsSuccessful:   true,
+		Warnings:       warnings,
 	}
 
 	return result, nil
 }
 
+// PointsValue converts points to their currency value for a customer tier.
+// Points are worth the program's RedemptionRate, increased by the tier's
+// RedemptionBonus, so a Gold bonus of 0.2 makes each point worth 20% more.
+//
+// Parameters:
+//   - points: Number of points being valued
+//   - tier: Customer's loyalty tier
+//
+// Returns:
+//   - float64: Currency value of the points, zero for non-positive points
+//
+// Example:
+//
+//	value := calculator.PointsValue(500, TierGold)
+//	// With RedemptionRate 0.01 and RedemptionBonus 0.2: value = 6.0
+func (c *Calculator) PointsValue(points int, tier LoyaltyTier) float64 {
+	if points <= 0 || c.config.RedemptionRate <= 0 {
+		return 0
+	}
+	return c.applyRedemptionBonus(float64(points)*c.config.RedemptionRate, tier)
+}
+
+// applyRedemptionBonus increases a redemption value by the tier's
+// RedemptionBonus. Negative bonuses are ignored.
+func (c *Calculator) applyRedemptionBonus(value float64, tier LoyaltyTier) float64 {
+	tierBenefit := c.getTierBenefit(tier)
+	if tierBenefit.RedemptionBonus > 0 {
+		value *= (1.0 + tierBenefit.RedemptionBonus)
+	}
+	return value
+}
+
 // CalculateReferralReward calculates points awarded for successful referrals.
 // It validates the referral program conditions and calculates rewards for the referrer
 // when a referee makes a qualifying purchase.
@@ -561,6 +618,108 @@ func (c *Calculator) CalculateReviewReward(customer Customer, reward ReviewRewar
 	return result, nil
 }
 
+// EarnForAction awards points for a non-purchase action such as writing a review,
+// referring a friend, or completing a profile.
+// Point values come from LoyaltyConfiguration.ActionPoints and caps from
+// LoyaltyConfiguration.ActionLimits. Once a cap is reached, further calls for the
+// same customer and scope return a valid result with zero points and a warning.
+//
+// The calculator remembers awards in memory so caps hold across calls.
+//
+// Parameters:
+//   - customer: Customer performing the action
+//   - action: Action name, e.g. "review", "referral", "profile_completion"
+//   - metadata: Action details; the value under the limit's ScopeKey scopes the cap
+//
+// Returns:
+//   - *PointsCalculationResult: Result with the awarded points and transaction
+//   - error: Error if the customer ID is missing or the action is not configured
+//
+// Example:
+//
+//	config.ActionPoints = map[string]int{"review": 50}
+//	config.ActionLimits = map[string]ActionLimit{"review": {MaxAwards: 1, ScopeKey: "product_id"}}
+//
+//	result, err := calculator.EarnForAction(customer, "review", map[string]interface{}{
+//		"product_id": "prod_123",
+//	})
+//	// result.TotalPoints == 50; a second review of prod_123 earns 0
+func (c *Calculator) EarnForAction(customer Customer, action string, metadata map[string]interface{}) (*PointsCalculationResult, error) {
+	if customer.ID == "" {
+		return nil, fmt.Errorf("customer ID is required")
+	}
+
+	points, exists := c.config.ActionPoints[action]
+	if !exists {
+		return nil, fmt.Errorf("action %s is not configured", action)
+	}
+
+	result := &PointsCalculationResult{
+		CustomerID: customer.ID,
+		NewBalance: customer.CurrentPoints,
+		IsValid:    true,
+	}
+
+	// Enforce the per-action cap
+	limit := c.config.ActionLimits[action]
+	awardKey := customer.ID + "|" + action
+	if limit.ScopeKey != "" {
+		awardKey += "|" + fmt.Sprintf("%v", metadata[limit.ScopeKey])
+	}
+
+	c.actionMu.Lock()
+	if c.actionAwards == nil {
+		c.actionAwards = make(map[string]int)
+	}
+	if limit.MaxAwards > 0 && c.actionAwards[awardKey] >= limit.MaxAwards {
+		c.actionMu.Unlock()
+		result.Warnings = append(result.Warnings, fmt.Sprintf("points limit reached for action %s", action))
+		return result, nil
+	}
+	c.actionAwards[awardKey]++
+	c.actionMu.Unlock()
+
+	pointsType := PointsTypeBonus
+	switch action {
+	case "review":
+		pointsType = PointsTypeReview
+	case "referral":
+		pointsType = PointsTypeReferral
+	}
+
+	result.BasePoints = points
+	result.TotalPoints = points
+	result.NewBalance = customer.CurrentPoints + points
+	result.PointsBreakdown = []PointsBreakdown{
+		{
+			Source:      action,
+			Description: fmt.Sprintf("Points for %s", action),
+			Amount:      0,
+			Rate:        0,
+			Multiplier:  1.0,
+			Points:      points,
+			PointsType:  pointsType,
+		},
+	}
+
+	result.Transactions = []PointsTransaction{
+		{
+			ID:          c.generateTransactionID(),
+			CustomerID:  customer.ID,
+			Type:        TransactionTypeEarn,
+			PointsType:  pointsType,
+			Amount:      points,
+			Balance:     result.NewBalance,
+			Description: fmt.Sprintf("Points for %s", action),
+			Timestamp:   time.Now(),
+			Source:      action,
+			Metadata:    metadata,
+		},
+	}
+
+	return result, nil
+}
+
 // GetAvailableRewards filters and returns rewards that a customer can redeem.
 // It checks point balance, tier requirements, availability dates, and stock levels
 // to determine which rewards are currently accessible to the customer.
@@ -755,11 +914,7 @@ func (c *Calculator) evaluateCondition(condition LoyaltyCondition, input PointsC
 	case "amount":
 		return c.compareValues(input.OrderAmount, condition.Operator, condition.Value)
 	case "quantity":
-		totalQuantity := 0
-		for _, item := range input.Items {
-			totalQuantity += item.Quantity
-		}
-		return c.compareValues(float64(totalQuantity), condition.Operator, condition.Value)
+		return c.compareValues(float64(totalItemQuantity(input.Items)), condition.Operator, condition.Value)
 	case "category":
 		for _, item := range input.Items {
 			if c.compareStringValues(item.Category, condition.Operator, condition.Value) {
@@ -876,7 +1031,8 @@ func (c *Calculator) toFloat64(value interface{}) (float64, error) {
 
 // applyRule applies a loyalty rule and returns bonus points.
 // It processes rule actions including earning points, multiplying points,
-// and applying bonus rates, creating detailed breakdown information.
+// applying bonus rates, and awarding flat points per item, creating detailed
+// breakdown information.
 //
 // Parameters:
 //   - rule: LoyaltyRule to apply
@@ -947,12 +1103,44 @@ func (c *Calculator) applyRule(rule LoyaltyRule, input PointsCalculationInput, b
 					RuleID:      rule.ID,
 				})
 			}
+		case "points_per_item":
+			if perItem, err := c.toFloat64(action.Value); err == nil {
+				quantity := totalItemQuantity(input.Items)
+				additionalPoints := int(math.Round(perItem * float64(quantity)))
+				bonusPoints += additionalPoints
+				appliedRule.PointsAwarded += additionalPoints
+				breakdown = append(breakdown, PointsBreakdown{
+					Source:      "rule_per_item",
+					Description: action.Description,
+					Amount:      input.OrderAmount,
+					Rate:        perItem,
+					Multiplier:  1.0,
+					Points:      additionalPoints,
+					PointsType:  action.PointsType,
+					RuleID:      rule.ID,
+				})
+			}
 		}
 	}
 
 	return bonusPoints, breakdown, appliedRule
 }
 
+// totalItemQuantity returns the number of units across all order items.
+//
+// Parameters:
+//   - items: Order items to count
+//
+// Returns:
+//   - int: Sum of item quantities
+func totalItemQuantity(items []OrderItem) int {
+	total := 0
+	for _, item := range items {
+		total += item.Quantity
+	}
+	return total
+}
+
 // calculateExpiryDate calculates points expiry date based on tier.
 // Uses tier-specific expiry period if available, otherwise falls back to default.
 //
@@ -1,6 +1,7 @@
 package loyalty
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -209,6 +210,46 @@ func TestCalculate(t *testing.T) {
 	})
 }
 
+func TestCalculatePointsPerItem(t *testing.T) {
+	config := getTestConfig()
+	config.DefaultRules = []LoyaltyRule{
+		{
+			ID:       "per-item",
+			Name:     "10 Points Per Item",
+			Type:     "earning",
+			IsActive: true,
+			Actions: []LoyaltyAction{
+				{Type: "points_per_item", Value: 10, PointsType: PointsTypeBonus},
+			},
+		},
+	}
+	calc := NewCalculator(config)
+	
+	result, err := calc.Calculate(PointsCalculationInput{
+		Customer:    Customer{ID: "customer1", Tier: TierBronze},
+		OrderAmount: 5.0,
+		Items: []OrderItem{
+			{ID: "sticker", Price: 1.0, Quantity: 2, TotalAmount: 2.0},
+			{ID: "pen", Price: 3.0, Quantity: 1, TotalAmount: 3.0},
+		},
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	
+	// 3 items at 10 points each, on top of 5 base points for $5
+	if result.BonusPoints != 30 {
+		t.Errorf("Expected 30 per-item bonus points, got %d", result.BonusPoints)
+	}
+	if result.TotalPoints != 35 {
+		t.Errorf("Expected 35 total points, got %d", result.TotalPoints)
+	}
+	if len(result.AppliedRules) != 1 || result.AppliedRules[0].PointsAwarded != 30 {
+		t.Errorf("Expected per-item rule to award 30 points, got %+v", result.AppliedRules)
+	}
+}
+
 func TestRedeemPoints(t *testing.T) {
 	config := getTestConfig()
 	calc := NewCalculator(config)
@@ -370,6 +411,61 @@ func TestRedeemPoints(t *testing.T) {
 	})
 }
 
+func TestRedeemPointsTierValue(t *testing.T) {
+	calc := NewCalculator(getTestConfig())
+	reward := Reward{
+		ID:         "points-discount",
+		Name:       "Points Discount",
+		Type:       RewardTypeDiscount,
+		PointsCost: 500,
+		IsActive:   true,
+	}
+	redeem := func(tier LoyaltyTier, orderAmount float64) *RedemptionResult {
+		result, err := calc.RedeemPoints(RedemptionInput{
+			Customer:    Customer{ID: "customer", Tier: tier, CurrentPoints: 1000},
+			RewardID:    reward.ID,
+			Quantity:    1,
+			OrderAmount: orderAmount,
+			Timestamp:   time.Now(),
+		}, reward)
+		if err != nil {
+			t.Fatalf("RedeemPoints failed: %v", err)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		tier     LoyaltyTier
+		order    float64
+		expected float64
+		points   int
+	}{
+		{"Silver", TierSilver, 0, 5.5, 500},
+		{"Gold", TierGold, 0, 6.0, 500},
+		// Gold points are worth 0.012 each, so 4.00 needs 334 points
+		{"GoldCappedToOrder", TierGold, 4.0, 4.0, 334},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := redeem(tt.tier, tt.order)
+			if math.Abs(result.DiscountAmount-tt.expected) > 1e-9 {
+				t.Errorf("Expected redeemed value %.2f, got %.2f", tt.expected, result.DiscountAmount)
+			}
+			if result.PointsRedeemed != tt.points {
+				t.Errorf("Expected %d points redeemed, got %d", tt.points, result.PointsRedeemed)
+			}
+			if result.NewBalance != 1000-tt.points || result.Transaction.Amount != -tt.points || result.Transaction.Balance != result.NewBalance {
+				t.Errorf("Expected balance %d and transaction amount %d, got %d and %d", 1000-tt.points, -tt.points, result.NewBalance, result.Transaction.Amount)
+			}
+		})
+	}
+
+	if silver, gold := calc.PointsValue(500, TierSilver), calc.PointsValue(500, TierGold); gold <= silver {
+		t.Errorf("Expected Gold points to be worth more than Silver, got %.2f vs %.2f", gold, silver)
+	}
+}
+
 func TestCalculateReferralReward(t *testing.T) {
 	config := getTestConfig()
 	calc := NewCalculator(config)
@@ -572,6 +668,105 @@ func TestCalculateReviewReward(t *testing.T) {
 	})
 }
 
+func TestEarnForAction(t *testing.T) {
+	config := getTestConfig()
+	config.ActionPoints = map[string]int{
+		"review":             50,
+		"profile_completion": 100,
+		"referral":           200,
+	}
+	config.ActionLimits = map[string]ActionLimit{
+		"review":             {MaxAwards: 1, ScopeKey: "product_id"},
+		"profile_completion": {MaxAwards: 1},
+	}
+	calc := NewCalculator(config)
+	
+	customer := Customer{
+		ID:            "customer1",
+		CurrentPoints: 100,
+	}
+	
+	t.Run("ReviewAction", func(t *testing.T) {
+		result, err := calc.EarnForAction(customer, "review", map[string]interface{}{"product_id": "prod1"})
+		if err != nil {
+			t.Fatalf("EarnForAction failed: %v", err)
+		}
+		
+		if result.TotalPoints != 50 {
+			t.Errorf("Expected 50 points, got %d", result.TotalPoints)
+		}
+		
+		if result.NewBalance != 150 {
+			t.Errorf("Expected new balance 150, got %d", result.NewBalance)
+		}
+		
+		if len(result.Transactions) != 1 || result.Transactions[0].PointsType != PointsTypeReview {
+			t.Errorf("Expected one review transaction, got %+v", result.Transactions)
+		}
+	})
+	
+	t.Run("CappedRepeatAction", func(t *testing.T) {
+		result, err := calc.EarnForAction(customer, "review", map[string]interface{}{"product_id": "prod1"})
+		if err != nil {
+			t.Fatalf("EarnForAction failed: %v", err)
+		}
+		
+		if result.TotalPoints != 0 {
+			t.Errorf("Expected 0 points for repeat review, got %d", result.TotalPoints)
+		}
+		
+		if len(result.Transactions) != 0 {
+			t.Error("Expected no transaction for capped action")
+		}
+		
+		if len(result.Warnings) == 0 {
+			t.Error("Expected warning for capped action")
+		}
+		
+		// A review of another product is still rewarded
+		other, err := calc.EarnForAction(customer, "review", map[string]interface{}{"product_id": "prod2"})
+		if err != nil {
+			t.Fatalf("EarnForAction failed: %v", err)
+		}
+		if other.TotalPoints != 50 {
+			t.Errorf("Expected 50 points for another product, got %d", other.TotalPoints)
+		}
+	})
+	
+	t.Run("UnscopedCap", func(t *testing.T) {
+		first, _ := calc.EarnForAction(customer, "profile_completion", nil)
+		second, _ := calc.EarnForAction(customer, "profile_completion", nil)
+		
+		if first.TotalPoints != 100 || second.TotalPoints != 0 {
+			t.Errorf("Expected 100 then 0 points, got %d then %d", first.TotalPoints, second.TotalPoints)
+		}
+	})
+	
+	t.Run("UncappedAction", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			result, err := calc.EarnForAction(customer, "referral", nil)
+			if err != nil {
+				t.Fatalf("EarnForAction failed: %v", err)
+			}
+			if result.TotalPoints != 200 {
+				t.Errorf("Expected 200 points, got %d", result.TotalPoints)
+			}
+		}
+	})
+	
+	t.Run("UnknownAction", func(t *testing.T) {
+		if _, err := calc.EarnForAction(customer, "unknown", nil); err == nil {
+			t.Error("Expected error for unconfigured action")
+		}
+	})
+	
+	t.Run("MissingCustomerID", func(t *testing.T) {
+		if _, err := calc.EarnForAction(Customer{}, "review", nil); err == nil {
+			t.Error("Expected error for missing customer ID")
+		}
+	})
+}
+
 func TestGetAvailableRewards(t *testing.T) {
 	config := getTestConfig()
 	calc := NewCalculator(config)
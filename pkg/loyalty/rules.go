@@ -880,6 +880,86 @@ func (re *RuleEngine) GetStatistics() map[string]interface{} {
 	}
 }
 
+// ValidateRule validates a loyalty rule without adding it to the engine.
+// It runs the same checks as AddRule.
+//
+// Parameters:
+//   - rule: LoyaltyRule to validate
+//
+// Returns:
+//   - error: Validation error if rule is invalid, nil if valid
+//
+// Example:
+//
+//	if err := engine.ValidateRule(rule); err != nil {
+//		log.Printf("Invalid loyalty rule %s: %v", rule.ID, err)
+//	}
+func (re *RuleEngine) ValidateRule(rule LoyaltyRule) error {
+	return re.validateRule(rule)
+}
+
+// ValidateConfiguration checks the program-level settings and tier benefits of
+// a loyalty configuration and reports every problem found. Default rules are
+// validated separately with RuleEngine.ValidateRule.
+//
+// Parameters:
+//   - config: LoyaltyConfiguration to validate
+//
+// Returns:
+//   - []error: One error per problem, empty if the configuration is valid
+//
+// Validation checks:
+//   - Points and redemption rates are non-negative
+//   - Minimum redemption and points expiry are non-negative
+//   - Maximum redemption percent is between 0 and 100
+//   - Tier thresholds, multipliers and redemption bonuses are non-negative
+//
+// Example:
+//
+//	for _, err := range loyalty.ValidateConfiguration(config) {
+//		log.Printf("Loyalty configuration: %v", err)
+//	}
+func ValidateConfiguration(config LoyaltyConfiguration) []error {
+	errs := []error{}
+
+	if config.BasePointsRate < 0 {
+		errs = append(errs, fmt.Errorf("base points rate cannot be negative"))
+	}
+	if config.RedemptionRate < 0 {
+		errs = append(errs, fmt.Errorf("redemption rate cannot be negative"))
+	}
+	if config.MinRedemption < 0 {
+		errs = append(errs, fmt.Errorf("minimum redemption cannot be negative"))
+	}
+	if config.PointsExpiry < 0 {
+		errs = append(errs, fmt.Errorf("points expiry cannot be negative"))
+	}
+	if config.MaxRedemptionPercent < 0 || config.MaxRedemptionPercent > 100 {
+		errs = append(errs, fmt.Errorf("maximum redemption percent must be between 0 and 100"))
+	}
+
+	tiers := make([]string, 0, len(config.TierThresholds)+len(config.TierBenefits))
+	for tier, threshold := range config.TierThresholds {
+		if threshold < 0 {
+			tiers = append(tiers, fmt.Sprintf("tier %s: threshold cannot be negative", tier))
+		}
+	}
+	for tier, benefit := range config.TierBenefits {
+		if benefit.PointsMultiplier < 0 {
+			tiers = append(tiers, fmt.Sprintf("tier %s: points multiplier cannot be negative", tier))
+		}
+		if benefit.RedemptionBonus < 0 {
+			tiers = append(tiers, fmt.Sprintf("tier %s: redemption bonus cannot be negative", tier))
+		}
+	}
+	sort.Strings(tiers)
+	for _, message := range tiers {
+		errs = append(errs, fmt.Errorf("%s", message))
+	}
+
+	return errs
+}
+
 // validateRule validates a loyalty rule.
 // Performs comprehensive validation of rule structure and data.
 //
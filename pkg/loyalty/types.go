@@ -232,6 +232,7 @@ type LoyaltyCondition struct {
 //   - "earn_points": Award points based on value
 //   - "multiply_points": Multiply earned points by value
 //   - "bonus_points": Award fixed bonus points
+//   - "points_per_item": Award value points for each unit in the order's Items, regardless of price
 //   - "tier_upgrade": Upgrade customer tier
 //
 // Example:
@@ -243,7 +244,7 @@ type LoyaltyCondition struct {
 //		Description: "Base points earning",
 //	}
 type LoyaltyAction struct {
-	Type        string      `json:"type"`        // "earn_points", "multiply_points", "bonus_points", "tier_upgrade"
+	Type        string      `json:"type"`        // "earn_points", "multiply_points", "bonus_points", "points_per_item", "tier_upgrade"
 	Value       interface{} `json:"value"`       // Action value
 	PointsType  PointsType  `json:"points_type,omitempty"`
 	Description string      `json:"description,omitempty"`
@@ -351,7 +352,7 @@ type Reward struct {
 //		Tier: TierGold,
 //		PointsMultiplier: 1.5,
 //		BonusPointsPercent: 10.0,
-//		RedemptionBonus: 1.2,
+//		RedemptionBonus: 0.2,
 //		FreeShippingThreshold: 75.0,
 //		EarlyAccess: true,
 //		PrioritySupport: true,
@@ -362,7 +363,7 @@ type TierBenefit struct {
 	Tier                LoyaltyTier `json:"tier"`
 	PointsMultiplier    float64     `json:"points_multiplier"`    // Base points multiplier
 	BonusPointsPercent  float64     `json:"bonus_points_percent"` // Additional bonus percentage
-	RedemptionBonus     float64     `json:"redemption_bonus"`     // Extra value when redeeming (20% = 1.2x value)
+	RedemptionBonus     float64     `json:"redemption_bonus"`     // Extra value when redeeming (0.2 = 20% more, 1.2x value)
 	FreeShippingThreshold float64   `json:"free_shipping_threshold,omitempty"`
 	EarlyAccess         bool        `json:"early_access"`         // Early access to sales
 	PrioritySupport     bool        `json:"priority_support"`     // Priority customer support
@@ -510,7 +511,7 @@ type PointsBreakdown struct {
 	Source      string     `json:"source"`      // "base", "category_bonus", "payment_bonus", "tier_bonus"
 	Description string     `json:"description"`
 	Amount      float64    `json:"amount"`      // Order amount for this breakdown
-	Rate        float64    `json:"rate"`        // Points per currency unit
+	Rate        float64    `json:"rate"`        // Points per currency unit, or per item for "rule_per_item"
 	Multiplier  float64    `json:"multiplier"`  // Applied multiplier
 	Points      int        `json:"points"`      // Calculated points
 	PointsType  PointsType `json:"points_type"`
@@ -677,6 +678,15 @@ type LoyaltyAnalytics struct {
 //		PointsExpiry: 12,
 //		MinRedemption: 100,
 //		MaxRedemptionPercent: 50.0,
+//		ActionPoints: map[string]int{
+//			"review":             50,
+//			"referral":           200,
+//			"profile_completion": 100,
+//		},
+//		ActionLimits: map[string]ActionLimit{
+//			"review":             {MaxAwards: 1, ScopeKey: "product_id"}, // One review bonus per product
+//			"profile_completion": {MaxAwards: 1},                        // Once per customer
+//		},
 //		IsActive: true,
 //		CreatedAt: time.Now(),
 //		UpdatedAt: time.Now(),
@@ -692,12 +702,29 @@ type LoyaltyConfiguration struct {
 	TierThresholds      map[LoyaltyTier]float64 `json:"tier_thresholds"`
 	TierBenefits        map[LoyaltyTier]TierBenefit `json:"tier_benefits"`
 	DefaultRules        []LoyaltyRule `json:"default_rules"`
+	ActionPoints        map[string]int `json:"action_points,omitempty"`        // Points per non-purchase action
+	ActionLimits        map[string]ActionLimit `json:"action_limits,omitempty"` // Award caps per action
 	IsActive            bool          `json:"is_active"`
 	CreatedAt           time.Time     `json:"created_at"`
 	UpdatedAt           time.Time     `json:"updated_at"`
 	Metadata            map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// ActionLimit caps how often a customer can earn points for a non-purchase action.
+// Awards are counted per customer, and per value of the ScopeKey metadata entry
+// when ScopeKey is set, so a review can be rewarded once per product.
+//
+// Example:
+//
+//	limit := ActionLimit{
+//		MaxAwards: 1,
+//		ScopeKey: "product_id",
+//	}
+type ActionLimit struct {
+	MaxAwards int    `json:"max_awards"`          // Maximum awards per scope (0 means unlimited)
+	ScopeKey  string `json:"scope_key,omitempty"` // Metadata key the cap is counted per
+}
+
 // ReferralProgram represents referral program configuration and settings.
 // Defines how customers can refer others and earn rewards.
 //
@@ -0,0 +1,39 @@
+package pricing
+
+import "fmt"
+
+// BreakEvenQuantity returns the unit volume a promotion must sell for its gross
+// profit to match the gross profit of the original price.
+//
+// Margins are gross profit per unit in currency (price minus cost), so the
+// original scenario earns baseMargin*currentUnits and the promotion breaks even
+// at baseMargin*currentUnits/discountedMargin units. Subtract currentUnits from
+// the result to get the extra units the promotion has to sell.
+//
+// Parameters:
+//   - baseMargin: Gross profit per unit at the original price
+//   - discountedMargin: Gross profit per unit at the discounted price
+//   - currentUnits: Units sold at the original price
+//
+// Returns:
+//   - float64: Units needed at the discounted price to break even
+//   - error: Error if the discounted margin is not positive (the promotion can
+//     never break even) or the base margin or current units are negative
+//
+// Example:
+//
+//	// $40 price, $25 cost, 100 units; a 10% discount leaves an $11 margin
+//	units, _ := pricing.BreakEvenQuantity(15, 11, 100) // 136.36 units, ~37 extra
+func BreakEvenQuantity(baseMargin float64, discountedMargin float64, currentUnits float64) (float64, error) {
+	if discountedMargin <= 0 {
+		return 0, fmt.Errorf("discounted margin must be positive, got %.2f", discountedMargin)
+	}
+	if baseMargin < 0 {
+		return 0, fmt.Errorf("base margin cannot be negative")
+	}
+	if currentUnits < 0 {
+		return 0, fmt.Errorf("current units cannot be negative")
+	}
+
+	return baseMargin * currentUnits / discountedMargin, nil
+}
@@ -0,0 +1,55 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBreakEvenQuantity(t *testing.T) {
+	tests := []struct {
+		name             string
+		baseMargin       float64
+		discountedMargin float64
+		currentUnits     float64
+		expected         float64
+	}{
+		{name: "10% off a $40 item with $25 cost", baseMargin: 15, discountedMargin: 11, currentUnits: 100, expected: 136.3636364},
+		{name: "margin halved doubles volume", baseMargin: 20, discountedMargin: 10, currentUnits: 50, expected: 100},
+		{name: "unchanged margin", baseMargin: 8, discountedMargin: 8, currentUnits: 30, expected: 30},
+		{name: "no current sales", baseMargin: 5, discountedMargin: 2, currentUnits: 0, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := BreakEvenQuantity(tt.baseMargin, tt.discountedMargin, tt.currentUnits)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result-tt.expected) > 0.00001 {
+				t.Errorf("Expected %f, got %f", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestBreakEvenQuantityErrors(t *testing.T) {
+	tests := []struct {
+		name             string
+		baseMargin       float64
+		discountedMargin float64
+		currentUnits     float64
+	}{
+		{name: "zero discounted margin", baseMargin: 10, discountedMargin: 0, currentUnits: 100},
+		{name: "negative discounted margin", baseMargin: 10, discountedMargin: -2, currentUnits: 100},
+		{name: "negative base margin", baseMargin: -1, discountedMargin: 2, currentUnits: 100},
+		{name: "negative current units", baseMargin: 10, discountedMargin: 5, currentUnits: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BreakEvenQuantity(tt.baseMargin, tt.discountedMargin, tt.currentUnits); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
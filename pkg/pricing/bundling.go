@@ -34,7 +34,7 @@
 //	}
 //
 //	// Generate recommendations
-//	recommendations, err := bm.GenerateBundleRecommendations(items, customer, context)
+//	recommendations, err := bm.GenerateBundleRecommendations(items, customer, context, 5)
 //	if err != nil {
 //		log.Fatal(err)
 //	}
@@ -47,10 +47,16 @@
 package pricing
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"math"
 	"sort"
+	"strconv"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // BundleManager handles comprehensive bundle creation, management, and optimization.
@@ -88,6 +94,80 @@ type BundleManager struct {
 	bundleTemplates []BundleTemplate
 	bundleRules     []BundleRule
 	analytics       map[string]BundleAnalytics
+	inventory       InventoryChecker
+	variants        *VariantAssigner
+	now             func() time.Time
+	random          io.Reader
+	onExpire        func(Bundle)
+}
+
+// InventoryChecker reports whether an item can be supplied in the requested quantity.
+// BundleManager consults it so that recommendations and mix-and-match selections
+// never include items that are out of stock. When no checker is set, all items are
+// assumed to be available.
+//
+// Example:
+//
+//	type warehouseStock map[string]int
+//
+//	func (s warehouseStock) Available(itemID string, qty int) bool {
+//		return s[itemID] >= qty
+//	}
+//
+//	bm.SetInventoryChecker(warehouseStock{"laptop": 5, "mouse": 0})
+type InventoryChecker interface {
+	Available(itemID string, qty int) bool
+}
+
+// VariantAssigner splits customers into experiment variants for A/B testing bundle
+// recommendations. Customers are bucketed by hashing the experiment ID together with
+// the customer ID, so a customer always lands in the same variant of an experiment
+// while different experiments bucket independently.
+//
+// Example:
+//
+//	assigner := pricing.NewVariantAssigner("bundle-recs-2024q3", []string{"control", "treatment"})
+//	variant := assigner.Assign("customer-123") // Always the same for this customer
+type VariantAssigner struct {
+	ExperimentID string   `json:"experiment_id"`
+	Variants     []string `json:"variants"`
+}
+
+// NewVariantAssigner creates a variant assigner for an experiment.
+//
+// Parameters:
+//   - experimentID: Identifier of the experiment, stamped on tagged recommendations
+//   - variants: Variant names; customers are spread evenly across them
+//
+// Returns:
+//   - *VariantAssigner: Assigner ready to bucket customers
+func NewVariantAssigner(experimentID string, variants []string) *VariantAssigner {
+	return &VariantAssigner{
+		ExperimentID: experimentID,
+		Variants:     variants,
+	}
+}
+
+// Assign returns the variant for a customer, derived from utils.GenerateHashID of the
+// experiment and customer IDs. It returns an empty string when there are no variants
+// or the customer ID is empty, since anonymous customers cannot be bucketed consistently.
+//
+// Parameters:
+//   - customerID: ID of the customer to bucket
+//
+// Returns:
+//   - string: Assigned variant name
+func (va *VariantAssigner) Assign(customerID string) string {
+	if len(va.Variants) == 0 || customerID == "" {
+		return ""
+	}
+
+	hash, err := strconv.ParseUint(utils.GenerateHashID(va.ExperimentID+":"+customerID), 16, 64)
+	if err != nil {
+		return va.Variants[0]
+	}
+
+	return va.Variants[hash%uint64(len(va.Variants))]
 }
 
 // BundleTemplate represents a reusable template for creating bundles.
@@ -296,6 +376,9 @@ type BundleAnalytics struct {
 //		Priority: 8,
 //		ValidUntil: time.Now().AddDate(0, 0, 7),
 //	}
+//
+// ExperimentID and Variant are set when a VariantAssigner is configured, so
+// conversions can be attributed to the experiment variant the customer saw.
 type BundleRecommendation struct {
 	BundleID      string    `json:"bundle_id"`
 	Name          string    `json:"name"`
@@ -309,6 +392,8 @@ type BundleRecommendation struct {
 	Reason        string    `json:"reason"`
 	Priority      int       `json:"priority"`
 	ValidUntil    time.Time `json:"valid_until,omitempty"`
+	ExperimentID  string    `json:"experiment_id,omitempty"`
+	Variant       string    `json:"variant,omitempty"`
 }
 
 // BundleOptimization represents the results of bundle optimization analysis.
@@ -402,6 +487,8 @@ func NewBundleManager() *BundleManager {
 		bundleTemplates: make([]BundleTemplate, 0),
 		bundleRules:     make([]BundleRule, 0),
 		analytics:       make(map[string]BundleAnalytics),
+		now:             time.Now,
+		random:          rand.Reader,
 	}
 }
 
@@ -441,15 +528,15 @@ func (bm *BundleManager) CreateBundle(name, description string, bundleType Bundl
 	}
 
 	bundle := &Bundle{
-		ID:          fmt.Sprintf("bundle_%d", time.Now().Unix()),
+		ID:          bm.newID("bundle"),
 		Name:        name,
 		Description: description,
 		Type:        bundleType,
 		Items:       make([]BundleItem, 0),
 		Pricing:     pricing,
 		IsActive:    true,
-		ValidFrom:   time.Now(),
-		ValidUntil:  time.Now().AddDate(1, 0, 0), // Valid for 1 year
+		ValidFrom:   bm.clock(),
+		ValidUntil:  bm.clock().AddDate(1, 0, 0), // Valid for 1 year
 		Tags:        make([]string, 0),
 		Metadata:    make(map[string]interface{}),
 	}
@@ -532,11 +619,19 @@ func (bm *BundleManager) CreateBundleFromTemplate(templateID string, items []Pri
 
 // GenerateBundleRecommendations generates intelligent bundle recommendations for given items.
 // Uses customer data, purchase history, and item relationships to suggest optimal bundles.
+// When a VariantAssigner is set, every recommendation is tagged with the experiment ID
+// and the customer's variant.
+//
+// Recommendations are ordered by Priority (highest first), then Confidence (highest
+// first), then BundleID (ascending), so the same inputs always produce the same list
+// in the same order. The cap is applied after sorting, so only the lowest-ranked
+// recommendations are dropped.
 //
 // Parameters:
 //   - items: Items to generate recommendations for
 //   - customer: Customer information and preferences
 //   - context: Pricing context and business rules
+//   - maxResults: Maximum number of recommendations to return; 0 or less means no limit
 //
 // Returns:
 //   - []BundleRecommendation: List of recommended bundles
@@ -553,7 +648,7 @@ func (bm *BundleManager) CreateBundleFromTemplate(templateID string, items []Pri
 //		},
 //	}
 //
-//	recommendations, err := bm.GenerateBundleRecommendations(items, customer, context)
+//	recommendations, err := bm.GenerateBundleRecommendations(items, customer, context, 5)
 //	if err != nil {
 //		return nil, err
 //	}
@@ -562,7 +657,33 @@ func (bm *BundleManager) CreateBundleFromTemplate(templateID string, items []Pri
 //		fmt.Printf("Bundle: %s, Confidence: %.2f, Savings: $%.2f\n", 
 //			rec.Name, rec.Confidence, rec.Savings)
 //	}
-func (bm *BundleManager) GenerateBundleRecommendations(items []PricingItem, customer Customer, context PricingContext) ([]BundleRecommendation, error) {
+func (bm *BundleManager) GenerateBundleRecommendations(items []PricingItem, customer Customer, context PricingContext, maxResults int) ([]BundleRecommendation, error) {
+	return bm.GenerateBundleRecommendationsWithMatching(items, customer, context, BundleMatching{Mode: BundleMatchByID}, maxResults)
+}
+
+// GenerateBundleRecommendationsWithMatching generates bundle recommendations like
+// GenerateBundleRecommendations, scoring existing bundles with the given matching
+// mode so near-substitutes count toward a match. Each cart item satisfies at most
+// one bundle item. Ordering and the maxResults cap follow the same contract as
+// GenerateBundleRecommendations.
+//
+// Parameters:
+//   - items: Items to generate recommendations for
+//   - customer: Customer information and preferences
+//   - context: Pricing context and business rules
+//   - matching: How cart items are matched against bundle items
+//   - maxResults: Maximum number of recommendations to return; 0 or less means no limit
+//
+// Returns:
+//   - []BundleRecommendation: List of recommended bundles
+//   - error: Error if recommendation generation fails
+//
+// Example:
+//
+//	// A bundle requiring "keyboard" matches any keyboard in the cart
+//	matching := pricing.BundleMatching{Mode: pricing.BundleMatchByCategory}
+//	recommendations, err := bm.GenerateBundleRecommendationsWithMatching(items, customer, context, matching, 10)
+func (bm *BundleManager) GenerateBundleRecommendationsWithMatching(items []PricingItem, customer Customer, context PricingContext, matching BundleMatching, maxResults int) ([]BundleRecommendation, error) {
 	recommendations := make([]BundleRecommendation, 0)
 
 	// Find existing bundles that match the items
@@ -571,7 +692,12 @@ func (bm *BundleManager) GenerateBundleRecommendations(items []PricingItem, cust
 			continue
 		}
 
-		matchScore := bm.calculateBundleMatchScore(items, bundle)
+		// Never recommend bundles that cannot be fulfilled
+		if !bm.bundleItemsAvailable(bundle.Items) {
+			continue
+		}
+
+		matchScore := bm.calculateBundleMatchScore(items, bundle, matching)
 		if matchScore > 0.5 { // Threshold for recommendation
 			recommendation := bm.createBundleRecommendation(bundle, items, matchScore)
 			recommendations = append(recommendations, recommendation)
@@ -580,16 +706,37 @@ func (bm *BundleManager) GenerateBundleRecommendations(items []PricingItem, cust
 
 	// Generate dynamic bundle recommendations
 	dynamicRecommendations := bm.generateDynamicBundleRecommendations(items, customer, context)
-	recommendations = append(recommendations, dynamicRecommendations...)
+	for _, recommendation := range dynamicRecommendations {
+		if bm.recommendationItemsAvailable(recommendation) {
+			recommendations = append(recommendations, recommendation)
+		}
+	}
 
-	// Sort by priority and confidence
-	sort.Slice(recommendations, func(i, j int) bool {
-		if recommendations[i].Priority == recommendations[j].Priority {
+	// Sort by priority, then confidence, then bundle ID so the order is deterministic
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		if recommendations[i].Priority != recommendations[j].Priority {
+			return recommendations[i].Priority > recommendations[j].Priority
+		}
+		if recommendations[i].Confidence != recommendations[j].Confidence {
 			return recommendations[i].Confidence > recommendations[j].Confidence
 		}
-		return recommendations[i].Priority > recommendations[j].Priority
+		return recommendations[i].BundleID < recommendations[j].BundleID
 	})
 
+	if maxResults > 0 && len(recommendations) > maxResults {
+		recommendations = recommendations[:maxResults]
+	}
+
+	// Tag recommendations with the customer's experiment variant
+	if bm.variants != nil {
+		if variant := bm.variants.Assign(customer.ID); variant != "" {
+			for i := range recommendations {
+				recommendations[i].ExperimentID = bm.variants.ExperimentID
+				recommendations[i].Variant = variant
+			}
+		}
+	}
+
 	return recommendations, nil
 }
 
@@ -750,7 +897,7 @@ func (bm *BundleManager) AnalyzeBundlePerformance(bundleID string, periodStart,
 //	bundle, err := bm.CreateMixAndMatchBundle("Fashion Mix", categories, 2, 6, pricing)
 func (bm *BundleManager) CreateMixAndMatchBundle(name string, categories []string, minItems, maxItems int, pricing BundlePricing) (*Bundle, error) {
 	bundle := &Bundle{
-		ID:          fmt.Sprintf("mixmatch_%d", time.Now().Unix()),
+		ID:          bm.newID("mixmatch"),
 		Name:        name,
 		Type:        BundleTypeMixMatch,
 		Items:       make([]BundleItem, 0),
@@ -758,8 +905,8 @@ func (bm *BundleManager) CreateMixAndMatchBundle(name string, categories []strin
 		MinItems:    minItems,
 		MaxItems:    maxItems,
 		IsActive:    true,
-		ValidFrom:   time.Now(),
-		ValidUntil:  time.Now().AddDate(0, 6, 0), // Valid for 6 months
+		ValidFrom:   bm.clock(),
+		ValidUntil:  bm.clock().AddDate(0, 6, 0), // Valid for 6 months
 		Metadata:    map[string]interface{}{"categories": categories},
 	}
 
@@ -767,6 +914,68 @@ func (bm *BundleManager) CreateMixAndMatchBundle(name string, categories []strin
 	return bundle, nil
 }
 
+// ValidateMixAndMatchSelection checks a customer's selection against a mix-and-match bundle.
+// The selection must respect the bundle's item limits, only contain items from the
+// bundle's categories, and every item must be available in the selected quantity.
+//
+// Parameters:
+//   - bundleID: ID of the mix-and-match bundle
+//   - items: Items chosen by the customer
+//
+// Returns:
+//   - error: Error if the bundle is not found or the selection is invalid
+//
+// Example:
+//
+//	selection := []pricing.PricingItem{
+//		{ID: "shirt-1", Category: "shirts", Quantity: 1},
+//		{ID: "pants-1", Category: "pants", Quantity: 1},
+//	}
+//
+//	if err := bm.ValidateMixAndMatchSelection(bundle.ID, selection); err != nil {
+//		fmt.Printf("Invalid selection: %v\n", err)
+//	}
+func (bm *BundleManager) ValidateMixAndMatchSelection(bundleID string, items []PricingItem) error {
+	bundle := bm.getBundle(bundleID)
+	if bundle == nil {
+		return fmt.Errorf("bundle not found: %s", bundleID)
+	}
+
+	if bundle.Type != BundleTypeMixMatch {
+		return fmt.Errorf("bundle %s is not a mix-and-match bundle", bundleID)
+	}
+
+	if len(items) < bundle.MinItems {
+		return fmt.Errorf("insufficient items: need at least %d, got %d", bundle.MinItems, len(items))
+	}
+
+	if bundle.MaxItems > 0 && len(items) > bundle.MaxItems {
+		return fmt.Errorf("too many items: maximum %d allowed, got %d", bundle.MaxItems, len(items))
+	}
+
+	categories, _ := bundle.Metadata["categories"].([]string)
+	for _, item := range items {
+		if len(categories) > 0 {
+			found := false
+			for _, category := range categories {
+				if item.Category == category {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("category not allowed in bundle: %s", item.Category)
+			}
+		}
+
+		if !bm.isAvailable(item.ID, item.Quantity) {
+			return fmt.Errorf("item not available: %s", item.ID)
+		}
+	}
+
+	return nil
+}
+
 // CreateFrequencyBundle creates a subscription-style frequency bundle.
 // Designed for recurring purchases with automatic delivery and pricing benefits.
 //
@@ -793,13 +1002,13 @@ func (bm *BundleManager) CreateMixAndMatchBundle(name string, categories []strin
 //	bundle, err := bm.CreateFrequencyBundle("Coffee Subscription", baseItem, 30, 20.0)
 func (bm *BundleManager) CreateFrequencyBundle(name string, baseItem PricingItem, frequency int, discount float64) (*Bundle, error) {
 	bundle := &Bundle{
-		ID:          fmt.Sprintf("frequency_%d", time.Now().Unix()),
+		ID:          bm.newID("frequency"),
 		Name:        name,
 		Type:        BundleTypeFrequency,
 		Items:       make([]BundleItem, 0),
 		IsActive:    true,
-		ValidFrom:   time.Now(),
-		ValidUntil:  time.Now().AddDate(1, 0, 0),
+		ValidFrom:   bm.clock(),
+		ValidUntil:  bm.clock().AddDate(1, 0, 0),
 		Metadata:    map[string]interface{}{"frequency": frequency, "discount": discount},
 	}
 
@@ -1051,24 +1260,83 @@ func (bm *BundleManager) applyCustomizations(bundle *Bundle, customizations map[
 	}
 }
 
-func (bm *BundleManager) calculateBundleMatchScore(items []PricingItem, bundle Bundle) float64 {
-	matchingItems := 0
+// isAvailable consults the inventory checker, treating every item as available
+// when none is set. Quantities below one are checked as a single unit.
+func (bm *BundleManager) isAvailable(itemID string, qty int) bool {
+	if bm.inventory == nil {
+		return true
+	}
+	if qty < 1 {
+		qty = 1
+	}
+	return bm.inventory.Available(itemID, qty)
+}
+
+func (bm *BundleManager) bundleItemsAvailable(items []BundleItem) bool {
 	for _, item := range items {
-		for _, bundleItem := range bundle.Items {
-			if item.ID == bundleItem.ItemID {
-				matchingItems++
-				break
-			}
+		if !bm.isAvailable(item.ItemID, item.Quantity) {
+			return false
 		}
 	}
+	return true
+}
 
+func (bm *BundleManager) recommendationItemsAvailable(recommendation BundleRecommendation) bool {
+	for _, itemID := range recommendation.Items {
+		if !bm.isAvailable(itemID, 1) {
+			return false
+		}
+	}
+	return true
+}
+
+func (bm *BundleManager) calculateBundleMatchScore(items []PricingItem, bundle Bundle, matching BundleMatching) float64 {
 	if len(bundle.Items) == 0 {
 		return 0
 	}
 
+	matchingItems := 0
+	used := make([]bool, len(items))
+	for _, bundleItem := range bundle.Items {
+		// Prefer an exact ID so substitutes stay free for other bundle items
+		match := -1
+		for i, item := range items {
+			if used[i] {
+				continue
+			}
+			if item.ID == bundleItem.ItemID {
+				match = i
+				break
+			}
+			if match < 0 && itemMatchesBundleItem(item, bundleItem, matching) {
+				match = i
+			}
+		}
+		if match >= 0 {
+			used[match] = true
+			matchingItems++
+		}
+	}
+
 	return float64(matchingItems) / float64(len(bundle.Items))
 }
 
+// itemMatchesBundleItem reports whether a cart item is an acceptable substitute
+// for a bundle item under the matching mode.
+func itemMatchesBundleItem(item PricingItem, bundleItem BundleItem, matching BundleMatching) bool {
+	switch matching.Mode {
+	case BundleMatchByCategory:
+		return bundleItem.Category != "" && item.Category == bundleItem.Category
+	case BundleMatchBySubstitution:
+		for _, substitute := range matching.Substitutes[bundleItem.ItemID] {
+			if item.ID == substitute {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (bm *BundleManager) createBundleRecommendation(bundle Bundle, items []PricingItem, matchScore float64) BundleRecommendation {
 	originalPrice := 0.0
 	for _, item := range items {
@@ -1079,13 +1347,19 @@ func (bm *BundleManager) createBundleRecommendation(bundle Bundle, items []Prici
 	savings := originalPrice - bundlePrice
 	savingsPercent := 0.0
 	if originalPrice > 0 {
-		savingsPercent = (savings / originalPrice) * 100
+		savingsPercent = utils.RoundToPercent((savings / originalPrice) * 100)
+	}
+
+	itemIDs := make([]string, len(bundle.Items))
+	for i, bundleItem := range bundle.Items {
+		itemIDs[i] = bundleItem.ItemID
 	}
 
 	return BundleRecommendation{
 		BundleID:       bundle.ID,
 		Name:           bundle.Name,
 		Type:           string(bundle.Type),
+		Items:          itemIDs,
 		OriginalPrice:  originalPrice,
 		BundlePrice:    bundlePrice,
 		Savings:        savings,
@@ -1134,7 +1408,7 @@ func (bm *BundleManager) findUpsellItems(items []PricingItem, customer Customer)
 func (bm *BundleManager) createCrossSellRecommendation(originalItems, crossSellItems []PricingItem) BundleRecommendation {
 	// Simplified implementation
 	return BundleRecommendation{
-		BundleID:   fmt.Sprintf("cross_sell_%d", time.Now().Unix()),
+		BundleID:   bm.newID("cross_sell"),
 		Name:       "Cross-sell Bundle",
 		Type:       "cross_sell",
 		Confidence: 0.7,
@@ -1146,7 +1420,7 @@ func (bm *BundleManager) createCrossSellRecommendation(originalItems, crossSellI
 func (bm *BundleManager) createUpsellRecommendation(originalItems, upsellItems []PricingItem) BundleRecommendation {
 	// Simplified implementation
 	return BundleRecommendation{
-		BundleID:   fmt.Sprintf("upsell_%d", time.Now().Unix()),
+		BundleID:   bm.newID("upsell"),
 		Name:       "Premium Bundle",
 		Type:       "up_sell",
 		Confidence: 0.8,
@@ -1275,6 +1549,103 @@ func (bm *BundleManager) AddBundleRule(rule BundleRule) {
 	bm.bundleRules = append(bm.bundleRules, rule)
 }
 
+// SetInventoryChecker sets the availability hook used when generating recommendations
+// and validating mix-and-match selections. Passing nil restores the default of
+// treating all items as available.
+//
+// Parameters:
+//   - checker: Inventory checker to consult
+//
+// Example:
+//
+//	bm.SetInventoryChecker(warehouseStock{"laptop": 5, "mouse": 0})
+func (bm *BundleManager) SetInventoryChecker(checker InventoryChecker) {
+	bm.inventory = checker
+}
+
+// SetVariantAssigner sets the experiment used to tag generated recommendations.
+// Passing nil stops tagging.
+//
+// Parameters:
+//   - assigner: Variant assigner for the running experiment
+//
+// Example:
+//
+//	bm.SetVariantAssigner(pricing.NewVariantAssigner("bundle-recs-2024q3", []string{"control", "treatment"}))
+func (bm *BundleManager) SetVariantAssigner(assigner *VariantAssigner) {
+	bm.variants = assigner
+}
+
+// SetClock sets the time source used for bundle IDs and validity periods.
+// Tests can pass a fixed clock to make generated bundles reproducible.
+// Passing nil restores time.Now.
+//
+// Parameters:
+//   - now: Function returning the current time
+//
+// Example:
+//
+//	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+//	bm.SetClock(func() time.Time { return fixed })
+func (bm *BundleManager) SetClock(now func() time.Time) {
+	bm.now = now
+}
+
+// SetRandSource sets the source of randomness used to make generated bundle IDs
+// unique. Tests can pass a seeded reader to make IDs reproducible.
+// Passing nil restores crypto/rand.
+//
+// Parameters:
+//   - random: Reader supplying random bytes
+//
+// Example:
+//
+//	bm.SetRandSource(mathrand.New(mathrand.NewSource(42)))
+func (bm *BundleManager) SetRandSource(random io.Reader) {
+	bm.random = random
+}
+
+// SetOnExpire sets a callback invoked by SweepExpired for every bundle it
+// deactivates, e.g. to invalidate caches or notify merchandisers.
+// Passing nil removes the callback.
+//
+// Parameters:
+//   - onExpire: Function receiving the deactivated bundle
+//
+// Example:
+//
+//	bm.SetOnExpire(func(bundle pricing.Bundle) {
+//		cache.Delete("bundle:" + bundle.ID)
+//	})
+func (bm *BundleManager) SetOnExpire(onExpire func(Bundle)) {
+	bm.onExpire = onExpire
+}
+
+// clock returns the current time from the configured clock, defaulting to time.Now.
+func (bm *BundleManager) clock() time.Time {
+	if bm.now == nil {
+		return time.Now()
+	}
+	return bm.now()
+}
+
+// newID builds a generated bundle or recommendation ID from a prefix, the current
+// Unix time, and a random suffix, e.g. "bundle_1717243200_9f86d081". The suffix keeps
+// IDs created within the same second apart; it is omitted if the source fails.
+func (bm *BundleManager) newID(prefix string) string {
+	id := fmt.Sprintf("%s_%d", prefix, bm.clock().Unix())
+
+	random := bm.random
+	if random == nil {
+		random = rand.Reader
+	}
+	suffix := make([]byte, 4)
+	if _, err := io.ReadFull(random, suffix); err != nil {
+		return id
+	}
+	return id + "_" + hex.EncodeToString(suffix)
+}
+
 // GetBundles returns all bundles managed by this bundle manager.
 // Includes both active and inactive bundles.
 //
@@ -1311,14 +1682,46 @@ func (bm *BundleManager) GetBundles() []Bundle {
 //	}
 func (bm *BundleManager) GetActiveBundles() []Bundle {
 	activeBundles := make([]Bundle, 0)
+	now := bm.clock()
 	for _, bundle := range bm.bundles {
-		if bundle.IsActive && time.Now().After(bundle.ValidFrom) && time.Now().Before(bundle.ValidUntil) {
+		if bundle.IsActive && !now.Before(bundle.ValidFrom) && now.Before(bundle.ValidUntil) {
 			activeBundles = append(activeBundles, bundle)
 		}
 	}
 	return activeBundles
 }
 
+// SweepExpired deactivates active bundles whose validity period has ended as of
+// the given time and returns their IDs. A bundle expires at its ValidUntil, matching
+// the window GetActiveBundles uses; bundles without a ValidUntil never expire.
+// The OnExpire callback, if set, is called once per deactivated bundle.
+//
+// Parameters:
+//   - asOf: Time to evaluate expiry against
+//
+// Returns:
+//   - []string: IDs of the bundles deactivated by this sweep
+//
+// Example:
+//
+//	expired := bm.SweepExpired(time.Now())
+//	fmt.Printf("Deactivated %d bundles\n", len(expired))
+func (bm *BundleManager) SweepExpired(asOf time.Time) []string {
+	expired := []string{}
+	for i := range bm.bundles {
+		bundle := &bm.bundles[i]
+		if !bundle.IsActive || bundle.ValidUntil.IsZero() || asOf.Before(bundle.ValidUntil) {
+			continue
+		}
+		bundle.IsActive = false
+		expired = append(expired, bundle.ID)
+		if bm.onExpire != nil {
+			bm.onExpire(*bundle)
+		}
+	}
+	return expired
+}
+
 // UpdateBundleAnalytics updates the analytics data for a specific bundle.
 // Used to track performance metrics and inform optimization decisions.
 //
@@ -0,0 +1,425 @@
+package pricing
+
+import (
+	"fmt"
+	mathrand "math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeInventory marks the listed item IDs as out of stock.
+type fakeInventory map[string]bool
+
+func (f fakeInventory) Available(itemID string, qty int) bool {
+	return !f[itemID]
+}
+
+func createTestInventoryBundle(id string, itemIDs ...string) Bundle {
+	bundle := Bundle{
+		ID:         id,
+		Name:       id,
+		Type:       BundleTypeFixed,
+		Pricing:    BundlePricing{Type: "fixed", Value: 50.0, BasePrice: 50.0},
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-time.Hour),
+		ValidUntil: time.Now().Add(time.Hour),
+	}
+	for _, itemID := range itemIDs {
+		bundle.Items = append(bundle.Items, BundleItem{ItemID: itemID, Quantity: 1, IsRequired: true, BasePrice: 30.0})
+	}
+	return bundle
+}
+
+func TestGenerateBundleRecommendationsInventory(t *testing.T) {
+	items := []PricingItem{
+		{ID: "laptop", BasePrice: 30.0, Quantity: 1},
+		{ID: "mouse", BasePrice: 30.0, Quantity: 1},
+		{ID: "keyboard", BasePrice: 30.0, Quantity: 1},
+	}
+
+	bm := NewBundleManager()
+	bm.bundles = append(bm.bundles,
+		createTestInventoryBundle("laptop_mouse", "laptop", "mouse"),
+		createTestInventoryBundle("laptop_keyboard", "laptop", "keyboard"),
+	)
+
+	t.Run("NoChecker", func(t *testing.T) {
+		recommendations, err := bm.GenerateBundleRecommendations(items, Customer{}, PricingContext{}, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recommendations) != 2 {
+			t.Errorf("Expected 2 recommendations when no checker is set, got %d", len(recommendations))
+		}
+	})
+
+	t.Run("OutOfStockItem", func(t *testing.T) {
+		bm.SetInventoryChecker(fakeInventory{"mouse": true})
+		defer bm.SetInventoryChecker(nil)
+
+		recommendations, err := bm.GenerateBundleRecommendations(items, Customer{}, PricingContext{}, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recommendations) != 1 {
+			t.Fatalf("Expected 1 recommendation, got %d", len(recommendations))
+		}
+		if recommendations[0].BundleID != "laptop_keyboard" {
+			t.Errorf("Expected laptop_keyboard recommendation, got %s", recommendations[0].BundleID)
+		}
+		for _, itemID := range recommendations[0].Items {
+			if itemID == "mouse" {
+				t.Error("Expected out-of-stock item to be excluded from recommendations")
+			}
+		}
+	})
+}
+
+func TestGenerateBundleRecommendationsOrderingAndCap(t *testing.T) {
+	items := []PricingItem{
+		{ID: "laptop", BasePrice: 30.0, Quantity: 1},
+		{ID: "mouse", BasePrice: 30.0, Quantity: 1},
+		{ID: "keyboard", BasePrice: 30.0, Quantity: 1},
+	}
+
+	bm := NewBundleManager()
+	bm.bundles = append(bm.bundles,
+		createTestInventoryBundle("partial", "laptop", "mouse", "monitor"),
+		createTestInventoryBundle("c_pair", "laptop", "mouse"),
+		createTestInventoryBundle("a_pair", "laptop", "keyboard"),
+		createTestInventoryBundle("b_pair", "mouse", "keyboard"),
+	)
+
+	t.Run("Ordering", func(t *testing.T) {
+		// Full matches share priority and confidence, so they fall back to bundle ID
+		expected := []string{"a_pair", "b_pair", "c_pair", "partial"}
+		for run := 0; run < 5; run++ {
+			recommendations, err := bm.GenerateBundleRecommendations(items, Customer{}, PricingContext{}, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(recommendations) != len(expected) {
+				t.Fatalf("Expected %d recommendations, got %d", len(expected), len(recommendations))
+			}
+			for i, recommendation := range recommendations {
+				if recommendation.BundleID != expected[i] {
+					t.Errorf("Run %d: expected recommendation %d to be %s, got %s", run, i, expected[i], recommendation.BundleID)
+				}
+			}
+		}
+	})
+
+	t.Run("Cap", func(t *testing.T) {
+		recommendations, err := bm.GenerateBundleRecommendations(items, Customer{}, PricingContext{}, 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recommendations) != 2 {
+			t.Fatalf("Expected the cap of 2 recommendations, got %d", len(recommendations))
+		}
+		if recommendations[0].BundleID != "a_pair" || recommendations[1].BundleID != "b_pair" {
+			t.Errorf("Expected the two highest-ranked recommendations, got %s and %s", recommendations[0].BundleID, recommendations[1].BundleID)
+		}
+	})
+
+	t.Run("CapAboveCount", func(t *testing.T) {
+		recommendations, err := bm.GenerateBundleRecommendationsWithMatching(items, Customer{}, PricingContext{}, BundleMatching{Mode: BundleMatchByID}, 10)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recommendations) != 4 {
+			t.Errorf("Expected all 4 recommendations under a larger cap, got %d", len(recommendations))
+		}
+	})
+}
+
+func TestValidateMixAndMatchSelectionInventory(t *testing.T) {
+	bm := NewBundleManager()
+	bundle, err := bm.CreateMixAndMatchBundle("Fashion Mix", []string{"shirts", "pants"}, 2, 3, BundlePricing{Type: "percentage", Value: 10.0})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	selection := []PricingItem{
+		{ID: "shirt-1", Category: "shirts", Quantity: 1},
+		{ID: "pants-1", Category: "pants", Quantity: 1},
+	}
+
+	if err := bm.ValidateMixAndMatchSelection(bundle.ID, selection); err != nil {
+		t.Errorf("Expected selection to be valid without a checker, got %v", err)
+	}
+
+	bm.SetInventoryChecker(fakeInventory{"pants-1": true})
+	if err := bm.ValidateMixAndMatchSelection(bundle.ID, selection); err == nil {
+		t.Error("Expected error for out-of-stock item")
+	}
+
+	bm.SetInventoryChecker(fakeInventory{})
+	if err := bm.ValidateMixAndMatchSelection(bundle.ID, selection[:1]); err == nil {
+		t.Error("Expected error for too few items")
+	}
+
+	invalidCategory := append([]PricingItem{{ID: "hat-1", Category: "hats", Quantity: 1}}, selection...)
+	if err := bm.ValidateMixAndMatchSelection(bundle.ID, invalidCategory); err == nil {
+		t.Error("Expected error for category outside the bundle")
+	}
+
+	if err := bm.ValidateMixAndMatchSelection("missing", selection); err == nil {
+		t.Error("Expected error for unknown bundle")
+	}
+}
+
+func TestVariantAssignerStableBucketing(t *testing.T) {
+	assigner := NewVariantAssigner("bundle-recs", []string{"control", "treatment"})
+
+	counts := map[string]int{}
+	for i := 0; i < 200; i++ {
+		customerID := fmt.Sprintf("customer-%d", i)
+		variant := assigner.Assign(customerID)
+		for j := 0; j < 3; j++ {
+			if again := assigner.Assign(customerID); again != variant {
+				t.Fatalf("Expected stable variant %s for %s, got %s", variant, customerID, again)
+			}
+		}
+		counts[variant]++
+	}
+
+	if counts["control"] == 0 || counts["treatment"] == 0 {
+		t.Errorf("Expected customers in both variants, got %v", counts)
+	}
+	if assigner.Assign("") != "" {
+		t.Error("Expected no variant for an anonymous customer")
+	}
+	if NewVariantAssigner("empty", nil).Assign("customer-1") != "" {
+		t.Error("Expected no variant without variants")
+	}
+}
+
+func TestGenerateBundleRecommendationsVariant(t *testing.T) {
+	items := []PricingItem{
+		{ID: "laptop", BasePrice: 30.0, Quantity: 1},
+		{ID: "mouse", BasePrice: 30.0, Quantity: 1},
+	}
+
+	bm := NewBundleManager()
+	bm.bundles = append(bm.bundles, createTestInventoryBundle("laptop_mouse", "laptop", "mouse"))
+
+	assigner := NewVariantAssigner("bundle-recs", []string{"control", "treatment"})
+	bm.SetVariantAssigner(assigner)
+
+	customer := Customer{ID: "customer-42"}
+	expected := assigner.Assign(customer.ID)
+
+	for i := 0; i < 3; i++ {
+		recommendations, err := bm.GenerateBundleRecommendations(items, customer, PricingContext{}, 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(recommendations) == 0 {
+			t.Fatal("Expected recommendations")
+		}
+		for _, recommendation := range recommendations {
+			if recommendation.ExperimentID != "bundle-recs" || recommendation.Variant != expected {
+				t.Errorf("Expected experiment bundle-recs variant %s, got %s %s", expected, recommendation.ExperimentID, recommendation.Variant)
+			}
+		}
+	}
+
+	bm.SetVariantAssigner(nil)
+	recommendations, _ := bm.GenerateBundleRecommendations(items, customer, PricingContext{}, 0)
+	for _, recommendation := range recommendations {
+		if recommendation.Variant != "" {
+			t.Errorf("Expected untagged recommendation without an assigner, got %s", recommendation.Variant)
+		}
+	}
+}
+
+func TestBundleMatchScoreModes(t *testing.T) {
+	bundle := createTestInventoryBundle("desk_setup", "laptop", "keyboard")
+	bundle.Items[0].Category = "computers"
+	bundle.Items[1].Category = "keyboards"
+
+	items := []PricingItem{
+		{ID: "laptop", Category: "computers", BasePrice: 30.0, Quantity: 1},
+		{ID: "kb-mech-01", Category: "keyboards", BasePrice: 30.0, Quantity: 1},
+	}
+
+	bm := NewBundleManager()
+	tests := []struct {
+		name     string
+		matching BundleMatching
+		expected float64
+	}{
+		{"StrictID", BundleMatching{Mode: BundleMatchByID}, 0.5},
+		{"Category", BundleMatching{Mode: BundleMatchByCategory}, 1.0},
+		{"Substitution", BundleMatching{Mode: BundleMatchBySubstitution, Substitutes: map[string][]string{"keyboard": {"kb-mech-01"}}}, 1.0},
+		{"SubstitutionUnlisted", BundleMatching{Mode: BundleMatchBySubstitution, Substitutes: map[string][]string{"keyboard": {"kb-wireless-02"}}}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if score := bm.calculateBundleMatchScore(items, bundle, tt.matching); score != tt.expected {
+				t.Errorf("Expected score %.2f, got %.2f", tt.expected, score)
+			}
+		})
+	}
+
+	t.Run("CartItemMatchesOnce", func(t *testing.T) {
+		twoKeyboards := createTestInventoryBundle("dual", "keyboard", "keyboard-2")
+		twoKeyboards.Items[0].Category = "keyboards"
+		twoKeyboards.Items[1].Category = "keyboards"
+		score := bm.calculateBundleMatchScore(items[1:], twoKeyboards, BundleMatching{Mode: BundleMatchByCategory})
+		if score != 0.5 {
+			t.Errorf("Expected one keyboard to satisfy one bundle item, got score %.2f", score)
+		}
+	})
+
+	t.Run("Recommendations", func(t *testing.T) {
+		bm.bundles = append(bm.bundles, bundle)
+		recommended := func(matching BundleMatching) bool {
+			recommendations, err := bm.GenerateBundleRecommendationsWithMatching(items, Customer{}, PricingContext{}, matching, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for _, recommendation := range recommendations {
+				if recommendation.BundleID == bundle.ID {
+					return true
+				}
+			}
+			return false
+		}
+
+		if recommended(BundleMatching{Mode: BundleMatchByID}) {
+			t.Error("Expected no recommendation with strict ID matching")
+		}
+		if !recommended(BundleMatching{Mode: BundleMatchByCategory}) {
+			t.Error("Expected a recommendation with category matching")
+		}
+	})
+}
+
+func TestBundleManagerDeterministicClockAndRand(t *testing.T) {
+	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	newManager := func() *BundleManager {
+		bm := NewBundleManager()
+		bm.SetClock(func() time.Time { return fixed })
+		bm.SetRandSource(mathrand.New(mathrand.NewSource(7)))
+		return bm
+	}
+	pricing := BundlePricing{Type: "percentage", Value: 10.0}
+	createBundles := func(bm *BundleManager) []*Bundle {
+		bundles := []*Bundle{}
+		for _, pair := range [][]string{{"laptop", "mouse"}, {"laptop", "keyboard"}} {
+			items := []PricingItem{
+				{ID: pair[0], BasePrice: 30.0, Quantity: 1},
+				{ID: pair[1], BasePrice: 30.0, Quantity: 1},
+			}
+			bundle, err := bm.CreateBundle(pair[0]+"+"+pair[1], "", BundleTypeFixed, items, pricing)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			bundles = append(bundles, bundle)
+		}
+		return bundles
+	}
+
+	first, second := newManager(), newManager()
+	firstBundles, secondBundles := createBundles(first), createBundles(second)
+
+	t.Run("StableIDs", func(t *testing.T) {
+		prefix := fmt.Sprintf("bundle_%d_", fixed.Unix())
+		for i := range firstBundles {
+			if firstBundles[i].ID != secondBundles[i].ID {
+				t.Errorf("Expected identical IDs across seeded managers, got %s and %s", firstBundles[i].ID, secondBundles[i].ID)
+			}
+			if !strings.HasPrefix(firstBundles[i].ID, prefix) {
+				t.Errorf("Expected ID with prefix %s, got %s", prefix, firstBundles[i].ID)
+			}
+			if !firstBundles[i].ValidFrom.Equal(fixed) {
+				t.Errorf("Expected ValidFrom from the fixed clock, got %v", firstBundles[i].ValidFrom)
+			}
+		}
+		if firstBundles[0].ID == firstBundles[1].ID {
+			t.Errorf("Expected bundles created in the same second to get distinct IDs, got %s twice", firstBundles[0].ID)
+		}
+		if active := first.GetActiveBundles(); len(active) != 2 {
+			t.Errorf("Expected bundles valid from the fixed clock to be active, got %d", len(active))
+		}
+	})
+
+	t.Run("StableOrdering", func(t *testing.T) {
+		cart := []PricingItem{
+			{ID: "laptop", BasePrice: 30.0, Quantity: 1},
+			{ID: "mouse", BasePrice: 30.0, Quantity: 1},
+			{ID: "keyboard", BasePrice: 30.0, Quantity: 1},
+		}
+
+		for run := 0; run < 3; run++ {
+			recommendations, err := first.GenerateBundleRecommendations(cart, Customer{}, PricingContext{}, 0)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(recommendations) != 2 {
+				t.Fatalf("Expected 2 recommendations, got %d", len(recommendations))
+			}
+			// Equal priority and confidence fall back to bundle ID order
+			if recommendations[0].BundleID >= recommendations[1].BundleID {
+				t.Errorf("Run %d: expected ties ordered by bundle ID, got %s before %s", run, recommendations[0].BundleID, recommendations[1].BundleID)
+			}
+		}
+	})
+
+	t.Run("DefaultsRestored", func(t *testing.T) {
+		bm := NewBundleManager()
+		bm.SetClock(nil)
+		bm.SetRandSource(nil)
+		bundle, err := bm.CreateBundle("Defaults", "", BundleTypeFixed, []PricingItem{{ID: "a", BasePrice: 10.0, Quantity: 1}}, pricing)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if time.Since(bundle.ValidFrom) > time.Minute || !strings.HasPrefix(bundle.ID, "bundle_") {
+			t.Errorf("Expected default clock and random source, got ID %s valid from %v", bundle.ID, bundle.ValidFrom)
+		}
+	})
+}
+
+func TestBundleManagerSweepExpired(t *testing.T) {
+	fixed := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	bm := NewBundleManager()
+	bm.SetClock(func() time.Time { return fixed })
+	pricing := BundlePricing{Type: "percentage", Value: 10.0}
+	for _, name := range []string{"expired", "ends-now", "active"} {
+		items := []PricingItem{
+			{ID: name + "-a", BasePrice: 30.0, Quantity: 1},
+			{ID: name + "-b", BasePrice: 30.0, Quantity: 1},
+		}
+		if _, err := bm.CreateBundle(name, "", BundleTypeFixed, items, pricing); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	bm.bundles[0].ValidUntil = fixed.Add(-time.Hour)
+	bm.bundles[1].ValidUntil = fixed
+	bm.bundles[2].ValidUntil = fixed.Add(time.Hour)
+
+	notified := []string{}
+	bm.SetOnExpire(func(bundle Bundle) {
+		notified = append(notified, bundle.Name)
+	})
+
+	expired := bm.SweepExpired(fixed)
+
+	if len(expired) != 2 || expired[0] != bm.bundles[0].ID || expired[1] != bm.bundles[1].ID {
+		t.Errorf("Expected the two past-due bundles to expire, got %v", expired)
+	}
+	if len(notified) != 2 || notified[0] != "expired" || notified[1] != "ends-now" {
+		t.Errorf("Expected OnExpire for each expired bundle, got %v", notified)
+	}
+	for i, bundle := range bm.GetBundles() {
+		if bundle.IsActive != (i == 2) {
+			t.Errorf("Bundle %s: expected IsActive=%v, got %v", bundle.Name, i == 2, bundle.IsActive)
+		}
+	}
+	if again := bm.SweepExpired(fixed); len(again) != 0 {
+		t.Errorf("Expected a repeated sweep to find nothing, got %v", again)
+	}
+}
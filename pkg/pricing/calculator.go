@@ -50,10 +50,16 @@
 package pricing
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // Calculator is the main pricing calculation engine that handles comprehensive pricing strategies.
@@ -92,13 +98,48 @@ import (
 //		CompetitorCount: 5,
 //	}
 //	calc.UpdateMarketData("product-1", marketData)
+//
+// Rule usage counters for rules with MaxUses are shared across calls and safe for
+// concurrent use. Calculate only reads them; Apply records the uses of a placed
+// order. See RemainingUses. Rules may be added with AddRule while
+// calculations run.
 type Calculator struct {
+	rulesMu         sync.RWMutex
 	rules           []PricingRule
 	bundles         []Bundle
 	tierPricing     []TierPricing
 	dynamicConfigs  []DynamicPricingConfig
+	priceLists      []PriceList
+	ruleFuncs       []RuleFunc
 	marketData      map[string]MarketData
 	analytics       map[string]PricingAnalytics
+
+	usageMu  sync.Mutex
+	ruleUses map[string]int // uses per rule ID, for rules with MaxUses
+}
+
+// UnlimitedUses is returned by RemainingUses for rules without a usage limit.
+const UnlimitedUses = -1
+
+// RuleFunc is a plugin hook for pricing logic that cannot be expressed as a
+// declarative PricingRule. Plugins registered with AddRuleFunc run for every item
+// after the declarative rules, in registration order, each receiving the price left
+// by the previous step. Returning a nil applied rule leaves the price unchanged.
+//
+// Example:
+//
+//	type oversizeSurcharge struct{}
+//
+//	func (oversizeSurcharge) Apply(item pricing.PricingItem, price float64, ctx pricing.PricingContext) (float64, *pricing.AppliedPricingRule) {
+//		if item.Weight <= 30 {
+//			return price, nil
+//		}
+//		return price + 15, &pricing.AppliedPricingRule{RuleID: "oversize", Name: "Oversize Surcharge", Adjustment: 15}
+//	}
+//
+//	calc.AddRuleFunc(oversizeSurcharge{})
+type RuleFunc interface {
+	Apply(item PricingItem, price float64, ctx PricingContext) (newPrice float64, applied *AppliedPricingRule)
 }
 
 // NewCalculator creates a new pricing calculator instance.
@@ -124,8 +165,10 @@ func NewCalculator() *Calculator {
 		bundles:        make([]Bundle, 0),
 		tierPricing:    make([]TierPricing, 0),
 		dynamicConfigs: make([]DynamicPricingConfig, 0),
+		priceLists:     make([]PriceList, 0),
 		marketData:     make(map[string]MarketData),
 		analytics:      make(map[string]PricingAnalytics),
+		ruleUses:       make(map[string]int),
 	}
 }
 
@@ -147,7 +190,9 @@ func NewCalculator() *Calculator {
 //
 // Returns:
 //   - *PricingResult: Comprehensive pricing result with calculated prices and recommendations
-//   - error: Error if calculation fails or input is invalid
+//   - error: Error if calculation fails or input is invalid, or one wrapping
+//     ErrInsufficientInventory when Options.RefuseInsufficientInventory is set and
+//     an item's quantity exceeds its stock
 //
 // Example:
 //
@@ -200,18 +245,26 @@ func (c *Calculator) Calculate(input PricingInput) (*PricingResult, error) {
 	}
 
 	// Merge rules from input and calculator
-	allRules := append(c.rules, input.Rules...)
+	allRules := append(c.rulesSnapshot(), input.Rules...)
 	allBundles := append(c.bundles, input.Bundles...)
 	allTierPricing := append(c.tierPricing, input.TierPricing...)
 
+	// Drop rules whose cart-level conditions are not met
+	allRules = c.filterCartRules(allRules, input.Items)
+
 	// Calculate pricing for each item
 	for _, item := range input.Items {
 		pricedItem, err := c.calculateItemPricing(item, input.Customer, input.Context, allRules, allTierPricing, input.Options)
+		if errors.Is(err, ErrInsufficientInventory) {
+			// Refusing must not quietly drop the item and price the rest of the order
+			return nil, err
+		}
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Error pricing item %s: %v", item.ID, err))
 			continue
 		}
 		result.Items = append(result.Items, *pricedItem)
+		result.Warnings = append(result.Warnings, pricedItem.Warnings...)
 	}
 
 	// Calculate bundle pricing if enabled
@@ -231,8 +284,67 @@ func (c *Calculator) Calculate(input PricingInput) (*PricingResult, error) {
 	return result, nil
 }
 
+// Apply prices an order like Calculate and records one use of every rule with
+// MaxUses that changed the price of at least one of its items, however many
+// items that is. Call it when the order is placed; Calculate on its own never
+// uses up a rule's budget, so quotes and cart previews can be repeated freely.
+//
+// The uses are claimed together under one lock. If a concurrent order took the
+// last use of a rule in the meantime, the order is priced again without that
+// rule, so a budget is never exceeded and the returned prices always match the
+// uses recorded.
+//
+// Parameters:
+//   - input: Complete pricing input with items, customer, context, and options
+//
+// Returns:
+//   - *PricingResult: Pricing result whose limited rules have been charged one use
+//   - error: Error if calculation fails or input is invalid
+//
+// Example:
+//
+//	quote, _ := calc.Calculate(input) // shown in the cart, no use recorded
+//	order, err := calc.Apply(input)   // at checkout, "launch-promo" loses one use
+//	if err != nil {
+//		return err
+//	}
+func (c *Calculator) Apply(input PricingInput) (*PricingResult, error) {
+	maxUses := map[string]int{}
+	for _, rule := range append(c.rulesSnapshot(), input.Rules...) {
+		if rule.MaxUses > 0 {
+			maxUses[rule.ID] = rule.MaxUses
+		}
+	}
+
+	for {
+		result, err := c.Calculate(input)
+		if err != nil {
+			return nil, err
+		}
+
+		used := map[string]int{}
+		for _, item := range result.Items {
+			for _, applied := range item.AppliedRules {
+				if limit, limited := maxUses[applied.RuleID]; limited {
+					used[applied.RuleID] = limit
+				}
+			}
+		}
+		if c.claimRuleUses(used) {
+			return result, nil
+		}
+		// Another order took the last use of a rule; price again without it
+	}
+}
+
 // calculateItemPricing calculates comprehensive pricing for a single item.
-// Applies dynamic pricing, tier pricing, and rule-based adjustments in sequence.
+// Applies dynamic pricing, tier pricing, rule-based adjustments, and registered
+// RuleFunc plugins in sequence, starting from the matching price list entry for the context, or BasePrice when none exists.
+// A quantity above a known InventoryLevel adds a warning, and with CapQuantityToInventory
+// the item is priced as if only the units in stock were ordered. With
+// RefuseInsufficientInventory the item is not priced and an error wrapping
+// ErrInsufficientInventory is returned instead. Backordered items are expected to
+// exceed stock and are not flagged.
 //
 // Parameters:
 //   - item: The item to price
@@ -244,8 +356,14 @@ func (c *Calculator) Calculate(input PricingInput) (*PricingResult, error) {
 //
 // Returns:
 //   - *PricedItem: Fully calculated item with final price and applied adjustments
-//   - error: Error if pricing calculation fails
+//   - error: Error if pricing calculation fails or stock is short under RefuseInsufficientInventory
 func (c *Calculator) calculateItemPricing(item PricingItem, customer Customer, context PricingContext, rules []PricingRule, tierPricing []TierPricing, options PricingOptions) (*PricedItem, error) {
+	// Prefer an explicit regional price over the default base price
+	priceList, listPrice, hasListPrice := c.findPriceListPrice(item.ID, context)
+	if hasListPrice {
+		item.BasePrice = listPrice
+	}
+
 	pricedItem := &PricedItem{
 		ItemID:        item.ID,
 		Name:          item.Name,
@@ -257,6 +375,25 @@ func (c *Calculator) calculateItemPricing(item PricingItem, customer Customer, c
 		AppliedRules:  make([]AppliedPricingRule, 0),
 		Metadata:      make(map[string]interface{}),
 	}
+	if hasListPrice {
+		pricedItem.Metadata["price_list"] = priceList.ID
+	}
+
+	// Warn when stock cannot cover the order, optionally pricing only what is in stock
+	// or refusing to price it
+	if item.InventoryLevel > 0 && item.Quantity > item.InventoryLevel && !item.Backordered {
+		if options.RefuseInsufficientInventory {
+			return nil, fmt.Errorf("%w: item %s requested quantity %d exceeds available inventory %d", ErrInsufficientInventory, item.ID, item.Quantity, item.InventoryLevel)
+		}
+		warning := fmt.Sprintf("Item %s: requested quantity %d exceeds available inventory %d", item.ID, item.Quantity, item.InventoryLevel)
+		if options.CapQuantityToInventory {
+			warning += fmt.Sprintf("; priced quantity capped to %d", item.InventoryLevel)
+			pricedItem.Metadata["requested_quantity"] = item.Quantity
+			item.Quantity = item.InventoryLevel
+			pricedItem.Quantity = item.Quantity
+		}
+		pricedItem.Warnings = append(pricedItem.Warnings, warning)
+	}
 
 	// Apply dynamic pricing if configured
 	if dynamicPrice := c.calculateDynamicPricing(item, context); dynamicPrice > 0 {
@@ -277,32 +414,124 @@ func (c *Calculator) calculateItemPricing(item PricingItem, customer Customer, c
 	applicableRules := c.getApplicableRules(item, customer, context, rules)
 	for _, rule := range applicableRules {
 		adjustedPrice, appliedRule := c.applyPricingRule(pricedItem.FinalPrice, rule, item, customer)
+		if rule.MaxUses > 0 && (appliedRule == nil || adjustedPrice == pricedItem.FinalPrice) {
+			// A limited rule that changes nothing is not reported, so Apply does not charge a use
+			continue
+		}
 		if appliedRule != nil {
 			pricedItem.FinalPrice = adjustedPrice
 			pricedItem.AppliedRules = append(pricedItem.AppliedRules, *appliedRule)
 		}
 	}
 
-	// Apply rounding
-	pricedItem.FinalPrice = c.roundPrice(pricedItem.FinalPrice, options.RoundingMode, options.RoundingPrecision)
+	// Apply plugin rules after the declarative ones, in registration order
+	for _, ruleFunc := range c.ruleFuncs {
+		adjustedPrice, appliedRule := ruleFunc.Apply(item, pricedItem.FinalPrice, context)
+		if appliedRule != nil {
+			pricedItem.FinalPrice = math.Max(adjustedPrice, 0)
+			pricedItem.AppliedRules = append(pricedItem.AppliedRules, *appliedRule)
+		}
+	}
+
+	// Apply rounding; charges round down when the policy favors the customer
+	roundingMode := options.RoundingMode
+	if options.CustomerFavorableRounding {
+		roundingMode = "floor"
+	}
+	pricedItem.FinalPrice = c.roundPrice(pricedItem.FinalPrice, roundingMode, options.RoundingPrecision)
 	pricedItem.UnitPrice = pricedItem.FinalPrice
 	pricedItem.TotalPrice = pricedItem.FinalPrice * float64(item.Quantity)
 
+	// Add-ons are charged after discounts and are never discounted
+	c.applyAddOns(pricedItem, item)
+
+	// Flag backorders and the deposit due now
+	if item.Backordered {
+		pricedItem.Backordered = true
+		pricedItem.BackorderLeadDays = item.BackorderLeadDays
+		if options.BackorderDepositPercent > 0 {
+			deposit := pricedItem.FinalPrice * math.Min(options.BackorderDepositPercent, 100) / 100
+			pricedItem.DepositPrice = c.roundPrice(deposit, roundingMode, options.RoundingPrecision)
+		}
+	}
+
 	// Calculate savings
 	pricedItem.Savings = pricedItem.OriginalPrice - pricedItem.FinalPrice
 	if pricedItem.OriginalPrice > 0 {
 		pricedItem.SavingsPercent = (pricedItem.Savings / pricedItem.OriginalPrice) * 100
 	}
 
+	// Compare-at reference for "was/now" display
+	pricedItem.CompareAtPrice = pricedItem.OriginalPrice
+	if item.CompareAtPrice > 0 {
+		pricedItem.CompareAtPrice = item.CompareAtPrice
+	}
+	pricedItem.SavingsVsCompareAt = pricedItem.CompareAtPrice - pricedItem.FinalPrice
+
+	// Compare with the competitor average when market data is known
+	if marketData, exists := c.marketData[item.ID]; exists {
+		pricedItem.CompetitivePosition = competitivePosition(pricedItem.FinalPrice, marketData)
+	}
+
 	// Calculate margin and markup
-	if item.CostPrice > 0 {
+	if item.CostPrice > 0 && pricedItem.FinalPrice > 0 {
 		pricedItem.Margin = ((pricedItem.FinalPrice - item.CostPrice) / pricedItem.FinalPrice) * 100
 		pricedItem.Markup = ((pricedItem.FinalPrice - item.CostPrice) / item.CostPrice) * 100
 	}
 
+	// Round percentages last so displays don't show float noise
+	pricedItem.SavingsPercent = utils.RoundToPercent(pricedItem.SavingsPercent)
+	pricedItem.Margin = utils.RoundToPercent(pricedItem.Margin)
+	pricedItem.Markup = utils.RoundToPercent(pricedItem.Markup)
+
 	return pricedItem, nil
 }
 
+// findPriceListPrice looks up the item's price in the price list that best matches the context.
+// Lists with an empty Region or Currency match any value; a list matching the region
+// outranks one matching only the currency, and one matching both outranks either.
+//
+// Parameters:
+//   - itemID: ID of the item to look up
+//   - context: Pricing context providing Region and Currency
+//
+// Returns:
+//   - PriceList: The price list the price came from
+//   - float64: Unit price from the price list
+//   - bool: True if a matching price list contains the item
+func (c *Calculator) findPriceListPrice(itemID string, context PricingContext) (PriceList, float64, bool) {
+	var best PriceList
+	bestPrice := 0.0
+	bestScore := -1
+
+	for _, list := range c.priceLists {
+		price, exists := list.Prices[itemID]
+		if !exists {
+			continue
+		}
+
+		score := 0
+		if list.Region != "" {
+			if !strings.EqualFold(list.Region, context.Region) {
+				continue
+			}
+			score += 2
+		}
+		if list.Currency != "" {
+			if !strings.EqualFold(list.Currency, context.Currency) {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore {
+			best, bestPrice, bestScore = list, price, score
+		}
+	}
+
+	return best, bestPrice, bestScore >= 0
+}
+
 // calculateDynamicPricing calculates dynamic pricing based on real-time market conditions.
 // Considers demand, inventory levels, competition, time factors, weather, and events.
 //
@@ -313,6 +542,7 @@ func (c *Calculator) calculateItemPricing(item PricingItem, customer Customer, c
 //   - Time: Peak hours, seasonal adjustments
 //   - Weather: Weather-dependent product pricing
 //   - Events: Special event-based pricing
+//   - Backorder: Surcharge for backordered items (replaces the inventory factor for them)
 //
 // Parameters:
 //   - item: Item to calculate dynamic pricing for
@@ -368,6 +598,9 @@ func (c *Calculator) calculateDynamicPricing(item PricingItem, context PricingCo
 			}
 		}
 
+		// Apply competitor strategy
+		adjustedPrice = c.applyCompetitorStrategy(adjustedPrice, config, item)
+
 		// Apply price constraints
 		if config.PriceFloor > 0 && adjustedPrice < config.PriceFloor {
 			adjustedPrice = config.PriceFloor
@@ -396,7 +629,7 @@ func (c *Calculator) calculateDynamicPricing(item PricingItem, context PricingCo
 // Each factor type has its own logic for determining price impact based on current conditions.
 //
 // Supported factor types:
-//   - "demand": Based on market demand levels (high/medium/low)
+//   - "demand": Scaled linearly by market demand index (see demandIndex)
 //   - "inventory": Based on current inventory levels
 //   - "competition": Based on competitor pricing data
 //   - "time": Based on time of day, day of week, season
@@ -414,17 +647,15 @@ func (c *Calculator) calculateFactorImpact(factor PricingFactor, item PricingIte
 	switch factor.Type {
 	case "demand":
 		// Use market data to determine demand impact
+		// Mid demand (0.5) is neutral; 0.0 and 1.0 give the full negative/positive impact
 		if marketData, exists := c.marketData[item.ID]; exists {
-			switch marketData.DemandLevel {
-			case "high":
-				return factor.Impact
-			case "low":
-				return -factor.Impact
-			default:
-				return 0
-			}
+			return factor.Impact * (demandIndex(marketData) - 0.5) * 2
 		}
 	case "inventory":
+		// Backordered items are priced by the backorder factor instead
+		if item.Backordered {
+			return 0
+		}
 		// Adjust based on inventory levels
 		if item.InventoryLevel < 10 {
 			return factor.Impact // Low inventory, increase price
@@ -458,11 +689,124 @@ func (c *Calculator) calculateFactorImpact(factor PricingFactor, item PricingIte
 		if context.Event != "" {
 			return factor.Impact
 		}
+	case "backorder":
+		// Backorder surcharge (or discount), optionally only for long lead times
+		if item.Backordered && float64(item.BackorderLeadDays) >= factor.Threshold {
+			return factor.Impact
+		}
 	}
 
 	return 0
 }
 
+// applyCompetitorStrategy sets the price relative to the competitor average price.
+// The price is returned unchanged when the config has no strategy, the strategy is
+// unknown, or there is no competitor average for the item.
+//
+// Supported strategies:
+//   - "match": Competitor average price
+//   - "undercut": Average reduced by CompetitorMargin percent
+//   - "premium": Average increased by CompetitorMargin percent
+//
+// Parameters:
+//   - price: Price after dynamic factors and rules
+//   - config: Dynamic pricing configuration holding the strategy and margin
+//   - item: Item being priced, used to look up market data
+//
+// Returns:
+//   - float64: Strategy price before floor, ceiling, and change limits
+//
+// Example:
+//
+//	// Competitor average $100, undercut by 5%
+//	config := pricing.DynamicPricingConfig{CompetitorStrategy: "undercut", CompetitorMargin: 5.0}
+//	price := calc.applyCompetitorStrategy(110.00, config, item) // Result: $95.00
+func (c *Calculator) applyCompetitorStrategy(price float64, config DynamicPricingConfig, item PricingItem) float64 {
+	if config.CompetitorStrategy == "" {
+		return price
+	}
+
+	marketData, exists := c.marketData[item.ID]
+	if !exists || marketData.AveragePrice <= 0 {
+		return price
+	}
+
+	switch config.CompetitorStrategy {
+	case "match":
+		return marketData.AveragePrice
+	case "undercut":
+		return marketData.AveragePrice * (1 - config.CompetitorMargin/100)
+	case "premium":
+		return marketData.AveragePrice * (1 + config.CompetitorMargin/100)
+	default:
+		return price
+	}
+}
+
+// competitivePosition compares a final unit price with the competitor average
+// price from market data.
+//
+// Parameters:
+//   - finalPrice: Item's final unit price
+//   - marketData: Market data for the item
+//
+// Returns:
+//   - *CompetitivePosition: Comparison with the market average, or nil when the
+//     average price is unknown
+//
+// Example:
+//
+//	position := competitivePosition(88.00, MarketData{AveragePrice: 100.00})
+//	// position.PercentBelowMarket = 12.0, position.Label = "12% below market"
+func competitivePosition(finalPrice float64, marketData MarketData) *CompetitivePosition {
+	if marketData.AveragePrice <= 0 {
+		return nil
+	}
+
+	percent := utils.RoundToPercent((marketData.AveragePrice - finalPrice) / marketData.AveragePrice * 100)
+	position := &CompetitivePosition{
+		MarketAverage:      marketData.AveragePrice,
+		Difference:         math.Round((finalPrice-marketData.AveragePrice)*100) / 100,
+		PercentBelowMarket: percent,
+	}
+	switch {
+	case math.Abs(percent) < 0.5:
+		position.Position = "at"
+		position.Label = "at market"
+	case percent > 0:
+		position.Position = "below"
+		position.Label = fmt.Sprintf("%.0f%% below market", percent)
+	default:
+		position.Position = "above"
+		position.Label = fmt.Sprintf("%.0f%% above market", -percent)
+	}
+	return position
+}
+
+// demandIndex returns the market demand on a 0.0-1.0 scale.
+// MarketData.DemandIndex is used when set (clamped to the scale); otherwise the
+// string DemandLevel is mapped: "high" to 1.0, "low" to 0.0, anything else to 0.5.
+//
+// Parameters:
+//   - marketData: Market data for the item
+//
+// Returns:
+//   - float64: Demand index where 0.5 is neutral
+func demandIndex(marketData MarketData) float64 {
+	if marketData.DemandIndex != nil {
+		return math.Max(0, math.Min(1, *marketData.DemandIndex))
+	}
+
+	switch marketData.DemandLevel {
+	case "high":
+		return 1.0
+	case "low":
+		return 0.0
+	default:
+		return 0.5
+	}
+}
+
 // calculateTierPricing calculates tier-based pricing for volume discounts.
 // Evaluates quantity-based pricing tiers and applies the best applicable tier.
 //
@@ -560,6 +904,11 @@ func (c *Calculator) calculateBundlePricing(items []PricedItem, bundles []Bundle
 			continue
 		}
 
+		// Every required item must be in the cart before the bundle applies
+		if !c.hasRequiredBundleItems(matchingItems, bundle) {
+			continue
+		}
+
 		// Calculate bundle pricing
 		bundlePrice := c.calculateBundlePrice(matchingItems, bundle)
 		originalPrice := c.calculateOriginalBundlePrice(matchingItems)
@@ -590,6 +939,7 @@ func (c *Calculator) calculateBundlePricing(items []PricedItem, bundles []Bundle
 //
 // Rule filtering criteria:
 //   - Rule must be active and within valid date range
+//   - Rule must not have reached its MaxUses limit
 //   - Item must be in applicable items list (if specified)
 //   - Item must not be in excluded items list
 //   - Customer must be in applicable segments (if specified)
@@ -613,6 +963,11 @@ func (c *Calculator) getApplicableRules(item PricingItem, customer Customer, con
 			continue
 		}
 
+		// Skip rules that have used up their budget
+		if rule.MaxUses > 0 && c.ruleUseCount(rule.ID) >= rule.MaxUses {
+			continue
+		}
+
 		// Check item applicability
 		if len(rule.ApplicableItems) > 0 {
 			found := false
@@ -749,6 +1104,10 @@ func (c *Calculator) applyPricingRule(currentPrice float64, rule PricingRule, it
 //   - "fixed": Fixed amount adjustment (positive or negative)
 //   - "markup": Markup percentage (always positive)
 //   - "markdown": Markdown percentage (always negative)
+//   - "percentage_then_fixed": Value percent off, then FixedValue off the discounted price
+//   - "fixed_then_percentage": FixedValue off, then Value percent off the reduced price
+//
+// Price limits and rounding are applied once, after every step of the adjustment.
 //
 // Parameters:
 //   - price: Original price to adjust
@@ -779,6 +1138,10 @@ func (c *Calculator) applyAdjustment(price float64, adjustment PriceAdjustment)
 		adjustedPrice = price * (1 + adjustment.Value/100)
 	case "markdown":
 		adjustedPrice = price * (1 - adjustment.Value/100)
+	case "percentage_then_fixed":
+		adjustedPrice = price*(1-adjustment.Value/100) - adjustment.FixedValue
+	case "fixed_then_percentage":
+		adjustedPrice = (price - adjustment.FixedValue) * (1 - adjustment.Value/100)
 	}
 
 	// Apply price limits
@@ -1023,6 +1386,164 @@ func (c *Calculator) evaluateTimeCondition(condition PricingCondition, timestamp
 	return true
 }
 
+// claimRuleUses records one use of each of a set of rules with usage limits.
+// Either every use is claimed or none is, and the check and increment happen
+// under a single lock, so concurrent orders can never claim more than a rule's
+// MaxUses uses in total.
+//
+// Parameters:
+//   - maxUses: MaxUses of each rule to claim a use of, by rule ID
+//
+// Returns:
+//   - bool: True if the uses were claimed, false if any rule's limit is already reached
+func (c *Calculator) claimRuleUses(maxUses map[string]int) bool {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+
+	if c.ruleUses == nil {
+		c.ruleUses = make(map[string]int)
+	}
+	for ruleID, limit := range maxUses {
+		if c.ruleUses[ruleID] >= limit {
+			return false
+		}
+	}
+	for ruleID := range maxUses {
+		c.ruleUses[ruleID]++
+	}
+	return true
+}
+
+// ruleUseCount returns how many orders placed with Apply have used a rule.
+func (c *Calculator) ruleUseCount(ruleID string) int {
+	c.usageMu.Lock()
+	defer c.usageMu.Unlock()
+	return c.ruleUses[ruleID]
+}
+
+// RemainingUses returns how many more orders a rule added with AddRule can be applied to.
+// Each order placed with Apply in which the rule changes the price of at least one
+// item counts as one use; Calculate does not count.
+//
+// Parameters:
+//   - ruleID: ID of the rule to look up
+//
+// Returns:
+//   - int: Remaining uses, or UnlimitedUses if the rule has no MaxUses or is not registered
+//
+// Example:
+//
+//	calc.AddRule(pricing.PricingRule{ID: "launch-promo", MaxUses: 100, ...})
+//	remaining := calc.RemainingUses("launch-promo") // 100 before any order is placed
+func (c *Calculator) RemainingUses(ruleID string) int {
+	for _, rule := range c.rulesSnapshot() {
+		if rule.ID != ruleID {
+			continue
+		}
+		if rule.MaxUses <= 0 {
+			return UnlimitedUses
+		}
+		remaining := rule.MaxUses - c.ruleUseCount(ruleID)
+		if remaining < 0 {
+			return 0
+		}
+		return remaining
+	}
+	return UnlimitedUses
+}
+
+// filterCartRules returns the rules whose cart conditions are satisfied by the cart.
+// Rules without cart conditions are always kept. The input slice is not modified.
+//
+// Parameters:
+//   - rules: Candidate pricing rules
+//   - items: All items in the cart
+//
+// Returns:
+//   - []PricingRule: Rules eligible for per-item evaluation
+func (c *Calculator) filterCartRules(rules []PricingRule, items []PricingItem) []PricingRule {
+	filtered := make([]PricingRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.CartConditions) > 0 && !c.evaluateCartConditions(rule.CartConditions, items) {
+			continue
+		}
+		filtered = append(filtered, rule)
+	}
+	return filtered
+}
+
+// evaluateCartConditions evaluates cart-level conditions against all items in the cart.
+// All conditions must be satisfied. Unknown condition types are not satisfied.
+//
+// Supported condition types:
+//   - "contains_items": The cart contains the item IDs listed in Value
+//     ([]string, []interface{} or a single string). Operator "any" requires at
+//     least one of them; any other operator requires all of them.
+//
+// Parameters:
+//   - conditions: Cart conditions to evaluate
+//   - items: All items in the cart
+//
+// Returns:
+//   - bool: True if every condition is satisfied
+//
+// Example:
+//
+//	// Phone and case must both be in the cart
+//	condition := pricing.PricingCondition{
+//		Type: "contains_items",
+//		Value: []string{"phone-001", "case-001"},
+//	}
+//	matched := calc.evaluateCartConditions([]pricing.PricingCondition{condition}, items)
+func (c *Calculator) evaluateCartConditions(conditions []PricingCondition, items []PricingItem) bool {
+	inCart := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.Quantity > 0 {
+			inCart[item.ID] = true
+		}
+	}
+
+	for _, condition := range conditions {
+		if condition.Type != "contains_items" {
+			return false
+		}
+
+		var required []string
+		switch value := condition.Value.(type) {
+		case []string:
+			required = value
+		case []interface{}:
+			for _, v := range value {
+				if str, ok := v.(string); ok {
+					required = append(required, str)
+				}
+			}
+		case string:
+			required = []string{value}
+		}
+		if len(required) == 0 {
+			return false
+		}
+
+		matched := 0
+		for _, itemID := range required {
+			if inCart[itemID] {
+				matched++
+			}
+		}
+
+		if condition.Operator == "any" {
+			if matched == 0 {
+				return false
+			}
+		} else if matched != len(required) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // Helper functions
 
 // isRuleApplicableToItem checks if pricing rule conditions apply to a specific item.
@@ -1057,22 +1578,110 @@ func (c *Calculator) findBundleItems(items []PricedItem, bundle Bundle) []Priced
 	return matchingItems
 }
 
+// hasRequiredBundleItems reports whether every required item of the bundle is present
+// among the matched cart items.
+func (c *Calculator) hasRequiredBundleItems(items []PricedItem, bundle Bundle) bool {
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		present[item.ItemID] = true
+	}
+
+	for _, bundleItem := range bundle.Items {
+		if isOptionalBundleItem(bundleItem) {
+			continue
+		}
+		if !present[bundleItem.ItemID] {
+			return false
+		}
+	}
+	return true
+}
+
+// calculateBundlePrice calculates the price of the selected bundle items.
+// Required items form the base that the bundle pricing ("fixed" or "percentage") is
+// applied to. Optional items only contribute when they are in the cart, and are priced
+// on their own: at BundleItem.BundlePrice per unit if set, otherwise at their final
+// price less BundleItem.Discount percent. Optional add-ons therefore never change the
+// discount base of the bundle itself.
+//
+// With "pay_for_n" pricing the required units are sorted from most to least
+// expensive and grouped into sets of SetSize; only the PayFor most expensive units
+// of each complete set are charged. Units left over from an incomplete set, and
+// every unit of a bundle with fewer units than SetSize, are charged in full.
+//
+// Parameters:
+//   - items: Cart items matched to the bundle (see findBundleItems)
+//   - bundle: Bundle configuration
+//
+// Returns:
+//   - float64: Total price of the selected bundle items
 func (c *Calculator) calculateBundlePrice(items []PricedItem, bundle Bundle) float64 {
-	totalPrice := 0.0
+	bundleItems := make(map[string]BundleItem, len(bundle.Items))
+	for _, bundleItem := range bundle.Items {
+		bundleItems[bundleItem.ItemID] = bundleItem
+	}
+
+	requiredPrice := 0.0
+	optionalPrice := 0.0
+	requiredUnitPrices := make([]float64, 0)
 	for _, item := range items {
-		totalPrice += item.FinalPrice * float64(item.Quantity)
+		bundleItem := bundleItems[item.ItemID]
+		if !isOptionalBundleItem(bundleItem) {
+			requiredPrice += item.FinalPrice * float64(item.Quantity)
+			for i := 0; i < item.Quantity; i++ {
+				requiredUnitPrices = append(requiredUnitPrices, item.FinalPrice)
+			}
+			continue
+		}
+
+		unitPrice := item.FinalPrice
+		if bundleItem.BundlePrice > 0 {
+			unitPrice = bundleItem.BundlePrice
+		} else if bundleItem.Discount > 0 {
+			unitPrice = item.FinalPrice * (1 - bundleItem.Discount/100)
+		}
+		optionalPrice += unitPrice * float64(item.Quantity)
 	}
 
 	switch bundle.Pricing.Type {
 	case "fixed":
-		return bundle.Pricing.Value
+		return bundle.Pricing.Value + optionalPrice
 	case "percentage":
-		return totalPrice * (1 - bundle.Pricing.Value/100)
+		return requiredPrice*(1-bundle.Pricing.Value/100) + optionalPrice
+	case "pay_for_n":
+		return payForNPrice(requiredUnitPrices, bundle.Pricing.PayFor, bundle.Pricing.SetSize) + optionalPrice
 	default:
-		return totalPrice
+		return requiredPrice + optionalPrice
 	}
 }
 
+// payForNPrice charges the payFor most expensive units of every complete set of
+// setSize units. A configuration that frees nothing (payFor below zero or not
+// below setSize) charges every unit.
+func payForNPrice(unitPrices []float64, payFor, setSize int) float64 {
+	if payFor < 0 || setSize <= payFor {
+		return utils.Sum(unitPrices)
+	}
+
+	sorted := append([]float64(nil), unitPrices...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	completeUnits := len(sorted) / setSize * setSize
+	total := 0.0
+	for i, price := range sorted {
+		if i >= completeUnits || i%setSize < payFor {
+			total += price
+		}
+	}
+	return total
+}
+
+// isOptionalBundleItem reports whether a bundle item is an optional add-on.
+// Items not explicitly marked optional are treated as required.
+func isOptionalBundleItem(item BundleItem) bool {
+	return item.IsOptional && !item.IsRequired
+}
+
 func (c *Calculator) calculateOriginalBundlePrice(items []PricedItem) float64 {
 	totalPrice := 0.0
 	for _, item := range items {
@@ -1085,24 +1694,49 @@ func (c *Calculator) roundPrice(price float64, mode string, precision int) float
 	multiplier := math.Pow(10, float64(precision))
 	switch mode {
 	case "floor":
-		return math.Floor(price*multiplier) / multiplier
+		return utils.RoundWithMode(price, precision, utils.RoundDown)
 	case "ceil":
-		return math.Ceil(price*multiplier) / multiplier
+		return utils.RoundWithMode(price, precision, utils.RoundUp)
 	default:
 		return math.Round(price*multiplier) / multiplier
 	}
 }
 
+// applyAddOns adds an item's add-on fees to its total. Fees are charged once per
+// line, or per unit when PerUnit is set, and taxable fees are tracked separately.
+func (c *Calculator) applyAddOns(pricedItem *PricedItem, item PricingItem) {
+	for _, addOn := range item.AddOns {
+		amount := addOn.Amount
+		if addOn.PerUnit {
+			amount *= float64(item.Quantity)
+		}
+
+		pricedItem.AddOns = append(pricedItem.AddOns, addOn)
+		pricedItem.AddOnTotal += amount
+		if addOn.Taxable {
+			pricedItem.TaxableAddOnTotal += amount
+		}
+	}
+
+	pricedItem.TotalPrice += pricedItem.AddOnTotal
+}
+
 func (c *Calculator) calculateTotals(result *PricingResult) {
 	subtotal := 0.0
 	totalSavings := 0.0
+	addOnTotal := 0.0
+	taxableAddOnTotal := 0.0
 
 	for _, item := range result.Items {
 		subtotal += item.TotalPrice
 		totalSavings += item.Savings * float64(item.Quantity)
+		addOnTotal += item.AddOnTotal
+		taxableAddOnTotal += item.TaxableAddOnTotal
 	}
 
 	result.Subtotal = subtotal
+	result.AddOnTotal = addOnTotal
+	result.TaxableAddOnTotal = taxableAddOnTotal
 	result.TotalSavings = totalSavings
 	result.TotalDiscount = totalSavings
 	result.GrandTotal = subtotal
@@ -1127,6 +1761,9 @@ func (c *Calculator) generateRecommendations(items []PricedItem, bundles []Bundl
 	return recommendations
 }
 
+// validateInput rejects items without an ID, with a negative base price, or with a
+// quantity below one. Zero-price items are valid and simply contribute nothing,
+// matching how the discount, coupon, and tax packages treat line items.
 func (c *Calculator) validateInput(input PricingInput) error {
 	if len(input.Items) == 0 {
 		return fmt.Errorf("no items provided")
@@ -1142,6 +1779,11 @@ func (c *Calculator) validateInput(input PricingInput) error {
 		if item.Quantity <= 0 {
 			return fmt.Errorf("item quantity must be positive")
 		}
+		for _, addOn := range item.AddOns {
+			if addOn.Amount < 0 {
+				return fmt.Errorf("add-on amount cannot be negative")
+			}
+		}
 	}
 
 	return nil
@@ -1172,9 +1814,71 @@ func (c *Calculator) validateInput(input PricingInput) error {
 //	}
 //	calc.AddRule(rule)
 func (c *Calculator) AddRule(rule PricingRule) {
+	c.rulesMu.Lock()
+	defer c.rulesMu.Unlock()
+
 	c.rules = append(c.rules, rule)
 }
 
+// rulesSnapshot returns a copy of the rules added with AddRule, taken under the
+// rules lock so callers can iterate it while rules are being added.
+func (c *Calculator) rulesSnapshot() []PricingRule {
+	c.rulesMu.RLock()
+	defer c.rulesMu.RUnlock()
+
+	return append([]PricingRule(nil), c.rules...)
+}
+
+// ValidateRule checks a pricing rule's configuration before it is added.
+// AddRule does not validate, so this is the place to catch a rule that would be
+// rejected or silently never applied at calculation time.
+//
+// Validation checks:
+//   - ID and name are not empty
+//   - The rule has at least one adjustment, each with a type
+//   - Percentage adjustments are between 0 and 100
+//   - ValidFrom is before ValidUntil
+//   - MaxUses is non-negative
+//
+// Parameters:
+//   - rule: The pricing rule to validate
+//
+// Returns:
+//   - error: nil if valid, error describing the first problem otherwise
+//
+// Example:
+//
+//	if err := pricing.ValidateRule(rule); err != nil {
+//		log.Printf("Invalid pricing rule %s: %v", rule.ID, err)
+//	}
+func ValidateRule(rule PricingRule) error {
+	if rule.ID == "" {
+		return fmt.Errorf("rule ID is required")
+	}
+	if rule.Name == "" {
+		return fmt.Errorf("rule name is required")
+	}
+	if len(rule.Adjustments) == 0 {
+		return fmt.Errorf("rule must have at least one adjustment")
+	}
+	for i, adjustment := range rule.Adjustments {
+		if adjustment.Type == "" {
+			return fmt.Errorf("adjustment %d: type is required", i)
+		}
+		if adjustment.Type == "percentage" && (adjustment.Value < 0 || adjustment.Value > 100) {
+			return fmt.Errorf("adjustment %d: percentage must be between 0 and 100", i)
+		}
+	}
+	if !rule.ValidUntil.IsZero() && rule.ValidFrom.After(rule.ValidUntil) {
+		return fmt.Errorf("valid from date must be before valid until date")
+	}
+	if rule.MaxUses < 0 {
+		return fmt.Errorf("max uses cannot be negative")
+	}
+
+	return nil
+}
+
 // AddBundle adds a new bundle configuration to the calculator.
 // Bundles enable cross-sell and upsell opportunities with special pricing.
 //
@@ -1262,6 +1966,46 @@ func (c *Calculator) AddDynamicConfig(config DynamicPricingConfig) {
 	c.dynamicConfigs = append(c.dynamicConfigs, config)
 }
 
+// AddPriceList adds a region and/or currency-specific price list to the calculator.
+// Items found in a price list matching the pricing context are priced from the list
+// instead of their BasePrice.
+//
+// Parameters:
+//   - priceList: The price list to add
+//
+// Example:
+//
+//	// Separate US and EU prices for the same item
+//	calc.AddPriceList(pricing.PriceList{
+//		ID: "us-usd",
+//		Region: "US",
+//		Currency: "USD",
+//		Prices: map[string]float64{"widget-001": 99.00},
+//	})
+//	calc.AddPriceList(pricing.PriceList{
+//		ID: "eu-eur",
+//		Region: "EU",
+//		Currency: "EUR",
+//		Prices: map[string]float64{"widget-001": 89.00},
+//	})
+func (c *Calculator) AddPriceList(priceList PriceList) {
+	c.priceLists = append(c.priceLists, priceList)
+}
+
+// AddRuleFunc registers a pricing plugin for logic the declarative rule schema
+// cannot express. Plugins run after all PricingRules, in the order they were added.
+// Plugins are code rather than configuration, so they are not covered by ConfigHash.
+//
+// Parameters:
+//   - ruleFunc: The plugin to run for every priced item
+//
+// Example:
+//
+//	calc.AddRuleFunc(oversizeSurcharge{})
+func (c *Calculator) AddRuleFunc(ruleFunc RuleFunc) {
+	c.ruleFuncs = append(c.ruleFuncs, ruleFunc)
+}
+
 // UpdateMarketData updates market data used for dynamic pricing calculations.
 // Market data influences pricing factors like demand, competition, and trends.
 //
@@ -1305,4 +2049,62 @@ func (c *Calculator) UpdateMarketData(itemID string, data MarketData) {
 //	calc.UpdateAnalytics("item-001", analytics)
 func (c *Calculator) UpdateAnalytics(itemID string, analytics PricingAnalytics) {
 	c.analytics[itemID] = analytics
-}
\ No newline at end of file
+}
+
+// ConfigHash returns a stable SHA-256 hash of the calculator's effective configuration.
+// The hash covers pricing rules, bundles, tier pricing, dynamic pricing configurations, and price lists,
+// making it suitable as a cache key: when the hash changes, cached pricing results
+// should be invalidated.
+//
+// Each collection is canonicalized before hashing by serializing every entry to JSON
+// and sorting the serialized entries, so calculators configured with the same entries
+// in a different insertion order produce the same hash. Market data and analytics are
+// not part of the configuration and do not affect the hash.
+//
+// Returns:
+//   - string: Hex-encoded SHA-256 hash of the canonical configuration
+//
+// Example:
+//
+//	hash := calc.ConfigHash()
+//	if hash != cachedHash {
+//		cache.Invalidate()
+//		cachedHash = hash
+//	}
+func (c *Calculator) ConfigHash() string {
+	rules := c.rulesSnapshot()
+	sections := []string{
+		"rules:" + canonicalizeEntries(len(rules), func(i int) interface{} { return rules[i] }),
+		"bundles:" + canonicalizeEntries(len(c.bundles), func(i int) interface{} { return c.bundles[i] }),
+		"tiers:" + canonicalizeEntries(len(c.tierPricing), func(i int) interface{} { return c.tierPricing[i] }),
+		"dynamic:" + canonicalizeEntries(len(c.dynamicConfigs), func(i int) interface{} { return c.dynamicConfigs[i] }),
+		"price_lists:" + canonicalizeEntries(len(c.priceLists), func(i int) interface{} { return c.priceLists[i] }),
+	}
+
+	return utils.GenerateChecksum(strings.Join(sections, "\n"))
+}
+
+// canonicalizeEntries serializes n collection entries to JSON and joins them in sorted order.
+// JSON encoding sorts map keys, and sorting the encoded entries removes any dependence
+// on insertion order. Entries that cannot be encoded fall back to their %#v representation.
+//
+// Parameters:
+//   - n: Number of entries in the collection
+//   - entry: Accessor returning the i-th entry
+//
+// Returns:
+//   - string: Canonical serialization of the collection
+func canonicalizeEntries(n int, entry func(i int) interface{}) string {
+	encoded := make([]string, n)
+	for i := 0; i < n; i++ {
+		data, err := json.Marshal(entry(i))
+		if err != nil {
+			encoded[i] = fmt.Sprintf("%#v", entry(i))
+			continue
+		}
+		encoded[i] = string(data)
+	}
+	sort.Strings(encoded)
+
+	return "[" + strings.Join(encoded, ",") + "]"
+}
@@ -1,6 +1,11 @@
 package pricing
 
 import (
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -178,6 +183,463 @@ func TestCalculateItemPricing(t *testing.T) {
 	}
 }
 
+func TestCalculateZeroQuantityAndZeroPrice(t *testing.T) {
+	calc := NewCalculator()
+	context := PricingContext{Timestamp: time.Now(), Channel: "online"}
+
+	_, err := calc.Calculate(PricingInput{
+		Items:   []PricingItem{{ID: "empty", BasePrice: 10.0, Quantity: 0}},
+		Context: context,
+	})
+	if err == nil {
+		t.Error("Expected error for zero-quantity item")
+	}
+
+	result, err := calc.Calculate(PricingInput{
+		Items:   []PricingItem{{ID: "sample", BasePrice: 0, CostPrice: 2.0, Quantity: 1}},
+		Context: context,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error for zero-price item, got: %v", err)
+	}
+	if result.Items[0].FinalPrice != 0 {
+		t.Errorf("Expected final price 0, got %f", result.Items[0].FinalPrice)
+	}
+	if math.IsNaN(result.Items[0].Margin) || math.IsInf(result.Items[0].Margin, 0) {
+		t.Errorf("Expected finite margin, got %f", result.Items[0].Margin)
+	}
+}
+
+func TestCalculateItemPricingRoundsPercentages(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:          "odd-discount",
+		Name:        "Odd Discount",
+		Type:        PricingTypePromo,
+		Strategy:    StrategyFixed,
+		IsActive:    true,
+		Priority:    1,
+		ValidFrom:   time.Now().Add(-time.Hour),
+		ValidUntil:  time.Now().Add(time.Hour),
+		Adjustments: []PriceAdjustment{{Type: "percentage", Value: 12.345}},
+	})
+
+	result, err := calc.Calculate(PricingInput{
+		Items:   []PricingItem{{ID: "item", BasePrice: 3.0, CostPrice: 1.0, Quantity: 1}},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingPrecision: 2},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	item := result.Items[0]
+	if item.FinalPrice != 2.63 {
+		t.Fatalf("Expected final price 2.63, got %v", item.FinalPrice)
+	}
+	if item.SavingsPercent != 12.3333 {
+		t.Errorf("Expected savings percent 12.3333, got %v", item.SavingsPercent)
+	}
+	if item.Margin != 61.9772 {
+		t.Errorf("Expected margin 61.9772, got %v", item.Margin)
+	}
+	if item.Markup != 163 {
+		t.Errorf("Expected markup 163, got %v", item.Markup)
+	}
+}
+
+func TestCalculateItemPricingCustomerFavorableRounding(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:          "odd-discount",
+		Name:        "Odd Discount",
+		Type:        PricingTypePromo,
+		Strategy:    StrategyFixed,
+		IsActive:    true,
+		Priority:    1,
+		ValidFrom:   time.Now().Add(-time.Hour),
+		ValidUntil:  time.Now().Add(time.Hour),
+		Adjustments: []PriceAdjustment{{Type: "percentage", Value: 12.345}},
+	})
+
+	result, err := calc.Calculate(PricingInput{
+		Items:   []PricingItem{{ID: "item", BasePrice: 3.0, Quantity: 1}},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingMode: "ceil", RoundingPrecision: 2, CustomerFavorableRounding: true},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// 2.62965 rounds down to the customer's benefit, overriding the ceil mode
+	if item := result.Items[0]; item.FinalPrice != 2.62 {
+		t.Errorf("Expected final price 2.62, got %v", item.FinalPrice)
+	}
+}
+
+func TestCalculateItemPricingInsufficientInventory(t *testing.T) {
+	tests := []struct {
+		name             string
+		item             PricingItem
+		capQuantity      bool
+		expectedQuantity int
+		expectedTotal    float64
+		expectWarning    bool
+	}{
+		{name: "in stock", item: PricingItem{ID: "item", BasePrice: 10.0, Quantity: 3, InventoryLevel: 5}, expectedQuantity: 3, expectedTotal: 30.0},
+		{name: "short warns", item: PricingItem{ID: "item", BasePrice: 10.0, Quantity: 8, InventoryLevel: 5}, expectedQuantity: 8, expectedTotal: 80.0, expectWarning: true},
+		{name: "short capped", item: PricingItem{ID: "item", BasePrice: 10.0, Quantity: 8, InventoryLevel: 5}, capQuantity: true, expectedQuantity: 5, expectedTotal: 50.0, expectWarning: true},
+		{name: "unknown inventory", item: PricingItem{ID: "item", BasePrice: 10.0, Quantity: 8}, capQuantity: true, expectedQuantity: 8, expectedTotal: 80.0},
+		{name: "backordered", item: PricingItem{ID: "item", BasePrice: 10.0, Quantity: 8, InventoryLevel: 5, Backordered: true}, capQuantity: true, expectedQuantity: 8, expectedTotal: 80.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := NewCalculator()
+			result, err := calc.Calculate(PricingInput{
+				Items:   []PricingItem{tt.item},
+				Context: PricingContext{Timestamp: time.Now()},
+				Options: PricingOptions{RoundingMode: "round", RoundingPrecision: 2, CapQuantityToInventory: tt.capQuantity},
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			item := result.Items[0]
+			if item.Quantity != tt.expectedQuantity || item.TotalPrice != tt.expectedTotal {
+				t.Errorf("Expected quantity %d totaling %.2f, got %d totaling %.2f", tt.expectedQuantity, tt.expectedTotal, item.Quantity, item.TotalPrice)
+			}
+			if tt.expectWarning != (len(result.Warnings) == 1) || len(item.Warnings) != len(result.Warnings) {
+				t.Errorf("Expected warning %v, got item warnings %v and result warnings %v", tt.expectWarning, item.Warnings, result.Warnings)
+			}
+			if tt.capQuantity && tt.expectWarning && item.Metadata["requested_quantity"] != tt.item.Quantity {
+				t.Errorf("Expected requested quantity %d in metadata, got %v", tt.item.Quantity, item.Metadata["requested_quantity"])
+			}
+		})
+	}
+}
+
+func TestCalculateRefusesInsufficientInventory(t *testing.T) {
+	calc := NewCalculator()
+	options := PricingOptions{RoundingMode: "round", RoundingPrecision: 2, CapQuantityToInventory: true, RefuseInsufficientInventory: true}
+
+	_, err := calc.Calculate(PricingInput{
+		Items: []PricingItem{
+			{ID: "in-stock", BasePrice: 5.0, Quantity: 1, InventoryLevel: 10},
+			{ID: "short", BasePrice: 10.0, Quantity: 8, InventoryLevel: 5},
+		},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: options,
+	})
+	if !errors.Is(err, ErrInsufficientInventory) {
+		t.Fatalf("Expected ErrInsufficientInventory, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "short") {
+		t.Errorf("Expected the error to name the short item, got: %v", err)
+	}
+
+	// Backordered items and items within stock are still priced
+	result, err := calc.Calculate(PricingInput{
+		Items: []PricingItem{
+			{ID: "in-stock", BasePrice: 5.0, Quantity: 5, InventoryLevel: 5},
+			{ID: "backordered", BasePrice: 10.0, Quantity: 8, InventoryLevel: 5, Backordered: true},
+		},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: options,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(result.Items) != 2 || len(result.Warnings) != 0 {
+		t.Errorf("Expected both items priced without warnings, got %d items and warnings %v", len(result.Items), result.Warnings)
+	}
+}
+
+func TestCalculateItemPricingCompareAt(t *testing.T) {
+	calc := NewCalculator()
+
+	rules := []PricingRule{
+		{
+			ID:         "sale",
+			Name:       "Sale",
+			Type:       PricingTypePromo,
+			Strategy:   StrategyFixed,
+			IsActive:   true,
+			ValidFrom:  time.Now().Add(-time.Hour),
+			ValidUntil: time.Now().Add(time.Hour),
+			Adjustments: []PriceAdjustment{
+				{Type: "percentage", Value: 10.0},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		compareAt         float64
+		expectedCompareAt float64
+		expectedSavings   float64
+	}{
+		{"compare-at above base price", 150.0, 150.0, 60.0},
+		{"compare-at below final price", 80.0, 80.0, -10.0},
+		{"unset falls back to original price", 0, 100.0, 10.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := PricingItem{
+				ID:             "jacket",
+				BasePrice:      100.0,
+				CompareAtPrice: tt.compareAt,
+				Quantity:       1,
+			}
+
+			pricedItem, err := calc.calculateItemPricing(item, Customer{}, PricingContext{}, rules, []TierPricing{}, PricingOptions{})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if pricedItem.FinalPrice != 90.0 {
+				t.Errorf("Expected final price 90.0, got %f", pricedItem.FinalPrice)
+			}
+			if pricedItem.CompareAtPrice != tt.expectedCompareAt {
+				t.Errorf("Expected compare-at price %f, got %f", tt.expectedCompareAt, pricedItem.CompareAtPrice)
+			}
+			if math.Abs(pricedItem.SavingsVsCompareAt-tt.expectedSavings) > 0.001 {
+				t.Errorf("Expected savings vs compare-at %f, got %f", tt.expectedSavings, pricedItem.SavingsVsCompareAt)
+			}
+			if pricedItem.Savings != 10.0 {
+				t.Errorf("Expected savings vs original price to stay 10.0, got %f", pricedItem.Savings)
+			}
+		})
+	}
+}
+
+func TestCalculatePriceLists(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddPriceList(PriceList{
+		ID:       "us-usd",
+		Region:   "US",
+		Currency: "USD",
+		Prices:   map[string]float64{"widget": 99.00},
+	})
+	calc.AddPriceList(PriceList{
+		ID:       "eu-eur",
+		Region:   "EU",
+		Currency: "EUR",
+		Prices:   map[string]float64{"widget": 89.00},
+	})
+
+	items := []PricingItem{
+		{ID: "widget", Name: "Widget", BasePrice: 120.00, Quantity: 2},
+		{ID: "gadget", Name: "Gadget", BasePrice: 50.00, Quantity: 1},
+	}
+
+	tests := []struct {
+		name          string
+		context       PricingContext
+		widgetPrice   float64
+		widgetList    interface{}
+		expectedTotal float64
+	}{
+		{name: "US price list", context: PricingContext{Region: "US", Currency: "USD"}, widgetPrice: 99.00, widgetList: "us-usd", expectedTotal: 248.00},
+		{name: "EU price list", context: PricingContext{Region: "eu", Currency: "eur"}, widgetPrice: 89.00, widgetList: "eu-eur", expectedTotal: 228.00},
+		{name: "no matching list", context: PricingContext{Region: "APAC", Currency: "SGD"}, widgetPrice: 120.00, widgetList: nil, expectedTotal: 290.00},
+		{name: "region matches but currency differs", context: PricingContext{Region: "EU", Currency: "USD"}, widgetPrice: 120.00, widgetList: nil, expectedTotal: 290.00},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.context.Timestamp = time.Now()
+			result, err := calc.Calculate(PricingInput{Items: items, Context: tt.context})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			widget := result.Items[0]
+			if widget.BasePrice != tt.widgetPrice || widget.FinalPrice != tt.widgetPrice {
+				t.Errorf("Expected widget price %.2f, got base %.2f final %.2f", tt.widgetPrice, widget.BasePrice, widget.FinalPrice)
+			}
+			if widget.Metadata["price_list"] != tt.widgetList {
+				t.Errorf("Expected price list %v, got %v", tt.widgetList, widget.Metadata["price_list"])
+			}
+			if gadget := result.Items[1]; gadget.FinalPrice != 50.00 {
+				t.Errorf("Expected gadget to fall back to base price 50.00, got %.2f", gadget.FinalPrice)
+			}
+			if result.Subtotal != tt.expectedTotal {
+				t.Errorf("Expected subtotal %.2f, got %.2f", tt.expectedTotal, result.Subtotal)
+			}
+		})
+	}
+
+	t.Run("region-specific list outranks currency-only list", func(t *testing.T) {
+		calc := NewCalculator()
+		calc.AddPriceList(PriceList{ID: "usd", Currency: "USD", Prices: map[string]float64{"widget": 105.00}})
+		calc.AddPriceList(PriceList{ID: "us", Region: "US", Prices: map[string]float64{"widget": 99.00}})
+
+		priceList, price, ok := calc.findPriceListPrice("widget", PricingContext{Region: "US", Currency: "USD"})
+		if !ok || priceList.ID != "us" || price != 99.00 {
+			t.Errorf("Expected US list price 99.00, got %s %.2f (found %v)", priceList.ID, price, ok)
+		}
+	})
+}
+
+func TestCalculateItemAddOns(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "twenty-off",
+		Name:       "20% Off",
+		Type:       PricingTypePromo,
+		Strategy:   StrategyFixed,
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-time.Hour),
+		ValidUntil: time.Now().Add(time.Hour),
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 20.0},
+		},
+	})
+
+	input := PricingInput{
+		Items: []PricingItem{
+			{
+				ID:        "scarf",
+				BasePrice: 50.0,
+				Quantity:  2,
+				AddOns: []ItemAddOn{
+					{ID: "gift-wrap", Name: "Gift Wrap", Amount: 5.0, Taxable: true},
+				},
+			},
+			{
+				ID:        "mug",
+				BasePrice: 10.0,
+				Quantity:  3,
+				AddOns: []ItemAddOn{
+					{ID: "handling", Name: "Fragile Handling", Amount: 1.5, PerUnit: true},
+				},
+			},
+		},
+		Options: PricingOptions{RoundingPrecision: 2},
+	}
+
+	result, err := calc.Calculate(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	scarf := result.Items[0]
+	if scarf.FinalPrice != 40.0 {
+		t.Errorf("Expected discounted price 40.0, got %f", scarf.FinalPrice)
+	}
+	// 2 x $40 plus the undiscounted $5 gift wrap
+	if scarf.TotalPrice != 85.0 {
+		t.Errorf("Expected scarf total 85.0, got %f", scarf.TotalPrice)
+	}
+	if scarf.AddOnTotal != 5.0 || scarf.TaxableAddOnTotal != 5.0 {
+		t.Errorf("Expected taxable add-on total 5.0, got %f/%f", scarf.AddOnTotal, scarf.TaxableAddOnTotal)
+	}
+	if scarf.Savings != 10.0 {
+		t.Errorf("Expected add-on to be excluded from savings, got %f", scarf.Savings)
+	}
+
+	mug := result.Items[1]
+	// 3 x $8 plus 3 x $1.50 handling
+	if math.Abs(mug.TotalPrice-28.5) > 0.001 {
+		t.Errorf("Expected mug total 28.5, got %f", mug.TotalPrice)
+	}
+	if mug.TaxableAddOnTotal != 0 {
+		t.Errorf("Expected non-taxable add-on, got %f", mug.TaxableAddOnTotal)
+	}
+
+	if math.Abs(result.Subtotal-113.5) > 0.001 || math.Abs(result.GrandTotal-113.5) > 0.001 {
+		t.Errorf("Expected subtotal and grand total 113.5, got %f/%f", result.Subtotal, result.GrandTotal)
+	}
+	if math.Abs(result.AddOnTotal-9.5) > 0.001 || result.TaxableAddOnTotal != 5.0 {
+		t.Errorf("Expected add-on totals 9.5/5.0, got %f/%f", result.AddOnTotal, result.TaxableAddOnTotal)
+	}
+
+	input.Items[0].AddOns[0].Amount = -5.0
+	if _, err := calc.Calculate(input); err == nil {
+		t.Error("Expected error for negative add-on amount")
+	}
+}
+
+// expressSurcharge is a RuleFunc plugin adding a flat fee to heavy items ordered
+// through the express channel.
+type expressSurcharge struct {
+	fee       float64
+	minWeight float64
+}
+
+func (p expressSurcharge) Apply(item PricingItem, price float64, ctx PricingContext) (float64, *AppliedPricingRule) {
+	if ctx.Channel != "express" || item.Weight < p.minWeight {
+		return price, nil
+	}
+	return price + p.fee, &AppliedPricingRule{
+		RuleID:     "express-heavy",
+		Name:       "Express Heavy Item Surcharge",
+		Type:       "plugin",
+		Adjustment: p.fee,
+	}
+}
+
+func TestCalculateRuleFunc(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "ten-off",
+		Name:       "10% Off",
+		Type:       PricingTypePromo,
+		Strategy:   StrategyFixed,
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-time.Hour),
+		ValidUntil: time.Now().Add(time.Hour),
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 10.0},
+		},
+	})
+	calc.AddRuleFunc(expressSurcharge{fee: 7.5, minWeight: 20})
+
+	items := []PricingItem{
+		{ID: "anvil", BasePrice: 100.0, Quantity: 1, Weight: 40},
+		{ID: "feather", BasePrice: 100.0, Quantity: 1, Weight: 0.1},
+	}
+
+	tests := []struct {
+		name            string
+		channel         string
+		expectedAnvil   float64
+		expectedFeather float64
+	}{
+		{name: "express channel surcharges heavy items", channel: "express", expectedAnvil: 97.5, expectedFeather: 90.0},
+		{name: "other channels are untouched", channel: "online", expectedAnvil: 90.0, expectedFeather: 90.0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := calc.Calculate(PricingInput{
+				Items:   items,
+				Context: PricingContext{Channel: tt.channel, Timestamp: time.Now()},
+				Options: PricingOptions{RoundingPrecision: 2},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			anvil, feather := result.Items[0], result.Items[1]
+			if anvil.FinalPrice != tt.expectedAnvil {
+				t.Errorf("Expected anvil price %f, got %f", tt.expectedAnvil, anvil.FinalPrice)
+			}
+			if feather.FinalPrice != tt.expectedFeather {
+				t.Errorf("Expected feather price %f, got %f", tt.expectedFeather, feather.FinalPrice)
+			}
+
+			// The plugin runs after declarative rules, so its entry comes last
+			last := anvil.AppliedRules[len(anvil.AppliedRules)-1]
+			if tt.channel == "express" && last.RuleID != "express-heavy" {
+				t.Errorf("Expected plugin rule to be applied last, got %+v", anvil.AppliedRules)
+			}
+		})
+	}
+}
+
 func TestCalculateDynamicPricing(t *testing.T) {
 	calc := NewCalculator()
 
@@ -228,6 +690,476 @@ func TestCalculateDynamicPricing(t *testing.T) {
 	}
 }
 
+func TestCalculateCompetitivePosition(t *testing.T) {
+	calc := NewCalculator()
+	calc.UpdateMarketData("deal", MarketData{ItemID: "deal", AveragePrice: 100.0})
+	calc.UpdateMarketData("premium", MarketData{ItemID: "premium", AveragePrice: 40.0})
+	calc.UpdateMarketData("matched", MarketData{ItemID: "matched", AveragePrice: 50.2})
+	calc.UpdateMarketData("no-average", MarketData{ItemID: "no-average", DemandLevel: "high"})
+
+	result, err := calc.Calculate(PricingInput{
+		Items: []PricingItem{
+			{ID: "deal", BasePrice: 88.0, Quantity: 1},
+			{ID: "premium", BasePrice: 50.0, Quantity: 1},
+			{ID: "matched", BasePrice: 50.0, Quantity: 1},
+			{ID: "no-average", BasePrice: 20.0, Quantity: 1},
+			{ID: "unknown", BasePrice: 20.0, Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		index    int
+		position string
+		percent  float64
+		label    string
+	}{
+		{index: 0, position: "below", percent: 12.0, label: "12% below market"},
+		{index: 1, position: "above", percent: -25.0, label: "25% above market"},
+		{index: 2, position: "at", percent: 0.3984, label: "at market"},
+	}
+	for _, tt := range tests {
+		position := result.Items[tt.index].CompetitivePosition
+		if position == nil {
+			t.Fatalf("Expected competitive position for %s", result.Items[tt.index].ItemID)
+		}
+		if position.Position != tt.position || position.PercentBelowMarket != tt.percent || position.Label != tt.label {
+			t.Errorf("%s: expected %s %.2f%% (%q), got %+v", result.Items[tt.index].ItemID, tt.position, tt.percent, tt.label, position)
+		}
+	}
+	if result.Items[0].CompetitivePosition.Difference != -12.0 {
+		t.Errorf("Expected difference -12.00, got %v", result.Items[0].CompetitivePosition.Difference)
+	}
+
+	// Missing market data or average price leaves the position empty
+	for _, item := range result.Items[3:] {
+		if item.CompetitivePosition != nil {
+			t.Errorf("%s: expected no competitive position, got %+v", item.ItemID, item.CompetitivePosition)
+		}
+	}
+}
+
+func TestCalculateDynamicPricingDemandIndex(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddDynamicConfig(DynamicPricingConfig{
+		ID:             "demand-scale",
+		IsActive:       true,
+		MaxPriceChange: 100.0,
+		Factors: []PricingFactor{
+			{Type: "demand", Weight: 100.0, Impact: 0.2, IsActive: true},
+		},
+	})
+
+	item := PricingItem{ID: "item1", BasePrice: 100.0, Quantity: 1, InventoryLevel: 50}
+	context := PricingContext{Timestamp: time.Now()}
+
+	tests := []struct {
+		index    float64
+		expected float64
+	}{
+		{index: 0.0, expected: 80.0},
+		{index: 0.25, expected: 90.0},
+		{index: 0.5, expected: 100.0},
+		{index: 0.75, expected: 110.0},
+		{index: 1.0, expected: 120.0},
+		{index: 1.5, expected: 120.0}, // clamped to 1.0
+	}
+
+	for _, tt := range tests {
+		index := tt.index
+		calc.UpdateMarketData("item1", MarketData{ItemID: "item1", DemandIndex: &index})
+
+		price := calc.calculateDynamicPricing(item, context)
+		if math.Abs(price-tt.expected) > 0.0001 {
+			t.Errorf("Demand index %.2f: expected price %f, got %f", tt.index, tt.expected, price)
+		}
+	}
+
+	// String levels fall back to the ends and middle of the scale
+	levels := map[string]float64{"low": 80.0, "medium": 100.0, "high": 120.0}
+	for level, expected := range levels {
+		calc.UpdateMarketData("item1", MarketData{ItemID: "item1", DemandLevel: level})
+
+		price := calc.calculateDynamicPricing(item, context)
+		if math.Abs(price-expected) > 0.0001 {
+			t.Errorf("Demand level %q: expected price %f, got %f", level, expected, price)
+		}
+	}
+}
+
+func TestCalculateDynamicPricingCompetitorStrategy(t *testing.T) {
+	item := PricingItem{ID: "item1", BasePrice: 110.0, Quantity: 1, InventoryLevel: 50}
+	context := PricingContext{Timestamp: time.Now()}
+
+	tests := []struct {
+		name     string
+		config   DynamicPricingConfig
+		expected float64
+	}{
+		{
+			name:     "match",
+			config:   DynamicPricingConfig{CompetitorStrategy: "match", MaxPriceChange: 50.0},
+			expected: 100.0,
+		},
+		{
+			name:     "undercut",
+			config:   DynamicPricingConfig{CompetitorStrategy: "undercut", CompetitorMargin: 5.0, MaxPriceChange: 50.0},
+			expected: 95.0,
+		},
+		{
+			name:     "premium",
+			config:   DynamicPricingConfig{CompetitorStrategy: "premium", CompetitorMargin: 10.0, MaxPriceChange: 50.0},
+			expected: 110.0,
+		},
+		{
+			name:     "undercut clamped by price floor",
+			config:   DynamicPricingConfig{CompetitorStrategy: "undercut", CompetitorMargin: 20.0, PriceFloor: 90.0, MaxPriceChange: 50.0},
+			expected: 90.0,
+		},
+		{
+			name:     "premium clamped by price ceiling",
+			config:   DynamicPricingConfig{CompetitorStrategy: "premium", CompetitorMargin: 30.0, PriceCeiling: 120.0, MaxPriceChange: 50.0},
+			expected: 120.0,
+		},
+		{
+			name:     "match clamped by max price change",
+			config:   DynamicPricingConfig{CompetitorStrategy: "match", MaxPriceChange: 5.0},
+			expected: 104.5, // 110 reduced by at most 5%
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := NewCalculator()
+			tt.config.ID = "competitor-" + tt.name
+			tt.config.IsActive = true
+			calc.AddDynamicConfig(tt.config)
+			calc.UpdateMarketData("item1", MarketData{ItemID: "item1", AveragePrice: 100.0})
+
+			price := calc.calculateDynamicPricing(item, context)
+			if math.Abs(price-tt.expected) > 0.0001 {
+				t.Errorf("Expected price %f, got %f", tt.expected, price)
+			}
+		})
+	}
+}
+
+func TestCalculateDynamicPricingBackorder(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddDynamicConfig(DynamicPricingConfig{
+		ID:             "backorder-surcharge",
+		IsActive:       true,
+		MaxPriceChange: 50.0,
+		Factors: []PricingFactor{
+			{Type: "backorder", Weight: 100.0, Impact: 0.1, Threshold: 14, IsActive: true},
+			{Type: "inventory", Weight: 100.0, Impact: 0.2, IsActive: true},
+		},
+	})
+	context := PricingContext{Timestamp: time.Now()}
+
+	tests := []struct {
+		name     string
+		item     PricingItem
+		expected float64
+	}{
+		{
+			name:     "in stock with low inventory",
+			item:     PricingItem{ID: "item1", BasePrice: 100.0, Quantity: 1, InventoryLevel: 5},
+			expected: 120.0,
+		},
+		{
+			name:     "backordered with long lead time",
+			item:     PricingItem{ID: "item1", BasePrice: 100.0, Quantity: 1, Backordered: true, BackorderLeadDays: 21},
+			expected: 110.0, // inventory factor is skipped for backorders
+		},
+		{
+			name:     "backordered below lead time threshold",
+			item:     PricingItem{ID: "item1", BasePrice: 100.0, Quantity: 1, Backordered: true, BackorderLeadDays: 7},
+			expected: 100.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := calc.calculateDynamicPricing(tt.item, context)
+			if math.Abs(price-tt.expected) > 0.0001 {
+				t.Errorf("Expected price %f, got %f", tt.expected, price)
+			}
+		})
+	}
+}
+
+func TestCalculateBackorderDeposit(t *testing.T) {
+	calc := NewCalculator()
+	input := PricingInput{
+		Items: []PricingItem{
+			{ID: "sofa", BasePrice: 800.0, Quantity: 1, Backordered: true, BackorderLeadDays: 30},
+			{ID: "lamp", BasePrice: 60.0, Quantity: 2},
+		},
+		Options: PricingOptions{RoundingPrecision: 2, BackorderDepositPercent: 25.0},
+	}
+
+	result, err := calc.Calculate(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sofa := result.Items[0]
+	if !sofa.Backordered || sofa.BackorderLeadDays != 30 {
+		t.Errorf("Expected sofa to be flagged as backordered for 30 days, got %v/%d", sofa.Backordered, sofa.BackorderLeadDays)
+	}
+	if sofa.DepositPrice != 200.0 {
+		t.Errorf("Expected deposit price 200.0, got %f", sofa.DepositPrice)
+	}
+	if sofa.FinalPrice != 800.0 {
+		t.Errorf("Expected final price to stay 800.0, got %f", sofa.FinalPrice)
+	}
+
+	lamp := result.Items[1]
+	if lamp.Backordered || lamp.DepositPrice != 0 {
+		t.Errorf("Expected in-stock lamp to have no deposit, got %v/%f", lamp.Backordered, lamp.DepositPrice)
+	}
+}
+
+func TestCalculateCartConditions(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:              "phone-case-combo",
+		Name:            "Case discount with phone",
+		IsActive:        true,
+		ValidFrom:       time.Now().Add(-24 * time.Hour),
+		ValidUntil:      time.Now().Add(24 * time.Hour),
+		ApplicableItems: []string{"case-001"},
+		CartConditions: []PricingCondition{
+			{Type: "contains_items", Value: []string{"phone-001", "case-001"}},
+		},
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 25.0},
+		},
+	})
+
+	phone := PricingItem{ID: "phone-001", Name: "Phone", Category: "phones", BasePrice: 800.0, Quantity: 1}
+	phoneCase := PricingItem{ID: "case-001", Name: "Case", Category: "accessories", BasePrice: 40.0, Quantity: 1}
+
+	findItem := func(result *PricingResult, id string) PricedItem {
+		for _, item := range result.Items {
+			if item.ItemID == id {
+				return item
+			}
+		}
+		t.Fatalf("Item %s not found in result", id)
+		return PricedItem{}
+	}
+
+	t.Run("both items present", func(t *testing.T) {
+		result, err := calc.Calculate(PricingInput{
+			Items:   []PricingItem{phone, phoneCase},
+			Context: PricingContext{Timestamp: time.Now()},
+			Options: PricingOptions{RoundingPrecision: 2},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if price := findItem(result, "case-001").FinalPrice; price != 30.0 {
+			t.Errorf("Expected discounted case price 30.0, got %f", price)
+		}
+		if price := findItem(result, "phone-001").FinalPrice; price != 800.0 {
+			t.Errorf("Expected phone price unchanged at 800.0, got %f", price)
+		}
+	})
+
+	t.Run("required item missing", func(t *testing.T) {
+		result, err := calc.Calculate(PricingInput{
+			Items:   []PricingItem{phoneCase},
+			Context: PricingContext{Timestamp: time.Now()},
+			Options: PricingOptions{RoundingPrecision: 2},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if price := findItem(result, "case-001").FinalPrice; price != 40.0 {
+			t.Errorf("Expected full case price 40.0, got %f", price)
+		}
+	})
+
+	t.Run("any operator", func(t *testing.T) {
+		conditions := []PricingCondition{
+			{Type: "contains_items", Operator: "any", Value: []interface{}{"phone-001", "tablet-001"}},
+		}
+		if !calc.evaluateCartConditions(conditions, []PricingItem{phone}) {
+			t.Error("Expected any-of condition to match")
+		}
+		if calc.evaluateCartConditions(conditions, []PricingItem{phoneCase}) {
+			t.Error("Expected any-of condition not to match")
+		}
+	})
+}
+
+func TestPricingRuleMaxUses(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "launch-promo",
+		Name:       "Launch Promo",
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-24 * time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+		MaxUses:    2,
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 10.0},
+		},
+	})
+
+	if remaining := calc.RemainingUses("launch-promo"); remaining != 2 {
+		t.Errorf("Expected 2 remaining uses, got %d", remaining)
+	}
+
+	input := PricingInput{
+		Items:   []PricingItem{{ID: "item1", Name: "Item", Category: "general", BasePrice: 100.0, Quantity: 1}},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingPrecision: 2},
+	}
+
+	expectedPrices := []float64{90.0, 90.0, 100.0}
+	for i, expected := range expectedPrices {
+		result, err := calc.Apply(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if price := result.Items[0].FinalPrice; price != expected {
+			t.Errorf("Calculation %d: expected price %f, got %f", i+1, expected, price)
+		}
+	}
+
+	if remaining := calc.RemainingUses("launch-promo"); remaining != 0 {
+		t.Errorf("Expected 0 remaining uses, got %d", remaining)
+	}
+
+	if remaining := calc.RemainingUses("unknown"); remaining != UnlimitedUses {
+		t.Errorf("Expected UnlimitedUses for unknown rule, got %d", remaining)
+	}
+}
+
+func TestPricingRuleMaxUsesQuotes(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "launch-promo",
+		Name:       "Launch Promo",
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-24 * time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+		MaxUses:    2,
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 10.0},
+		},
+	})
+
+	input := PricingInput{
+		Items: []PricingItem{
+			{ID: "item1", Name: "Item", Category: "general", BasePrice: 100.0, Quantity: 1},
+			{ID: "item2", Name: "Other Item", Category: "general", BasePrice: 50.0, Quantity: 3},
+		},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingPrecision: 2},
+	}
+
+	// Quoting the cart repeatedly leaves the budget untouched
+	for i := 0; i < 5; i++ {
+		result, err := calc.Calculate(input)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Items[0].FinalPrice != 90.0 || result.Items[1].FinalPrice != 45.0 {
+			t.Errorf("Quote %d: expected both items discounted, got %+v", i+1, result.Items)
+		}
+	}
+	if remaining := calc.RemainingUses("launch-promo"); remaining != 2 {
+		t.Errorf("Expected 2 remaining uses after quotes, got %d", remaining)
+	}
+
+	// Placing the order uses the rule once, not once per item
+	if _, err := calc.Apply(input); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if remaining := calc.RemainingUses("launch-promo"); remaining != 1 {
+		t.Errorf("Expected 1 remaining use after one order, got %d", remaining)
+	}
+}
+
+func TestPricingRuleMaxUsesNoOp(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "empty-promo",
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-24 * time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+		MaxUses:    1,
+	})
+
+	input := PricingInput{
+		Items:   []PricingItem{{ID: "item1", Name: "Item", Category: "general", BasePrice: 100.0, Quantity: 1}},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingPrecision: 2},
+	}
+
+	result, err := calc.Apply(input)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Items[0].AppliedRules) != 0 {
+		t.Errorf("Expected no applied rules, got %d", len(result.Items[0].AppliedRules))
+	}
+
+	// A rule that leaves the price unchanged keeps its use
+	if remaining := calc.RemainingUses("empty-promo"); remaining != 1 {
+		t.Errorf("Expected 1 remaining use, got %d", remaining)
+	}
+}
+
+func TestPricingRuleMaxUsesConcurrent(t *testing.T) {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:         "flash-sale",
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-24 * time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+		MaxUses:    10,
+		Adjustments: []PriceAdjustment{
+			{Type: "percentage", Value: 50.0},
+		},
+	})
+
+	input := PricingInput{
+		Items:   []PricingItem{{ID: "item1", Name: "Item", Category: "general", BasePrice: 100.0, Quantity: 1}},
+		Context: PricingContext{Timestamp: time.Now()},
+		Options: PricingOptions{RoundingPrecision: 2},
+	}
+
+	var wg sync.WaitGroup
+	var discounted atomic.Int64
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := calc.Apply(input)
+			if err == nil && result.Items[0].FinalPrice == 50.0 {
+				discounted.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if discounted.Load() != 10 {
+		t.Errorf("Expected exactly 10 discounted calculations, got %d", discounted.Load())
+	}
+	if remaining := calc.RemainingUses("flash-sale"); remaining != 0 {
+		t.Errorf("Expected 0 remaining uses, got %d", remaining)
+	}
+}
+
 func TestApplyAdjustment(t *testing.T) {
 	calc := NewCalculator()
 
@@ -276,6 +1208,79 @@ func TestApplyAdjustment(t *testing.T) {
 	}
 }
 
+func TestApplyCombinedAdjustment(t *testing.T) {
+	calc := NewCalculator()
+	percentage := PriceAdjustment{Type: "percentage", Value: 10.0}
+	fixed := PriceAdjustment{Type: "fixed", Value: 5.0}
+
+	tests := []struct {
+		name       string
+		adjustment PriceAdjustment
+		sequential []PriceAdjustment
+		expected   float64
+	}{
+		{
+			name: "percentage then fixed",
+			adjustment: PriceAdjustment{
+				Type:       "percentage_then_fixed",
+				Value:      10.0,
+				FixedValue: 5.0,
+			},
+			sequential: []PriceAdjustment{percentage, fixed},
+			expected:   85.0,
+		},
+		{
+			name: "fixed then percentage",
+			adjustment: PriceAdjustment{
+				Type:       "fixed_then_percentage",
+				Value:      10.0,
+				FixedValue: 5.0,
+			},
+			sequential: []PriceAdjustment{fixed, percentage},
+			expected:   85.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := calc.applyAdjustment(100.0, tt.adjustment)
+
+			sequential := 100.0
+			for _, adjustment := range tt.sequential {
+				sequential = calc.applyAdjustment(sequential, adjustment)
+			}
+
+			if result != tt.expected {
+				t.Errorf("Expected %f, got %f", tt.expected, result)
+			}
+			if result != sequential {
+				t.Errorf("Expected combined result %f to match sequential result %f", result, sequential)
+			}
+		})
+	}
+
+	// Limits apply after both steps, not between them
+	limited := calc.applyAdjustment(100.0, PriceAdjustment{
+		Type:       "percentage_then_fixed",
+		Value:      50.0,
+		FixedValue: 45.0,
+		MinPrice:   10.0,
+	})
+	if limited != 10.0 {
+		t.Errorf("Expected min price 10.0, got %f", limited)
+	}
+
+	rounded := calc.applyAdjustment(100.0, PriceAdjustment{
+		Type:       "fixed_then_percentage",
+		Value:      10.0,
+		FixedValue: 5.0,
+		RoundTo:    1.0,
+	})
+	if rounded != 86.0 {
+		t.Errorf("Expected rounded price 86.0, got %f", rounded)
+	}
+}
+
 func TestValidateInput(t *testing.T) {
 	calc := NewCalculator()
 
@@ -428,6 +1433,229 @@ func TestCalculatorConfiguration(t *testing.T) {
 	}
 }
 
+func TestConfigHash(t *testing.T) {
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	validUntil := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ruleA := PricingRule{
+		ID:          "rule-a",
+		Name:        "Rule A",
+		IsActive:    true,
+		ValidFrom:   validFrom,
+		ValidUntil:  validUntil,
+		Adjustments: []PriceAdjustment{{Type: "percentage", Value: 10.0}},
+		Metadata:    map[string]interface{}{"source": "test", "campaign": "spring"},
+	}
+	ruleB := PricingRule{
+		ID:          "rule-b",
+		Name:        "Rule B",
+		IsActive:    true,
+		ValidFrom:   validFrom,
+		ValidUntil:  validUntil,
+		Adjustments: []PriceAdjustment{{Type: "fixed", Value: 5.0}},
+	}
+	bundleA := Bundle{ID: "bundle-a", Name: "Bundle A", IsActive: true}
+	bundleB := Bundle{ID: "bundle-b", Name: "Bundle B", IsActive: true}
+	tierA := TierPricing{ID: "tier-a", Tiers: []PriceTier{{MinQuantity: 10, Discount: 5.0}}}
+	tierB := TierPricing{ID: "tier-b", Tiers: []PriceTier{{MinQuantity: 50, Discount: 10.0}}}
+	dynamicA := DynamicPricingConfig{ID: "dynamic-a", MaxPriceChange: 20.0}
+	dynamicB := DynamicPricingConfig{ID: "dynamic-b", MaxPriceChange: 30.0}
+
+	calc1 := NewCalculator()
+	calc1.AddRule(ruleA)
+	calc1.AddRule(ruleB)
+	calc1.AddBundle(bundleA)
+	calc1.AddBundle(bundleB)
+	calc1.AddTierPricing(tierA)
+	calc1.AddTierPricing(tierB)
+	calc1.AddDynamicConfig(dynamicA)
+	calc1.AddDynamicConfig(dynamicB)
+
+	calc2 := NewCalculator()
+	calc2.AddDynamicConfig(dynamicB)
+	calc2.AddTierPricing(tierB)
+	calc2.AddBundle(bundleB)
+	calc2.AddRule(ruleB)
+	calc2.AddDynamicConfig(dynamicA)
+	calc2.AddTierPricing(tierA)
+	calc2.AddBundle(bundleA)
+	calc2.AddRule(ruleA)
+
+	hash1 := calc1.ConfigHash()
+	hash2 := calc2.ConfigHash()
+	if len(hash1) != 64 {
+		t.Errorf("Expected 64-character SHA-256 hash, got %d characters", len(hash1))
+	}
+	if hash1 != hash2 {
+		t.Errorf("Expected equal configs in different orders to hash equally, got %s and %s", hash1, hash2)
+	}
+
+	// Market data is not part of the configuration
+	calc2.UpdateMarketData("item1", MarketData{ItemID: "item1", DemandLevel: "high"})
+	if calc2.ConfigHash() != hash1 {
+		t.Error("Expected market data not to affect the config hash")
+	}
+
+	// Changing any rule must change the hash
+	calc2.AddRule(PricingRule{ID: "rule-c", Name: "Rule C"})
+	if calc2.ConfigHash() == hash1 {
+		t.Error("Expected config hash to change after adding a rule")
+	}
+}
+
+func TestCalculateBundlePricingOptionalItems(t *testing.T) {
+	calc := NewCalculator()
+
+	bundle := Bundle{
+		ID:   "laptop-bundle",
+		Name: "Laptop Essentials Bundle",
+		Type: BundleTypeFixed,
+		Items: []BundleItem{
+			{ItemID: "laptop-001", Quantity: 1, IsRequired: true},
+			{ItemID: "mouse-001", Quantity: 1, IsOptional: true, Discount: 20.0},
+		},
+		Pricing: BundlePricing{
+			Type:  "percentage",
+			Value: 10.0,
+		},
+		IsActive:   true,
+		ValidFrom:  time.Now().Add(-24 * time.Hour),
+		ValidUntil: time.Now().Add(24 * time.Hour),
+	}
+
+	laptop := PricedItem{ItemID: "laptop-001", Quantity: 1, FinalPrice: 1000.0, OriginalPrice: 1000.0}
+	mouse := PricedItem{ItemID: "mouse-001", Quantity: 1, FinalPrice: 50.0, OriginalPrice: 50.0}
+
+	tests := []struct {
+		name            string
+		items           []PricedItem
+		expectedApplied bool
+		expectedPrice   float64
+		expectedSavings float64
+	}{
+		{
+			name:            "optional item included",
+			items:           []PricedItem{laptop, mouse},
+			expectedApplied: true,
+			expectedPrice:   940.0, // 10% off laptop + 20% off mouse
+			expectedSavings: 110.0,
+		},
+		{
+			name:            "optional item excluded",
+			items:           []PricedItem{laptop},
+			expectedApplied: true,
+			expectedPrice:   900.0,
+			expectedSavings: 100.0,
+		},
+		{
+			name:            "required item missing",
+			items:           []PricedItem{mouse},
+			expectedApplied: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := calc.calculateBundlePricing(tt.items, []Bundle{bundle}, Customer{}, PricingContext{})
+
+			if !tt.expectedApplied {
+				if len(results) != 0 {
+					t.Errorf("Expected bundle not to apply, got %+v", results)
+				}
+				return
+			}
+
+			if len(results) != 1 {
+				t.Fatalf("Expected 1 bundle result, got %d", len(results))
+			}
+			if math.Abs(results[0].BundlePrice-tt.expectedPrice) > 0.0001 {
+				t.Errorf("Expected bundle price %f, got %f", tt.expectedPrice, results[0].BundlePrice)
+			}
+			if math.Abs(results[0].BundleSavings-tt.expectedSavings) > 0.0001 {
+				t.Errorf("Expected bundle savings %f, got %f", tt.expectedSavings, results[0].BundleSavings)
+			}
+		})
+	}
+}
+
+func TestCalculateBundlePricePayForN(t *testing.T) {
+	calc := NewCalculator()
+
+	tests := []struct {
+		name          string
+		payFor        int
+		setSize       int
+		items         []PricedItem
+		expectedPrice float64
+	}{
+		{
+			name:    "buy 3 pay for 2",
+			payFor:  2,
+			setSize: 3,
+			items: []PricedItem{
+				{ItemID: "shirt", Quantity: 1, FinalPrice: 30.0},
+				{ItemID: "socks", Quantity: 1, FinalPrice: 10.0},
+				{ItemID: "hat", Quantity: 1, FinalPrice: 20.0},
+			},
+			expectedPrice: 50.0, // socks free
+		},
+		{
+			name:    "4 for 3",
+			payFor:  3,
+			setSize: 4,
+			items: []PricedItem{
+				{ItemID: "shirt", Quantity: 2, FinalPrice: 30.0},
+				{ItemID: "socks", Quantity: 1, FinalPrice: 10.0},
+				{ItemID: "hat", Quantity: 1, FinalPrice: 20.0},
+			},
+			expectedPrice: 80.0, // socks free
+		},
+		{
+			name:    "leftover units paid in full",
+			payFor:  2,
+			setSize: 3,
+			items: []PricedItem{
+				{ItemID: "shirt", Quantity: 3, FinalPrice: 30.0},
+				{ItemID: "socks", Quantity: 1, FinalPrice: 10.0},
+			},
+			expectedPrice: 70.0, // one shirt free, socks left over
+		},
+		{
+			name:    "fewer items than set size",
+			payFor:  2,
+			setSize: 3,
+			items: []PricedItem{
+				{ItemID: "shirt", Quantity: 1, FinalPrice: 30.0},
+				{ItemID: "socks", Quantity: 1, FinalPrice: 10.0},
+			},
+			expectedPrice: 40.0,
+		},
+		{
+			name:    "misconfigured set size",
+			payFor:  3,
+			setSize: 3,
+			items: []PricedItem{
+				{ItemID: "shirt", Quantity: 3, FinalPrice: 30.0},
+			},
+			expectedPrice: 90.0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bundle := Bundle{
+				ID:      "apparel",
+				Items:   []BundleItem{{ItemID: "shirt"}, {ItemID: "socks"}, {ItemID: "hat"}},
+				Pricing: BundlePricing{Type: "pay_for_n", PayFor: tt.payFor, SetSize: tt.setSize},
+			}
+
+			price := calc.calculateBundlePrice(tt.items, bundle)
+			if math.Abs(price-tt.expectedPrice) > 0.0001 {
+				t.Errorf("Expected bundle price %f, got %f", tt.expectedPrice, price)
+			}
+		})
+	}
+}
+
 // Benchmarks
 
 func BenchmarkCalculate(b *testing.B) {
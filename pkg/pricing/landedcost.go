@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"fmt"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
+)
+
+// FreightAllocationBasis selects how a shipment's freight is spread across its lines.
+type FreightAllocationBasis string
+
+const (
+	// FreightByValue allocates freight in proportion to each line's cost (UnitCost × Quantity).
+	FreightByValue FreightAllocationBasis = "value"
+
+	// FreightByWeight allocates freight in proportion to each line's weight (UnitWeight × Quantity).
+	FreightByWeight FreightAllocationBasis = "weight"
+)
+
+// ShipmentLine is one item of an inbound shipment for landed cost allocation.
+type ShipmentLine struct {
+	// ID identifies the item, e.g. its SKU
+	ID string `json:"id"`
+
+	// UnitCost is the purchase cost of a single unit
+	UnitCost float64 `json:"unit_cost"`
+
+	// Quantity is the number of units received
+	Quantity int `json:"quantity"`
+
+	// UnitWeight is the weight of a single unit, in any unit used consistently across lines
+	UnitWeight float64 `json:"unit_weight,omitempty"`
+}
+
+// AllocateFreight spreads a shipment's freight-in cost across its lines by
+// value or by weight. Shares are rounded with utils.AllocateProportional, so
+// they add up to the freight exactly at the given precision. The same function
+// can spread customs duties by passing the duty total instead of freight.
+//
+// Parameters:
+//   - freight: Total freight cost of the shipment
+//   - lines: Shipment lines to allocate across
+//   - basis: FreightByValue or FreightByWeight
+//   - decimals: Decimal places of the currency's minor unit
+//
+// Returns:
+//   - []float64: Freight share of each line, in the order of lines
+//   - error: Error if freight is negative, the basis is unknown, or freight is
+//     owed but every line has zero value or weight
+//
+// Example:
+//
+//	lines := []pricing.ShipmentLine{
+//		{ID: "chair", UnitCost: 40, Quantity: 10, UnitWeight: 6},
+//		{ID: "lamp", UnitCost: 25, Quantity: 20, UnitWeight: 1.5},
+//	}
+//	shares, _ := pricing.AllocateFreight(300, lines, pricing.FreightByWeight, 2) // [200.00, 100.00]
+func AllocateFreight(freight float64, lines []ShipmentLine, basis FreightAllocationBasis, decimals int) ([]float64, error) {
+	if freight < 0 {
+		return nil, fmt.Errorf("freight cannot be negative")
+	}
+
+	weights := make([]float64, len(lines))
+	total := 0.0
+	for i, line := range lines {
+		switch basis {
+		case FreightByValue:
+			weights[i] = line.UnitCost * float64(line.Quantity)
+		case FreightByWeight:
+			weights[i] = line.UnitWeight * float64(line.Quantity)
+		default:
+			return nil, fmt.Errorf("unknown freight allocation basis %q", basis)
+		}
+		total += weights[i]
+	}
+	if freight > 0 && total <= 0 {
+		return nil, fmt.Errorf("cannot allocate freight by %s: shipment lines have no %s", basis, basis)
+	}
+
+	return utils.AllocateProportional(freight, weights, decimals), nil
+}
+
+// LandedUnitCost returns the fully-loaded cost of one unit for inventory
+// valuation: its purchase cost plus its share of the freight-in and duties
+// allocated to its line.
+//
+// Parameters:
+//   - itemCost: Purchase cost of a single unit
+//   - freightAllocated: Freight allocated to the whole line, e.g. from AllocateFreight
+//   - dutyAllocated: Duties allocated to the whole line
+//   - quantity: Units on the line
+//
+// Returns:
+//   - float64: Landed cost per unit
+//   - error: Error if quantity is not positive or any cost is negative
+//
+// Example:
+//
+//	// 10 chairs at $40 carrying $200 freight and $36 duty
+//	cost, _ := pricing.LandedUnitCost(40, 200, 36, 10) // 63.60
+func LandedUnitCost(itemCost float64, freightAllocated float64, dutyAllocated float64, quantity int) (float64, error) {
+	if quantity <= 0 {
+		return 0, fmt.Errorf("quantity must be positive, got %d", quantity)
+	}
+	if itemCost < 0 || freightAllocated < 0 || dutyAllocated < 0 {
+		return 0, fmt.Errorf("item cost, freight and duty cannot be negative")
+	}
+
+	return itemCost + (freightAllocated+dutyAllocated)/float64(quantity), nil
+}
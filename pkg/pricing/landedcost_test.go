@@ -0,0 +1,123 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAllocateFreightByWeight(t *testing.T) {
+	lines := []ShipmentLine{
+		{ID: "chair", UnitCost: 40, Quantity: 10, UnitWeight: 6},
+		{ID: "lamp", UnitCost: 25, Quantity: 20, UnitWeight: 1.5},
+		{ID: "rug", UnitCost: 90, Quantity: 3, UnitWeight: 10},
+		{ID: "gift-card", UnitCost: 5, Quantity: 50},
+	}
+
+	shares, err := AllocateFreight(100, lines, FreightByWeight, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Weights 60, 30, 30 and 0 kg of 120 kg total
+	expected := []float64{50.00, 25.00, 25.00, 0}
+	sum := 0.0
+	for i, share := range shares {
+		if math.Abs(share-expected[i]) > 0.0000001 {
+			t.Errorf("Line %s: expected %.2f, got %.2f", lines[i].ID, expected[i], share)
+		}
+		sum += share
+	}
+	if math.Abs(sum-100) > 0.0000001 {
+		t.Errorf("Expected shares to sum to 100.00, got %f", sum)
+	}
+
+	// Fractional-cent shares are rounded without losing the odd cent
+	shares, err = AllocateFreight(10.01, lines[:3], FreightByWeight, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(shares[0]+shares[1]+shares[2]-10.01) > 0.0000001 {
+		t.Errorf("Expected shares to sum to 10.01, got %v", shares)
+	}
+
+	// Each line's landed unit cost includes its freight share
+	landed, err := LandedUnitCost(lines[0].UnitCost, 50, 36, lines[0].Quantity)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(landed-48.6) > 0.0000001 {
+		t.Errorf("Expected chair landed cost 48.60, got %f", landed)
+	}
+}
+
+func TestAllocateFreightByValue(t *testing.T) {
+	lines := []ShipmentLine{
+		{ID: "a", UnitCost: 10, Quantity: 1},
+		{ID: "b", UnitCost: 10, Quantity: 1},
+		{ID: "c", UnitCost: 10, Quantity: 1},
+	}
+
+	shares, err := AllocateFreight(10, lines, FreightByValue, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sum := shares[0] + shares[1] + shares[2]
+	if math.Abs(sum-10) > 0.0000001 {
+		t.Errorf("Expected shares to sum to 10.00, got %v", shares)
+	}
+	for _, share := range shares {
+		if share != 3.33 && share != 3.34 {
+			t.Errorf("Expected shares of 3.33 or 3.34, got %v", shares)
+		}
+	}
+}
+
+func TestAllocateFreightErrors(t *testing.T) {
+	weightless := []ShipmentLine{{ID: "a", UnitCost: 10, Quantity: 1}}
+
+	if _, err := AllocateFreight(-5, weightless, FreightByValue, 2); err == nil {
+		t.Error("Expected an error for negative freight")
+	}
+	if _, err := AllocateFreight(5, weightless, "volume", 2); err == nil {
+		t.Error("Expected an error for an unknown basis")
+	}
+	if _, err := AllocateFreight(5, weightless, FreightByWeight, 2); err == nil {
+		t.Error("Expected an error when no line has weight")
+	}
+}
+
+func TestLandedUnitCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		itemCost float64
+		freight  float64
+		duty     float64
+		quantity int
+		expected float64
+		wantErr  bool
+	}{
+		{name: "freight and duty spread per unit", itemCost: 40, freight: 200, duty: 36, quantity: 10, expected: 63.6},
+		{name: "no freight or duty", itemCost: 12.5, quantity: 4, expected: 12.5},
+		{name: "zero quantity", itemCost: 40, freight: 200, quantity: 0, wantErr: true},
+		{name: "negative quantity", itemCost: 40, quantity: -2, wantErr: true},
+		{name: "negative duty", itemCost: 40, duty: -1, quantity: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := LandedUnitCost(tt.itemCost, tt.freight, tt.duty, tt.quantity)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result-tt.expected) > 0.0000001 {
+				t.Errorf("Expected %f, got %f", tt.expected, result)
+			}
+		})
+	}
+}
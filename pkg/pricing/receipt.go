@@ -0,0 +1,168 @@
+package pricing
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/currency"
+)
+
+// receiptFormatter formats receipt amounts using the currency package's built-in
+// currency table. The currency Calculator is safe for concurrent use.
+var receiptFormatter = currency.NewCalculator()
+
+// ReceiptLine represents a single line item on a receipt.
+//
+// Example:
+//
+//	line := ReceiptLine{
+//		Name: "Premium Widget",
+//		Quantity: 2,
+//		UnitPrice: 89.99,
+//		Total: 179.98,
+//	}
+type ReceiptLine struct {
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitPrice float64 `json:"unit_price"`
+	Total     float64 `json:"total"`
+}
+
+// Receipt is a compact, printable representation of a PricingResult.
+// It keeps only the line items and summary totals needed on a customer receipt.
+//
+// Example:
+//
+//	result, _ := calc.Calculate(input)
+//	receipt := ToReceipt(result)
+//	fmt.Println(receipt.String())
+type Receipt struct {
+	Lines      []ReceiptLine `json:"lines"`
+	Subtotal   float64       `json:"subtotal"`
+	Savings    float64       `json:"savings,omitempty"`
+	GrandTotal float64       `json:"grand_total"`
+	Currency   string        `json:"currency"`
+}
+
+// ToReceipt builds a Receipt from a pricing result.
+// Items without a name fall back to their item ID. A nil result yields an empty receipt.
+//
+// Parameters:
+//   - result: Pricing result to summarize
+//
+// Returns:
+//   - Receipt: Line items and totals in the result's currency
+func ToReceipt(result *PricingResult) Receipt {
+	if result == nil {
+		return Receipt{}
+	}
+
+	receipt := Receipt{
+		Lines:      make([]ReceiptLine, 0, len(result.Items)),
+		Subtotal:   result.Subtotal,
+		Savings:    result.TotalSavings,
+		GrandTotal: result.GrandTotal,
+		Currency:   result.Currency,
+	}
+
+	for _, item := range result.Items {
+		name := item.Name
+		if name == "" {
+			name = item.ItemID
+		}
+
+		receipt.Lines = append(receipt.Lines, ReceiptLine{
+			Name:      name,
+			Quantity:  item.Quantity,
+			UnitPrice: item.FinalPrice,
+			Total:     item.TotalPrice,
+		})
+	}
+
+	return receipt
+}
+
+// String renders the receipt as plain text with columns aligned.
+// Names are left-aligned; quantities and amounts are right-aligned. The savings
+// line is omitted when there are no savings.
+//
+// Example output:
+//
+//	Item            Qty    Unit   Total
+//	Premium Widget    2  $10.00  $20.00
+//	Gadget            1  $15.00  $15.00
+//	-----------------------------------
+//	Subtotal                     $35.00
+//	Total                        $35.00
+func (r Receipt) String() string {
+	const gap = "  "
+
+	headers := [4]string{"Item", "Qty", "Unit", "Total"}
+	rows := make([][4]string, 0, len(r.Lines))
+	for _, line := range r.Lines {
+		rows = append(rows, [4]string{
+			line.Name,
+			fmt.Sprintf("%d", line.Quantity),
+			r.formatAmount(line.UnitPrice),
+			r.formatAmount(line.Total),
+		})
+	}
+
+	summary := [][2]string{{"Subtotal", r.formatAmount(r.Subtotal)}}
+	if r.Savings > 0 {
+		summary = append(summary, [2]string{"Savings", r.formatAmount(r.Savings)})
+	}
+	summary = append(summary, [2]string{"Total", r.formatAmount(r.GrandTotal)})
+
+	var widths [4]int
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+	for _, entry := range summary {
+		widths[0] = max(widths[0], utf8.RuneCountInString(entry[0]))
+		widths[3] = max(widths[3], utf8.RuneCountInString(entry[1]))
+	}
+
+	lineWidth := widths[0] + widths[1] + widths[2] + widths[3] + 3*len(gap)
+
+	var sb strings.Builder
+	writeRow := func(cells [4]string) {
+		fmt.Fprintf(&sb, "%-*s%s%*s%s%*s%s%*s\n",
+			widths[0], cells[0], gap,
+			widths[1], cells[1], gap,
+			widths[2], cells[2], gap,
+			widths[3], cells[3])
+	}
+
+	writeRow(headers)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	sb.WriteString(strings.Repeat("-", lineWidth))
+	sb.WriteString("\n")
+	for _, entry := range summary {
+		fmt.Fprintf(&sb, "%-*s%*s\n", lineWidth-widths[3], entry[0], widths[3], entry[1])
+	}
+
+	return sb.String()
+}
+
+// formatAmount formats an amount in the receipt currency with its symbol.
+// Unsupported or missing currencies fall back to two decimals followed by the code.
+func (r Receipt) formatAmount(amount float64) string {
+	formatted, err := receiptFormatter.Format(currency.Money{
+		Amount:   amount,
+		Currency: currency.CurrencyCode(r.Currency),
+	}, &currency.FormatOptions{ShowSymbol: true})
+	if err != nil {
+		return strings.TrimSpace(fmt.Sprintf("%.2f %s", amount, r.Currency))
+	}
+
+	return formatted
+}
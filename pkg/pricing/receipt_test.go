@@ -0,0 +1,83 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+)
+
+func createTestReceiptResult() *PricingResult {
+	return &PricingResult{
+		Items: []PricedItem{
+			{ItemID: "widget-001", Name: "Premium Widget", Quantity: 2, FinalPrice: 1250.00, TotalPrice: 2500.00, Savings: 2.50},
+			{ItemID: "gadget-002", Name: "Gadget", Quantity: 1, FinalPrice: 15.00, TotalPrice: 15.00},
+		},
+		Subtotal:     2515.00,
+		TotalSavings: 5.00,
+		GrandTotal:   2515.00,
+		Currency:     "USD",
+	}
+}
+
+func TestToReceipt(t *testing.T) {
+	receipt := ToReceipt(createTestReceiptResult())
+
+	if len(receipt.Lines) != 2 {
+		t.Fatalf("Expected 2 receipt lines, got %d", len(receipt.Lines))
+	}
+
+	line := receipt.Lines[0]
+	if line.Name != "Premium Widget" || line.Quantity != 2 || line.UnitPrice != 1250.00 || line.Total != 2500.00 {
+		t.Errorf("Unexpected first line: %+v", line)
+	}
+
+	if receipt.Subtotal != 2515.00 || receipt.Savings != 5.00 || receipt.GrandTotal != 2515.00 {
+		t.Errorf("Unexpected totals: %+v", receipt)
+	}
+
+	if receipt.Currency != "USD" {
+		t.Errorf("Expected currency USD, got %s", receipt.Currency)
+	}
+
+	if empty := ToReceipt(nil); len(empty.Lines) != 0 {
+		t.Error("Expected empty receipt for nil result")
+	}
+}
+
+func TestReceiptString(t *testing.T) {
+	receipt := ToReceipt(createTestReceiptResult())
+
+	expected := strings.Join([]string{
+		"Item            Qty       Unit      Total",
+		"Premium Widget    2  $1,250.00  $2,500.00",
+		"Gadget            1     $15.00     $15.00",
+		"-----------------------------------------",
+		"Subtotal                        $2,515.00",
+		"Savings                             $5.00",
+		"Total                           $2,515.00",
+	}, "\n") + "\n"
+
+	if got := receipt.String(); got != expected {
+		t.Errorf("Unexpected receipt layout:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestReceiptStringCurrencyFormatting(t *testing.T) {
+	result := createTestReceiptResult()
+	result.Currency = "EUR"
+	result.TotalSavings = 0
+
+	rendered := ToReceipt(result).String()
+
+	if !strings.Contains(rendered, "2.500,00 €") {
+		t.Errorf("Expected EUR formatting, got:\n%s", rendered)
+	}
+	if strings.Contains(rendered, "Savings") {
+		t.Errorf("Expected no savings line when there are no savings, got:\n%s", rendered)
+	}
+
+	result.Currency = "XXX"
+	rendered = ToReceipt(result).String()
+	if !strings.Contains(rendered, "2500.00 XXX") {
+		t.Errorf("Expected fallback formatting for unsupported currency, got:\n%s", rendered)
+	}
+}
@@ -0,0 +1,166 @@
+package pricing
+
+import "github.com/masumrpg/ecommerce-engine/pkg/utils"
+
+// CalculatorConfig is the configuration of a Calculator: everything ConfigHash
+// covers plus the registered RuleFuncs. Take one with Snapshot and build a
+// calculator from it with NewCalculatorFromConfig, e.g. to prepare a new rule
+// set off the request path and swap it in atomically.
+//
+// Market data, analytics and MaxUses counters are runtime state rather than
+// configuration and are not included.
+//
+// Example:
+//
+//	cfg := live.Load().Snapshot()
+//	cfg.Rules = append(cfg.Rules, flashSaleRule)
+//	live.Store(pricing.NewCalculatorFromConfig(cfg)) // live is an atomic.Pointer[pricing.Calculator]
+type CalculatorConfig struct {
+	Rules          []PricingRule          `json:"rules,omitempty"`
+	Bundles        []Bundle               `json:"bundles,omitempty"`
+	TierPricing    []TierPricing          `json:"tier_pricing,omitempty"`
+	DynamicConfigs []DynamicPricingConfig `json:"dynamic_configs,omitempty"`
+	PriceLists     []PriceList            `json:"price_lists,omitempty"`
+	RuleFuncs      []RuleFunc             `json:"-"` // Plugins are code, so they are shared rather than copied
+}
+
+// Snapshot returns a deep copy of the calculator's configuration. Changing the
+// snapshot does not affect the calculator, and later changes to the calculator
+// do not show up in the snapshot. Rules are read under the same lock as AddRule,
+// so a snapshot may be taken while rules are being added.
+//
+// Returns:
+//   - CalculatorConfig: Independent copy of the rules, bundles, tiers, dynamic
+//     configurations, price lists and plugins
+//
+// Example:
+//
+//	backup := calc.Snapshot()
+//	calc.AddRule(experimentalRule)
+//	calc = pricing.NewCalculatorFromConfig(backup) // roll back
+func (c *Calculator) Snapshot() CalculatorConfig {
+	return cloneCalculatorConfig(CalculatorConfig{
+		Rules:          c.rulesSnapshot(),
+		Bundles:        c.bundles,
+		TierPricing:    c.tierPricing,
+		DynamicConfigs: c.dynamicConfigs,
+		PriceLists:     c.priceLists,
+		RuleFuncs:      c.ruleFuncs,
+	})
+}
+
+// NewCalculatorFromConfig creates a calculator with a deep copy of the given
+// configuration, so the caller may keep modifying cfg without affecting it.
+// Market data, analytics and rule usage counters start empty.
+//
+// Parameters:
+//   - cfg: Configuration, typically from Snapshot
+//
+// Returns:
+//   - *Calculator: A new calculator configured from cfg
+//
+// Example:
+//
+//	next := pricing.NewCalculatorFromConfig(pricing.CalculatorConfig{
+//		Rules:      loadedRules,
+//		PriceLists: loadedPriceLists,
+//	})
+func NewCalculatorFromConfig(cfg CalculatorConfig) *Calculator {
+	cfg = cloneCalculatorConfig(cfg)
+
+	c := NewCalculator()
+	c.rules = append(c.rules, cfg.Rules...)
+	c.bundles = append(c.bundles, cfg.Bundles...)
+	c.tierPricing = append(c.tierPricing, cfg.TierPricing...)
+	c.dynamicConfigs = append(c.dynamicConfigs, cfg.DynamicConfigs...)
+	c.priceLists = append(c.priceLists, cfg.PriceLists...)
+	c.ruleFuncs = cfg.RuleFuncs
+	return c
+}
+
+// cloneCalculatorConfig deep-copies every collection of a configuration.
+func cloneCalculatorConfig(cfg CalculatorConfig) CalculatorConfig {
+	copied := CalculatorConfig{
+		RuleFuncs: append([]RuleFunc(nil), cfg.RuleFuncs...),
+	}
+	for _, rule := range cfg.Rules {
+		copied.Rules = append(copied.Rules, clonePricingRule(rule))
+	}
+	for _, bundle := range cfg.Bundles {
+		copied.Bundles = append(copied.Bundles, cloneBundle(bundle))
+	}
+	for _, tier := range cfg.TierPricing {
+		tier.Tiers = append([]PriceTier(nil), tier.Tiers...)
+		copied.TierPricing = append(copied.TierPricing, tier)
+	}
+	for _, dynamic := range cfg.DynamicConfigs {
+		copied.DynamicConfigs = append(copied.DynamicConfigs, cloneDynamicConfig(dynamic))
+	}
+	for _, priceList := range cfg.PriceLists {
+		if priceList.Prices != nil {
+			prices := make(map[string]float64, len(priceList.Prices))
+			for itemID, price := range priceList.Prices {
+				prices[itemID] = price
+			}
+			priceList.Prices = prices
+		}
+		copied.PriceLists = append(copied.PriceLists, priceList)
+	}
+	return copied
+}
+
+// clonePricingRule deep-copies a pricing rule's slices, conditions and metadata.
+func clonePricingRule(rule PricingRule) PricingRule {
+	rule.Conditions = cloneConditions(rule.Conditions)
+	rule.CartConditions = cloneConditions(rule.CartConditions)
+	rule.Adjustments = append([]PriceAdjustment(nil), rule.Adjustments...)
+	rule.ApplicableItems = append([]string(nil), rule.ApplicableItems...)
+	rule.ExcludedItems = append([]string(nil), rule.ExcludedItems...)
+	rule.CustomerSegments = append([]string(nil), rule.CustomerSegments...)
+	rule.Channels = append([]string(nil), rule.Channels...)
+	rule.Regions = append([]string(nil), rule.Regions...)
+	rule.Metadata = utils.CloneMap(rule.Metadata)
+	return rule
+}
+
+// cloneBundle deep-copies a bundle's items, conditions, tags and metadata.
+func cloneBundle(bundle Bundle) Bundle {
+	items := bundle.Items
+	bundle.Items = nil
+	for _, item := range items {
+		item.Attributes = utils.CloneMap(item.Attributes)
+		bundle.Items = append(bundle.Items, item)
+	}
+	bundle.Conditions = cloneConditions(bundle.Conditions)
+	bundle.Tags = append([]string(nil), bundle.Tags...)
+	bundle.Metadata = utils.CloneMap(bundle.Metadata)
+	return bundle
+}
+
+// cloneDynamicConfig deep-copies a dynamic pricing configuration's factors,
+// rules and metadata.
+func cloneDynamicConfig(config DynamicPricingConfig) DynamicPricingConfig {
+	rules := config.Rules
+	config.Rules = nil
+	for _, rule := range rules {
+		rule.Conditions = cloneConditions(rule.Conditions)
+		rule.Adjustments = append([]PriceAdjustment(nil), rule.Adjustments...)
+		config.Rules = append(config.Rules, rule)
+	}
+	config.Factors = append([]PricingFactor(nil), config.Factors...)
+	config.Metadata = utils.CloneMap(config.Metadata)
+	return config
+}
+
+// cloneConditions copies conditions, including slice and map condition values.
+func cloneConditions(conditions []PricingCondition) []PricingCondition {
+	if conditions == nil {
+		return nil
+	}
+	copied := make([]PricingCondition, len(conditions))
+	for i, condition := range conditions {
+		condition.Value = utils.CloneValue(condition.Value)
+		copied[i] = condition
+	}
+	return copied
+}
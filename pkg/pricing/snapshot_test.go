@@ -0,0 +1,116 @@
+package pricing
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func createSnapshotTestCalculator() *Calculator {
+	calc := NewCalculator()
+	calc.AddRule(PricingRule{
+		ID:              "spring-sale",
+		Name:            "Spring Sale",
+		IsActive:        true,
+		ValidFrom:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		ValidUntil:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		ApplicableItems: []string{"shirt"},
+		CartConditions:  []PricingCondition{{Type: "contains_items", Value: []string{"shirt", "hat"}}},
+		Adjustments:     []PriceAdjustment{{Type: "percentage", Value: 10.0}},
+		Metadata:        map[string]interface{}{"campaign": "spring"},
+	})
+	calc.AddBundle(Bundle{
+		ID:    "outfit",
+		Name:  "Outfit",
+		Items: []BundleItem{{ItemID: "shirt", Quantity: 1, Attributes: map[string]interface{}{"size": "M"}}},
+	})
+	calc.AddTierPricing(TierPricing{ID: "bulk", Tiers: []PriceTier{{MinQuantity: 10, Discount: 5.0}}})
+	calc.AddPriceList(PriceList{ID: "us", Region: "US", Prices: map[string]float64{"shirt": 20.0}})
+	return calc
+}
+
+func TestSnapshotIsIndependentOfCalculator(t *testing.T) {
+	calc := createSnapshotTestCalculator()
+	hash := calc.ConfigHash()
+
+	snapshot := calc.Snapshot()
+
+	// Mutating the snapshot leaves the calculator untouched
+	snapshot.Rules[0].Adjustments[0].Value = 50.0
+	snapshot.Rules[0].ApplicableItems[0] = "jacket"
+	snapshot.Rules[0].CartConditions[0].Value.([]string)[0] = "jacket"
+	snapshot.Rules[0].Metadata["campaign"] = "summer"
+	snapshot.Bundles[0].Items[0].Attributes["size"] = "XL"
+	snapshot.TierPricing[0].Tiers[0].Discount = 25.0
+	snapshot.PriceLists[0].Prices["shirt"] = 1.0
+	if calc.ConfigHash() != hash {
+		t.Error("Expected calculator configuration unchanged after mutating the snapshot")
+	}
+
+	// Mutating the calculator leaves an earlier snapshot untouched
+	snapshot = calc.Snapshot()
+	calc.AddRule(PricingRule{ID: "flash-sale", Name: "Flash Sale", IsActive: true})
+	calc.rules[0].Metadata["campaign"] = "autumn"
+	calc.priceLists[0].Prices["shirt"] = 99.0
+	if len(snapshot.Rules) != 1 {
+		t.Errorf("Expected 1 rule in snapshot, got %d", len(snapshot.Rules))
+	}
+	if snapshot.Rules[0].Metadata["campaign"] != "spring" || snapshot.PriceLists[0].Prices["shirt"] != 20.0 {
+		t.Errorf("Expected snapshot unchanged after mutating the calculator, got %+v", snapshot)
+	}
+}
+
+func TestSnapshotConcurrentWithAddRule(t *testing.T) {
+	calc := createSnapshotTestCalculator()
+
+	// Run with -race: Snapshot must not read the rules while AddRule appends
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			calc.AddRule(PricingRule{ID: fmt.Sprintf("rule-%d", i), IsActive: true})
+		}(i)
+		go func() {
+			defer wg.Done()
+			if snapshot := calc.Snapshot(); len(snapshot.Rules) < 1 {
+				t.Errorf("Expected at least the initial rule in the snapshot, got %d", len(snapshot.Rules))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if rules := calc.Snapshot().Rules; len(rules) != 21 {
+		t.Errorf("Expected 21 rules after concurrent AddRule calls, got %d", len(rules))
+	}
+}
+
+func TestNewCalculatorFromConfig(t *testing.T) {
+	calc := createSnapshotTestCalculator()
+	snapshot := calc.Snapshot()
+
+	restored := NewCalculatorFromConfig(snapshot)
+	if restored.ConfigHash() != calc.ConfigHash() {
+		t.Error("Expected restored calculator to have the same configuration")
+	}
+
+	// The restored calculator does not share state with the config it was built from
+	hash := restored.ConfigHash()
+	snapshot.Rules[0].Adjustments[0].Value = 50.0
+	snapshot.PriceLists[0].Prices["shirt"] = 1.0
+	if restored.ConfigHash() != hash {
+		t.Error("Expected restored calculator unchanged after mutating its source config")
+	}
+
+	result, err := restored.Calculate(PricingInput{
+		Items:   []PricingItem{{ID: "shirt", BasePrice: 20.0, Quantity: 1}},
+		Context: PricingContext{Region: "US"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result.Items) != 1 || result.Items[0].BasePrice != 20.0 {
+		t.Errorf("Expected restored calculator to price items, got %+v", result.Items)
+	}
+}
@@ -48,6 +48,7 @@
 package pricing
 
 import (
+	"errors"
 	"time"
 )
 
@@ -97,6 +98,33 @@ const (
 	BundleTypeSubscription BundleType = "subscription" // Subscription bundle
 )
 
+// BundleMatchMode controls how cart items are matched against bundle items when
+// scoring bundle recommendations.
+type BundleMatchMode string
+
+const (
+	BundleMatchByID           BundleMatchMode = "id"           // Only the exact item ID matches
+	BundleMatchByCategory     BundleMatchMode = "category"     // Any item in the same category matches
+	BundleMatchBySubstitution BundleMatchMode = "substitution" // Listed substitute item IDs match
+)
+
+// BundleMatching configures bundle match scoring. An exact item ID always
+// matches; the mode adds near-substitutes on top of it.
+//
+// Example:
+//
+//	// Any keyboard SKU satisfies the bundle's "keyboard" item
+//	matching := BundleMatching{
+//		Mode: BundleMatchBySubstitution,
+//		Substitutes: map[string][]string{
+//			"keyboard": {"kb-mech-01", "kb-wireless-02"},
+//		},
+//	}
+type BundleMatching struct {
+	Mode        BundleMatchMode     `json:"mode"`
+	Substitutes map[string][]string `json:"substitutes,omitempty"` // Bundle item ID -> acceptable item IDs
+}
+
 // PricingRule represents a comprehensive pricing rule that can be applied to items.
 // Rules define conditions under which specific price adjustments should be made,
 // supporting complex business logic for dynamic pricing strategies.
@@ -129,6 +157,27 @@ const (
 //		ValidFrom: time.Now(),
 //		ValidUntil: time.Now().AddDate(0, 3, 0), // 3 months
 //	}
+//
+//	// Buy-together rule: discount the case only when the phone is in the cart
+//	caseRule := PricingRule{
+//		ID: "phone-case-combo",
+//		ApplicableItems: []string{"case-001"},
+//		CartConditions: []PricingCondition{
+//			{Type: "contains_items", Value: []string{"phone-001", "case-001"}},
+//		},
+//		Adjustments: []PriceAdjustment{
+//			{Type: "percentage", Value: 25.0},
+//		},
+//		IsActive: true,
+//	}
+//
+// CartConditions are evaluated once against the whole cart before per-item rules
+// run; a rule whose cart conditions are not all met is skipped for every item.
+//
+// MaxUses limits how many orders a rule is applied to in total. An order placed
+// with Calculator.Apply uses it once however many items it discounts, while
+// Calculate quotes never use it up. The Calculator keeps the usage counter by
+// rule ID, so rules with a limit need unique IDs.
 type PricingRule struct {
 	ID               string          `json:"id"`
 	Name             string          `json:"name"`
@@ -139,7 +188,9 @@ type PricingRule struct {
 	IsActive         bool            `json:"is_active"`
 	ValidFrom        time.Time       `json:"valid_from"`
 	ValidUntil       time.Time       `json:"valid_until"`
+	MaxUses          int             `json:"max_uses,omitempty"` // Total order budget (0 means unlimited)
 	Conditions       []PricingCondition `json:"conditions,omitempty"`
+	CartConditions   []PricingCondition `json:"cart_conditions,omitempty"` // Evaluated against the whole cart
 	Adjustments      []PriceAdjustment  `json:"adjustments,omitempty"`
 	ApplicableItems  []string        `json:"applicable_items,omitempty"`
 	ExcludedItems    []string        `json:"excluded_items,omitempty"`
@@ -160,12 +211,14 @@ type PricingRule struct {
 //   - "inventory": Inventory level conditions
 //   - "category": Product category matching
 //   - "brand": Product brand matching
+//   - "contains_items": Cart contains the listed item IDs (PricingRule.CartConditions only)
 //
 // Supported operators:
 //   - ">", "<", ">=", "<=": Numeric comparisons
 //   - "=", "!=": Equality comparisons
 //   - "in": Value in list
 //   - "between": Value between two values
+//   - "all", "any": Whether every or at least one listed item must be present ("contains_items")
 //
 // Example:
 //
@@ -191,6 +244,13 @@ type PricingCondition struct {
 //   - "fixed": Fixed amount adjustment (positive or negative)
 //   - "markup": Markup percentage (always positive)
 //   - "markdown": Markdown percentage (always negative)
+//   - "percentage_then_fixed": Value percent off, then FixedValue off the result
+//   - "fixed_then_percentage": FixedValue off, then Value percent off the result
+//
+// The combined types apply both steps in the order given by the type name, and
+// MinPrice, MaxPrice, and RoundTo are applied once, after the second step. On a
+// $100 price with Value 10 and FixedValue 5, "percentage_then_fixed" yields
+// $85.00 while "fixed_then_percentage" yields $85.50.
 //
 // Example:
 //
@@ -204,8 +264,9 @@ type PricingCondition struct {
 //		Description: "15% Volume Discount",
 //	}
 type PriceAdjustment struct {
-	Type        string  `json:"type"`        // "percentage", "fixed", "markup", "markdown"
+	Type        string  `json:"type"`        // "percentage", "fixed", "markup", "markdown", "percentage_then_fixed", "fixed_then_percentage"
 	Value       float64 `json:"value"`       // Adjustment value
+	FixedValue  float64 `json:"fixed_value,omitempty"`  // Fixed amount for combined adjustment types
 	MinPrice    float64 `json:"min_price,omitempty"`    // Minimum price limit
 	MaxPrice    float64 `json:"max_price,omitempty"`    // Maximum price limit
 	RoundTo     float64 `json:"round_to,omitempty"`     // Round to nearest value
@@ -241,6 +302,35 @@ type TierPricing struct {
 	ValidUntil  time.Time   `json:"valid_until"`
 }
 
+// PriceList represents explicit per-item prices maintained for a region and/or currency.
+// When a price list matches the pricing context, its price replaces the item's
+// BasePrice instead of converting a single base price.
+//
+// An empty Region or Currency matches any context value. When several lists
+// contain the item, the one matching the most fields wins (Region outranks Currency);
+// ties go to the list added first.
+//
+// Example:
+//
+//	// Euro price list for European customers
+//	priceList := PriceList{
+//		ID: "eu-eur",
+//		Name: "Europe (EUR)",
+//		Region: "EU",
+//		Currency: "EUR",
+//		Prices: map[string]float64{
+//			"widget-001": 89.00,
+//			"gadget-002": 129.00,
+//		},
+//	}
+type PriceList struct {
+	ID       string             `json:"id"`
+	Name     string             `json:"name"`
+	Region   string             `json:"region,omitempty"`   // Empty matches any region
+	Currency string             `json:"currency,omitempty"` // Empty matches any currency
+	Prices   map[string]float64 `json:"prices"`             // Item ID to unit price
+}
+
 // PriceTier represents a single tier in a tiered pricing structure.
 // Defines quantity ranges and associated pricing or discounts.
 //
@@ -364,9 +454,17 @@ type BundleItem struct {
 //   - "percentage": Percentage discount off total individual prices
 //   - "tiered": Different pricing based on quantity or value tiers
 //   - "dynamic": Dynamic pricing based on market conditions
+//   - "pay_for_n": Pay for the PayFor most expensive of every SetSize items
 //
 // Example:
 //
+//	// Buy 3, pay for 2: the cheapest item in every set of 3 is free
+//	payForTwo := BundlePricing{
+//		Type: "pay_for_n",
+//		PayFor: 2,
+//		SetSize: 3,
+//	}
+//	
 //	// Percentage-based bundle pricing
 //	pricing := BundlePricing{
 //		Type: "percentage",
@@ -386,13 +484,15 @@ type BundleItem struct {
 //		SavingsValue: 50.01, // $50.01 savings
 //	}
 type BundlePricing struct {
-	Type         string  `json:"type"`         // "fixed", "percentage", "tiered", "dynamic"
+	Type         string  `json:"type"`         // "fixed", "percentage", "tiered", "dynamic", "pay_for_n"
 	Value        float64 `json:"value"`        // Price or discount value
 	MinPrice     float64 `json:"min_price,omitempty"`     // Minimum bundle price
 	MaxPrice     float64 `json:"max_price,omitempty"`     // Maximum bundle price
 	BasePrice    float64 `json:"base_price,omitempty"`    // Base bundle price
 	SavingsType  string  `json:"savings_type,omitempty"`  // "amount", "percentage"
 	SavingsValue float64 `json:"savings_value,omitempty"` // Savings amount or percentage
+	PayFor       int     `json:"pay_for,omitempty"`       // Items paid for per set ("pay_for_n")
+	SetSize      int     `json:"set_size,omitempty"`      // Items per set ("pay_for_n")
 }
 
 // PricingItem represents an item that needs pricing calculation.
@@ -422,6 +522,17 @@ type BundlePricing struct {
 //		InventoryLevel: 150,
 //		Tags: []string{"premium", "bestseller"},
 //	}
+//
+// CompareAtPrice is an optional "was" reference price for merchandising, such as
+// the highest price in a recent window or the MSRP. It is carried through to the
+// PricedItem so the UI can show "was $X, now $Y".
+//
+// AddOns are optional line-level fees the customer opted into, such as gift wrap.
+//
+// Backordered marks an item that is out of stock but can still be ordered, with
+// BackorderLeadDays as the expected wait. Backordered items can be surcharged with a
+// "backorder" dynamic pricing factor or charged a deposit via
+// PricingOptions.BackorderDepositPercent.
 type PricingItem struct {
 	ID           string  `json:"id"`
 	Name         string  `json:"name"`
@@ -433,13 +544,38 @@ type PricingItem struct {
 	BasePrice    float64 `json:"base_price"`
 	CostPrice    float64 `json:"cost_price,omitempty"`
 	MSRP         float64 `json:"msrp,omitempty"`
+	CompareAtPrice float64 `json:"compare_at_price,omitempty"`
 	Weight       float64 `json:"weight,omitempty"`
 	Dimensions   Dimensions `json:"dimensions,omitempty"`
 	InventoryLevel int   `json:"inventory_level,omitempty"`
+	Backordered  bool    `json:"backordered,omitempty"`
+	BackorderLeadDays int `json:"backorder_lead_days,omitempty"`
 	IsDigital    bool    `json:"is_digital,omitempty"`
 	IsSubscription bool  `json:"is_subscription,omitempty"`
 	Tags         []string `json:"tags,omitempty"`
 	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	AddOns       []ItemAddOn `json:"add_ons,omitempty"`
+}
+
+// ItemAddOn represents an optional fee attached to a line item, such as gift wrapping
+// or special handling. Add-ons are added to the item total after all discounts and are
+// never discounted themselves. The fee is charged once per line unless PerUnit is set.
+//
+// Example:
+//
+//	// $5 gift wrap for the whole line
+//	giftWrap := ItemAddOn{
+//		ID: "gift-wrap",
+//		Name: "Gift Wrap",
+//		Amount: 5.00,
+//		Taxable: true,
+//	}
+type ItemAddOn struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Amount  float64 `json:"amount"`
+	PerUnit bool    `json:"per_unit,omitempty"` // Multiply Amount by the item quantity
+	Taxable bool    `json:"taxable,omitempty"`
 }
 
 // Dimensions represents the physical dimensions of an item.
@@ -608,8 +744,17 @@ type PricingOptions struct {
 	MinMargin        float64 `json:"min_margin,omitempty"`        // Minimum profit margin
 	CalculateBundle  bool    `json:"calculate_bundle,omitempty"`
 	CalculateTiers   bool    `json:"calculate_tiers,omitempty"`
+	BackorderDepositPercent float64 `json:"backorder_deposit_percent,omitempty"` // Share of a backordered item's price due now
+	CustomerFavorableRounding bool  `json:"customer_favorable_rounding,omitempty"` // Round final prices and deposits down, overriding RoundingMode
+	CapQuantityToInventory bool    `json:"cap_quantity_to_inventory,omitempty"` // Price only the units in stock when Quantity exceeds InventoryLevel
+	RefuseInsufficientInventory bool `json:"refuse_insufficient_inventory,omitempty"` // Fail with ErrInsufficientInventory when Quantity exceeds InventoryLevel; wins over CapQuantityToInventory
 }
 
+// ErrInsufficientInventory is wrapped by the error Calculate returns when an item's
+// Quantity exceeds its InventoryLevel and RefuseInsufficientInventory is set;
+// check for it with errors.Is(err, ErrInsufficientInventory).
+var ErrInsufficientInventory = errors.New("insufficient inventory")
+
 // PricedItem represents the pricing result for an individual item.
 // Contains the final price, applied rules, discounts, and calculation details.
 //
@@ -635,6 +780,16 @@ type PricingOptions struct {
 //			TierDiscount: 10.0,
 //		},
 //	}
+//
+// CompareAtPrice is the item's compare-at price, or OriginalPrice when none was
+// given. SavingsVsCompareAt is CompareAtPrice minus FinalPrice per unit; it is
+// negative when the final price is above the reference.
+//
+// TotalPrice includes AddOnTotal; TaxableAddOnTotal is the taxable part of it.
+// Warnings are also copied to the PricingResult.
+//
+// CompetitivePosition compares FinalPrice with the competitor AveragePrice from
+// UpdateMarketData; it is nil when the item has no market data.
 type PricedItem struct {
 	ItemID        string            `json:"item_id"`
 	Name          string            `json:"name"`
@@ -646,14 +801,50 @@ type PricedItem struct {
 	OriginalPrice float64           `json:"original_price,omitempty"`
 	Savings       float64           `json:"savings,omitempty"`
 	SavingsPercent float64          `json:"savings_percent,omitempty"`
+	CompareAtPrice float64          `json:"compare_at_price,omitempty"`
+	SavingsVsCompareAt float64      `json:"savings_vs_compare_at,omitempty"`
+	AddOns        []ItemAddOn       `json:"add_ons,omitempty"`
+	AddOnTotal    float64           `json:"add_on_total,omitempty"`
+	TaxableAddOnTotal float64       `json:"taxable_add_on_total,omitempty"`
+	Backordered   bool              `json:"backordered,omitempty"`
+	BackorderLeadDays int           `json:"backorder_lead_days,omitempty"`
+	DepositPrice  float64           `json:"deposit_price,omitempty"` // Per-unit amount due now for a backordered item
 	AppliedRules  []AppliedPricingRule `json:"applied_rules,omitempty"`
 	TierInfo      *TierInfo         `json:"tier_info,omitempty"`
 	BundleInfo    *BundleInfo       `json:"bundle_info,omitempty"`
 	Margin        float64           `json:"margin,omitempty"`
 	Markup        float64           `json:"markup,omitempty"`
+	Warnings      []string          `json:"warnings,omitempty"`
+	CompetitivePosition *CompetitivePosition `json:"competitive_position,omitempty"`
 	Metadata      map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// CompetitivePosition describes how an item's final price compares with the
+// competitor average price, e.g. to show a "great deal" badge.
+//
+// PercentBelowMarket is positive when the item is cheaper than the market
+// average and negative when it is more expensive. Position is "below", "above"
+// or "at" (within half a percent), and Label is a display string such as
+// "12% below market".
+//
+// Example:
+//
+//	// Final price $88.00, competitor average $100.00
+//	position := CompetitivePosition{
+//		MarketAverage:      100.00,
+//		Difference:         -12.00,
+//		PercentBelowMarket: 12.0,
+//		Position:           "below",
+//		Label:              "12% below market",
+//	}
+type CompetitivePosition struct {
+	MarketAverage      float64 `json:"market_average"`
+	Difference         float64 `json:"difference"` // FinalPrice minus MarketAverage
+	PercentBelowMarket float64 `json:"percent_below_market"`
+	Position           string  `json:"position"` // "below", "at", "above"
+	Label              string  `json:"label"`
+}
+
 // AppliedPricingRule represents a pricing rule that was successfully applied to an item.
 // Tracks which rule was used, the adjustment made, and the reasoning behind the application.
 //
@@ -761,12 +952,17 @@ type BundleInfo struct {
 //		},
 //		CalculationTime: time.Now(),
 //	}
+//
+// Subtotal and GrandTotal include item add-ons; AddOnTotal and TaxableAddOnTotal
+// break them out so callers can exclude non-taxable fees from the tax base.
 type PricingResult struct {
 	Items           []PricedItem      `json:"items"`
 	Subtotal        float64           `json:"subtotal"`
 	TotalSavings    float64           `json:"total_savings"`
 	TotalDiscount   float64           `json:"total_discount"`
 	GrandTotal      float64           `json:"grand_total"`
+	AddOnTotal      float64           `json:"add_on_total,omitempty"`
+	TaxableAddOnTotal float64         `json:"taxable_add_on_total,omitempty"`
 	Currency        string            `json:"currency"`
 	AppliedBundles  []BundleInfo      `json:"applied_bundles,omitempty"`
 	AppliedTiers    []TierInfo        `json:"applied_tiers,omitempty"`
@@ -835,6 +1031,14 @@ type PricingRecommendation struct {
 //		MinPriceRatio: 0.7, // Never go below 70% of base price
 //		MaxPriceRatio: 1.5, // Never go above 150% of base price
 //	}
+//
+// CompetitorStrategy sets the price relative to the competitor average price
+// from MarketData, after factors and rules have been applied:
+//   - "match": Competitor average
+//   - "undercut": Competitor average × (1 - CompetitorMargin/100)
+//   - "premium": Competitor average × (1 + CompetitorMargin/100)
+//
+// The result is still limited by PriceFloor, PriceCeiling, and MaxPriceChange.
 type DynamicPricingConfig struct {
 	ID                string            `json:"id"`
 	Name              string            `json:"name"`
@@ -844,6 +1048,8 @@ type DynamicPricingConfig struct {
 	MaxPriceChange    float64           `json:"max_price_change"`    // Maximum price change percentage
 	PriceFloor        float64           `json:"price_floor"`         // Minimum allowed price
 	PriceCeiling      float64           `json:"price_ceiling"`       // Maximum allowed price
+	CompetitorStrategy string           `json:"competitor_strategy,omitempty"` // "match", "undercut", "premium"
+	CompetitorMargin  float64           `json:"competitor_margin,omitempty"`   // Undercut/premium percentage
 	Factors           []PricingFactor   `json:"factors"`
 	Rules             []DynamicPricingRule `json:"rules"`
 	IsActive          bool              `json:"is_active"`
@@ -861,6 +1067,10 @@ type DynamicPricingConfig struct {
 //   - "time": Time-based factors (peak hours, seasons)
 //   - "weather": Weather conditions impact
 //   - "events": Special events or holidays
+//   - "backorder": Backordered items, optionally only when BackorderLeadDays reaches Threshold
+//
+// Backordered items are handled by the "backorder" factor alone: the "inventory"
+// factor ignores them, so a backorder never also counts as low stock.
 //
 // Example:
 //
@@ -876,7 +1086,7 @@ type DynamicPricingConfig struct {
 //		IsActive: true,
 //	}
 type PricingFactor struct {
-	Type        string  `json:"type"`        // "demand", "inventory", "competition", "time", "weather", "events"
+	Type        string  `json:"type"`        // "demand", "inventory", "competition", "time", "weather", "events", "backorder"
 	Weight      float64 `json:"weight"`      // Factor weight (0-1)
 	Threshold   float64 `json:"threshold,omitempty"`   // Threshold value
 	Impact      float64 `json:"impact"`      // Price impact percentage
@@ -933,6 +1143,10 @@ type DynamicPricingRule struct {
 //		LastUpdated: time.Now(),
 //		Source: "market_intelligence_api",
 //	}
+//
+// DemandIndex, when set, takes precedence over DemandLevel. It ranges from 0.0
+// (no demand) to 1.0 (peak demand), with 0.5 being neutral. DemandLevel maps to
+// 0.0 ("low"), 0.5 ("medium") and 1.0 ("high") when no index is provided.
 type MarketData struct {
 	ItemID          string            `json:"item_id"`
 	AveragePrice    float64           `json:"average_price"`
@@ -940,6 +1154,7 @@ type MarketData struct {
 	MaxPrice        float64           `json:"max_price"`
 	CompetitorPrices map[string]float64 `json:"competitor_prices"`
 	DemandLevel     string            `json:"demand_level"` // "low", "medium", "high"
+	DemandIndex     *float64          `json:"demand_index,omitempty"` // 0.0-1.0, 0.5 is neutral
 	TrendDirection  string            `json:"trend_direction"` // "up", "down", "stable"
 	LastUpdated     time.Time         `json:"last_updated"`
 	Source          string            `json:"source"`
@@ -0,0 +1,110 @@
+package pricing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// unitFamily groups measurement units that can be converted into one another.
+type unitFamily string
+
+const (
+	unitFamilyMass   unitFamily = "mass"
+	unitFamilyVolume unitFamily = "volume"
+)
+
+// measurementUnit describes a unit by its family and its size in the family's
+// base unit (grams for mass, milliliters for volume).
+type measurementUnit struct {
+	family unitFamily
+	base   float64
+}
+
+// measurementUnits lists the supported units. Mass factors match the shipping
+// package's weight conversion.
+var measurementUnits = map[string]measurementUnit{
+	"g":  {family: unitFamilyMass, base: 1},
+	"kg": {family: unitFamilyMass, base: 1000},
+	"lb": {family: unitFamilyMass, base: 453.592},
+	"oz": {family: unitFamilyMass, base: 28.3495},
+	"ml": {family: unitFamilyVolume, base: 1},
+	"cl": {family: unitFamilyVolume, base: 10},
+	"l":  {family: unitFamilyVolume, base: 1000},
+}
+
+// NormalizedUnitPrice converts a package price into a price per target unit, for
+// comparing products sold in different sizes.
+//
+// Supported units are "g", "kg", "lb", "oz" (mass) and "ml", "cl", "l" (volume),
+// case-insensitive. The target unit may carry a numeric multiplier such as "100g"
+// or "100 ml" to get the price per that amount.
+//
+// Parameters:
+//   - price: Price of the package
+//   - quantityValue: Amount contained in the package
+//   - quantityUnit: Unit of quantityValue
+//   - targetUnit: Unit (optionally with multiplier) to express the price in
+//
+// Returns:
+//   - float64: Price per target unit
+//   - error: Error if a unit is unknown, the units belong to different families,
+//     or the quantity is not positive
+//
+// Example:
+//
+//	// 500g pack for $3.00
+//	perHundredGrams, _ := pricing.NormalizedUnitPrice(3.00, 500, "g", "100g") // $0.60
+//	perKilogram, _ := pricing.NormalizedUnitPrice(3.00, 500, "g", "kg")       // $6.00
+func NormalizedUnitPrice(price float64, quantityValue float64, quantityUnit string, targetUnit string) (float64, error) {
+	if quantityValue <= 0 {
+		return 0, fmt.Errorf("quantity must be positive")
+	}
+
+	fromMultiplier, from, err := parseMeasurementUnit(quantityUnit)
+	if err != nil {
+		return 0, err
+	}
+	toMultiplier, to, err := parseMeasurementUnit(targetUnit)
+	if err != nil {
+		return 0, err
+	}
+
+	if from.family != to.family {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", quantityUnit, from.family, targetUnit, to.family)
+	}
+
+	quantityInBase := quantityValue * fromMultiplier * from.base
+	targetInBase := toMultiplier * to.base
+
+	return price / quantityInBase * targetInBase, nil
+}
+
+// parseMeasurementUnit splits an optional numeric multiplier from a unit name,
+// e.g. "100g" yields 100 and the gram unit.
+func parseMeasurementUnit(unit string) (float64, measurementUnit, error) {
+	normalized := strings.ToLower(strings.TrimSpace(unit))
+
+	split := strings.IndexFunc(normalized, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if split < 0 {
+		return 0, measurementUnit{}, fmt.Errorf("unsupported unit: %s", unit)
+	}
+
+	multiplier := 1.0
+	if split > 0 {
+		value, err := strconv.ParseFloat(normalized[:split], 64)
+		if err != nil || value <= 0 {
+			return 0, measurementUnit{}, fmt.Errorf("invalid unit multiplier: %s", unit)
+		}
+		multiplier = value
+	}
+
+	measurement, exists := measurementUnits[strings.TrimSpace(normalized[split:])]
+	if !exists {
+		return 0, measurementUnit{}, fmt.Errorf("unsupported unit: %s", unit)
+	}
+
+	return multiplier, measurement, nil
+}
@@ -0,0 +1,60 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizedUnitPrice(t *testing.T) {
+	tests := []struct {
+		name          string
+		price         float64
+		quantityValue float64
+		quantityUnit  string
+		targetUnit    string
+		expected      float64
+	}{
+		{name: "grams per 100g", price: 3.00, quantityValue: 500, quantityUnit: "g", targetUnit: "100g", expected: 0.60},
+		{name: "kilograms per 100g", price: 4.50, quantityValue: 1.5, quantityUnit: "kg", targetUnit: "100g", expected: 0.30},
+		{name: "grams per kg", price: 3.00, quantityValue: 500, quantityUnit: "g", targetUnit: "kg", expected: 6.00},
+		{name: "pounds per kg", price: 4.53592, quantityValue: 1, quantityUnit: "lb", targetUnit: "kg", expected: 10.00},
+		{name: "milliliters per liter", price: 1.20, quantityValue: 330, quantityUnit: "ml", targetUnit: "l", expected: 3.6363636},
+		{name: "liters per liter", price: 2.50, quantityValue: 1.5, quantityUnit: "L", targetUnit: "l", expected: 1.6666667},
+		{name: "centiliters per 100 ml", price: 2.00, quantityValue: 75, quantityUnit: "cl", targetUnit: "100 ml", expected: 0.2666667},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizedUnitPrice(tt.price, tt.quantityValue, tt.quantityUnit, tt.targetUnit)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(result-tt.expected) > 0.00001 {
+				t.Errorf("Expected %f, got %f", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNormalizedUnitPriceErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		quantityValue float64
+		quantityUnit  string
+		targetUnit    string
+	}{
+		{name: "mass to volume", quantityValue: 500, quantityUnit: "g", targetUnit: "l"},
+		{name: "volume to mass", quantityValue: 1, quantityUnit: "l", targetUnit: "100g"},
+		{name: "unknown unit", quantityValue: 1, quantityUnit: "bushel", targetUnit: "kg"},
+		{name: "invalid multiplier", quantityValue: 1, quantityUnit: "kg", targetUnit: "0g"},
+		{name: "zero quantity", quantityValue: 0, quantityUnit: "kg", targetUnit: "kg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NormalizedUnitPrice(1.00, tt.quantityValue, tt.quantityUnit, tt.targetUnit); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
@@ -33,7 +33,11 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/currency"
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // ShippingCalculator handles comprehensive shipping cost calculations and delivery estimations.
@@ -44,6 +48,7 @@ import (
 //   - Weight, value, and dimensional pricing
 //   - Carrier-specific rules and surcharges
 //   - Free shipping eligibility
+//   - Progressive shipping discounts by order value
 //   - Delivery time calculations
 //   - Shipping restrictions by location or item type
 //
@@ -64,7 +69,12 @@ type ShippingCalculator struct {
 	DeliveryTimeRules []DeliveryTimeRule
 	Restrictions      []ShippingRestriction
 	FreeShippingRules []FreeShippingRule
+	ShippingDiscountRules []ShippingDiscountRule
 	PackagingRules    []PackagingRule
+	RemoteAreas       []RemoteArea // Destinations that trigger "remote_area" surcharges
+	MaxItemWeight     Weight       // Carrier cap on a single package; zero means no cap
+	SplitOverweightItems bool      // Split lines over MaxItemWeight into several packages
+	FallbackOption    *ShippingOption // Offered only when every rule and carrier option is filtered out
 }
 
 // NewShippingCalculator creates a new shipping calculator with empty rule sets.
@@ -75,6 +85,7 @@ type ShippingCalculator struct {
 //   - Empty delivery time rules (will use default delivery times)
 //   - Empty restrictions (no shipping restrictions)
 //   - Empty free shipping rules (no free shipping)
+//   - Empty shipping discount rules (no shipping discounts)
 //   - Empty packaging rules (no special packaging requirements)
 //
 // Example:
@@ -91,6 +102,7 @@ func NewShippingCalculator() *ShippingCalculator {
 		DeliveryTimeRules: []DeliveryTimeRule{},
 		Restrictions:      []ShippingRestriction{},
 		FreeShippingRules: []FreeShippingRule{},
+		ShippingDiscountRules: []ShippingDiscountRule{},
 		PackagingRules:    []PackagingRule{},
 	}
 }
@@ -143,7 +155,8 @@ func Calculate(input ShippingCalculationInput) ShippingCalculationResult {
 // multiple stages: validation, zone determination, restriction checks, and cost calculation.
 //
 // Calculation Process:
-//   1. Input validation (items, addresses, weights)
+//   1. Input validation (items, addresses, weights); every item needs a positive
+//      quantity, so zero or negative quantities and negative values are rejected
 //   2. Shipping zone determination based on origin/destination
 //   3. Restriction checks (prohibited items, blocked destinations)
 //   4. Available shipping method calculation
@@ -152,6 +165,10 @@ func Calculate(input ShippingCalculationInput) ShippingCalculationResult {
 //   7. Free shipping eligibility evaluation
 //   8. Recommendation generation (cheapest, fastest, recommended)
 //
+// When input.ShippingRules is empty a built-in standard option is always offered.
+// The calculator's FallbackOption is different: it is only offered when rules or
+// carriers were given but none of them produced an option.
+//
 // Parameters:
 //   - input: ShippingCalculationInput containing:
 //     * Items: List of items to ship with weights, dimensions, values
@@ -199,6 +216,14 @@ func (sc *ShippingCalculator) CalculateShipping(input ShippingCalculationInput)
 			Warnings:     []string{},
 		}
 	}
+	if err := validateItems(input.Items); err != nil {
+		return ShippingCalculationResult{
+			IsValid:      false,
+			ErrorMessage: err.Error(),
+			Options:      []ShippingOption{},
+			Warnings:     []string{},
+		}
+	}
 
 	result := ShippingCalculationResult{
 		Options:     []ShippingOption{},
@@ -208,6 +233,14 @@ func (sc *ShippingCalculator) CalculateShipping(input ShippingCalculationInput)
 		Warnings:    []string{},
 	}
 
+	// Flag incomplete addresses before they silently default the zone
+	result.Warnings = append(result.Warnings, sc.validateAddresses(input)...)
+
+	// Flag items too heavy to ship as a single package
+	splitPackages, weightWarnings := sc.checkItemWeights(input.Items)
+	result.SplitPackages = splitPackages
+	result.Warnings = append(result.Warnings, weightWarnings...)
+
 	// Determine shipping zone
 	zone := sc.determineShippingZone(input.Origin, input.Destination)
 	result.Zone = zone
@@ -234,6 +267,7 @@ func (sc *ShippingCalculator) CalculateShipping(input ShippingCalculationInput)
 			Cost:            10.0,
 			BaseCost:        10.0,
 			EstimatedDays:   5,
+			DeliveryWindow:  sc.calculateDeliveryWindow(ShippingMethodStandard, zone, 5),
 			Zone:            zone,
 			Description:     "Standard shipping",
 			TrackingIncluded: false,
@@ -263,9 +297,28 @@ func (sc *ShippingCalculator) CalculateShipping(input ShippingCalculationInput)
 		}
 	}
 
+	// Keep checkout possible when nothing qualified
+	if len(result.Options) == 0 && sc.FallbackOption != nil {
+		result.Options = append(result.Options, sc.fallbackOption(zone))
+		result.Warnings = append(result.Warnings, "No shipping options qualified; offering fallback option")
+	}
+
+	// Apply progressive shipping discounts by order value
+	sc.applyShippingDiscounts(&result, input)
+
 	// Check for free shipping eligibility
 	sc.applyFreeShipping(&result, input)
 
+	// Report the click-and-collect order discount
+	result.PickupDiscount = pickupDiscount(&result, input)
+
+	// Convert final costs into the customer's currency
+	if err := convertOptionCosts(&result, input); err != nil {
+		result.IsValid = false
+		result.ErrorMessage = err.Error()
+		return result
+	}
+
 	// Sort options by cost
 	sort.Slice(result.Options, func(i, j int) bool {
 		return result.Options[i].Cost < result.Options[j].Cost
@@ -277,6 +330,35 @@ func (sc *ShippingCalculator) CalculateShipping(input ShippingCalculationInput)
 	return result
 }
 
+// fallbackOption returns a copy of the configured FallbackOption for the current
+// shipment. The zone and delivery window are filled in when the configuration
+// leaves them empty, and Metadata["fallback"] is set so analytics can count how
+// often no regular option qualified.
+//
+// Parameters:
+//   - zone: Shipping zone of the current shipment
+//
+// Returns:
+//   - ShippingOption: Fallback option ready to add to the result
+func (sc *ShippingCalculator) fallbackOption(zone ShippingZone) ShippingOption {
+	option := *sc.FallbackOption
+	if option.Zone == "" {
+		option.Zone = zone
+	}
+	if option.DeliveryWindow == (DeliveryWindow{}) && option.EstimatedDays > 0 {
+		option.DeliveryWindow = sc.calculateDeliveryWindow(option.Method, option.Zone, option.EstimatedDays)
+	}
+
+	metadata := make(map[string]interface{}, len(option.Metadata)+1)
+	for key, value := range option.Metadata {
+		metadata[key] = value
+	}
+	metadata["fallback"] = true
+	option.Metadata = metadata
+
+	return option
+}
+
 // calculateShippingOption calculates the total cost for a specific shipping option.
 // This function handles the core cost calculation logic including base rates,
 // weight-based pricing, value-based pricing, dimensional weight, and surcharges.
@@ -347,36 +429,52 @@ func (sc *ShippingCalculator) calculateShippingOption(rule ShippingRule, input S
 	}
 
 	// Calculate base cost
-	cost := rule.BaseCost
+	breakdown := CostBreakdown{Base: rule.BaseCost}
 
 	// Apply flat rate if specified
 	if rule.FlatRate > 0 {
-		cost = rule.FlatRate
+		breakdown.Base = rule.FlatRate
 	} else {
 		// Apply weight-based pricing
 		if rule.WeightRate > 0 {
 			weightInRuleUnit := convertWeight(totalWeight, WeightUnitKG) // Convert to kg for calculation
-			cost += weightInRuleUnit * rule.WeightRate
+			breakdown.Weight = weightInRuleUnit * rule.WeightRate
 		}
 
 		// Apply value-based pricing
 		if rule.ValueRate > 0 {
-			cost += totalValue * (rule.ValueRate / 100)
+			breakdown.Value = totalValue * (rule.ValueRate / 100)
 		}
 
 		// Apply dimensional weight pricing
 		if rule.DimensionalRate > 0 {
 			dimensionalWeight := calculateDimensionalWeight(input.Items)
-			cost += dimensionalWeight.Value * rule.DimensionalRate
+			breakdown.Dimensional = dimensionalWeight.Value * rule.DimensionalRate
 		}
 	}
 
 	// Apply surcharges
-	appliedSurcharges := sc.calculateSurcharges(rule.Surcharges, input.Items, totalValue)
+	appliedSurcharges := sc.calculateSurcharges(rule.Surcharges, input.Items, totalValue, input.Destination)
 	for _, surcharge := range appliedSurcharges {
-		cost += surcharge.Amount
+		breakdown.Surcharges += surcharge.Amount
+	}
+
+	cost := breakdown.Base + breakdown.Weight + breakdown.Value + breakdown.Dimensional + breakdown.Surcharges
+
+	// Clamp the computed cost to the rule's bounds before rounding
+	var metadata map[string]interface{}
+	if clamped, bound := clampShippingCost(cost, rule); bound != "" {
+		metadata = map[string]interface{}{
+			"cost_clamp":     bound,
+			"unclamped_cost": math.Round(cost*100) / 100,
+		}
+		breakdown.Clamp = clamped - cost
+		cost = clamped
 	}
 
+	roundedCost := math.Round(cost*100) / 100 // Round to 2 decimal places
+	breakdown.reconcile(roundedCost)
+
 	// Calculate delivery time
 	estimatedDays := sc.calculateDeliveryTime(rule.Method, zone, totalWeight, distance)
 
@@ -384,15 +482,18 @@ func (sc *ShippingCalculator) calculateShippingOption(rule ShippingRule, input S
 		ID:              rule.ID,
 		Method:          rule.Method,
 		ServiceName:     rule.Name,
-		Cost:            math.Round(cost*100) / 100, // Round to 2 decimal places
+		Cost:            roundedCost,
 		BaseCost:        rule.BaseCost,
+		CostBreakdown:   &breakdown,
 		Surcharges:      appliedSurcharges,
 		EstimatedDays:   estimatedDays,
+		DeliveryWindow:  sc.calculateDeliveryWindow(rule.Method, zone, estimatedDays),
 		Zone:            zone,
 		Description:     fmt.Sprintf("%s shipping via %s", rule.Method, rule.Name),
 		TrackingIncluded: rule.Method != ShippingMethodStandard,
 		InsuranceIncluded: totalValue > 100, // Include insurance for valuable items
 		SignatureRequired: totalValue > 500, // Require signature for high-value items
+		Metadata:        metadata,
 	}
 
 	// Set delivery date
@@ -403,6 +504,45 @@ func (sc *ShippingCalculator) calculateShippingOption(rule ShippingRule, input S
 	return option
 }
 
+// clampShippingCost bounds a computed rule cost by the rule's MinCost and MaxCost.
+// A bound of 0 is treated as unset.
+//
+// Parameters:
+//   - cost: Unrounded cost computed from the rule's components and surcharges
+//   - rule: Shipping rule carrying the bounds
+//
+// Returns:
+//   - float64: Cost after clamping
+//   - string: "min" or "max" when a bound applied, empty otherwise
+func clampShippingCost(cost float64, rule ShippingRule) (float64, string) {
+	if rule.MinCost > 0 && cost < rule.MinCost {
+		return rule.MinCost, "min"
+	}
+	if rule.MaxCost > 0 && cost > rule.MaxCost {
+		return rule.MaxCost, "max"
+	}
+	return cost, ""
+}
+
+// Total returns the option cost the breakdown accounts for: the sum of all
+// components minus Discount.
+func (b CostBreakdown) Total() float64 {
+	total := b.Base + b.Weight + b.Value + b.Dimensional + b.Surcharges + b.Clamp + b.Rounding - b.Discount
+	return math.Round(total*100) / 100
+}
+
+// reconcile rounds each component to cents and records in Rounding whatever is
+// needed for the components to add up to the rounded cost.
+func (b *CostBreakdown) reconcile(cost float64) {
+	components := []*float64{&b.Base, &b.Weight, &b.Value, &b.Dimensional, &b.Surcharges, &b.Clamp}
+	sum := 0.0
+	for _, component := range components {
+		*component = math.Round(*component*100) / 100
+		sum += *component
+	}
+	b.Rounding = math.Round((cost-sum)*100) / 100
+}
+
 // calculateCarrierOption calculates the cost for a specific carrier's shipping option.
 // This function applies carrier-specific rules, service levels, and pricing structures.
 //
@@ -412,6 +552,7 @@ func (sc *ShippingCalculator) calculateShippingOption(rule ShippingRule, input S
 //   - Carrier-specific surcharges and discounts
 //   - Volume-based pricing tiers
 //   - Special handling requirements
+//   - Origin-destination transit time matrix (falls back to DeliveryDays)
 //
 // Parameters:
 //   - carrier: Carrier information and rules
@@ -458,6 +599,12 @@ func (sc *ShippingCalculator) calculateCarrierOption(rule CarrierRule, input Shi
 		cost += zoneRate
 	}
 
+	// Prefer the carrier's published transit time for this origin-destination pair
+	deliveryDays := rule.DeliveryDays
+	if transitDays, found := carrierTransitDays(rule, input.Origin, input.Destination); found {
+		deliveryDays = transitDays
+	}
+
 	option := &ShippingOption{
 		ID:                fmt.Sprintf("%s_%s", rule.CarrierID, rule.ServiceCode),
 		Method:            rule.Method,
@@ -466,7 +613,8 @@ func (sc *ShippingCalculator) calculateCarrierOption(rule CarrierRule, input Shi
 		ServiceName:       fmt.Sprintf("%s %s", rule.CarrierName, rule.Method),
 		Cost:              math.Round(cost*100) / 100,
 		BaseCost:          rule.BaseCost,
-		EstimatedDays:     rule.DeliveryDays,
+		EstimatedDays:     deliveryDays,
+		DeliveryWindow:    sc.calculateDeliveryWindow(rule.Method, zone, deliveryDays),
 		Zone:              zone,
 		TrackingIncluded:  rule.TrackingIncluded,
 		InsuranceIncluded: rule.InsuranceIncluded,
@@ -474,15 +622,76 @@ func (sc *ShippingCalculator) calculateCarrierOption(rule CarrierRule, input Shi
 		Description:       fmt.Sprintf("%s shipping via %s", rule.Method, rule.CarrierName),
 	}
 
-	if rule.DeliveryDays > 0 {
-		option.DeliveryDate = time.Now().AddDate(0, 0, rule.DeliveryDays)
+	if deliveryDays > 0 {
+		option.DeliveryDate = time.Now().AddDate(0, 0, deliveryDays)
 	}
 
 	return option
 }
 
+// carrierTransitDays looks up transit days in a carrier's origin-destination zone matrix.
+// Each address is mapped to a carrier transit zone by its state, falling back to its country.
+//
+// Parameters:
+//   - rule: Carrier rule with TransitZones and TransitMatrix
+//   - origin: Shipment origin address
+//   - destination: Shipment destination address
+//
+// Returns:
+//   - int: Transit days for the zone pair
+//   - bool: True if both addresses map to zones with a matrix entry
+//
+// Example:
+//   - TransitZones: NY -> East, CA -> West
+//   - TransitMatrix: East -> West = 4 days
+//   - Origin NY, Destination CA: 4 days
+func carrierTransitDays(rule CarrierRule, origin, destination Address) (int, bool) {
+	if len(rule.TransitMatrix) == 0 {
+		return 0, false
+	}
+
+	originZone, found := carrierTransitZone(rule.TransitZones, origin)
+	if !found {
+		return 0, false
+	}
+	destinationZone, found := carrierTransitZone(rule.TransitZones, destination)
+	if !found {
+		return 0, false
+	}
+
+	days, found := rule.TransitMatrix[originZone][destinationZone]
+	return days, found
+}
+
+// carrierTransitZone maps an address to a carrier transit zone by state, then by country.
+func carrierTransitZone(zones map[string]string, address Address) (string, bool) {
+	if zone, found := zones[address.State]; found && address.State != "" {
+		return zone, true
+	}
+	if zone, found := zones[address.Country]; found && address.Country != "" {
+		return zone, true
+	}
+	return "", false
+}
+
 // Helper functions
 
+// validateItems rejects items that cannot be shipped meaningfully. As in the
+// discount, coupon, tax and pricing packages, every item must carry a positive
+// quantity, and a negative declared value is an input error.
+// Zero-value items (free samples, inserts) are valid and add weight only.
+func validateItems(items []ShippingItem) error {
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return fmt.Errorf("item %s has invalid quantity %d", item.ID, item.Quantity)
+		}
+		if item.Value < 0 {
+			return fmt.Errorf("item %s has negative value", item.ID)
+		}
+	}
+	return nil
+}
+
 // calculateTotalWeight calculates the total weight of all items in the shipment.
 // This function aggregates weights from multiple items, handling unit conversions
 // to ensure consistent weight calculations across different measurement systems.
@@ -508,11 +717,7 @@ func calculateTotalWeight(items []ShippingItem) Weight {
 	unit := WeightUnitKG // Default unit
 
 	for _, item := range items {
-		quantity := item.Quantity
-		if quantity == 0 {
-			quantity = 1 // Default quantity to 1 if not specified
-		}
-		itemWeight := convertWeight(item.Weight, WeightUnitKG) * float64(quantity)
+		itemWeight := convertWeight(item.Weight, WeightUnitKG) * float64(item.Quantity)
 		totalWeight += itemWeight
 	}
 
@@ -547,6 +752,70 @@ func calculateTotalValue(items []ShippingItem) float64 {
 	return totalValue
 }
 
+// checkItemWeights checks each item line against the calculator's MaxItemWeight.
+// Unlike the shipping rules' MaxWeight, which caps the whole shipment, this is a
+// carrier's per-package limit.
+//
+// A unit heavier than the limit can never ship as one package and is always
+// flagged. A line whose units only exceed the limit together is split into
+// packages holding as many units as fit when SplitOverweightItems is set, and is
+// flagged otherwise. Overweight units are packed one per package when splitting.
+//
+// Parameters:
+//   - items: List of items to check
+//
+// Returns:
+//   - []Package: Packages for lines that were split, nil when nothing was split
+//   - []string: Warnings for items exceeding the limit
+func (sc *ShippingCalculator) checkItemWeights(items []ShippingItem) ([]Package, []string) {
+	limit := sc.MaxItemWeight
+	if limit.Value <= 0 {
+		return nil, nil
+	}
+
+	var packages []Package
+	var warnings []string
+	for _, item := range items {
+		quantity := item.Quantity
+		unitWeight := convertWeight(item.Weight, limit.Unit)
+		if unitWeight*float64(quantity) <= limit.Value {
+			continue
+		}
+
+		unitsPerPackage := 1
+		if unitWeight > limit.Value {
+			warnings = append(warnings, fmt.Sprintf("item %s weighs %.2f %s per unit, exceeding the %.2f %s package limit",
+				item.ID, unitWeight, limit.Unit, limit.Value, limit.Unit))
+		} else {
+			unitsPerPackage = int(limit.Value / unitWeight)
+			if !sc.SplitOverweightItems {
+				warnings = append(warnings, fmt.Sprintf("item %s (quantity %d) exceeds the %.2f %s package limit and needs multiple packages",
+					item.ID, quantity, limit.Value, limit.Unit))
+			}
+		}
+		if !sc.SplitOverweightItems {
+			continue
+		}
+
+		for packed := 0; packed < quantity; packed += unitsPerPackage {
+			units := utils.MinInt(unitsPerPackage, quantity-packed)
+			packageItem := item
+			packageItem.Quantity = units
+			packages = append(packages, Package{
+				ID:          fmt.Sprintf("%s-%d", item.ID, packed/unitsPerPackage+1),
+				Items:       []ShippingItem{packageItem},
+				Weight:      Weight{Value: unitWeight * float64(units), Unit: limit.Unit},
+				Dimensions:  item.Dimensions,
+				Value:       item.Value * float64(units),
+				IsFragile:   item.IsFragile,
+				IsHazardous: item.IsHazardous,
+			})
+		}
+	}
+
+	return packages, warnings
+}
+
 // calculateDimensionalWeight calculates the dimensional weight of the shipment.
 // Dimensional weight is used by carriers to account for large, lightweight packages
 // that take up significant space. The higher of actual weight or dimensional weight
@@ -812,34 +1081,66 @@ func (sc *ShippingCalculator) addressMatchesZoneRule(address Address, rule ZoneR
 	}
 
 	// Check postal codes
-	if len(rule.PostalCodes) > 0 {
-		postalMatches := false
-		for _, postalCode := range rule.PostalCodes {
-			if address.PostalCode == postalCode {
-				postalMatches = true
+	if len(rule.PostalCodes) > 0 && !postalCodeInList(address.PostalCode, rule.PostalCodes) {
+		return false
+	}
+
+	// Check postal code ranges
+	if len(rule.PostalCodeRanges) > 0 && !postalCodeInRanges(address.PostalCode, rule.PostalCodeRanges) {
+		return false
+	}
+
+	return true
+}
+
+// addressInRemoteArea checks if address falls in a remote area. The country must
+// match when the area lists countries, and the postal code must match any of the
+// area's codes, prefixes or ranges.
+func (sc *ShippingCalculator) addressInRemoteArea(address Address, area RemoteArea) bool {
+	if address.PostalCode == "" {
+		return false
+	}
+
+	if len(area.Countries) > 0 {
+		countryMatches := false
+		for _, country := range area.Countries {
+			if address.Country == country {
+				countryMatches = true
 				break
 			}
 		}
-		if !postalMatches {
+		if !countryMatches {
 			return false
 		}
 	}
 
-	// Check postal code ranges
-	if len(rule.PostalCodeRanges) > 0 {
-		rangeMatches := false
-		for _, pcRange := range rule.PostalCodeRanges {
-			if address.PostalCode >= pcRange.Start && address.PostalCode <= pcRange.End {
-				rangeMatches = true
-				break
-			}
+	for _, prefix := range area.PostalCodePrefixes {
+		if prefix != "" && strings.HasPrefix(address.PostalCode, prefix) {
+			return true
 		}
-		if !rangeMatches {
-			return false
+	}
+
+	return postalCodeInList(address.PostalCode, area.PostalCodes) || postalCodeInRanges(address.PostalCode, area.PostalCodeRanges)
+}
+
+// postalCodeInList checks if postal code is one of codes
+func postalCodeInList(postalCode string, codes []string) bool {
+	for _, code := range codes {
+		if postalCode == code {
+			return true
 		}
 	}
+	return false
+}
 
-	return true
+// postalCodeInRanges checks if postal code falls within any of the ranges
+func postalCodeInRanges(postalCode string, ranges []PostalCodeRange) bool {
+	for _, pcRange := range ranges {
+		if postalCode >= pcRange.Start && postalCode <= pcRange.End {
+			return true
+		}
+	}
+	return false
 }
 
 // calculateDistance calculates the great-circle distance between two addresses using the Haversine formula.
@@ -960,6 +1261,57 @@ func (sc *ShippingCalculator) calculateDeliveryTime(method ShippingMethod, zone
 	}
 }
 
+// calculateDeliveryWindow widens a point delivery estimate into an earliest/latest range.
+// The variance comes from the matching DeliveryTimeRule when one exists, otherwise from
+// a per-method default: same-day, overnight and pickup are exact, express varies by one
+// day, and standard by two.
+//
+// Parameters:
+//   - method: Shipping method used to look up the variance
+//   - zone: Shipping zone used to look up the variance
+//   - estimatedDays: Point estimate from calculateDeliveryTime or the carrier rule
+//
+// Returns:
+//   - DeliveryWindow: Earliest and latest delivery days, never below zero
+//
+// Example:
+//   - Method: Standard, EstimatedDays: 5, no matching rule
+//   - Result: DeliveryWindow{EarliestDays: 3, LatestDays: 7}
+func (sc *ShippingCalculator) calculateDeliveryWindow(method ShippingMethod, zone ShippingZone, estimatedDays int) DeliveryWindow {
+	variance := defaultDeliveryVariance(method)
+	for _, rule := range sc.DeliveryTimeRules {
+		if rule.Method == method && rule.Zone == zone {
+			variance = rule.VarianceDays
+			break
+		}
+	}
+
+	earliest := estimatedDays - variance
+	if earliest < 0 {
+		earliest = 0
+	}
+
+	return DeliveryWindow{
+		EarliestDays: earliest,
+		LatestDays:   estimatedDays + variance,
+	}
+}
+
+// defaultDeliveryVariance returns the delivery window variance in days for a method
+// without a matching DeliveryTimeRule.
+func defaultDeliveryVariance(method ShippingMethod) int {
+	switch method {
+	case ShippingMethodSameDay, ShippingMethodOvernight, ShippingMethodPickup:
+		return 0
+	case ShippingMethodExpress:
+		return 1
+	case ShippingMethodStandard:
+		return 2
+	default:
+		return 1
+	}
+}
+
 // calculateSurcharges calculates applicable surcharges based on item characteristics and shipment value.
 // This function evaluates various surcharge types including fragile handling, hazardous materials,
 // oversized items, fuel surcharges, and insurance premiums.
@@ -970,7 +1322,7 @@ func (sc *ShippingCalculator) calculateDeliveryTime(method ShippingMethod, zone
 //   - Oversized: Additional fees for large packages
 //   - Fuel: Variable fuel cost adjustments
 //   - Insurance: Value-based insurance premiums
-//   - Remote area: Extra fees for difficult-to-reach destinations
+//   - Remote area: Extra fees for destinations in the calculator's RemoteAreas
 //
 // Calculation Process:
 //   1. Evaluate each surcharge rule against items and shipment
@@ -983,6 +1335,7 @@ func (sc *ShippingCalculator) calculateDeliveryTime(method ShippingMethod, zone
 //   - surcharges: List of surcharge rules to evaluate
 //   - items: List of shipping items to check against rules
 //   - totalValue: Total shipment value for percentage-based surcharges
+//   - destination: Delivery address checked against remote areas
 //
 // Returns:
 //   - []AppliedSurcharge: List of surcharges that apply with calculated amounts
@@ -991,11 +1344,11 @@ func (sc *ShippingCalculator) calculateDeliveryTime(method ShippingMethod, zone
 //   - Fragile item surcharge: +$5.00
 //   - Insurance (0.5% of $500): +$2.50
 //   - Total applied surcharges: $7.50
-func (sc *ShippingCalculator) calculateSurcharges(surcharges []Surcharge, items []ShippingItem, totalValue float64) []AppliedSurcharge {
+func (sc *ShippingCalculator) calculateSurcharges(surcharges []Surcharge, items []ShippingItem, totalValue float64, destination Address) []AppliedSurcharge {
 	applied := []AppliedSurcharge{}
 
 	for _, surcharge := range surcharges {
-		if sc.shouldApplySurcharge(surcharge, items, totalValue) {
+		if sc.shouldApplySurcharge(surcharge, items, totalValue, destination) {
 			amount := surcharge.Amount
 			if surcharge.IsPercentage {
 				amount = totalValue * (surcharge.Amount / 100)
@@ -1014,7 +1367,7 @@ func (sc *ShippingCalculator) calculateSurcharges(surcharges []Surcharge, items
 }
 
 // shouldApplySurcharge determines if a surcharge should be applied
-func (sc *ShippingCalculator) shouldApplySurcharge(surcharge Surcharge, items []ShippingItem, totalValue float64) bool {
+func (sc *ShippingCalculator) shouldApplySurcharge(surcharge Surcharge, items []ShippingItem, totalValue float64, destination Address) bool {
 	switch surcharge.Type {
 	case "fragile":
 		for _, item := range items {
@@ -1041,6 +1394,13 @@ func (sc *ShippingCalculator) shouldApplySurcharge(surcharge Surcharge, items []
 	case "insurance":
 		// Apply insurance surcharge for high-value shipments
 		return totalValue > 1000
+	case "remote_area":
+		// Carrier delivery area surcharge for remote destinations
+		for _, area := range sc.RemoteAreas {
+			if sc.addressInRemoteArea(destination, area) {
+				return true
+			}
+		}
 	}
 
 	return false
@@ -1207,6 +1567,7 @@ func (sc *ShippingCalculator) checkDimensionLimits(items []ShippingItem, maxDime
 //   - Product categories or item types
 //   - Promotional campaigns or coupon codes
 //   - Weight or quantity thresholds
+//   - Maximum actual or dimensional weight (heavy or bulky orders never qualify)
 //
 // Application Logic:
 //   1. Check each free shipping rule for applicability
@@ -1219,13 +1580,20 @@ func (sc *ShippingCalculator) checkDimensionLimits(items []ShippingItem, maxDime
 //   - result: Shipping calculation result to modify
 //   - input: Shipping calculation input with order details
 //
+// Order value thresholds use input.OrderTotal (the post-discount total) when it is
+// set, falling back to the raw item value. If no rule qualifies, the shortfall to
+// the nearest reachable threshold is recorded in result.AmountToFreeShipping.
+//
 // Example:
 //   - Rule: Free standard shipping on orders over $50
 //   - Order value: $75
 //   - Result: Standard shipping cost reduced to $0.00
 func (sc *ShippingCalculator) applyFreeShipping(result *ShippingCalculationResult, input ShippingCalculationInput) {
+	orderTotal := orderValue(result, input)
+
+	result.AmountToFreeShipping = 0
 	for _, rule := range sc.FreeShippingRules {
-		if sc.qualifiesForFreeShipping(rule, input, result.TotalValue) {
+		if sc.qualifiesForFreeShipping(rule, input, orderTotal) {
 			// Find cheapest option and make it free
 			if len(result.Options) > 0 {
 				cheapestIndex := 0
@@ -1234,12 +1602,201 @@ func (sc *ShippingCalculator) applyFreeShipping(result *ShippingCalculationResul
 						cheapestIndex = i
 					}
 				}
+				if breakdown := result.Options[cheapestIndex].CostBreakdown; breakdown != nil {
+					breakdown.Discount += result.Options[cheapestIndex].Cost
+				}
 				result.Options[cheapestIndex].Cost = 0
 				result.Options[cheapestIndex].ServiceName += " (Free Shipping)"
 			}
-			break
+			return
+		}
+	}
+
+	result.AmountToFreeShipping = sc.amountToFreeShipping(input, orderTotal)
+}
+
+// pickupDiscount returns the order discount earned by choosing in-store pickup, or nil
+// when no incentive is configured, the customer requested another method, the order
+// value is below the incentive minimum, or no zero-cost pickup option is offered.
+// Percentage discounts are taken from the order value and capped at MaxDiscount.
+//
+// Parameters:
+//   - result: Shipping calculation result with final option costs
+//   - input: Shipping calculation input carrying the pickup incentive
+//
+// Returns:
+//   - *OrderDiscount: Discount tied to the qualifying pickup option, or nil
+//
+// Example:
+//   - Incentive: 5% off orders over $25, capped at $10
+//   - Order value: $90, free store pickup offered
+//   - Result: $4.50 order discount for the pickup option
+func pickupDiscount(result *ShippingCalculationResult, input ShippingCalculationInput) *OrderDiscount {
+	incentive := input.PickupIncentive
+	if incentive == nil || incentive.DiscountValue <= 0 {
+		return nil
+	}
+	if input.RequestedMethod != "" && input.RequestedMethod != ShippingMethodPickup {
+		return nil
+	}
+
+	orderTotal := orderValue(result, input)
+	if orderTotal < incentive.MinOrderValue {
+		return nil
+	}
+
+	for _, option := range result.Options {
+		if option.Method != ShippingMethodPickup || option.Cost != 0 {
+			continue
+		}
+
+		amount := incentive.DiscountValue
+		if incentive.DiscountType == "percentage" {
+			amount = orderTotal * (incentive.DiscountValue / 100)
+			if incentive.MaxDiscount > 0 {
+				amount = math.Min(amount, incentive.MaxDiscount)
+			}
+		}
+		amount = math.Min(amount, orderTotal)
+
+		return &OrderDiscount{
+			OptionID:    option.ID,
+			Type:        incentive.DiscountType,
+			Value:       incentive.DiscountValue,
+			Amount:      math.Round(amount*100) / 100,
+			Description: incentive.Description,
 		}
 	}
+
+	return nil
+}
+
+// orderValue returns the order value used for value thresholds: input.OrderTotal
+// (the post-discount total) when set, otherwise the raw item value.
+func orderValue(result *ShippingCalculationResult, input ShippingCalculationInput) float64 {
+	if input.OrderTotal > 0 {
+		return input.OrderTotal
+	}
+	return result.TotalValue
+}
+
+// applyShippingDiscounts reduces option costs using progressive shipping discount rules.
+// For each rule the highest tier reached by the order value is used; when several
+// rules apply to an option, the largest discount wins. Discounts never make an
+// option's cost negative and are recorded in ShippingOption.Discount.
+//
+// Parameters:
+//   - result: Shipping calculation result whose options are discounted
+//   - input: Shipping calculation input with order details
+//
+// Example:
+//   - Rule: $5 off shipping over $50, $10 off over $100
+//   - Order value: $120, standard shipping $12.00
+//   - Result: Standard shipping cost reduced to $2.00 with a $10.00 discount
+func (sc *ShippingCalculator) applyShippingDiscounts(result *ShippingCalculationResult, input ShippingCalculationInput) {
+	orderTotal := orderValue(result, input)
+	now := time.Now()
+
+	for i := range result.Options {
+		option := &result.Options[i]
+
+		bestDiscount := 0.0
+		for _, rule := range sc.ShippingDiscountRules {
+			if !rule.IsActive || now.Before(rule.ValidFrom) || now.After(rule.ValidUntil) {
+				continue
+			}
+			if !containsZone(rule.ApplicableZones, option.Zone) || !containsMethod(rule.ApplicableMethods, option.Method) {
+				continue
+			}
+
+			tier := highestShippingDiscountTier(rule.Tiers, orderTotal)
+			if tier == nil {
+				continue
+			}
+
+			discount := tier.DiscountValue
+			if tier.DiscountType == "percentage" {
+				discount = option.Cost * (tier.DiscountValue / 100)
+			}
+			if discount > bestDiscount {
+				bestDiscount = discount
+			}
+		}
+
+		bestDiscount = math.Min(bestDiscount, option.Cost)
+		if bestDiscount <= 0 {
+			continue
+		}
+
+		costBefore := option.Cost
+		option.Discount = math.Round(bestDiscount*100) / 100
+		option.Cost = math.Round((option.Cost-bestDiscount)*100) / 100
+		if option.CostBreakdown != nil {
+			option.CostBreakdown.Discount += math.Round((costBefore-option.Cost)*100) / 100
+		}
+	}
+}
+
+// highestShippingDiscountTier returns the tier with the largest threshold the order value reaches,
+// or nil if the order value is below every tier.
+func highestShippingDiscountTier(tiers []ShippingDiscountTier, orderTotal float64) *ShippingDiscountTier {
+	var best *ShippingDiscountTier
+	for i := range tiers {
+		if orderTotal < tiers[i].MinOrderValue {
+			continue
+		}
+		if best == nil || tiers[i].MinOrderValue > best.MinOrderValue {
+			best = &tiers[i]
+		}
+	}
+	return best
+}
+
+// containsZone reports whether zone is in zones; an empty list matches every zone.
+func containsZone(zones []ShippingZone, zone ShippingZone) bool {
+	if len(zones) == 0 {
+		return true
+	}
+	for _, z := range zones {
+		if z == zone {
+			return true
+		}
+	}
+	return false
+}
+
+// containsMethod reports whether method is in methods; an empty list matches every method.
+func containsMethod(methods []ShippingMethod, method ShippingMethod) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// amountToFreeShipping returns the smallest amount that would lift the order total
+// over a free shipping threshold. Only rules the order would otherwise satisfy
+// (zone, weight, categories, validity) are considered.
+func (sc *ShippingCalculator) amountToFreeShipping(input ShippingCalculationInput, orderTotal float64) float64 {
+	shortfall := 0.0
+	for _, rule := range sc.FreeShippingRules {
+		if rule.MinOrderValue <= orderTotal {
+			continue
+		}
+		if !sc.qualifiesForFreeShipping(rule, input, rule.MinOrderValue) {
+			continue
+		}
+
+		remaining := rule.MinOrderValue - orderTotal
+		if shortfall == 0 || remaining < shortfall {
+			shortfall = remaining
+		}
+	}
+	return math.Round(shortfall*100) / 100
 }
 
 // qualifiesForFreeShipping checks if order qualifies for free shipping
@@ -1266,6 +1823,20 @@ func (sc *ShippingCalculator) qualifiesForFreeShipping(rule FreeShippingRule, in
 		}
 	}
 
+	// Heavy or bulky shipments are disqualified regardless of value
+	if rule.MaxWeight.Value > 0 {
+		totalWeight := calculateTotalWeight(input.Items)
+		if convertWeight(totalWeight, rule.MaxWeight.Unit) > rule.MaxWeight.Value {
+			return false
+		}
+	}
+	if rule.MaxDimensionalWeight.Value > 0 {
+		dimensionalWeight := calculateDimensionalWeight(input.Items)
+		if convertWeight(dimensionalWeight, rule.MaxDimensionalWeight.Unit) > rule.MaxDimensionalWeight.Value {
+			return false
+		}
+	}
+
 	// Check applicable zones
 	if len(rule.ApplicableZones) > 0 {
 		zone := sc.determineShippingZone(input.Origin, input.Destination)
@@ -1312,6 +1883,76 @@ func (sc *ShippingCalculator) qualifiesForFreeShipping(rule FreeShippingRule, in
 	return true
 }
 
+// validateAddresses checks the origin and destination with utils.ValidateAddress and
+// utils.AddressWarnings and returns any problems as warnings. The destination's state and postal code are only
+// expected when a shipping or zone rule matches on them.
+func (sc *ShippingCalculator) validateAddresses(input ShippingCalculationInput) []string {
+	required := utils.AddressRequirements{}
+	for _, rule := range input.ShippingRules {
+		if len(rule.ApplicableStates) > 0 {
+			required.State = true
+		}
+	}
+	for _, rule := range sc.ZoneRules {
+		if len(rule.States) > 0 {
+			required.State = true
+		}
+		if len(rule.PostalCodes) > 0 || len(rule.PostalCodeRanges) > 0 {
+			required.PostalCode = true
+		}
+	}
+
+	warnings := []string{}
+	addresses := []struct {
+		label    string
+		address  Address
+		required utils.AddressRequirements
+	}{
+		{"origin", input.Origin, utils.AddressRequirements{}},
+		{"destination", input.Destination, required},
+	}
+	for _, a := range addresses {
+		address := utils.Address{Country: a.address.Country, State: a.address.State, PostalCode: a.address.PostalCode}
+		if err := utils.ValidateAddress(address); err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: %v", a.label, err))
+			continue
+		}
+		for _, warning := range utils.AddressWarnings(address, a.required) {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", a.label, warning))
+		}
+	}
+	return warnings
+}
+
+// convertOptionCosts fills CostInCurrency on every option when the input carries a
+// currency converter and target currency. Costs are treated as BaseCurrency amounts
+// (USD when unset). The raw Cost is never modified.
+func convertOptionCosts(result *ShippingCalculationResult, input ShippingCalculationInput) error {
+	if input.CurrencyConverter == nil || input.Currency == "" {
+		return nil
+	}
+
+	baseCurrency := input.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = currency.USD
+	}
+
+	for i := range result.Options {
+		option := &result.Options[i]
+		conversion, err := input.CurrencyConverter.Convert(currency.ConversionInput{
+			Amount: option.Cost,
+			From:   baseCurrency,
+			To:     input.Currency,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to convert shipping cost for option %s from %s to %s: %w", option.ID, baseCurrency, input.Currency, err)
+		}
+		converted := conversion.ConvertedAmount
+		option.CostInCurrency = &converted
+	}
+	return nil
+}
+
 // setRecommendedOptions sets recommended, cheapest, and fastest options
 func (sc *ShippingCalculator) setRecommendedOptions(result *ShippingCalculationResult) {
 	if len(result.Options) == 0 {
@@ -151,6 +151,42 @@ func NewShippingRuleEngine() *ShippingRuleEngine {
 //		log.Printf("Failed to add rule: %v", err)
 //	}
 func (sre *ShippingRuleEngine) AddShippingRule(rule ShippingRule) error {
+	if err := ValidateShippingRule(rule); err != nil {
+		return err
+	}
+
+	// Check for duplicate ID
+	for _, existingRule := range sre.ShippingRules {
+		if existingRule.ID == rule.ID {
+			return fmt.Errorf("shipping rule with ID %s already exists", rule.ID)
+		}
+	}
+
+	sre.ShippingRules = append(sre.ShippingRules, rule)
+	return nil
+}
+
+// ValidateShippingRule checks a shipping rule's own fields, independent of any
+// engine. AddShippingRule runs it before checking for duplicate IDs.
+//
+// Validation checks:
+//   - ID and name are not empty
+//   - Base cost and rates are non-negative
+//   - Minimum weight does not exceed maximum weight
+//   - Valid from date is before valid until date
+//
+// Parameters:
+//   - rule: The shipping rule to validate
+//
+// Returns:
+//   - error: nil if valid, error describing the first problem otherwise
+//
+// Example:
+//
+//	if err := shipping.ValidateShippingRule(rule); err != nil {
+//		log.Printf("Invalid shipping rule %s: %v", rule.ID, err)
+//	}
+func ValidateShippingRule(rule ShippingRule) error {
 	if rule.ID == "" {
 		return errors.New("shipping rule ID cannot be empty")
 	}
@@ -163,14 +199,27 @@ func (sre *ShippingRuleEngine) AddShippingRule(rule ShippingRule) error {
 		return errors.New("base cost cannot be negative")
 	}
 
-	// Check for duplicate ID
-	for _, existingRule := range sre.ShippingRules {
-		if existingRule.ID == rule.ID {
-			return fmt.Errorf("shipping rule with ID %s already exists", rule.ID)
-		}
+	if rule.WeightRate < 0 || rule.ValueRate < 0 || rule.DimensionalRate < 0 {
+		return errors.New("shipping rates cannot be negative")
+	}
+
+	if rule.MinWeight.Value > 0 && rule.MaxWeight.Value > 0 &&
+		convertWeight(rule.MinWeight, WeightUnitKG) > convertWeight(rule.MaxWeight, WeightUnitKG) {
+		return errors.New("minimum weight cannot exceed maximum weight")
+	}
+
+	if rule.MinCost < 0 || rule.MaxCost < 0 {
+		return errors.New("cost bounds cannot be negative")
+	}
+
+	if rule.MinCost > 0 && rule.MaxCost > 0 && rule.MinCost > rule.MaxCost {
+		return errors.New("minimum cost cannot exceed maximum cost")
+	}
+
+	if !rule.ValidFrom.IsZero() && !rule.ValidUntil.IsZero() && rule.ValidFrom.After(rule.ValidUntil) {
+		return errors.New("valid from date must be before valid until date")
 	}
 
-	sre.ShippingRules = append(sre.ShippingRules, rule)
 	return nil
 }
 
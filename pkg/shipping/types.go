@@ -61,7 +61,11 @@
 //
 package shipping
 
-import "time"
+import (
+	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/currency"
+)
 
 // ShippingMethod represents different shipping methods available for delivery.
 // Each method has different cost structures, delivery times, and service levels.
@@ -241,7 +245,7 @@ type Weight struct {
 type ShippingItem struct {
 	ID          string     `json:"id"`
 	Name        string     `json:"name"`
-	Quantity    int        `json:"quantity"`
+	Quantity    int        `json:"quantity"` // Must be positive; zero quantities are rejected
 	Weight      Weight     `json:"weight"`
 	Dimensions  Dimensions `json:"dimensions"`
 	Value       float64    `json:"value"`
@@ -313,6 +317,8 @@ type ShippingRule struct {
 	DimensionalRate   float64        `json:"dimensional_rate,omitempty"`   // Cost per dimensional weight
 	FlatRate          float64        `json:"flat_rate,omitempty"`          // Fixed rate regardless of weight/value
 	FreeShippingThreshold float64    `json:"free_shipping_threshold,omitempty"`
+	MinCost           float64        `json:"min_cost,omitempty"`           // Floor for the computed cost, 0 for none
+	MaxCost           float64        `json:"max_cost,omitempty"`           // Ceiling for the computed cost, 0 for none
 	Surcharges        []Surcharge    `json:"surcharges,omitempty"`
 	IsActive          bool           `json:"is_active"`
 	ValidFrom         time.Time      `json:"valid_from"`
@@ -392,6 +398,26 @@ type PostalCodeRange struct {
 	End   string `json:"end"`
 }
 
+// RemoteArea describes destinations that carriers charge a delivery area
+// surcharge (DAS) for. A destination is remote when its postal code matches any
+// of the codes, prefixes or ranges, within the listed countries if any.
+//
+// Example usage:
+//
+//	area := shipping.RemoteArea{
+//		Countries:          []string{"US"},
+//		PostalCodePrefixes: []string{"995", "996"}, // Alaska
+//		PostalCodeRanges: []shipping.PostalCodeRange{
+//			{Start: "96701", End: "96898"}, // Hawaii
+//		},
+//	}
+type RemoteArea struct {
+	Countries          []string          `json:"countries,omitempty"`
+	PostalCodes        []string          `json:"postal_codes,omitempty"`
+	PostalCodePrefixes []string          `json:"postal_code_prefixes,omitempty"`
+	PostalCodeRanges   []PostalCodeRange `json:"postal_code_ranges,omitempty"`
+}
+
 // CarrierRule represents shipping rules specific to a particular carrier (e.g., FedEx, UPS, DHL).
 // Contains carrier-specific pricing, limitations, and service details.
 //
@@ -411,7 +437,19 @@ type PostalCodeRange struct {
 //		MaxWeight:         shipping.Weight{Value: 70, Unit: shipping.WeightUnitKG},
 //		DeliveryDays:      2,
 //		TrackingIncluded:  true,
+//		TransitZones: map[string]string{
+//			"NY": "East",
+//			"CA": "West",
+//		},
+//		TransitMatrix: map[string]map[string]int{
+//			"East": {"East": 1, "West": 4},
+//			"West": {"East": 4, "West": 1},
+//		},
 //	}
+//
+// TransitZones assigns origin and destination addresses to the carrier's own transit
+// zones by state code, falling back to country code. When both addresses map to a
+// zone pair present in TransitMatrix, those transit days replace DeliveryDays.
 type CarrierRule struct {
 	CarrierID      string         `json:"carrier_id"`
 	CarrierName    string         `json:"carrier_name"`
@@ -426,6 +464,8 @@ type CarrierRule struct {
 	TrackingIncluded bool         `json:"tracking_included"`
 	InsuranceIncluded bool        `json:"insurance_included"`
 	SignatureRequired bool        `json:"signature_required"`
+	TransitZones   map[string]string `json:"transit_zones,omitempty"`         // State or country code to carrier transit zone
+	TransitMatrix  map[string]map[string]int `json:"transit_matrix,omitempty"` // Origin zone to destination zone to transit days
 }
 
 // ShippingCalculationInput represents all the input data required for shipping cost calculation.
@@ -450,7 +490,18 @@ type CarrierRule struct {
 //		ShippingRules:   rules,
 //		RequestedMethod: shipping.ShippingMethodExpress,
 //		InsuranceValue:  1000.00,
+//		OrderTotal:      899.99, // after discounts
 //	}
+//
+// OrderTotal is the authoritative order total after discounts. When set, free
+// shipping thresholds are evaluated against it instead of the raw item value.
+//
+// CurrencyConverter and Currency are optional. When both are set, each option's
+// final Cost (expressed in BaseCurrency, USD when empty) is converted into Currency
+// and reported in ShippingOption.CostInCurrency; Cost itself is left unchanged.
+//
+// PickupIncentive is optional. When set and a zero-cost pickup option is offered,
+// the result carries the order discount in ShippingCalculationResult.PickupDiscount.
 type ShippingCalculationInput struct {
 	Items           []ShippingItem `json:"items"`
 	Packages        []Package      `json:"packages,omitempty"`
@@ -463,6 +514,11 @@ type ShippingCalculationInput struct {
 	InsuranceValue  float64        `json:"insurance_value,omitempty"`
 	DeliveryDate    time.Time      `json:"delivery_date,omitempty"`
 	IsPriority      bool           `json:"is_priority,omitempty"`
+	OrderTotal      float64        `json:"order_total,omitempty"`
+	CurrencyConverter *currency.Calculator `json:"-"`
+	BaseCurrency    currency.CurrencyCode `json:"base_currency,omitempty"`
+	Currency        currency.CurrencyCode `json:"currency,omitempty"`
+	PickupIncentive *PickupIncentive `json:"pickup_incentive,omitempty"`
 }
 
 // ShippingOption represents a calculated shipping option with cost and service details.
@@ -478,6 +534,7 @@ type ShippingCalculationInput struct {
 //		Cost:              25.50,
 //		BaseCost:          15.00,
 //		EstimatedDays:     2,
+//		DeliveryWindow:    shipping.DeliveryWindow{EarliestDays: 1, LatestDays: 3},
 //		TrackingIncluded:  true,
 //		Zone:              shipping.ShippingZoneNational,
 //		Description:       "Express delivery in 2 business days",
@@ -490,8 +547,12 @@ type ShippingOption struct {
 	ServiceName     string         `json:"service_name"`
 	Cost            float64        `json:"cost"`
 	BaseCost        float64        `json:"base_cost"`
+	Discount        float64        `json:"discount,omitempty"` // Shipping discount already subtracted from Cost
+	CostInCurrency  *currency.Money `json:"cost_in_currency,omitempty"` // Cost converted into the customer's currency
+	CostBreakdown   *CostBreakdown `json:"cost_breakdown,omitempty"` // Per-component cost for rule-based options
 	Surcharges      []AppliedSurcharge `json:"surcharges,omitempty"`
 	EstimatedDays   int            `json:"estimated_days"`
+	DeliveryWindow  DeliveryWindow `json:"delivery_window"`
 	DeliveryDate    time.Time      `json:"delivery_date,omitempty"`
 	TrackingIncluded bool          `json:"tracking_included"`
 	InsuranceIncluded bool         `json:"insurance_included"`
@@ -499,6 +560,49 @@ type ShippingOption struct {
 	Zone            ShippingZone   `json:"zone"`
 	Description     string         `json:"description"`
 	Restrictions    []string       `json:"restrictions,omitempty"`
+	Metadata        map[string]interface{} `json:"metadata,omitempty"` // Calculation notes, e.g. a MinCost/MaxCost clamp
+}
+
+// CostBreakdown splits a rule-based shipping option's cost into the components that
+// produced it. Components are rounded to cents, and Rounding absorbs the difference
+// between their sum and the rounded total, so Total() always equals ShippingOption.Cost.
+// Discount holds amounts taken off afterwards by shipping discounts or free shipping.
+//
+// Example usage:
+//
+//	breakdown := shipping.CostBreakdown{
+//		Base:        5.00,
+//		Weight:      6.00,  // 3kg at $2.00/kg
+//		Value:       1.50,  // 1% of $150
+//		Dimensional: 0,
+//		Surcharges:  3.00,  // fragile handling
+//		Discount:    2.00,
+//	}
+//	// breakdown.Total() == 13.50
+type CostBreakdown struct {
+	Base        float64 `json:"base"`        // Base cost, or the flat rate when one is set
+	Weight      float64 `json:"weight"`      // Weight-based charge
+	Value       float64 `json:"value"`       // Value-based charge
+	Dimensional float64 `json:"dimensional"` // Dimensional weight charge
+	Surcharges  float64 `json:"surcharges"`  // Sum of applied surcharges
+	Clamp       float64 `json:"clamp"`       // Adjustment bringing the cost within the rule's MinCost/MaxCost
+	Rounding    float64 `json:"rounding"`    // Adjustment reconciling rounded components with the cost
+	Discount    float64 `json:"discount"`    // Shipping discounts and free shipping, subtracted from the total
+}
+
+// DeliveryWindow represents the range of days in which a shipment is expected to arrive.
+// It is derived from the point estimate plus or minus a method/zone-specific variance,
+// which lets callers display ranges such as "arrives in 3-7 days".
+//
+// Example usage:
+//
+//	window := shipping.DeliveryWindow{
+//		EarliestDays: 3,
+//		LatestDays:   7,
+//	}
+type DeliveryWindow struct {
+	EarliestDays int `json:"earliest_days"`
+	LatestDays   int `json:"latest_days"`
 }
 
 // AppliedSurcharge represents a surcharge that was actually applied to a shipping calculation.
@@ -535,6 +639,10 @@ type AppliedSurcharge struct {
 //		Distance:         2500.0,
 //		IsValid:          true,
 //	}
+//
+// AmountToFreeShipping is how much more the customer must spend to reach the
+// nearest free shipping threshold. It is zero when free shipping already applies
+// or no threshold-based rule can be reached.
 type ShippingCalculationResult struct {
 	Options         []ShippingOption `json:"options"`
 	RecommendedOption *ShippingOption `json:"recommended_option,omitempty"`
@@ -547,10 +655,15 @@ type ShippingCalculationResult struct {
 	IsValid         bool             `json:"is_valid"`
 	ErrorMessage    string           `json:"error_message,omitempty"`
 	Warnings        []string         `json:"warnings,omitempty"`
+	AmountToFreeShipping float64     `json:"amount_to_free_shipping,omitempty"`
+	PickupDiscount  *OrderDiscount   `json:"pickup_discount,omitempty"`
+	SplitPackages   []Package        `json:"split_packages,omitempty"` // Packages for lines over the calculator's MaxItemWeight
 }
 
 // DeliveryTimeRule represents rules for calculating delivery time estimates.
 // Defines base delivery times and additional delays based on various factors.
+// VarianceDays widens the point estimate into a DeliveryWindow; a matching rule's
+// value is used as-is, so zero yields an exact-day window.
 //
 // Example usage:
 //
@@ -564,6 +677,7 @@ type ShippingCalculationResult struct {
 //		DistanceThreshold: 1000.0,
 //		HolidayDelay:      1,
 //		WeekendDelay:      0,
+//		VarianceDays:      1,
 //	}
 type DeliveryTimeRule struct {
 	Method        ShippingMethod `json:"method"`
@@ -575,6 +689,7 @@ type DeliveryTimeRule struct {
 	DistanceThreshold float64    `json:"distance_threshold,omitempty"`
 	HolidayDelay  int            `json:"holiday_delay,omitempty"`
 	WeekendDelay  int            `json:"weekend_delay,omitempty"`
+	VarianceDays  int            `json:"variance_days,omitempty"` // Days either side of the estimate for the delivery window
 }
 
 // ShippingRestriction represents restrictions that prevent or limit shipping to certain destinations or for certain items.
@@ -608,6 +723,8 @@ type ShippingRestriction struct {
 
 // FreeShippingRule represents rules that determine when free shipping is offered.
 // Defines conditions that must be met for customers to qualify for free shipping.
+// MaxWeight and MaxDimensionalWeight disqualify heavy or bulky shipments even when
+// the order value reaches MinOrderValue.
 //
 // Example usage:
 //
@@ -618,6 +735,7 @@ type ShippingRestriction struct {
 //		ApplicableZones:      []shipping.ShippingZone{shipping.ShippingZoneNational},
 //		ApplicableCategories: []string{"electronics", "books"},
 //		ExcludedCategories:   []string{"hazardous"},
+//		MaxWeight:            shipping.Weight{Value: 30, Unit: shipping.WeightUnitKG},
 //		MembershipRequired:   false,
 //		ValidFrom:            time.Now(),
 //		ValidUntil:           time.Now().AddDate(0, 3, 0),
@@ -628,6 +746,8 @@ type FreeShippingRule struct {
 	Name            string         `json:"name"`
 	MinOrderValue   float64        `json:"min_order_value,omitempty"`
 	MinWeight       Weight         `json:"min_weight,omitempty"`
+	MaxWeight       Weight         `json:"max_weight,omitempty"`             // Heavier shipments do not qualify
+	MaxDimensionalWeight Weight    `json:"max_dimensional_weight,omitempty"` // Bulkier shipments do not qualify
 	ApplicableZones []ShippingZone `json:"applicable_zones,omitempty"`
 	ApplicableCategories []string  `json:"applicable_categories,omitempty"`
 	ExcludedCategories []string    `json:"excluded_categories,omitempty"`
@@ -638,6 +758,96 @@ type FreeShippingRule struct {
 	IsActive        bool           `json:"is_active"`
 }
 
+// ShippingDiscountRule represents a progressive discount on shipping costs by order value,
+// such as "$5 off shipping over $50, $10 off over $100". Unlike free shipping rules it
+// reduces the cost of every applicable option rather than zeroing the cheapest one.
+// Only the highest tier the order value reaches is applied.
+//
+// Example usage:
+//
+//	discountRule := shipping.ShippingDiscountRule{
+//		ID:   "tiered_shipping_discount",
+//		Name: "Save on Shipping",
+//		Tiers: []shipping.ShippingDiscountTier{
+//			{MinOrderValue: 50.00, DiscountType: "fixed_amount", DiscountValue: 5.00},
+//			{MinOrderValue: 100.00, DiscountType: "fixed_amount", DiscountValue: 10.00},
+//		},
+//		ApplicableZones: []shipping.ShippingZone{shipping.ShippingZoneNational},
+//		ValidFrom:       time.Now(),
+//		ValidUntil:      time.Now().AddDate(0, 3, 0),
+//		IsActive:        true,
+//	}
+type ShippingDiscountRule struct {
+	ID                string                 `json:"id"`
+	Name              string                 `json:"name"`
+	Tiers             []ShippingDiscountTier `json:"tiers"`
+	ApplicableZones   []ShippingZone         `json:"applicable_zones,omitempty"`
+	ApplicableMethods []ShippingMethod       `json:"applicable_methods,omitempty"`
+	ValidFrom         time.Time              `json:"valid_from"`
+	ValidUntil        time.Time              `json:"valid_until"`
+	IsActive          bool                   `json:"is_active"`
+}
+
+// ShippingDiscountTier represents one order value threshold of a ShippingDiscountRule.
+// Percentage discounts are taken from the option's cost; fixed amounts never
+// reduce the cost below zero.
+//
+// Example usage:
+//
+//	tier := shipping.ShippingDiscountTier{
+//		MinOrderValue: 100.00,
+//		DiscountType:  "percentage",
+//		DiscountValue: 50.0, // 50% off shipping
+//	}
+type ShippingDiscountTier struct {
+	MinOrderValue float64 `json:"min_order_value"`
+	DiscountType  string  `json:"discount_type"` // "percentage" or "fixed_amount"
+	DiscountValue float64 `json:"discount_value"`
+}
+
+// PickupIncentive represents an order discount offered to customers who choose
+// in-store pickup (click-and-collect) instead of delivery. The shipping calculator
+// does not apply it to item prices; it reports the qualifying discount in the result
+// so the order layer can apply it when the pickup option is chosen.
+//
+// Example usage:
+//
+//	incentive := &shipping.PickupIncentive{
+//		DiscountType:  "percentage",
+//		DiscountValue: 5.0, // 5% off the order
+//		MinOrderValue: 25.00,
+//		MaxDiscount:   10.00,
+//		Description:   "5% off when you collect in store",
+//	}
+type PickupIncentive struct {
+	DiscountType  string  `json:"discount_type"` // "percentage" or "fixed_amount"
+	DiscountValue float64 `json:"discount_value"`
+	MinOrderValue float64 `json:"min_order_value,omitempty"`
+	MaxDiscount   float64 `json:"max_discount,omitempty"` // Cap for percentage discounts; 0 means no cap
+	Description   string  `json:"description,omitempty"`
+}
+
+// OrderDiscount represents a discount on the order (not on shipping) that depends on
+// the shipping choice, such as a pickup incentive. OptionID identifies the shipping
+// option the customer must select for the discount to apply.
+//
+// Example usage:
+//
+//	discount := shipping.OrderDiscount{
+//		OptionID:    "store_pickup",
+//		Type:        "percentage",
+//		Value:       5.0,
+//		Amount:      4.50,
+//		Description: "5% off when you collect in store",
+//	}
+type OrderDiscount struct {
+	OptionID    string  `json:"option_id"`
+	Type        string  `json:"type"`
+	Value       float64 `json:"value"`
+	Amount      float64 `json:"amount"`
+	Description string  `json:"description,omitempty"`
+}
+
 // PackagingRule represents rules for package optimization and material selection.
 // Defines packaging constraints, costs, and capabilities for different package types.
 //
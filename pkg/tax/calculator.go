@@ -48,7 +48,11 @@ import (
 	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/masumrpg/ecommerce-engine/pkg/currency"
+	"github.com/masumrpg/ecommerce-engine/pkg/utils"
 )
 
 // TaxCalculator handles comprehensive tax calculations for e-commerce transactions.
@@ -97,6 +101,97 @@ func NewTaxCalculator(config TaxConfiguration) *TaxCalculator {
 	}
 }
 
+// NewTaxCalculatorFromConfig creates a tax calculator from a deep copy of the
+// configuration, so the caller may keep modifying config (for example a
+// Snapshot being edited for the next reload) without affecting the calculator.
+//
+// Parameters:
+//   - config: Tax configuration, typically from Snapshot
+//
+// Returns:
+//   - *TaxCalculator: A new tax calculator whose Rules are config.DefaultRules
+//
+// Example:
+//
+//	cfg := live.Load().Snapshot()
+//	cfg.DefaultRules = append(cfg.DefaultRules, newCityTax)
+//	live.Store(tax.NewTaxCalculatorFromConfig(cfg)) // live is an atomic.Pointer[tax.TaxCalculator]
+func NewTaxCalculatorFromConfig(config TaxConfiguration) *TaxCalculator {
+	return NewTaxCalculator(cloneTaxConfiguration(config))
+}
+
+// Snapshot returns a deep copy of the calculator's configuration with
+// DefaultRules set to the calculator's current Rules. Changing the snapshot does
+// not affect the calculator, and later changes to the calculator do not show up
+// in the snapshot. ValidationRules are not part of TaxConfiguration and are not
+// included.
+//
+// Returns:
+//   - TaxConfiguration: Independent copy of the configuration and rules
+//
+// Example:
+//
+//	backup := calc.Snapshot()
+//	calc.Rules = append(calc.Rules, experimentalRule)
+//	calc = tax.NewTaxCalculatorFromConfig(backup) // roll back
+func (tc *TaxCalculator) Snapshot() TaxConfiguration {
+	config := tc.Configuration
+	config.DefaultRules = tc.Rules
+	return cloneTaxConfiguration(config)
+}
+
+// cloneTaxConfiguration deep-copies a configuration's rules, certificates,
+// holidays and settings.
+func cloneTaxConfiguration(config TaxConfiguration) TaxConfiguration {
+	rules := config.DefaultRules
+	config.DefaultRules = nil
+	for _, rule := range rules {
+		config.DefaultRules = append(config.DefaultRules, cloneTaxRule(rule))
+	}
+	holidays := config.TaxHolidays
+	config.TaxHolidays = nil
+	for _, holiday := range holidays {
+		holiday.ApplicableCategories = append([]string(nil), holiday.ApplicableCategories...)
+		config.TaxHolidays = append(config.TaxHolidays, holiday)
+	}
+	config.ExemptionCertificates = append([]string(nil), config.ExemptionCertificates...)
+	config.Settings = utils.CloneMap(config.Settings)
+	return config
+}
+
+// cloneTaxRule deep-copies a tax rule's thresholds, location lists, conditions
+// and exemptions.
+func cloneTaxRule(rule TaxRule) TaxRule {
+	rule.Thresholds = append([]TaxThreshold(nil), rule.Thresholds...)
+	rule.ApplicableCategories = append([]string(nil), rule.ApplicableCategories...)
+	rule.ExemptCategories = append([]string(nil), rule.ExemptCategories...)
+	rule.ApplicableCountries = append([]string(nil), rule.ApplicableCountries...)
+	rule.ApplicableStates = append([]string(nil), rule.ApplicableStates...)
+	rule.ApplicableCities = append([]string(nil), rule.ApplicableCities...)
+	rule.PostalCodes = append([]string(nil), rule.PostalCodes...)
+	rule.Conditions = cloneTaxConditions(rule.Conditions)
+	exemptions := rule.Exemptions
+	rule.Exemptions = nil
+	for _, exemption := range exemptions {
+		exemption.Conditions = cloneTaxConditions(exemption.Conditions)
+		rule.Exemptions = append(rule.Exemptions, exemption)
+	}
+	return rule
+}
+
+// cloneTaxConditions copies conditions, including slice and map condition values.
+func cloneTaxConditions(conditions []TaxCondition) []TaxCondition {
+	if conditions == nil {
+		return nil
+	}
+	copied := make([]TaxCondition, len(conditions))
+	for i, condition := range conditions {
+		condition.Value = utils.CloneValue(condition.Value)
+		copied[i] = condition
+	}
+	return copied
+}
+
 // Calculate is a convenience function that calculates taxes for the given input
 // using default tax configuration. This function creates a temporary tax calculator
 // with standard settings and performs the calculation.
@@ -135,6 +230,7 @@ func Calculate(input TaxCalculationInput) TaxCalculationResult {
 		DefaultCurrency:     "USD",
 		RoundingMode:        "round",
 		RoundingPrecision:   2,
+		UseCurrencyPrecision: true,
 		TaxInclusivePricing: false,
 		CompoundTaxes:       false,
 		TaxOnShipping:       true,
@@ -149,6 +245,55 @@ func Calculate(input TaxCalculationInput) TaxCalculationResult {
 	return calc.CalculateTax(input)
 }
 
+// OriginBasedStates lists US states that source sales tax from the seller's location
+// for in-state sales. A seller shipping from one of these states always has nexus
+// for deliveries within the same state.
+var OriginBasedStates = []string{"AZ", "IL", "MS", "MO", "OH", "PA", "TN", "TX", "UT", "VA"}
+
+// HasNexus reports whether the seller must collect tax for a destination.
+// Nexus exists when the destination state is one of the seller's registered nexus
+// states, or when the seller ships from an origin-based state to an address in
+// that same state. State codes are compared case-insensitively.
+//
+// Parameters:
+//   - origin: Seller's ship-from address
+//   - destination: Customer's delivery address
+//   - nexusStates: States where the seller is registered to collect tax
+//
+// Returns:
+//   - bool: True if tax must be collected for the destination
+//
+// Example:
+//
+//	origin := Address{Country: "US", State: "TX"}
+//	HasNexus(origin, Address{Country: "US", State: "NY"}, []string{"NY", "CA"}) // true
+//	HasNexus(origin, Address{Country: "US", State: "FL"}, []string{"NY", "CA"}) // false
+//	HasNexus(origin, Address{Country: "US", State: "TX"}, []string{"NY", "CA"}) // true (origin-based)
+func HasNexus(origin Address, destination Address, nexusStates []string) bool {
+	state := strings.TrimSpace(destination.State)
+	if state == "" {
+		return false
+	}
+
+	for _, nexusState := range nexusStates {
+		if strings.EqualFold(strings.TrimSpace(nexusState), state) {
+			return true
+		}
+	}
+
+	// Origin-based states: selling within the ship-from state always creates nexus
+	if strings.EqualFold(strings.TrimSpace(origin.State), state) &&
+		(origin.Country == "" || destination.Country == "" || strings.EqualFold(origin.Country, destination.Country)) {
+		for _, originState := range OriginBasedStates {
+			if strings.EqualFold(originState, state) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // CalculateTax performs comprehensive tax calculation for the given input.
 // This is the main calculation method that processes all items, applies
 // applicable tax rules, handles exemptions, and generates detailed breakdowns.
@@ -158,7 +303,8 @@ func Calculate(input TaxCalculationInput) TaxCalculationResult {
 //   2. Subtotal calculation
 //   3. Discount and shipping adjustments
 //   4. Rule evaluation and application
-//   5. Tax calculation per item
+//   5. Tax calculation per item, plus a shipping line when TaxOnShipping is set
+//      and an applicable rule has ShippingTaxable
 //   6. Override application
 //   7. Amount rounding
 //   8. Result validation
@@ -208,6 +354,19 @@ func (tc *TaxCalculator) CalculateTax(input TaxCalculationInput) TaxCalculationR
 		return result
 	}
 
+	// Flag incomplete addresses that would silently match no tax rules
+	result.Warnings = append(result.Warnings, tc.validateAddress(input)...)
+
+	// Spread an order-level discount over the items so each is taxed on its share
+	if input.OrderDiscountAmount > 0 {
+		var warning string
+		input.Items, warning = tc.allocateOrderDiscount(input.Items, input.OrderDiscountAmount, result.Currency)
+		if warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+		result.Metadata["order_discount"] = input.OrderDiscountAmount
+	}
+
 	// Calculate subtotal
 	result.Subtotal = tc.calculateSubtotal(input.Items)
 
@@ -221,6 +380,20 @@ func (tc *TaxCalculator) CalculateTax(input TaxCalculationInput) TaxCalculationR
 		result.Subtotal += input.ShippingAmount
 	}
 
+	// Skip collection entirely when the seller has no nexus at the destination
+	if len(input.NexusStates) > 0 {
+		destination := input.ShippingAddress
+		if destination.Country == "" {
+			destination = input.BillingAddress
+		}
+		if !HasNexus(input.OriginAddress, destination, input.NexusStates) {
+			result.GrandTotal = result.Subtotal
+			result.Metadata["nexus"] = false
+			tc.roundAmounts(&result)
+			return result
+		}
+	}
+
 	// Get applicable tax rules
 	applicableRules := tc.getApplicableRules(input)
 
@@ -243,6 +416,18 @@ func (tc *TaxCalculator) CalculateTax(input TaxCalculationInput) TaxCalculationR
 		}
 	}
 
+	// Tax the shipping charge as its own line when shipping is taxed here
+	if tc.Configuration.TaxOnShipping && input.ShippingAmount > 0 && rulesTaxShipping(applicableRules) {
+		breakdown := tc.calculateShippingTax(applicableRules, input)
+		result.TaxBreakdown = append(result.TaxBreakdown, breakdown)
+		result.TotalTax += breakdown.TotalTax
+		result.TaxableAmount += breakdown.TaxableAmount
+		result.ExemptAmount += breakdown.ExemptAmount
+		for _, appliedTax := range breakdown.AppliedTaxes {
+			tc.aggregateAppliedTax(&result, appliedTax)
+		}
+	}
+
 	// Apply tax overrides
 	tc.applyTaxOverrides(&result, input.Overrides)
 
@@ -254,12 +439,22 @@ func (tc *TaxCalculator) CalculateTax(input TaxCalculationInput) TaxCalculationR
 		result.EffectiveRate = (result.TotalTax / result.Subtotal) * 100
 	}
 
+	// Calculate combined rate of stacked taxes per item
+	for i := range result.TaxBreakdown {
+		result.TaxBreakdown[i].EffectiveRate = calculateItemEffectiveRate(result.TaxBreakdown[i])
+	}
+
+	// Calculate blended rate across taxable items
+	if taxableSubtotal := calculateTaxableSubtotal(result.TaxBreakdown); taxableSubtotal > 0 {
+		result.WeightedEffectiveRate = (result.TotalTax / taxableSubtotal) * 100
+	}
+
 	// Round amounts based on configuration
 	tc.roundAmounts(&result)
 
 	// Validate result
 	if warnings := tc.validateResult(result); len(warnings) > 0 {
-		result.Warnings = warnings
+		result.Warnings = append(result.Warnings, warnings...)
 	}
 
 	return result
@@ -281,6 +476,41 @@ func (tc *TaxCalculator) calculateSubtotal(items []TaxableItem) float64 {
 	return subtotal
 }
 
+// allocateOrderDiscount lowers each item's TotalAmount by its share of an
+// order-level discount. Shares are proportional to TotalAmount and rounded with
+// utils.AllocateProportional, so they add up to the discount exactly. A discount
+// larger than the items' total is capped to it.
+//
+// Parameters:
+//   - items: Items to discount; the slice is not modified
+//   - discount: Order-level discount amount
+//   - currencyCode: Currency used to pick the rounding precision
+//
+// Returns:
+//   - []TaxableItem: Copies of the items with discounted TotalAmount
+//   - string: Warning when the discount had to be capped, empty otherwise
+func (tc *TaxCalculator) allocateOrderDiscount(items []TaxableItem, discount float64, currencyCode string) ([]TaxableItem, string) {
+	warning := ""
+	itemsTotal := tc.calculateSubtotal(items)
+	if discount > itemsTotal {
+		warning = fmt.Sprintf("Order discount %.2f exceeds item total %.2f and was capped", discount, itemsTotal)
+		discount = itemsTotal
+	}
+
+	weights := make([]float64, len(items))
+	for i, item := range items {
+		weights[i] = item.TotalAmount
+	}
+	shares := utils.AllocateProportional(discount, weights, tc.roundingPrecision(currencyCode))
+
+	discounted := make([]TaxableItem, len(items))
+	for i, item := range items {
+		item.TotalAmount = math.Max(item.TotalAmount-shares[i], 0)
+		discounted[i] = item
+	}
+	return discounted, warning
+}
+
 // getApplicableRules filters and returns tax rules that apply to the given input.
 // This method evaluates each rule against the input criteria including:
 //   - Rule active status and validity period
@@ -563,10 +793,13 @@ func (tc *TaxCalculator) toFloat64(value interface{}) (float64, error) {
 // and generates a detailed breakdown of taxes for the item.
 //
 // The calculation process:
+//   0. Zero-amount items return immediately with no tax
 //   1. Check item-level exemptions
 //   2. Check customer-level exemptions
-//   3. Apply applicable tax rules
-//   4. Handle compound tax calculations if configured
+//   3. Check tax holidays for the transaction date
+//   4. Apply applicable tax rules, using tax code rates where configured
+//   5. Handle compound tax calculations if configured
+//   6. Report gift cards that no rule taxes as exempt
 //
 // Parameters:
 //   - item: The taxable item to calculate tax for
@@ -586,6 +819,11 @@ func (tc *TaxCalculator) calculateItemTax(item TaxableItem, rules []TaxRule, inp
 		ExemptAmount:  0,
 	}
 
+	// Free items carry no tax, even under fixed-amount rules
+	if item.TotalAmount == 0 {
+		return breakdown
+	}
+
 	// Check if item is exempt
 	if item.IsExempt {
 		breakdown.ExemptAmount = item.TotalAmount
@@ -602,9 +840,18 @@ func (tc *TaxCalculator) calculateItemTax(item TaxableItem, rules []TaxRule, inp
 		return breakdown
 	}
 
+	// Check tax holidays
+	if holiday, ok := tc.findTaxHoliday(item, input.TransactionDate); ok {
+		breakdown.ExemptAmount = item.TotalAmount
+		breakdown.TaxableAmount = 0
+		breakdown.ExemptionReason = fmt.Sprintf("Tax holiday: %s", holiday.Name)
+		return breakdown
+	}
+
 	// Apply applicable tax rules
 	for _, rule := range rules {
 		if tc.isRuleApplicableToItem(rule, item) {
+			rule = resolveTaxCodeRate(rule, item, input.TaxCodeRates)
 			appliedTax := tc.calculateTaxForRule(rule, breakdown.TaxableAmount, item)
 			if appliedTax.TaxAmount > 0 {
 				breakdown.AppliedTaxes = append(breakdown.AppliedTaxes, appliedTax)
@@ -618,9 +865,148 @@ func (tc *TaxCalculator) calculateItemTax(item TaxableItem, rules []TaxRule, inp
 		}
 	}
 
+	// Gift card sales are exempt unless the jurisdiction taxes them
+	if item.IsGiftCard && len(breakdown.AppliedTaxes) == 0 {
+		breakdown.ExemptAmount = item.TotalAmount
+		breakdown.TaxableAmount = 0
+		breakdown.ExemptionReason = "Gift card sale not taxable"
+	}
+
 	return breakdown
 }
 
+// resolveTaxCodeRate returns the rule with its Rate replaced by the rate the
+// resolver holds for the item's TaxCode in the rule's jurisdiction. Only
+// percentage-based rules (percentage, compound, or unset method) are affected;
+// fixed, tiered, progressive and per-weight rules keep their own rates.
+//
+// Parameters:
+//   - rule: Tax rule being applied to the item
+//   - item: Taxable item carrying the tax code
+//   - resolver: Tax code rates from the calculation input
+//
+// Returns:
+//   - TaxRule: The rule, with the resolved rate if the code has one
+func resolveTaxCodeRate(rule TaxRule, item TaxableItem, resolver TaxCodeRateResolver) TaxRule {
+	switch rule.Method {
+	case TaxMethodFixed, TaxMethodTiered, TaxMethodProgressive, TaxMethodPerWeight:
+		return rule
+	}
+	if rate, ok := resolver.Resolve(rule.Jurisdiction, item.TaxCode); ok {
+		rule.Rate = rate
+	}
+	return rule
+}
+
+// rulesTaxShipping reports whether any of the rules taxes the shipping charge.
+//
+// Parameters:
+//   - rules: Applicable tax rules for this calculation
+//
+// Returns:
+//   - bool: True if at least one rule has ShippingTaxable set
+func rulesTaxShipping(rules []TaxRule) bool {
+	for _, rule := range rules {
+		if rule.ShippingTaxable {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateShippingTax calculates tax on the shipping charge.
+// Shipping is taxed only by applicable rules with ShippingTaxable set; item
+// category filters and amount thresholds do not apply to it. Shipping that no
+// rule taxes, or that a customer-level exemption covers, is reported as exempt.
+//
+// Parameters:
+//   - rules: Applicable tax rules for this calculation
+//   - input: Complete tax calculation input carrying ShippingAmount
+//
+// Returns:
+//   - TaxBreakdown: Shipping line with ItemID ShippingBreakdownID
+func (tc *TaxCalculator) calculateShippingTax(rules []TaxRule, input TaxCalculationInput) TaxBreakdown {
+	shipping := TaxableItem{
+		ID:          ShippingBreakdownID,
+		Name:        "Shipping",
+		Quantity:    1,
+		UnitPrice:   input.ShippingAmount,
+		TotalAmount: input.ShippingAmount,
+	}
+	breakdown := TaxBreakdown{
+		ItemID:        shipping.ID,
+		ItemName:      shipping.Name,
+		ItemAmount:    shipping.TotalAmount,
+		AppliedTaxes:  []AppliedTax{},
+		TaxableAmount: shipping.TotalAmount,
+	}
+
+	if tc.isCustomerExempt(input.Customer, shipping) {
+		breakdown.ExemptAmount = shipping.TotalAmount
+		breakdown.TaxableAmount = 0
+		breakdown.ExemptionReason = "Customer exemption"
+		return breakdown
+	}
+
+	for _, rule := range rules {
+		if !rule.ShippingTaxable {
+			continue
+		}
+		appliedTax := tc.calculateTaxForRule(rule, breakdown.TaxableAmount, shipping)
+		if appliedTax.TaxAmount > 0 {
+			breakdown.AppliedTaxes = append(breakdown.AppliedTaxes, appliedTax)
+			breakdown.TotalTax += appliedTax.TaxAmount
+
+			if tc.Configuration.CompoundTaxes {
+				breakdown.TaxableAmount += appliedTax.TaxAmount
+			}
+		}
+	}
+
+	if len(breakdown.AppliedTaxes) == 0 {
+		breakdown.ExemptAmount = shipping.TotalAmount
+		breakdown.TaxableAmount = 0
+		breakdown.ExemptionReason = "Shipping not taxable"
+	}
+
+	return breakdown
+}
+
+// calculateItemEffectiveRate returns the combined tax rate of a breakdown line
+// as a percentage of its non-exempt item amount. TaxableAmount is not used as
+// the base because compound taxes add earlier taxes to it. Lines with no
+// non-exempt amount have a rate of 0.
+//
+// Parameters:
+//   - breakdown: Tax breakdown line for a single item
+//
+// Returns:
+//   - float64: Effective rate in percent (e.g. 10.5 for 10.5%)
+func calculateItemEffectiveRate(breakdown TaxBreakdown) float64 {
+	base := breakdown.ItemAmount - breakdown.ExemptAmount
+	if base <= 0 {
+		return 0
+	}
+	return (breakdown.TotalTax / base) * 100
+}
+
+// calculateTaxableSubtotal sums the item amounts that are subject to tax, excluding
+// exempt amounts. Unlike TaxableAmount it is not inflated by compound taxes, so it
+// serves as the weight base for the blended tax rate.
+//
+// Parameters:
+//   - breakdowns: Per-item tax breakdowns
+//
+// Returns:
+//   - float64: Total taxable item amount
+func calculateTaxableSubtotal(breakdowns []TaxBreakdown) float64 {
+	total := 0.0
+	for _, breakdown := range breakdowns {
+		total += breakdown.ItemAmount - breakdown.ExemptAmount
+	}
+	return total
+}
+
 // isCustomerExempt determines if a customer is exempt from tax for a specific item.
 // This method checks all customer exemptions to see if any apply to the given item.
 //
@@ -674,11 +1060,60 @@ func (tc *TaxCalculator) isExemptionApplicable(exemption TaxExemption, item Taxa
 	return false
 }
 
+// findTaxHoliday returns the first configured tax holiday that exempts the item
+// on the given transaction date. An item qualifies when the date falls within
+// the holiday window (through the end of EndDate's day), its category or subcategory is listed, and its unit price
+// does not exceed the holiday's price cap (when one is set).
+//
+// Parameters:
+//   - item: Taxable item to check against the configured holidays
+//   - transactionDate: Date of the transaction being taxed
+//
+// Returns:
+//   - TaxHoliday: The matching tax holiday, if any
+//   - bool: True if a tax holiday applies to the item
+func (tc *TaxCalculator) findTaxHoliday(item TaxableItem, transactionDate time.Time) (TaxHoliday, bool) {
+	for _, holiday := range tc.Configuration.TaxHolidays {
+		// EndDate names the last day of the holiday, so the whole of that day qualifies
+		endYear, endMonth, endDay := holiday.EndDate.Date()
+		holidayEnd := time.Date(endYear, endMonth, endDay+1, 0, 0, 0, 0, holiday.EndDate.Location())
+		if transactionDate.Before(holiday.StartDate) || !transactionDate.Before(holidayEnd) {
+			continue
+		}
+
+		found := false
+		for _, category := range holiday.ApplicableCategories {
+			if item.Category == category || item.Subcategory == category {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		if holiday.PriceCap > 0 {
+			unitPrice := item.UnitPrice
+			if unitPrice == 0 && item.Quantity > 0 {
+				unitPrice = item.TotalAmount / float64(item.Quantity)
+			}
+			if unitPrice > holiday.PriceCap {
+				continue
+			}
+		}
+
+		return holiday, true
+	}
+
+	return TaxHoliday{}, false
+}
+
 // isRuleApplicableToItem determines if a tax rule applies to a specific item.
 // This method checks rule criteria including:
 //   - Applicable categories (item must match)
 //   - Exempt categories (item must not match)
 //   - Amount thresholds (min/max amounts)
+//   - Gift cards (rule must have GiftCardsTaxable)
 //
 // Parameters:
 //   - rule: Tax rule to evaluate for applicability
@@ -687,6 +1122,10 @@ func (tc *TaxCalculator) isExemptionApplicable(exemption TaxExemption, item Taxa
 // Returns:
 //   - bool: True if the rule applies to the item
 func (tc *TaxCalculator) isRuleApplicableToItem(rule TaxRule, item TaxableItem) bool {
+	if item.IsGiftCard && !rule.GiftCardsTaxable {
+		return false
+	}
+
 	// Check applicable categories
 	if len(rule.ApplicableCategories) > 0 {
 		found := false
@@ -726,6 +1165,8 @@ func (tc *TaxCalculator) isRuleApplicableToItem(rule TaxRule, item TaxableItem)
 //   - Tiered: Tax rate based on amount tiers
 //   - Progressive: Progressive tax rates across tiers
 //   - Compound: Tax calculated on amount including previous taxes
+//   - PerWeight: PerWeightRate per kilogram of item weight (Weight × Quantity);
+//     items without a weight owe nothing under this method
 //
 // Parameters:
 //   - rule: Tax rule defining calculation method and rates
@@ -759,6 +1200,9 @@ func (tc *TaxCalculator) calculateTaxForRule(rule TaxRule, taxableAmount float64
 	case TaxMethodCompound:
 		// Compound tax is calculated on the amount including previous taxes
 		appliedTax.TaxAmount = taxableAmount * (rule.Rate / 100)
+	case TaxMethodPerWeight:
+		appliedTax.Rate = rule.PerWeightRate
+		appliedTax.TaxAmount = item.Weight * float64(item.Quantity) * rule.PerWeightRate
 	default:
 		appliedTax.TaxAmount = taxableAmount * (rule.Rate / 100)
 	}
@@ -947,59 +1391,86 @@ func (tc *TaxCalculator) applyTaxOverrides(result *TaxCalculationResult, overrid
 //
 // The method rounds totals, applied taxes, and tax breakdowns according
 // to the configured precision (typically 2 decimal places for currency).
+// Breakdown lines are rounded with utils.AllocateRemainderWithPrecision so that
+// they always sum to the rounded total of the lines. Effective rates are rounded
+// with utils.RoundToPercent. With CustomerFavorableRounding every amount is
+// rounded down regardless of the configured mode.
+// When UseCurrencyPrecision is set, the result currency's decimal places are
+// used instead, so JPY rounds to whole yen and USD to cents.
 //
 // Parameters:
 //   - result: Tax calculation result to round amounts in
 func (tc *TaxCalculator) roundAmounts(result *TaxCalculationResult) {
-	precision := tc.Configuration.RoundingPrecision
-	multiplier := math.Pow(10, float64(precision))
-
-	switch tc.Configuration.RoundingMode {
-	case "round":
-		result.TotalTax = math.Round(result.TotalTax*multiplier) / multiplier
-		result.GrandTotal = math.Round(result.GrandTotal*multiplier) / multiplier
-		result.Subtotal = math.Round(result.Subtotal*multiplier) / multiplier
-	case "floor":
-		result.TotalTax = math.Floor(result.TotalTax*multiplier) / multiplier
-		result.GrandTotal = math.Floor(result.GrandTotal*multiplier) / multiplier
-		result.Subtotal = math.Floor(result.Subtotal*multiplier) / multiplier
-	case "ceil":
-		result.TotalTax = math.Ceil(result.TotalTax*multiplier) / multiplier
-		result.GrandTotal = math.Ceil(result.GrandTotal*multiplier) / multiplier
-		result.Subtotal = math.Ceil(result.Subtotal*multiplier) / multiplier
+	precision := tc.roundingPrecision(result.Currency)
+	mode := tc.Configuration.RoundingMode
+	if tc.Configuration.CustomerFavorableRounding {
+		mode = "floor"
 	}
-
-	// Round applied taxes
-	for i := range result.AppliedTaxes {
-		switch tc.Configuration.RoundingMode {
+	roundAmount := func(amount float64) float64 {
+		switch mode {
 		case "round":
-			result.AppliedTaxes[i].TaxAmount = math.Round(result.AppliedTaxes[i].TaxAmount*multiplier) / multiplier
+			return utils.Round(amount, precision)
 		case "floor":
-			result.AppliedTaxes[i].TaxAmount = math.Floor(result.AppliedTaxes[i].TaxAmount*multiplier) / multiplier
+			return utils.RoundWithMode(amount, precision, utils.RoundDown)
 		case "ceil":
-			result.AppliedTaxes[i].TaxAmount = math.Ceil(result.AppliedTaxes[i].TaxAmount*multiplier) / multiplier
+			return utils.RoundWithMode(amount, precision, utils.RoundUp)
 		}
+		return amount
 	}
 
-	// Round tax breakdown
-	for i := range result.TaxBreakdown {
-		switch tc.Configuration.RoundingMode {
-		case "round":
-			result.TaxBreakdown[i].TotalTax = math.Round(result.TaxBreakdown[i].TotalTax*multiplier) / multiplier
-		case "floor":
-			result.TaxBreakdown[i].TotalTax = math.Floor(result.TaxBreakdown[i].TotalTax*multiplier) / multiplier
-		case "ceil":
-			result.TaxBreakdown[i].TotalTax = math.Ceil(result.TaxBreakdown[i].TotalTax*multiplier) / multiplier
-		}
+	// Percentages are rounded to percent precision, independent of currency
+	result.EffectiveRate = utils.RoundToPercent(result.EffectiveRate)
+	result.WeightedEffectiveRate = utils.RoundToPercent(result.WeightedEffectiveRate)
+
+	result.TotalTax = roundAmount(result.TotalTax)
+	result.GrandTotal = roundAmount(result.GrandTotal)
+	result.Subtotal = roundAmount(result.Subtotal)
+
+	// Round applied taxes
+	for i := range result.AppliedTaxes {
+		result.AppliedTaxes[i].TaxAmount = roundAmount(result.AppliedTaxes[i].TaxAmount)
+	}
+
+	// Round tax breakdown so that the lines add up to their rounded total
+	if len(result.TaxBreakdown) == 0 {
+		return
+	}
+	lineTaxes := make([]float64, len(result.TaxBreakdown))
+	for i, breakdown := range result.TaxBreakdown {
+		lineTaxes[i] = breakdown.TotalTax
+	}
+	linesTotal := roundAmount(utils.Sum(lineTaxes))
+	for i, lineTax := range utils.AllocateRemainderWithPrecision(linesTotal, lineTaxes, precision) {
+		result.TaxBreakdown[i].TotalTax = lineTax
+		result.TaxBreakdown[i].EffectiveRate = utils.RoundToPercent(result.TaxBreakdown[i].EffectiveRate)
 	}
 }
 
+// roundingPrecision returns the number of decimal places to round amounts to.
+// With UseCurrencyPrecision enabled, the precision comes from the currency package
+// and falls back to 2 for unknown or empty currency codes.
+//
+// Parameters:
+//   - currencyCode: Currency code of the calculation result
+//
+// Returns:
+//   - int: Number of decimal places
+func (tc *TaxCalculator) roundingPrecision(currencyCode string) int {
+	if !tc.Configuration.UseCurrencyPrecision {
+		return tc.Configuration.RoundingPrecision
+	}
+	return currency.GetCurrencyDecimalPlaces(currency.CurrencyCode(strings.ToUpper(currencyCode)))
+}
+
 // validateInput validates the tax calculation input for completeness and correctness.
 // This method checks for:
 //   - Presence of items to calculate tax for
-//   - Valid item data (ID, amount, quantity)
+//   - Valid item data (ID, non-negative amount, quantity of at least one)
 //   - Valid address information
 //   - Required transaction date
+//   - At most one of DiscountAmount and OrderDiscountAmount
+//
+// Zero-amount items are valid; they contribute zero tax.
 //
 // Parameters:
 //   - input: Tax calculation input to validate
@@ -1033,9 +1504,53 @@ func (tc *TaxCalculator) validateInput(input TaxCalculationInput) []string {
 		errors = append(errors, "transaction date is required")
 	}
 
+	if input.DiscountAmount > 0 && input.OrderDiscountAmount > 0 {
+		errors = append(errors, "discount_amount and order_discount_amount cannot both be set; use order_discount_amount")
+	}
+
 	return errors
 }
 
+// validateAddress checks the address used for tax with utils.ValidateAddress and
+// utils.AddressWarnings and returns any problems as warnings. The shipping address is checked when any of its
+// fields are set, otherwise the billing address is. State and postal code are only
+// expected when an active rule matches on them.
+//
+// Parameters:
+//   - input: Tax calculation input whose address should be checked
+//
+// Returns:
+//   - []string: Slice of address warning messages
+func (tc *TaxCalculator) validateAddress(input TaxCalculationInput) []string {
+	required := utils.AddressRequirements{}
+	for _, rule := range tc.Rules {
+		if !rule.IsActive {
+			continue
+		}
+		if len(rule.ApplicableStates) > 0 {
+			required.State = true
+		}
+		if len(rule.PostalCodes) > 0 {
+			required.PostalCode = true
+		}
+	}
+
+	label, addr := "shipping address", input.ShippingAddress
+	if addr == (Address{}) {
+		label, addr = "billing address", input.BillingAddress
+	}
+
+	warnings := []string{}
+	address := utils.Address{Country: addr.Country, State: addr.State, PostalCode: addr.PostalCode}
+	if err := utils.ValidateAddress(address); err != nil {
+		return append(warnings, fmt.Sprintf("%s: %v", label, err))
+	}
+	for _, warning := range utils.AddressWarnings(address, required) {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", label, warning))
+	}
+	return warnings
+}
+
 // validateResult validates the tax calculation result for reasonableness.
 // This method checks for:
 //   - Unusually high tax rates (>50%)
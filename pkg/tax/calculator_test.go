@@ -126,6 +126,388 @@ func TestCalculateTax(t *testing.T) {
 	}
 }
 
+func TestHasNexus(t *testing.T) {
+	nexusStates := []string{"NY", "CA"}
+
+	tests := []struct {
+		name        string
+		origin      Address
+		destination Address
+		expected    bool
+	}{
+		{"in nexus", Address{Country: "US", State: "TX"}, Address{Country: "US", State: "NY"}, true},
+		{"in nexus lowercase", Address{Country: "US", State: "TX"}, Address{Country: "US", State: "ca"}, true},
+		{"out of nexus", Address{Country: "US", State: "TX"}, Address{Country: "US", State: "FL"}, false},
+		{"origin-based in-state sale", Address{Country: "US", State: "TX"}, Address{Country: "US", State: "TX"}, true},
+		{"destination-based in-state sale", Address{Country: "US", State: "FL"}, Address{Country: "US", State: "FL"}, false},
+		{"origin-based state in another country", Address{Country: "US", State: "TX"}, Address{Country: "MX", State: "TX"}, false},
+		{"missing destination state", Address{Country: "US", State: "NY"}, Address{Country: "US"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := HasNexus(tt.origin, tt.destination, nexusStates); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestCalculateTaxWithoutNexus(t *testing.T) {
+	calc := createTestTaxCalculator()
+
+	input := createTestTaxInput()
+	input.OriginAddress = Address{Country: "US", State: "CA"}
+	input.NexusStates = []string{"CA"}
+
+	result := calc.CalculateTax(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+	if result.TotalTax != 0 {
+		t.Errorf("Expected no tax without nexus, got %f", result.TotalTax)
+	}
+	if result.GrandTotal != result.Subtotal {
+		t.Errorf("Expected grand total to equal subtotal, got %f and %f", result.GrandTotal, result.Subtotal)
+	}
+	if nexus, ok := result.Metadata["nexus"].(bool); !ok || nexus {
+		t.Errorf("Expected nexus metadata false, got %v", result.Metadata["nexus"])
+	}
+
+	input.NexusStates = []string{"CA", "NY"}
+	result = calc.CalculateTax(input)
+	if result.TotalTax <= 0 {
+		t.Errorf("Expected tax to be collected in a nexus state, got %f", result.TotalTax)
+	}
+}
+
+func TestCalculateCurrencyPrecision(t *testing.T) {
+	tests := []struct {
+		name               string
+		currency           string
+		amount             float64
+		expectedTax        float64
+		expectedGrandTotal float64
+	}{
+		{name: "JPY rounds to whole yen", currency: "JPY", amount: 1234, expectedTax: 99, expectedGrandTotal: 1333},
+		{name: "USD rounds to cents", currency: "USD", amount: 1234, expectedTax: 98.72, expectedGrandTotal: 1332.72},
+		{name: "unknown currency defaults to cents", currency: "XXX", amount: 1234, expectedTax: 98.72, expectedGrandTotal: 1332.72},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := createTestTaxInput()
+			input.Items[0].UnitPrice = tt.amount
+			input.Items[0].TotalAmount = tt.amount
+			input.Currency = tt.currency
+			rule := createTestTaxRule()
+			rule.Rate = 8
+			input.TaxRules = []TaxRule{rule}
+
+			result := Calculate(input)
+			if !result.IsValid {
+				t.Fatalf("Expected valid result, got errors %v", result.Errors)
+			}
+			if result.TotalTax != tt.expectedTax {
+				t.Errorf("Expected total tax %v, got %v", tt.expectedTax, result.TotalTax)
+			}
+			if result.GrandTotal != tt.expectedGrandTotal {
+				t.Errorf("Expected grand total %v, got %v", tt.expectedGrandTotal, result.GrandTotal)
+			}
+			for _, breakdown := range result.TaxBreakdown {
+				if breakdown.TotalTax != tt.expectedTax {
+					t.Errorf("Expected breakdown tax %v, got %v", tt.expectedTax, breakdown.TotalTax)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateTaxBreakdownReconciles(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items = []TaxableItem{
+		{ID: "pen", UnitPrice: 0.10, TotalAmount: 0.10, Quantity: 1},
+		{ID: "pad", UnitPrice: 0.10, TotalAmount: 0.10, Quantity: 1},
+		{ID: "clip", UnitPrice: 0.10, TotalAmount: 0.10, Quantity: 1},
+	}
+	rule := createTestTaxRule()
+	rule.Rate = 15
+	input.TaxRules = []TaxRule{rule}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+
+	sum := 0.0
+	for _, breakdown := range result.TaxBreakdown {
+		sum += breakdown.TotalTax
+	}
+	if result.TotalTax != 0.05 || math.Abs(sum-result.TotalTax) > 1e-9 {
+		t.Errorf("Expected breakdown lines to sum to total tax 0.05, got total %v and lines summing to %v", result.TotalTax, sum)
+	}
+}
+
+func TestCalculateRoundsEffectiveRates(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items[0].UnitPrice = 3.0
+	input.Items[0].TotalAmount = 3.0
+	rule := createTestTaxRule()
+	rule.Method = TaxMethodFixed
+	rule.Rate = 1.0
+	input.TaxRules = []TaxRule{rule}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+	if result.EffectiveRate != 33.3333 {
+		t.Errorf("Expected effective rate 33.3333, got %v", result.EffectiveRate)
+	}
+	if result.WeightedEffectiveRate != 33.3333 {
+		t.Errorf("Expected weighted effective rate 33.3333, got %v", result.WeightedEffectiveRate)
+	}
+}
+
+func TestCalculateCustomerFavorableRounding(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items[0].UnitPrice = 1.0
+	input.Items[0].TotalAmount = 1.0
+
+	calc := createTestTaxCalculator()
+	calc.Rules[0].Rate = 8.875
+	result := calc.CalculateTax(input)
+	if result.TotalTax != 0.09 {
+		t.Errorf("Expected tax 0.09 with standard rounding, got %v", result.TotalTax)
+	}
+
+	calc.Configuration.CustomerFavorableRounding = true
+	result = calc.CalculateTax(input)
+	if result.TotalTax != 0.08 {
+		t.Errorf("Expected tax 0.08 with customer-favorable rounding, got %v", result.TotalTax)
+	}
+	if result.GrandTotal != 1.08 {
+		t.Errorf("Expected grand total 1.08, got %v", result.GrandTotal)
+	}
+	if result.TaxBreakdown[0].TotalTax != 0.08 {
+		t.Errorf("Expected breakdown tax 0.08, got %v", result.TaxBreakdown[0].TotalTax)
+	}
+}
+
+func TestCalculateGiftCardTax(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items = append(input.Items, TaxableItem{
+		ID:          "gift-card",
+		Name:        "Gift Card",
+		Category:    "gift_cards",
+		Quantity:    1,
+		UnitPrice:   50.0,
+		TotalAmount: 50.0,
+		IsGiftCard:  true,
+	})
+	rule := createTestTaxRule()
+	rule.ApplicableCategories = nil
+	input.TaxRules = []TaxRule{rule}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+	expectedTax := 100.0 * rule.Rate / 100
+	if math.Abs(result.TotalTax-expectedTax) > 0.001 {
+		t.Errorf("Expected tax %.2f on the non-gift-card item only, got %.2f", expectedTax, result.TotalTax)
+	}
+	giftCard := result.TaxBreakdown[1]
+	if giftCard.TotalTax != 0 || giftCard.ExemptAmount != 50.0 || giftCard.ExemptionReason != "Gift card sale not taxable" {
+		t.Errorf("Expected the gift card to be reported exempt, got %+v", giftCard)
+	}
+
+	// Jurisdictions that tax gift card sales opt in per rule
+	input.TaxRules[0].GiftCardsTaxable = true
+	result = Calculate(input)
+	expectedTax = 150.0 * rule.Rate / 100
+	if math.Abs(result.TotalTax-expectedTax) > 0.001 {
+		t.Errorf("Expected tax %.2f including the gift card, got %.2f", expectedTax, result.TotalTax)
+	}
+}
+
+func TestCalculateShippingTax(t *testing.T) {
+	input := createTestTaxInput()
+	input.ShippingAmount = 10.0
+	stateRule := createTestTaxRule()
+	stateRule.ID = "state"
+	stateRule.Rate = 4
+	stateRule.ShippingTaxable = true
+	cityRule := createTestTaxRule()
+	cityRule.ID = "city"
+	cityRule.Rate = 2
+	input.TaxRules = []TaxRule{stateRule, cityRule}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+	if len(result.TaxBreakdown) != 2 || result.TaxBreakdown[1].ItemID != ShippingBreakdownID {
+		t.Fatalf("Expected item and shipping breakdown lines, got %+v", result.TaxBreakdown)
+	}
+
+	shipping := result.TaxBreakdown[1]
+	if shipping.TotalTax != 0.4 || len(shipping.AppliedTaxes) != 1 || shipping.AppliedTaxes[0].RuleID != "state" {
+		t.Errorf("Expected shipping taxed 0.40 by the state rule only, got %+v", shipping)
+	}
+	if result.TotalTax != 6.4 {
+		t.Errorf("Expected total tax 6.40 (6.00 items + 0.40 shipping), got %v", result.TotalTax)
+	}
+
+	// Shipping configured out of the calculation is neither added nor taxed
+	calc := NewTaxCalculator(TaxConfiguration{
+		DefaultCurrency:   "USD",
+		RoundingMode:      "round",
+		RoundingPrecision: 2,
+		TaxOnShipping:     false,
+	})
+	calc.Rules = []TaxRule{stateRule, cityRule}
+	input.ShippingAmount = 20.0
+	result = calc.CalculateTax(input)
+	if len(result.TaxBreakdown) != 1 || result.ExemptAmount != 0 {
+		t.Errorf("Expected no shipping line without TaxOnShipping, got %+v", result.TaxBreakdown)
+	}
+	if result.Subtotal != 100 || result.TotalTax != 6.0 || result.GrandTotal != 106 {
+		t.Errorf("Expected subtotal 100, tax 6.00, grand total 106, got %v, %v, %v", result.Subtotal, result.TotalTax, result.GrandTotal)
+	}
+
+	// No shipping line when no rule taxes shipping
+	input.ShippingAmount = 10.0
+	stateRule.ShippingTaxable = false
+	input.TaxRules = []TaxRule{stateRule, cityRule}
+	result = Calculate(input)
+	if len(result.TaxBreakdown) != 1 || result.ExemptAmount != 0 {
+		t.Errorf("Expected no shipping line when no rule taxes shipping, got %+v", result.TaxBreakdown)
+	}
+	if result.TotalTax != 6.0 || result.Subtotal != 110 {
+		t.Errorf("Expected total tax 6.00 on items only and shipping in the subtotal, got %v and %v", result.TotalTax, result.Subtotal)
+	}
+}
+
+func TestCalculatePerWeightTax(t *testing.T) {
+	tests := []struct {
+		name        string
+		weight      float64
+		quantity    int
+		expectedTax float64
+	}{
+		{name: "weighted item pays per kg", weight: 1.5, quantity: 2, expectedTax: 1.5},
+		{name: "item without weight pays nothing", weight: 0, quantity: 2, expectedTax: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := createTestTaxInput()
+			input.Items[0].Weight = tt.weight
+			input.Items[0].Quantity = tt.quantity
+			rule := createTestTaxRule()
+			rule.Type = TaxTypeExcise
+			rule.Method = TaxMethodPerWeight
+			rule.PerWeightRate = 0.5
+			input.TaxRules = []TaxRule{rule}
+
+			result := Calculate(input)
+			if !result.IsValid {
+				t.Fatalf("Expected valid result, got errors %v", result.Errors)
+			}
+			if result.TotalTax != tt.expectedTax {
+				t.Errorf("Expected total tax %v, got %v", tt.expectedTax, result.TotalTax)
+			}
+		})
+	}
+}
+
+func TestCalculateAddressWarnings(t *testing.T) {
+	tests := []struct {
+		name             string
+		shippingAddress  Address
+		expectedWarnings []string
+	}{
+		{
+			name:             "complete address",
+			shippingAddress:  Address{City: "New York", State: "NY", PostalCode: "10001", Country: "US"},
+			expectedWarnings: []string{},
+		},
+		{
+			name:             "blank country",
+			shippingAddress:  Address{City: "New York", State: "NY"},
+			expectedWarnings: []string{"shipping address: address country is required"},
+		},
+		{
+			name:             "missing state referenced by rule",
+			shippingAddress:  Address{City: "New York", Country: "US"},
+			expectedWarnings: []string{"shipping address: address state is missing but rules reference states"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := createTestTaxInput()
+			input.ShippingAddress = tt.shippingAddress
+			input.TaxRules = []TaxRule{createTestTaxRule()}
+
+			result := Calculate(input)
+			if !result.IsValid {
+				t.Fatalf("Expected valid result, got errors %v", result.Errors)
+			}
+			if len(result.Warnings) != len(tt.expectedWarnings) {
+				t.Fatalf("Expected warnings %v, got %v", tt.expectedWarnings, result.Warnings)
+			}
+			for i, warning := range tt.expectedWarnings {
+				if result.Warnings[i] != warning {
+					t.Errorf("Expected warning %q, got %q", warning, result.Warnings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateWeightedEffectiveRate(t *testing.T) {
+	electronics := createTestTaxRule()
+	electronics.ID = "electronics"
+	electronics.Rate = 10
+	electronics.ApplicableCategories = []string{"electronics"}
+
+	food := createTestTaxRule()
+	food.ID = "food"
+	food.Rate = 2
+	food.ApplicableCategories = []string{"food"}
+
+	input := createTestTaxInput()
+	input.Items = []TaxableItem{
+		{ID: "laptop", UnitPrice: 100, TotalAmount: 100, Quantity: 1, Category: "electronics"},
+		{ID: "groceries", UnitPrice: 300, TotalAmount: 300, Quantity: 1, Category: "food"},
+		{ID: "medicine", UnitPrice: 100, TotalAmount: 100, Quantity: 1, Category: "health", IsExempt: true},
+	}
+	input.TaxRules = []TaxRule{electronics, food}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+
+	// Tax: 10 on electronics + 6 on food = 16, over 400 taxable
+	if result.TotalTax != 16 {
+		t.Errorf("Expected total tax 16, got %v", result.TotalTax)
+	}
+	if math.Abs(result.WeightedEffectiveRate-4.0) > 0.0001 {
+		t.Errorf("Expected weighted effective rate 4.0, got %v", result.WeightedEffectiveRate)
+	}
+	if math.Abs(result.EffectiveRate-3.2) > 0.0001 {
+		t.Errorf("Expected effective rate 3.2, got %v", result.EffectiveRate)
+	}
+
+	input.Items = []TaxableItem{
+		{ID: "medicine", UnitPrice: 100, TotalAmount: 100, Quantity: 1, Category: "health", IsExempt: true},
+	}
+	if result := Calculate(input); result.WeightedEffectiveRate != 0 {
+		t.Errorf("Expected zero weighted rate without taxable items, got %v", result.WeightedEffectiveRate)
+	}
+}
+
 func TestCalculateSubtotal(t *testing.T) {
 	calc := createTestTaxCalculator()
 	items := []TaxableItem{
@@ -404,6 +786,149 @@ func TestCalculateTaxForRule(t *testing.T) {
 	}
 }
 
+func createTestTaxHoliday() TaxHoliday {
+	return TaxHoliday{
+		ID:                   "back-to-school",
+		Name:                 "Back-to-School Holiday",
+		StartDate:            time.Now().AddDate(0, 0, -1),
+		EndDate:              time.Now().AddDate(0, 0, 1),
+		ApplicableCategories: []string{"clothing"},
+		PriceCap:             100.0,
+	}
+}
+
+func TestCalculateTaxDuringTaxHoliday(t *testing.T) {
+	calc := createTestTaxCalculator()
+	calc.Configuration.TaxHolidays = []TaxHoliday{createTestTaxHoliday()}
+	input := createTestTaxInput()
+	input.Items[0].Category = "clothing"
+	input.Items[0].UnitPrice = 50.0
+	input.Items[0].TotalAmount = 50.0
+
+	result := calc.CalculateTax(input)
+
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors: %v", result.Errors)
+	}
+	if result.TotalTax != 0 {
+		t.Errorf("Expected no tax during tax holiday, got %f", result.TotalTax)
+	}
+	breakdown := result.TaxBreakdown[0]
+	if breakdown.ExemptAmount != 50.0 {
+		t.Errorf("Expected exempt amount 50.0, got %f", breakdown.ExemptAmount)
+	}
+	if breakdown.ExemptionReason != "Tax holiday: Back-to-School Holiday" {
+		t.Errorf("Expected tax holiday exemption reason, got %q", breakdown.ExemptionReason)
+	}
+}
+
+func TestCalculateTaxOutsideTaxHoliday(t *testing.T) {
+	calc := createTestTaxCalculator()
+	holiday := createTestTaxHoliday()
+	holiday.StartDate = time.Now().AddDate(0, 1, 0)
+	holiday.EndDate = time.Now().AddDate(0, 1, 3)
+	calc.Configuration.TaxHolidays = []TaxHoliday{holiday}
+	input := createTestTaxInput()
+	input.Items[0].Category = "clothing"
+	input.Items[0].UnitPrice = 50.0
+	input.Items[0].TotalAmount = 50.0
+
+	result := calc.CalculateTax(input)
+
+	if result.TotalTax <= 0 {
+		t.Errorf("Expected tax outside tax holiday, got %f", result.TotalTax)
+	}
+	if result.TaxBreakdown[0].ExemptAmount != 0 {
+		t.Errorf("Expected no exempt amount, got %f", result.TaxBreakdown[0].ExemptAmount)
+	}
+}
+
+func TestCalculateTaxHolidayLastDay(t *testing.T) {
+	holiday := createTestTaxHoliday()
+	holiday.StartDate = time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC)
+	holiday.EndDate = time.Date(2024, 8, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		transactionDate time.Time
+		exempt          bool
+	}{
+		{"start of last day", time.Date(2024, 8, 4, 0, 0, 0, 0, time.UTC), true},
+		{"afternoon of last day", time.Date(2024, 8, 4, 15, 30, 0, 0, time.UTC), true},
+		{"last second of last day", time.Date(2024, 8, 4, 23, 59, 59, 999999999, time.UTC), true},
+		{"midnight after last day", time.Date(2024, 8, 5, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calc := createTestTaxCalculator()
+			calc.Configuration.TaxHolidays = []TaxHoliday{holiday}
+			input := createTestTaxInput()
+			input.TransactionDate = tt.transactionDate
+			input.Items[0].Category = "clothing"
+			input.Items[0].UnitPrice = 50.0
+			input.Items[0].TotalAmount = 50.0
+
+			result := calc.CalculateTax(input)
+
+			if tt.exempt && result.TotalTax != 0 {
+				t.Errorf("Expected no tax on %v, got %f", tt.transactionDate, result.TotalTax)
+			}
+			if !tt.exempt && result.TotalTax <= 0 {
+				t.Errorf("Expected tax on %v, got %f", tt.transactionDate, result.TotalTax)
+			}
+		})
+	}
+}
+
+func TestCalculateTaxHolidayAbovePriceCap(t *testing.T) {
+	calc := createTestTaxCalculator()
+	calc.Configuration.TaxHolidays = []TaxHoliday{createTestTaxHoliday()}
+	input := createTestTaxInput()
+	input.Items[0].Category = "clothing"
+	input.Items[0].UnitPrice = 150.0
+	input.Items[0].TotalAmount = 150.0
+
+	result := calc.CalculateTax(input)
+
+	if result.TotalTax <= 0 {
+		t.Errorf("Expected item above price cap to be taxed, got %f", result.TotalTax)
+	}
+	if result.TaxBreakdown[0].ExemptionReason != "" {
+		t.Errorf("Expected no exemption reason, got %q", result.TaxBreakdown[0].ExemptionReason)
+	}
+}
+
+func TestCalculateTaxZeroQuantityAndZeroPrice(t *testing.T) {
+	calc := createTestTaxCalculator()
+	input := createTestTaxInput()
+	input.Items[0].Quantity = 0
+
+	result := calc.CalculateTax(input)
+
+	if result.IsValid {
+		t.Error("Expected zero-quantity item to be rejected")
+	}
+
+	fixedRule := createTestTaxRule()
+	fixedRule.Method = TaxMethodFixed
+	fixedRule.Rate = 2.5
+	calc.Configuration.DefaultRules = []TaxRule{fixedRule}
+	calc.Rules = []TaxRule{fixedRule}
+	input = createTestTaxInput()
+	input.Items[0].UnitPrice = 0
+	input.Items[0].TotalAmount = 0
+
+	result = calc.CalculateTax(input)
+
+	if !result.IsValid {
+		t.Fatalf("Expected zero-price item to be valid, got errors %v", result.Errors)
+	}
+	if result.TotalTax != 0 {
+		t.Errorf("Expected zero tax for zero-price item, got %f", result.TotalTax)
+	}
+}
+
 // Benchmark tests
 func BenchmarkCalculateTax(b *testing.B) {
 	calc := createTestTaxCalculator()
@@ -427,4 +952,220 @@ func BenchmarkCalculateSubtotal(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		calc.calculateSubtotal(items)
 	}
-}
\ No newline at end of file
+}
+func TestCalculateItemEffectiveRate(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items = append(input.Items, TaxableItem{
+		ID:          "exempt-item",
+		Name:        "Exempt Item",
+		Category:    "general",
+		UnitPrice:   50.0,
+		Quantity:    1,
+		TotalAmount: 50.0,
+		IsExempt:    true,
+	})
+	state := createTestTaxRule()
+	state.Rate = 6.0
+	county := createTestTaxRule()
+	county.ID = "county-tax"
+	county.Name = "County Tax"
+	county.Rate = 2.375
+	input.TaxRules = []TaxRule{state, county}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+	if len(result.TaxBreakdown[0].AppliedTaxes) != 2 {
+		t.Fatalf("Expected 2 stacked taxes, got %d", len(result.TaxBreakdown[0].AppliedTaxes))
+	}
+	if result.TaxBreakdown[0].EffectiveRate != 8.375 {
+		t.Errorf("Expected item effective rate 8.375, got %v", result.TaxBreakdown[0].EffectiveRate)
+	}
+	if result.TaxBreakdown[1].EffectiveRate != 0 {
+		t.Errorf("Expected exempt item effective rate 0, got %v", result.TaxBreakdown[1].EffectiveRate)
+	}
+
+	// Compound taxes: 10% then 5% of 110 is 15.50 on the $100 item
+	calc := NewTaxCalculator(TaxConfiguration{
+		DefaultCurrency:   "USD",
+		RoundingMode:      "round",
+		RoundingPrecision: 2,
+		CompoundTaxes:     true,
+	})
+	state.Rate = 10
+	state.Priority = 2
+	county.Rate = 5
+	county.Priority = 1
+	calc.Rules = []TaxRule{state, county}
+	result = calc.CalculateTax(input)
+	if result.TotalTax != 15.5 {
+		t.Fatalf("Expected compound tax 15.50, got %v", result.TotalTax)
+	}
+	if result.TaxBreakdown[0].EffectiveRate != 15.5 || result.WeightedEffectiveRate != 15.5 {
+		t.Errorf("Expected item and weighted effective rates 15.5, got %v and %v", result.TaxBreakdown[0].EffectiveRate, result.WeightedEffectiveRate)
+	}
+}
+
+func TestCalculateOrderDiscountAllocation(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items = []TaxableItem{
+		{ID: "laptop", Name: "Laptop", Category: "electronics", UnitPrice: 60.0, Quantity: 1, TotalAmount: 60.0},
+		{ID: "mouse", Name: "Mouse", Category: "electronics", UnitPrice: 20.0, Quantity: 2, TotalAmount: 40.0},
+	}
+	rule := createTestTaxRule()
+	rule.Rate = 10
+	input.TaxRules = []TaxRule{rule}
+
+	result := Calculate(input)
+	if result.TotalTax != 10.0 {
+		t.Fatalf("Expected tax 10.00 without order discount, got %v", result.TotalTax)
+	}
+
+	input.OrderDiscountAmount = 10.0
+	result = Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+
+	expected := []struct {
+		amount float64
+		tax    float64
+	}{
+		{54.0, 5.4},
+		{36.0, 3.6},
+	}
+	for i, want := range expected {
+		breakdown := result.TaxBreakdown[i]
+		if math.Abs(breakdown.TaxableAmount-want.amount) > 1e-9 || math.Abs(breakdown.TotalTax-want.tax) > 1e-9 {
+			t.Errorf("Item %d: expected taxable %.2f and tax %.2f, got %.2f and %.2f", i, want.amount, want.tax, breakdown.TaxableAmount, breakdown.TotalTax)
+		}
+	}
+	if result.Subtotal != 90.0 || result.TotalTax != 9.0 {
+		t.Errorf("Expected subtotal 90.00 and tax 9.00, got %v and %v", result.Subtotal, result.TotalTax)
+	}
+	if input.Items[0].TotalAmount != 60.0 {
+		t.Errorf("Expected input items to be left unchanged, got %v", input.Items[0].TotalAmount)
+	}
+
+	// Uneven split: the remainder cent goes to one item and the shares still sum to the discount
+	input.Items = []TaxableItem{
+		{ID: "a", Category: "electronics", UnitPrice: 10.0, Quantity: 1, TotalAmount: 10.0},
+		{ID: "b", Category: "electronics", UnitPrice: 10.0, Quantity: 1, TotalAmount: 10.0},
+		{ID: "c", Category: "electronics", UnitPrice: 10.0, Quantity: 1, TotalAmount: 10.0},
+	}
+	result = Calculate(input)
+	taxable := 0.0
+	for _, breakdown := range result.TaxBreakdown {
+		taxable += breakdown.TaxableAmount
+	}
+	if math.Abs(taxable-20.0) > 1e-9 || result.TaxBreakdown[0].TaxableAmount != 6.66 {
+		t.Errorf("Expected taxable amounts 6.66/6.67/6.67 summing to 20.00, got %+v", result.TaxBreakdown)
+	}
+
+	// A discount above the item total is capped
+	input.OrderDiscountAmount = 50.0
+	result = Calculate(input)
+	if result.TotalTax != 0 || len(result.Warnings) == 0 {
+		t.Errorf("Expected no tax and a cap warning, got tax %v and warnings %v", result.TotalTax, result.Warnings)
+	}
+
+	// Combining it with DiscountAmount would subtract the discount twice
+	input.OrderDiscountAmount = 10.0
+	input.DiscountAmount = 10.0
+	result = Calculate(input)
+	if result.IsValid || len(result.Errors) != 1 {
+		t.Errorf("Expected both discount fields to be rejected, got valid=%v errors %v", result.IsValid, result.Errors)
+	}
+}
+
+func TestCalculateTaxCodeRates(t *testing.T) {
+	input := createTestTaxInput()
+	input.Items = []TaxableItem{
+		{ID: "jacket", Name: "Jacket", Category: "apparel", TaxCode: "PC040100", UnitPrice: 100.0, Quantity: 1, TotalAmount: 100.0},
+		{ID: "tablet", Name: "Tablet", Category: "electronics", TaxCode: "P0000000", UnitPrice: 200.0, Quantity: 1, TotalAmount: 200.0},
+		{ID: "cable", Name: "Cable", Category: "electronics", UnitPrice: 10.0, Quantity: 1, TotalAmount: 10.0},
+	}
+	rule := createTestTaxRule()
+	rule.Rate = 8.0
+	input.TaxRules = []TaxRule{rule}
+	input.TaxCodeRates = TaxCodeRateResolver{
+		JurisdictionState: {"PC040100": 4.0},
+		JurisdictionCity:  {"P0000000": 1.0},
+	}
+
+	result := Calculate(input)
+	if !result.IsValid {
+		t.Fatalf("Expected valid result, got errors %v", result.Errors)
+	}
+
+	expected := []struct {
+		rate float64
+		tax  float64
+	}{
+		{4.0, 4.0},  // clothing code resolves to the reduced state rate
+		{8.0, 16.0}, // code only has a city rate, so the state rule rate applies
+		{8.0, 0.8},  // no tax code
+	}
+	for i, want := range expected {
+		breakdown := result.TaxBreakdown[i]
+		if len(breakdown.AppliedTaxes) != 1 {
+			t.Fatalf("Item %d: expected 1 applied tax, got %d", i, len(breakdown.AppliedTaxes))
+		}
+		if breakdown.AppliedTaxes[0].Rate != want.rate || breakdown.TotalTax != want.tax {
+			t.Errorf("Item %d: expected rate %v and tax %v, got %v and %v", i, want.rate, want.tax, breakdown.AppliedTaxes[0].Rate, breakdown.TotalTax)
+		}
+	}
+	if result.TotalTax != 20.8 {
+		t.Errorf("Expected total tax 20.8, got %v", result.TotalTax)
+	}
+
+	// Fixed-amount rules keep their own rate
+	rule.Method = TaxMethodFixed
+	rule.Rate = 2.0
+	input.TaxRules = []TaxRule{rule}
+	result = Calculate(input)
+	if result.TaxBreakdown[0].TotalTax != 2.0 {
+		t.Errorf("Expected fixed tax 2.0 regardless of tax code, got %v", result.TaxBreakdown[0].TotalTax)
+	}
+}
+
+func TestTaxCalculatorSnapshot(t *testing.T) {
+	calc := createTestTaxCalculator()
+	calc.Configuration.TaxHolidays = []TaxHoliday{createTestTaxHoliday()}
+
+	snapshot := calc.Snapshot()
+	if len(snapshot.DefaultRules) != 1 || snapshot.DefaultRules[0].ID != "test-rule" {
+		t.Fatalf("Expected snapshot to carry the calculator's rules, got %+v", snapshot.DefaultRules)
+	}
+
+	// Mutating the snapshot leaves the calculator untouched
+	snapshot.DefaultRules[0].Rate = 20.0
+	snapshot.DefaultRules[0].ApplicableStates[0] = "CA"
+	snapshot.TaxHolidays[0].ApplicableCategories[0] = "electronics"
+	if calc.Rules[0].Rate != 0.08 || calc.Rules[0].ApplicableStates[0] != "NY" {
+		t.Errorf("Expected calculator rules unchanged, got %+v", calc.Rules[0])
+	}
+	if calc.Configuration.TaxHolidays[0].ApplicableCategories[0] != "clothing" {
+		t.Errorf("Expected calculator holidays unchanged, got %+v", calc.Configuration.TaxHolidays[0])
+	}
+
+	// Mutating the calculator leaves an earlier snapshot untouched
+	snapshot = calc.Snapshot()
+	calc.Rules[0].Rate = 5.0
+	calc.Rules = append(calc.Rules, createTestTaxRule())
+	if len(snapshot.DefaultRules) != 1 || snapshot.DefaultRules[0].Rate != 0.08 {
+		t.Errorf("Expected snapshot unchanged after mutating the calculator, got %+v", snapshot.DefaultRules)
+	}
+
+	// A calculator restored from the snapshot is independent of it
+	restored := NewTaxCalculatorFromConfig(snapshot)
+	snapshot.DefaultRules[0].Rate = 50.0
+	if restored.Rules[0].Rate != 0.08 {
+		t.Errorf("Expected restored rules unchanged after mutating the snapshot, got %v", restored.Rules[0].Rate)
+	}
+	result := restored.CalculateTax(createTestTaxInput())
+	if !result.IsValid || result.TotalTax != 0.08 {
+		t.Errorf("Expected restored calculator to charge 0.08 tax, got %v (errors %v)", result.TotalTax, result.Errors)
+	}
+}
@@ -465,6 +465,25 @@ func (tre *TaxRuleEngine) ValidateRules() []error {
 	return errors
 }
 
+// ValidateRule validates a single rule without adding it to the engine.
+// It runs the same checks as AddRule, including custom validation rules, but
+// does not look for conflicts with the engine's rules.
+//
+// Parameters:
+//   - rule: TaxRule to validate
+//
+// Returns:
+//   - error: nil if valid, otherwise an error describing the validation failure
+//
+// Example:
+//
+//	if err := engine.ValidateRule(rule); err != nil {
+//		log.Printf("Rule %s is invalid: %v", rule.ID, err)
+//	}
+func (tre *TaxRuleEngine) ValidateRule(rule TaxRule) error {
+	return tre.validateRule(rule)
+}
+
 // OptimizeRules optimizes the order of rules for better performance.
 // Currently sorts rules alphabetically by name for consistent ordering.
 // Future implementations may include more sophisticated optimization strategies.
@@ -115,6 +115,10 @@ const (
 	// Used when multiple taxes are applied and one is calculated on the total
 	// including other taxes.
 	TaxMethodCompound TaxCalculationMethod = "compound"
+	
+	// TaxMethodPerWeight applies a rate per kilogram of item weight.
+	// Used for excise duties such as sugar or alcohol taxes.
+	TaxMethodPerWeight TaxCalculationMethod = "per_weight"
 )
 
 // TaxJurisdiction represents the governmental level or authority that
@@ -163,6 +167,7 @@ const (
 //		UnitPrice: 999.99,
 //		TotalAmount: 999.99,
 //		HSCode: "8471.30.01",
+//		TaxCode: "P0000000",
 //		IsDigital: false,
 //	}
 type TaxableItem struct {
@@ -187,7 +192,8 @@ type TaxableItem struct {
 	// TotalAmount is the total amount for all units (usually Quantity * UnitPrice)
 	TotalAmount float64 `json:"total_amount"`
 	
-	// Weight is the physical weight, used for shipping tax calculations
+	// Weight is the physical weight of a single unit in kilograms, used for
+	// weight conditions and per-weight excise taxes
 	Weight float64 `json:"weight,omitempty"`
 	
 	// Volume is the physical volume, used for shipping tax calculations
@@ -199,6 +205,10 @@ type TaxableItem struct {
 	// HSCode is the Harmonized System code for international trade classification
 	HSCode string `json:"hs_code,omitempty"`
 	
+	// TaxCode is a standardized product tax code (e.g. "PC040100" for clothing)
+	// looked up in the input's TaxCodeRates before falling back to the rule Rate
+	TaxCode string `json:"tax_code,omitempty"`
+	
 	// SKU is the stock keeping unit identifier
 	SKU string `json:"sku,omitempty"`
 	
@@ -211,6 +221,10 @@ type TaxableItem struct {
 	// IsLuxury indicates if this item qualifies as a luxury good
 	IsLuxury bool `json:"is_luxury,omitempty"`
 	
+	// IsGiftCard indicates the sale of a gift card, which is only taxed by rules
+	// with GiftCardsTaxable set
+	IsGiftCard bool `json:"is_gift_card,omitempty"`
+	
 	// IsExempt indicates if this item is exempt from taxation
 	IsExempt bool `json:"is_exempt,omitempty"`
 	
@@ -259,6 +273,19 @@ type TaxRule struct {
 	// Rate is the tax rate (percentage for percentage method, amount for fixed method)
 	Rate float64 `json:"rate"`
 	
+	// PerWeightRate is the tax amount per kilogram for the per-weight method
+	PerWeightRate float64 `json:"per_weight_rate,omitempty"`
+	
+	// ShippingTaxable indicates whether this rule also taxes the shipping charge
+	// when the configuration has TaxOnShipping set; leave it off in
+	// jurisdictions where shipping is exempt
+	ShippingTaxable bool `json:"shipping_taxable,omitempty"`
+	
+	// GiftCardsTaxable indicates whether this rule taxes gift card sales; most
+	// jurisdictions tax gift cards on redemption rather than sale, so it is off
+	// by default
+	GiftCardsTaxable bool `json:"gift_cards_taxable,omitempty"`
+	
 	// MinAmount is the minimum taxable amount for this rule to apply
 	MinAmount float64 `json:"min_amount,omitempty"`
 	
@@ -513,6 +540,13 @@ type TaxCalculationInput struct {
 	// ShippingAddress is the destination address for tax jurisdiction determination
 	ShippingAddress Address       `json:"shipping_address"`
 	
+	// OriginAddress is the seller's ship-from address, used for nexus determination
+	OriginAddress   Address       `json:"origin_address,omitempty"`
+	
+	// NexusStates lists the states where the seller must collect tax; when set,
+	// no tax is collected for destinations without nexus (see HasNexus)
+	NexusStates     []string      `json:"nexus_states,omitempty"`
+	
 	// TransactionDate is the date of the transaction
 	TransactionDate time.Time     `json:"transaction_date"`
 	
@@ -525,15 +559,30 @@ type TaxCalculationInput struct {
 	// ExchangeRate is the currency exchange rate if different from base currency
 	ExchangeRate    float64       `json:"exchange_rate,omitempty"`
 	
-	// ShippingAmount is the shipping cost for the transaction
+	// ShippingAmount is the shipping cost for the transaction; with TaxOnShipping
+	// it is added to the Subtotal and taxed by applicable rules with
+	// ShippingTaxable set, otherwise it is left out of the calculation
 	ShippingAmount  float64       `json:"shipping_amount,omitempty"`
 	
-	// DiscountAmount is the total discount applied to the transaction
+	// DiscountAmount is the total discount applied to the transaction; with
+	// TaxOnDiscounts it lowers the Subtotal but not the items' taxable amounts.
+	// New callers should use OrderDiscountAmount instead; setting both is
+	// rejected because the discount would be counted twice
 	DiscountAmount  float64       `json:"discount_amount,omitempty"`
 	
+	// OrderDiscountAmount is an order-level discount applied before tax. It is
+	// allocated across items in proportion to their TotalAmount, and each item
+	// is taxed on its discounted amount. This is the field to use for order
+	// discounts; it cannot be combined with DiscountAmount
+	OrderDiscountAmount float64   `json:"order_discount_amount,omitempty"`
+	
 	// TaxRules contains specific tax rules to apply for this calculation
 	TaxRules        []TaxRule     `json:"tax_rules,omitempty"`
 	
+	// TaxCodeRates maps item tax codes to rates per jurisdiction; percentage and
+	// compound rules use the resolved rate for items with a matching TaxCode
+	TaxCodeRates    TaxCodeRateResolver `json:"tax_code_rates,omitempty"`
+	
 	// Overrides contains any manual tax overrides to apply
 	Overrides       []TaxOverride `json:"overrides,omitempty"`
 	
@@ -541,6 +590,38 @@ type TaxCalculationInput struct {
 	Context         map[string]interface{} `json:"context,omitempty"`
 }
 
+// TaxCodeRateResolver maps standardized product tax codes to percentage rates
+// for each jurisdiction, as used by tax-service integrations that classify
+// items by code rather than by free-text category. A code with no entry for a
+// jurisdiction falls back to the rule's own Rate.
+//
+// Example:
+//
+//	rates := TaxCodeRateResolver{
+//		JurisdictionState: {
+//			"PC040100": 4.0, // clothing at a reduced rate
+//			"PF050001": 0.0, // groceries exempt
+//		},
+//	}
+type TaxCodeRateResolver map[TaxJurisdiction]map[string]float64
+
+// Resolve returns the rate configured for a tax code in a jurisdiction.
+//
+// Parameters:
+//   - jurisdiction: Jurisdiction of the rule being applied
+//   - code: Item tax code
+//
+// Returns:
+//   - float64: Percentage rate for the code
+//   - bool: False if the code is empty or has no rate in the jurisdiction
+func (r TaxCodeRateResolver) Resolve(jurisdiction TaxJurisdiction, code string) (float64, bool) {
+	if code == "" {
+		return 0, false
+	}
+	rate, ok := r[jurisdiction][code]
+	return rate, ok
+}
+
 // TaxOverride represents manual tax overrides that can be applied during
 // tax calculation. Overrides allow for manual adjustments to tax rates,
 // amounts, or exemptions for specific scenarios.
@@ -625,9 +706,13 @@ type AppliedTax struct {
 	OverrideReason string              `json:"override_reason,omitempty"`
 }
 
+// ShippingBreakdownID is the ItemID of the TaxBreakdown line for the shipping charge.
+const ShippingBreakdownID = "shipping"
+
 // TaxBreakdown represents detailed tax breakdown by item.
 // It shows how taxes were calculated for each individual item
-// in the transaction.
+// in the transaction. A taxed shipping charge gets its own line
+// with ItemID ShippingBreakdownID.
 //
 // Example:
 //
@@ -663,6 +748,11 @@ type TaxBreakdown struct {
 	
 	// ExemptionReason explains why part of the amount was exempt
 	ExemptionReason string   `json:"exemption_reason,omitempty"`
+	
+	// EffectiveRate is the combined percentage rate of all taxes stacked on this
+	// item (TotalTax / (ItemAmount - ExemptAmount) * 100), or 0 when nothing is
+	// taxable; compound taxes do not inflate the base
+	EffectiveRate float64    `json:"effective_rate"`
 }
 
 // TaxCalculationResult represents the complete result of tax calculation.
@@ -709,6 +799,11 @@ type TaxCalculationResult struct {
 	// EffectiveRate is the overall effective tax rate
 	EffectiveRate   float64         `json:"effective_rate"`
 	
+	// WeightedEffectiveRate is the blended tax rate across taxable items, weighted
+	// by their taxable amounts (TotalTax / taxable subtotal × 100). Exempt amounts
+	// are excluded, unlike EffectiveRate which divides by the full subtotal.
+	WeightedEffectiveRate float64   `json:"weighted_effective_rate"`
+	
 	// Currency is the currency code for all amounts
 	Currency        string          `json:"currency"`
 	
@@ -840,13 +935,19 @@ type TaxConfiguration struct {
 	// RoundingPrecision is the number of decimal places for rounding
 	RoundingPrecision  int               `json:"rounding_precision"`
 	
+	// UseCurrencyPrecision rounds to the transaction currency's decimal places
+	// (e.g. 0 for JPY, 2 for USD) instead of RoundingPrecision
+	UseCurrencyPrecision bool            `json:"use_currency_precision"`
+	
 	// TaxInclusivePricing indicates whether prices include tax by default
 	TaxInclusivePricing bool             `json:"tax_inclusive_pricing"`
 	
 	// CompoundTaxes indicates whether to compound taxes
 	CompoundTaxes      bool              `json:"compound_taxes"`
 	
-	// TaxOnShipping indicates whether to apply tax to shipping costs
+	// TaxOnShipping indicates whether shipping costs are part of the calculation.
+	// When false, ShippingAmount is ignored: it is not added to the Subtotal and
+	// no shipping line is taxed, even by rules with ShippingTaxable set
 	TaxOnShipping      bool              `json:"tax_on_shipping"`
 	
 	// TaxOnDiscounts indicates whether to apply tax after discounts
@@ -861,10 +962,53 @@ type TaxConfiguration struct {
 	// ReportingFrequency specifies how often reports are generated ("monthly", "quarterly", "annually")
 	ReportingFrequency string            `json:"reporting_frequency"`
 	
+	// TaxHolidays lists temporary category exemptions such as sales-tax holidays
+	TaxHolidays        []TaxHoliday      `json:"tax_holidays,omitempty"`
+	
+	// CustomerFavorableRounding rounds every charged amount (taxes, subtotal and
+	// grand total) down, overriding RoundingMode, for consumer-protection rules
+	CustomerFavorableRounding bool       `json:"customer_favorable_rounding,omitempty"`
+	
 	// Settings provides additional configuration options
 	Settings           map[string]interface{} `json:"settings,omitempty"`
 }
 
+// TaxHoliday represents a temporary window during which items in specific
+// categories are exempt from tax, such as a back-to-school sales-tax holiday.
+// Items qualify only when the transaction date falls inside the window and,
+// if a price cap is set, their unit price does not exceed it.
+//
+// Example:
+//
+//	holiday := &TaxHoliday{
+//		ID: "back-to-school-2024",
+//		Name: "Back-to-School Sales Tax Holiday",
+//		StartDate: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC),
+//		EndDate: time.Date(2024, 8, 4, 0, 0, 0, 0, time.UTC),
+//		ApplicableCategories: []string{"clothing", "school_supplies"},
+//		PriceCap: 100.0,
+//	}
+type TaxHoliday struct {
+	// ID is the unique identifier for the tax holiday
+	ID          string    `json:"id"`
+	
+	// Name is the human-readable name of the tax holiday
+	Name        string    `json:"name"`
+	
+	// StartDate is the first moment of the holiday window (inclusive)
+	StartDate   time.Time `json:"start_date"`
+	
+	// EndDate is the last day of the holiday window; the whole day is included,
+	// up to midnight in EndDate's location
+	EndDate     time.Time `json:"end_date"`
+	
+	// ApplicableCategories lists item categories or subcategories that qualify
+	ApplicableCategories []string `json:"applicable_categories"`
+	
+	// PriceCap is the maximum unit price that qualifies (0 means no cap)
+	PriceCap    float64   `json:"price_cap,omitempty"`
+}
+
 // TaxValidationRule represents validation rules for tax calculations.
 // These rules help ensure tax calculations are accurate and compliant
 // with business rules and regulatory requirements.
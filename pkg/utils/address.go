@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+)
+
+// Address holds the address components that tax and shipping rules match on.
+// The tax and shipping packages each define their own Address type; both map onto
+// this struct so addresses can be validated the same way everywhere.
+//
+// Example:
+//	address := Address{Country: "US", State: "CA", PostalCode: "90210"}
+type Address struct {
+	Country    string
+	State      string
+	PostalCode string
+}
+
+// AddressRequirements lists the optional address components that configured rules
+// depend on. A missing component is only worth a warning when some rule references it.
+//
+// Example:
+//	// Zone rules match on states, so a missing state changes the result
+//	required := AddressRequirements{State: true}
+type AddressRequirements struct {
+	State      bool
+	PostalCode bool
+}
+
+// ErrMissingCountry is returned by ValidateAddress when an address has no country.
+var ErrMissingCountry = errors.New("address country is required")
+
+// ValidateAddress checks that an address carries the fields tax and shipping
+// calculations cannot do without. A country is always required: without it zones
+// fall back to local and no tax rule can match. Use AddressWarnings for the
+// components that only matter when a rule references them.
+//
+// Parameters:
+//   - a: Address components to validate
+//
+// Returns:
+//   - ErrMissingCountry if the country is blank, nil otherwise
+//
+// Example:
+//	err := ValidateAddress(Address{State: "CA"})
+//	// err: ErrMissingCountry
+func ValidateAddress(a Address) error {
+	if strings.TrimSpace(a.Country) == "" {
+		return ErrMissingCountry
+	}
+	return nil
+}
+
+// AddressWarnings reports optional address components that are missing although
+// configured rules depend on them. State and postal code are only checked when
+// required says a rule references them.
+//
+// Parameters:
+//   - address: Address components to check
+//   - required: Optional components that configured rules depend on
+//
+// Returns:
+//   - Warnings for missing optional components (nil if none)
+//
+// Example:
+//	warnings := AddressWarnings(Address{Country: "US"}, AddressRequirements{State: true})
+//	// warnings: ["address state is missing but rules reference states"]
+func AddressWarnings(address Address, required AddressRequirements) []string {
+	var warnings []string
+	if required.State && strings.TrimSpace(address.State) == "" {
+		warnings = append(warnings, "address state is missing but rules reference states")
+	}
+	if required.PostalCode && strings.TrimSpace(address.PostalCode) == "" {
+		warnings = append(warnings, "address postal code is missing but rules reference postal codes")
+	}
+	return warnings
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name        string
+		address     Address
+		expectedErr error
+	}{
+		{"Complete address", Address{Country: "US", State: "CA", PostalCode: "90210"}, nil},
+		{"Country only", Address{Country: "US"}, nil},
+		{"Blank country", Address{State: "CA", PostalCode: "90210"}, ErrMissingCountry},
+		{"Whitespace country", Address{Country: "  "}, ErrMissingCountry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAddress(tt.address); !errors.Is(err, tt.expectedErr) {
+				t.Errorf("ValidateAddress error = %v; want %v", err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestAddressWarnings(t *testing.T) {
+	tests := []struct {
+		name             string
+		address          Address
+		required         AddressRequirements
+		expectedWarnings int
+	}{
+		{"Complete address", Address{Country: "US", State: "CA", PostalCode: "90210"}, AddressRequirements{State: true, PostalCode: true}, 0},
+		{"Missing state not referenced", Address{Country: "US"}, AddressRequirements{}, 0},
+		{"Missing state referenced", Address{Country: "US", PostalCode: "90210"}, AddressRequirements{State: true}, 1},
+		{"Missing state and postal code referenced", Address{Country: "US"}, AddressRequirements{State: true, PostalCode: true}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if warnings := AddressWarnings(tt.address, tt.required); len(warnings) != tt.expectedWarnings {
+				t.Errorf("AddressWarnings = %v; want %d warnings", warnings, tt.expectedWarnings)
+			}
+		})
+	}
+}
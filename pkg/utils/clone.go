@@ -0,0 +1,65 @@
+package utils
+
+// CloneMap returns a deep copy of a metadata-style map. Nested maps and slices
+// of interface values are copied recursively; other values are copied as is.
+//
+// Parameters:
+//   - m: Map to copy; nil stays nil
+//
+// Returns:
+//   - A map that shares no nested maps or slices with m
+//
+// Example:
+//	meta := map[string]interface{}{"tags": []interface{}{"sale"}}
+//	copied := CloneMap(meta)
+//	copied["tags"].([]interface{})[0] = "new" // meta is unchanged
+func CloneMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		copied[key] = CloneValue(value)
+	}
+	return copied
+}
+
+// CloneValue returns a deep copy of a value held in an interface, such as a
+// rule condition value. map[string]interface{}, []interface{}, []string and
+// []float64 are copied; other values are returned unchanged.
+//
+// Parameters:
+//   - value: Value to copy
+//
+// Returns:
+//   - A copy that shares no supported maps or slices with value
+//
+// Example:
+//	categories := CloneValue([]string{"books", "music"}).([]string)
+func CloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return CloneMap(v)
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, element := range v {
+			copied[i] = CloneValue(element)
+		}
+		return copied
+	case []string:
+		if v == nil {
+			return v
+		}
+		return append([]string{}, v...)
+	case []float64:
+		if v == nil {
+			return v
+		}
+		return append([]float64{}, v...)
+	default:
+		return value
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import "testing"
+
+func TestCloneMap(t *testing.T) {
+	original := map[string]interface{}{
+		"name":   "summer",
+		"tags":   []interface{}{"sale", map[string]interface{}{"level": 1}},
+		"skus":   []string{"a", "b"},
+		"nested": map[string]interface{}{"weights": []float64{1.5, 2.5}},
+	}
+
+	copied := CloneMap(original)
+	copied["name"] = "winter"
+	copied["tags"].([]interface{})[0] = "clearance"
+	copied["tags"].([]interface{})[1].(map[string]interface{})["level"] = 2
+	copied["skus"].([]string)[0] = "z"
+	copied["nested"].(map[string]interface{})["weights"].([]float64)[0] = 9
+
+	if original["name"] != "summer" {
+		t.Errorf("Expected top-level value unchanged, got %v", original["name"])
+	}
+	tags := original["tags"].([]interface{})
+	if tags[0] != "sale" || tags[1].(map[string]interface{})["level"] != 1 {
+		t.Errorf("Expected nested slice and map unchanged, got %v", tags)
+	}
+	if original["skus"].([]string)[0] != "a" {
+		t.Errorf("Expected string slice unchanged, got %v", original["skus"])
+	}
+	if original["nested"].(map[string]interface{})["weights"].([]float64)[0] != 1.5 {
+		t.Errorf("Expected float slice unchanged, got %v", original["nested"])
+	}
+
+	if CloneMap(nil) != nil {
+		t.Error("Expected nil map to stay nil")
+	}
+	if CloneValue(42) != 42 {
+		t.Error("Expected scalar values to be returned as is")
+	}
+}
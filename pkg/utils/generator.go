@@ -23,6 +23,7 @@ import (
 	mathRand "math/rand"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -284,21 +285,32 @@ func GenerateCustomID(prefix string, includeTimestamp, includeRandom bool) strin
 	return strings.Join(parts, "-")
 }
 
+// DefaultCouponCodeMaxRetries is the default number of attempts a
+// CouponCodeGenerator makes to produce a code free of blocklisted substrings.
+const DefaultCouponCodeMaxRetries = 100
+
 // CouponCodeGenerator provides customizable coupon code generation with
 // support for character set filtering and pattern-based generation.
 // By default, it excludes visually similar characters to reduce user confusion.
+// An optional blocklist prevents codes that spell offensive or reserved words.
 //
 // Example usage:
 //
 //	gen := NewCouponCodeGenerator(8)
 //	code := gen.GenerateCouponCode() // Returns "ABCD2345" (example)
 //
+//	// Blocklisted words, with an error once the retries run out
+//	gen.SetBlocklist([]string{"BAD", "FAIL"})
+//	safeCode, err := gen.TryGenerateCouponCode()
+//
 //	// Custom pattern
 //	patternCode := gen.GenerateCouponCodeWithPattern("SAVE-XXX") // Returns "SAVE-ABC" (example)
 type CouponCodeGenerator struct {
-	length   int      // Length of generated coupon codes
-	charset  string   // Character set to use for generation
-	excluded []string // Characters to exclude from generation
+	length     int      // Length of generated coupon codes
+	charset    string   // Character set to use for generation
+	excluded   []string // Characters to exclude from generation
+	blocklist  []string // Upper-cased substrings that must not appear in codes
+	maxRetries int      // Maximum attempts to avoid blocklisted substrings
 }
 
 // NewCouponCodeGenerator creates a new coupon code generator with the specified length.
@@ -321,6 +333,7 @@ func NewCouponCodeGenerator(length int) *CouponCodeGenerator {
 		length:  length,
 		charset: "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
 		excluded: []string{"0", "O", "1", "I", "L"}, // Exclude confusing characters
+		maxRetries: DefaultCouponCodeMaxRetries,
 	}
 }
 
@@ -352,27 +365,91 @@ func (g *CouponCodeGenerator) SetExcludedChars(excluded []string) {
 	g.excluded = excluded
 }
 
+// SetBlocklist sets substrings that must never appear in generated coupon codes.
+// Matching is case-insensitive, so "bad" also blocks "BAD" and "Bad".
+// Empty entries are ignored.
+//
+// Parameters:
+//   - words: Slice of substrings to block.
+//
+// Example:
+//
+//	gen.SetBlocklist([]string{"FAIL", "DEAD"}) // Never generate codes containing these
+func (g *CouponCodeGenerator) SetBlocklist(words []string) {
+	g.blocklist = make([]string, 0, len(words))
+	for _, word := range words {
+		if word != "" {
+			g.blocklist = append(g.blocklist, strings.ToUpper(word))
+		}
+	}
+}
+
+// SetMaxRetries sets how many times the generator attempts to produce a code
+// free of blocklisted substrings before giving up (see TryGenerateCouponCode).
+// Values less than 1 reset the limit to DefaultCouponCodeMaxRetries.
+//
+// Parameters:
+//   - maxRetries: Maximum number of generation attempts per code.
+//
+// Example:
+//
+//	gen.SetMaxRetries(500)
+func (g *CouponCodeGenerator) SetMaxRetries(maxRetries int) {
+	if maxRetries < 1 {
+		maxRetries = DefaultCouponCodeMaxRetries
+	}
+	g.maxRetries = maxRetries
+}
+
 // GenerateCouponCode generates a random coupon code using the configured
 // character set and length. Excluded characters are automatically filtered
-// out from the generation process.
+// out from the generation process, and codes containing a blocklisted
+// substring are regenerated. Use TryGenerateCouponCode to find out when the
+// blocklist cannot be satisfied.
 //
 // Returns:
-//   - string: A randomly generated coupon code of the configured length.
+//   - string: A randomly generated coupon code of the configured length, or an
+//     empty string if every attempt contained a blocklisted substring.
 //
 // Example:
 //
 //	gen := NewCouponCodeGenerator(6)
 //	code := gen.GenerateCouponCode() // Returns "ABC123" (example)
 func (g *CouponCodeGenerator) GenerateCouponCode() string {
+	code, _ := g.TryGenerateCouponCode()
+	return code
+}
+
+// TryGenerateCouponCode generates a random coupon code like GenerateCouponCode,
+// but reports an error when no code free of blocklisted substrings is found
+// within the configured number of retries (see SetMaxRetries).
+//
+// Returns:
+//   - string: A randomly generated coupon code of the configured length.
+//   - error: An error if no code free of blocklisted substrings could be
+//     generated within the configured number of retries.
+//
+// Example:
+//
+//	gen := NewCouponCodeGenerator(6)
+//	gen.SetBlocklist([]string{"FAIL"})
+//	code, err := gen.TryGenerateCouponCode() // Returns "ABC123" (example)
+func (g *CouponCodeGenerator) TryGenerateCouponCode() (string, error) {
 	charset := g.getFilteredCharset()
-	code := make([]byte, g.length)
 
-	for i := range code {
-		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		code[i] = charset[n.Int64()]
+	for attempt := 0; attempt < g.maxRetries; attempt++ {
+		code := make([]byte, g.length)
+		for i := range code {
+			n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+			code[i] = charset[n.Int64()]
+		}
+
+		if !g.isBlocked(string(code)) {
+			return string(code), nil
+		}
 	}
 
-	return string(code)
+	return "", fmt.Errorf("failed to generate coupon code without blocklisted substrings after %d attempts", g.maxRetries)
 }
 
 // GenerateCouponCodeWithPattern generates a coupon code following a specific pattern.
@@ -412,7 +489,9 @@ func (g *CouponCodeGenerator) GenerateCouponCodeWithPattern(pattern string) stri
 //   - count: Number of unique coupon codes to generate.
 //
 // Returns:
-//   - []string: Slice of unique coupon codes.
+//   - []string: Slice of unique coupon codes free of blocklisted substrings, or
+//     nil if a code could not be generated without them. Use
+//     TryGenerateBatchCouponCodes to get the error.
 //
 // Example:
 //
@@ -420,18 +499,59 @@ func (g *CouponCodeGenerator) GenerateCouponCodeWithPattern(pattern string) stri
 //	codes := gen.GenerateBatchCouponCodes(5)
 //	// Returns ["ABC123", "DEF456", "GHI789", "JKL234", "MNP567"] (example)
 func (g *CouponCodeGenerator) GenerateBatchCouponCodes(count int) []string {
+	codes, _ := g.TryGenerateBatchCouponCodes(count)
+	return codes
+}
+
+// TryGenerateBatchCouponCodes generates multiple unique coupon codes like
+// GenerateBatchCouponCodes, but stops with an error as soon as a code cannot be
+// generated without blocklisted substrings.
+//
+// Parameters:
+//   - count: Number of unique coupon codes to generate.
+//
+// Returns:
+//   - []string: Slice of unique coupon codes free of blocklisted substrings.
+//   - error: An error if any code could not be generated without blocklisted
+//     substrings within the configured number of retries.
+//
+// Example:
+//
+//	codes, err := gen.TryGenerateBatchCouponCodes(5)
+func (g *CouponCodeGenerator) TryGenerateBatchCouponCodes(count int) ([]string, error) {
 	codes := make([]string, 0, count)
 	generated := make(map[string]bool)
 
 	for len(codes) < count {
-		code := g.GenerateCouponCode()
+		code, err := g.TryGenerateCouponCode()
+		if err != nil {
+			return nil, err
+		}
 		if !generated[code] {
 			codes = append(codes, code)
 			generated[code] = true
 		}
 	}
 
-	return codes
+	return codes, nil
+}
+
+// isBlocked reports whether the code contains any blocklisted substring,
+// ignoring case.
+//
+// Parameters:
+//   - code: The candidate coupon code.
+//
+// Returns:
+//   - bool: True if the code contains a blocklisted substring.
+func (g *CouponCodeGenerator) isBlocked(code string) bool {
+	upper := strings.ToUpper(code)
+	for _, word := range g.blocklist {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
 }
 
 // getFilteredCharset returns the character set with all excluded characters removed.
@@ -714,10 +834,16 @@ func (g *TokenGenerator) GenerateVerificationCode() string {
 //
 //	gen = NewReferenceGenerator("INV", "", 6)
 //	ref = gen.GenerateInvoiceReference() // Returns "INV-2024-01-123456"
+//
+//	gen = NewReferenceGenerator("ORD", "SHOP", 8)
+//	gen.EnableSequence()
+//	ref = gen.GenerateOrderReference() // Returns "ORD-20240115-000000012-SHOP"
 type ReferenceGenerator struct {
-	prefix string // Prefix to identify reference type
-	suffix string // Optional suffix for additional identification
-	length int    // Length of the random numeric component
+	prefix     string // Prefix to identify reference type
+	suffix     string // Optional suffix for additional identification
+	length     int    // Length of the random numeric component
+	sequential bool   // Use a sequence and check digit instead of random numbers
+	sequence   int64  // Last sequence number issued, accessed atomically
 }
 
 // NewReferenceGenerator creates a new reference generator with the specified
@@ -745,10 +871,28 @@ func NewReferenceGenerator(prefix, suffix string, length int) *ReferenceGenerato
 	}
 }
 
+// EnableSequence switches GenerateOrderReference from random numbers to an atomic
+// per-generator sequence followed by a check digit. References from the same
+// generator are then guaranteed unique, and typos can be detected with
+// ValidateOrderReference. The sequence starts at 1 and is zero-padded to the
+// configured length.
+//
+// Example:
+//
+//	gen := NewReferenceGenerator("ORD", "SHOP", 8)
+//	gen.EnableSequence()
+//	ref := gen.GenerateOrderReference() // Returns "ORD-20240115-000000012-SHOP"
+func (g *ReferenceGenerator) EnableSequence() {
+	g.sequential = true
+}
+
 // GenerateOrderReference generates an order reference number with date and
 // random numeric components. The format includes the current date (YYYYMMDD)
 // for easy chronological sorting and identification.
 //
+// When EnableSequence has been called, the random component is replaced by the
+// next sequence number and a Luhn check digit, separated from the date by a dash.
+//
 // Returns:
 //   - string: Order reference in format "PREFIX-YYYYMMDD-NUMBERS-SUFFIX" or
 //             "PREFIX-YYYYMMDD-NUMBERS" if no suffix is configured.
@@ -763,6 +907,15 @@ func NewReferenceGenerator(prefix, suffix string, length int) *ReferenceGenerato
 func (g *ReferenceGenerator) GenerateOrderReference() string {
 	timestamp := time.Now().Format("20060102")
 	random := GenerateNumericID(g.length)
+	if g.sequential {
+		sequence := atomic.AddInt64(&g.sequence, 1)
+		width := g.length
+		if width <= 0 {
+			width = 8
+		}
+		number := fmt.Sprintf("%0*d", width, sequence)
+		random = "-" + number + strconv.Itoa(calculateLuhnCheckDigit(timestamp+number))
+	}
 
 	parts := []string{}
 	if g.prefix != "" {
@@ -776,6 +929,47 @@ func (g *ReferenceGenerator) GenerateOrderReference() string {
 	return strings.Join(parts, "")
 }
 
+// ValidateOrderReference checks that a sequential order reference produced by
+// this generator is well formed and that its check digit matches. It detects
+// single mistyped digits and most swapped adjacent digits.
+//
+// Parameters:
+//   - ref: Order reference to validate.
+//
+// Returns:
+//   - bool: True if the reference has this generator's prefix and suffix, a valid
+//           date, and a correct check digit.
+//
+// Example:
+//
+//	gen := NewReferenceGenerator("ORD", "SHOP", 8)
+//	gen.EnableSequence()
+//	ref := gen.GenerateOrderReference()
+//	gen.ValidateOrderReference(ref)                           // Returns true
+//	gen.ValidateOrderReference("ORD-20240115-000000013-SHOP") // Returns false
+func (g *ReferenceGenerator) ValidateOrderReference(ref string) bool {
+	if !strings.HasPrefix(ref, g.prefix) || !strings.HasSuffix(ref, g.suffix) {
+		return false
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(ref, g.prefix), g.suffix)
+
+	timestamp, number, found := strings.Cut(body, "-")
+	if !found || len(number) < 2 {
+		return false
+	}
+	if _, err := time.Parse("20060102", timestamp); err != nil {
+		return false
+	}
+	for _, digit := range number {
+		if digit < '0' || digit > '9' {
+			return false
+		}
+	}
+
+	checkDigit := int(number[len(number)-1] - '0')
+	return calculateLuhnCheckDigit(timestamp+number[:len(number)-1]) == checkDigit
+}
+
 // GenerateInvoiceReference generates an invoice reference number with year-month
 // and random numeric components. The format uses YYYY-MM for monthly grouping
 // of invoices, which is common in accounting systems.
@@ -907,8 +1101,8 @@ func (g *BarcodeGenerator) GenerateUPC() string {
 // component to create unique product identifiers.
 //
 // Parameters:
-//   - category: Product category name (first 3 characters used).
-//   - subcategory: Product subcategory name (first 3 characters used).
+//   - category: Product category name (first 3 characters used; shorter names are used in full).
+//   - subcategory: Product subcategory name (first 3 characters used; shorter names are used in full).
 //
 // Returns:
 //   - string: SKU in format "CAT-SUB-123456" or variations based on provided parameters.
@@ -918,6 +1112,7 @@ func (g *BarcodeGenerator) GenerateUPC() string {
 //	gen := NewBarcodeGenerator()
 //	sku := gen.GenerateSKU("Electronics", "Mobile") // Returns "ELE-MOB-123456"
 //	sku = gen.GenerateSKU("Books", "")              // Returns "BOO-123456"
+//	sku = gen.GenerateSKU("TV", "4K")               // Returns "TV-4K-123456"
 //	sku = gen.GenerateSKU("", "")                   // Returns "123456"
 func (g *BarcodeGenerator) GenerateSKU(category, subcategory string) string {
 	parts := []string{}
@@ -931,6 +1126,50 @@ func (g *BarcodeGenerator) GenerateSKU(category, subcategory string) string {
 	return strings.Join(parts, "-")
 }
 
+// maxUniqueSKUAttempts bounds the random retries made by GenerateUniqueSKU before
+// it falls back to scanning for a free numeric component.
+const maxUniqueSKUAttempts = 100
+
+// GenerateUniqueSKU generates a SKU that is not already present in the existing set.
+// Random SKUs are tried first; if they keep colliding, the numeric component is
+// scanned in order for the first free value, so generation always terminates.
+//
+// Parameters:
+//   - category: Product category name (first 3 characters used; shorter names are used in full).
+//   - subcategory: Product subcategory name (first 3 characters used; shorter names are used in full).
+//   - existing: Set of SKUs already in use. Can be nil.
+//
+// Returns:
+//   - string: Unused SKU in the same format as GenerateSKU, or an empty string if
+//             every SKU for the category and subcategory is taken.
+//
+// Example:
+//
+//	gen := NewBarcodeGenerator()
+//	existing := map[string]bool{"ELE-MOB-123456": true}
+//	sku := gen.GenerateUniqueSKU("Electronics", "Mobile", existing) // Returns "ELE-MOB-654321"
+//	existing[sku] = true
+func (g *BarcodeGenerator) GenerateUniqueSKU(category, subcategory string, existing map[string]bool) string {
+	sku := g.GenerateSKU(category, subcategory)
+	for attempt := 1; existing[sku] && attempt < maxUniqueSKUAttempts; attempt++ {
+		sku = g.GenerateSKU(category, subcategory)
+	}
+	if !existing[sku] {
+		return sku
+	}
+
+	// Random attempts exhausted; scan the 6-digit space in order
+	prefix := sku[:len(sku)-6]
+	for number := 100000; number <= 999999; number++ {
+		candidate := prefix + strconv.Itoa(number)
+		if !existing[candidate] {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
 // calculateEAN13CheckDigit calculates the check digit for EAN-13 barcodes
 // using the standard algorithm. This is an internal helper method that
 // implements the EAN-13 check digit calculation formula.
@@ -975,6 +1214,35 @@ func (g *BarcodeGenerator) calculateUPCCheckDigit(code string) int {
 	return (10 - (sum % 10)) % 10
 }
 
+// calculateLuhnCheckDigit calculates the Luhn (mod 10) check digit for a string
+// of digits. Non-digit characters are ignored. This is an internal helper used
+// for sequential order references.
+//
+// Parameters:
+//   - digits: The digits to calculate the check digit for.
+//
+// Returns:
+//   - int: The check digit (0-9).
+func calculateLuhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		if digits[i] < '0' || digits[i] > '9' {
+			continue
+		}
+		num := int(digits[i] - '0')
+		if double {
+			num *= 2
+			if num > 9 {
+				num -= 9
+			}
+		}
+		sum += num
+		double = !double
+	}
+	return (10 - (sum % 10)) % 10
+}
+
 // SlugGenerator provides URL slug generation for creating SEO-friendly URLs
 // from text input. It handles text normalization, special character removal,
 // and length constraints to create clean, web-safe URL segments.
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -277,6 +278,58 @@ func TestGenerateBatchCouponCodes(t *testing.T) {
 	}
 }
 
+func TestCouponCodeGeneratorBlocklist(t *testing.T) {
+	gen := NewCouponCodeGenerator(4)
+	gen.SetCharset("ABD")
+	gen.SetExcludedChars(nil)
+	gen.SetBlocklist([]string{"bad", "DAB"})
+
+	codes, err := gen.TryGenerateBatchCouponCodes(20)
+	if err != nil {
+		t.Fatalf("TryGenerateBatchCouponCodes() unexpected error: %v", err)
+	}
+	for _, code := range codes {
+		if strings.Contains(code, "BAD") || strings.Contains(code, "DAB") {
+			t.Errorf("Code contains blocklisted substring: %s", code)
+		}
+	}
+
+	for i := 0; i < 50; i++ {
+		code, err := gen.TryGenerateCouponCode()
+		if err != nil {
+			t.Fatalf("TryGenerateCouponCode() unexpected error: %v", err)
+		}
+		if strings.Contains(code, "BAD") || strings.Contains(code, "DAB") {
+			t.Errorf("Code contains blocklisted substring: %s", code)
+		}
+		if code := gen.GenerateCouponCode(); strings.Contains(code, "BAD") || strings.Contains(code, "DAB") {
+			t.Errorf("Code contains blocklisted substring: %s", code)
+		}
+	}
+}
+
+func TestCouponCodeGeneratorBlocklistMaxRetries(t *testing.T) {
+	gen := NewCouponCodeGenerator(4)
+	gen.SetCharset("A")
+	gen.SetBlocklist([]string{"aa"})
+	gen.SetMaxRetries(5)
+
+	if _, err := gen.TryGenerateCouponCode(); err == nil {
+		t.Error("TryGenerateCouponCode() expected error when every code is blocklisted")
+	}
+	if _, err := gen.TryGenerateBatchCouponCodes(3); err == nil {
+		t.Error("TryGenerateBatchCouponCodes() expected error when every code is blocklisted")
+	}
+
+	// The original methods keep their signatures and return nothing instead
+	if code := gen.GenerateCouponCode(); code != "" {
+		t.Errorf("GenerateCouponCode() = %q; want empty when every code is blocklisted", code)
+	}
+	if codes := gen.GenerateBatchCouponCodes(3); codes != nil {
+		t.Errorf("GenerateBatchCouponCodes() = %v; want nil when every code is blocklisted", codes)
+	}
+}
+
 func TestCouponCodeGeneratorSetters(t *testing.T) {
 	gen := NewCouponCodeGenerator(8)
 
@@ -497,6 +550,89 @@ func TestGenerateOrderReference(t *testing.T) {
 	}
 }
 
+func TestGenerateSequentialOrderReference(t *testing.T) {
+	gen := NewReferenceGenerator("ORD", "SHOP", 8)
+	gen.EnableSequence()
+
+	today := time.Now().Format("20060102")
+	seen := make(map[string]bool)
+	for i := 0; i < 10000; i++ {
+		ref := gen.GenerateOrderReference()
+		if seen[ref] {
+			t.Fatalf("Duplicate order reference: %s", ref)
+		}
+		seen[ref] = true
+
+		if !strings.HasPrefix(ref, "ORD-"+today+"-") {
+			t.Fatalf("Order reference should keep the date prefix: %s", ref)
+		}
+		if !gen.ValidateOrderReference(ref) {
+			t.Fatalf("Order reference should have a valid checksum: %s", ref)
+		}
+	}
+}
+
+func TestGenerateSequentialOrderReferenceConcurrent(t *testing.T) {
+	gen := NewReferenceGenerator("ORD", "", 6)
+	gen.EnableSequence()
+
+	const workers = 8
+	const perWorker = 500
+	refs := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				refs <- gen.GenerateOrderReference()
+			}
+		}()
+	}
+	wg.Wait()
+	close(refs)
+
+	seen := make(map[string]bool)
+	for ref := range refs {
+		if seen[ref] {
+			t.Fatalf("Duplicate order reference: %s", ref)
+		}
+		seen[ref] = true
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("Expected %d unique references, got %d", workers*perWorker, len(seen))
+	}
+}
+
+func TestValidateOrderReference(t *testing.T) {
+	gen := NewReferenceGenerator("ORD", "SHOP", 8)
+	gen.EnableSequence()
+	ref := gen.GenerateOrderReference()
+
+	tests := []struct {
+		name  string
+		ref   string
+		valid bool
+	}{
+		{"generated", ref, true},
+		{"known valid", "ORD-20240115-000000012-SHOP", true},
+		{"wrong check digit", "ORD-20240115-000000013-SHOP", false},
+		{"mistyped digit", "ORD-20240115-000000112-SHOP", false},
+		{"swapped digits", "ORD-20240115-000000102-SHOP", false},
+		{"wrong prefix", "INV-20240115-000000012-SHOP", false},
+		{"wrong suffix", "ORD-20240115-000000012-WEB", false},
+		{"invalid date", "ORD-20241315-000000012-SHOP", false},
+		{"non numeric", "ORD-20240115-0000000A2-SHOP", false},
+		{"random reference", "ORD-2024011512345678-SHOP", false},
+	}
+
+	for _, tt := range tests {
+		if got := gen.ValidateOrderReference(tt.ref); got != tt.valid {
+			t.Errorf("ValidateOrderReference(%s) [%s] = %v; want %v", tt.ref, tt.name, got, tt.valid)
+		}
+	}
+}
+
 func TestGenerateInvoiceReference(t *testing.T) {
 	gen := NewReferenceGenerator("INV", "", 4)
 
@@ -608,6 +744,46 @@ func TestGenerateSKU(t *testing.T) {
 	}
 }
 
+func TestGenerateUniqueSKU(t *testing.T) {
+	gen := NewBarcodeGenerator()
+
+	existing := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		existing[gen.GenerateSKU("TV", "4K")] = true
+	}
+
+	for i := 0; i < 1000; i++ {
+		sku := gen.GenerateUniqueSKU("TV", "4K", existing)
+		if sku == "" {
+			t.Fatal("GenerateUniqueSKU should find an unused SKU")
+		}
+		if existing[sku] {
+			t.Fatalf("GenerateUniqueSKU returned an existing SKU: %s", sku)
+		}
+		if !strings.HasPrefix(sku, "TV-4K-") {
+			t.Errorf("GenerateUniqueSKU should keep short category names in full: %s", sku)
+		}
+		existing[sku] = true
+	}
+
+	if sku := gen.GenerateUniqueSKU("Electronics", "Mobile", nil); !strings.HasPrefix(sku, "ELE-MOB-") {
+		t.Errorf("GenerateUniqueSKU with nil set = %s; want ELE-MOB- prefix", sku)
+	}
+}
+
+func TestGenerateUniqueSKUExhausted(t *testing.T) {
+	gen := NewBarcodeGenerator()
+
+	existing := make(map[string]bool)
+	for number := 100000; number <= 999999; number++ {
+		existing["A-"+strconv.Itoa(number)] = true
+	}
+
+	if sku := gen.GenerateUniqueSKU("a", "", existing); sku != "" {
+		t.Errorf("GenerateUniqueSKU should return empty string when all SKUs are taken, got %s", sku)
+	}
+}
+
 func TestNewSlugGenerator(t *testing.T) {
 	gen := NewSlugGenerator()
 	if gen == nil {
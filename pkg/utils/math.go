@@ -18,8 +18,13 @@
 package utils
 
 import (
+	"errors"
 	"math"
+	"math/big"
+	"math/bits"
 	"math/rand"
+	"sort"
+	"sync/atomic"
 	"time"
 )
 
@@ -107,14 +112,25 @@ func RoundWithMode(value float64, decimals int, mode RoundingMode) float64 {
 	case RoundHalfEven:
 		return roundHalfEven(scaled) / multiplier
 	case RoundUp:
-		return math.Ceil(scaled) / multiplier
+		return math.Ceil(snapToInteger(scaled)) / multiplier
 	case RoundDown:
-		return math.Floor(scaled) / multiplier
+		return math.Floor(snapToInteger(scaled)) / multiplier
 	default:
 		return math.Floor(scaled+0.5) / multiplier
 	}
 }
 
+// snapToInteger returns the nearest integer when value is within float noise of it,
+// so that directional rounding does not move an exact amount such as
+// 0.29*100 = 28.999999999999996 down (or up) by a whole unit.
+func snapToInteger(value float64) float64 {
+	nearest := math.Round(value)
+	if math.Abs(value-nearest) <= 1e-9*math.Max(1, math.Abs(value)) {
+		return nearest
+	}
+	return value
+}
+
 // roundHalfEven implements banker's rounding (round half to even).
 // This is an internal helper function that implements the IEEE 754 standard
 // for rounding, which helps reduce bias in statistical calculations.
@@ -473,6 +489,121 @@ func SumInt(values []int) int {
 	return sum
 }
 
+// AllocateRemainder rounds parts to cents so that they sum exactly to total.
+// Each part is rounded down to a whole cent, and the cents still missing from
+// total are handed out one at a time to the parts with the largest remainders
+// (or taken from the smallest remainders when the floors overshoot). Use it
+// whenever a breakdown (per-item discounts, per-line tax, allocations) must
+// reconcile with a rounded total.
+//
+// Parameters:
+//   - total: The amount the rounded parts must add up to
+//   - parts: Unrounded part amounts, typically summing to total
+//
+// Returns:
+//   - Parts rounded to cents whose sum equals total rounded to cents
+//
+// Example:
+//	// Split $10.00 three ways
+//	shares := AllocateRemainder(10.00, []float64{3.3333, 3.3333, 3.3333}) // [3.34, 3.33, 3.33]
+//	// Line taxes that round to 0.02 + 0.02 but total 0.03
+//	lines := AllocateRemainder(0.03, []float64{0.015, 0.015}) // [0.02, 0.01]
+func AllocateRemainder(total float64, parts []float64) []float64 {
+	return AllocateRemainderWithPrecision(total, parts, 2)
+}
+
+// AllocateRemainderWithPrecision works like AllocateRemainder but uses the given
+// number of decimal places as the minor unit, e.g. 0 for JPY or 3 for KWD.
+// Ties between equal remainders go to the earlier part, so results are stable.
+//
+// Parameters:
+//   - total: The amount the rounded parts must add up to
+//   - parts: Unrounded part amounts, typically summing to total
+//   - decimals: Decimal places of the minor unit (negative values are treated as 0)
+//
+// Returns:
+//   - Parts rounded to the minor unit whose sum equals total rounded to it
+//
+// Example:
+//	yen := AllocateRemainderWithPrecision(100, []float64{33.3, 33.3, 33.4}, 0) // [33, 33, 34]
+func AllocateRemainderWithPrecision(total float64, parts []float64, decimals int) []float64 {
+	if decimals < 0 {
+		decimals = 0
+	}
+	result := make([]float64, len(parts))
+	if len(parts) == 0 {
+		return result
+	}
+
+	multiplier := math.Pow(10, float64(decimals))
+	units := make([]int64, len(parts))
+	remainders := make([]float64, len(parts))
+	allocated := int64(0)
+	for i, part := range parts {
+		// Round away float noise first so 0.29*100 = 28.999... floors to 29
+		scaled := math.Round(part*multiplier*1e6) / 1e6
+		units[i] = int64(math.Floor(scaled))
+		remainders[i] = scaled - float64(units[i])
+		allocated += units[i]
+	}
+
+	order := make([]int, len(parts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return remainders[order[a]] > remainders[order[b]]
+	})
+
+	missing := int64(math.Round(total*multiplier)) - allocated
+	for step := 0; missing != 0; step++ {
+		if missing > 0 {
+			units[order[step%len(order)]]++
+			missing--
+		} else {
+			units[order[len(order)-1-step%len(order)]]--
+			missing++
+		}
+	}
+
+	for i, unit := range units {
+		result[i] = float64(unit) / multiplier
+	}
+	return result
+}
+
+// AllocateProportional splits total across parts in proportion to their weights
+// and rounds the shares with AllocateRemainderWithPrecision, so they add up to
+// total exactly. Use it to spread an order-level amount, such as a discount,
+// across line items by value.
+//
+// Parameters:
+//   - total: The amount to split
+//   - weights: Relative size of each part, e.g. line item values; negative weights count as 0
+//   - decimals: Decimal places of the minor unit
+//
+// Returns:
+//   - Shares in the order of weights; all zero when the weights sum to 0
+//
+// Example:
+//	// $10 order discount across $60 and $40 lines
+//	shares := AllocateProportional(10.00, []float64{60, 40}, 2) // [6.00, 4.00]
+func AllocateProportional(total float64, weights []float64, decimals int) []float64 {
+	weightSum := 0.0
+	for _, weight := range weights {
+		weightSum += math.Max(weight, 0)
+	}
+	if weightSum <= 0 {
+		return make([]float64, len(weights))
+	}
+
+	parts := make([]float64, len(weights))
+	for i, weight := range weights {
+		parts[i] = total * math.Max(weight, 0) / weightSum
+	}
+	return AllocateRemainderWithPrecision(total, parts, decimals)
+}
+
 // Average calculates the arithmetic mean of a slice of float64 values.
 // This function is fundamental for statistical analysis, performance metrics,
 // price averaging, and data analysis in ecommerce applications.
@@ -759,6 +890,58 @@ func IsEqual(a, b, tolerance float64) bool {
 	return math.Abs(a-b) <= tolerance
 }
 
+// DefaultEqualTolerance is the initial tolerance used by IsEqualDefault: one cent,
+// the smallest difference that matters for most monetary comparisons.
+const DefaultEqualTolerance = 0.01
+
+// defaultToleranceBits holds the current default tolerance as float64 bits so it
+// can be read and replaced atomically.
+var defaultToleranceBits atomic.Uint64
+
+func init() {
+	defaultToleranceBits.Store(math.Float64bits(DefaultEqualTolerance))
+}
+
+// SetDefaultTolerance changes the tolerance used by IsEqualDefault. The value is
+// stored atomically, so it is safe to call concurrently with IsEqualDefault, but
+// the setting is process-wide: prefer configuring it once at startup rather than
+// toggling it per request. Negative tolerances are treated as their absolute value.
+//
+// Parameters:
+//   - tolerance: New default maximum acceptable difference
+//
+// Example:
+//	// Compare to a tenth of a cent throughout the application
+//	SetDefaultTolerance(0.001)
+func SetDefaultTolerance(tolerance float64) {
+	defaultToleranceBits.Store(math.Float64bits(math.Abs(tolerance)))
+}
+
+// DefaultTolerance returns the tolerance currently used by IsEqualDefault.
+//
+// Returns:
+//   - The process-wide default tolerance (DefaultEqualTolerance unless changed)
+func DefaultTolerance() float64 {
+	return math.Float64frombits(defaultToleranceBits.Load())
+}
+
+// IsEqualDefault checks if two float64 values are equal within the package default
+// tolerance (one cent unless changed with SetDefaultTolerance). Use IsEqual when a
+// comparison needs its own tolerance.
+//
+// Parameters:
+//   - a: First floating-point value to compare
+//   - b: Second floating-point value to compare
+//
+// Returns:
+//   - true if the absolute difference is within the default tolerance, false otherwise
+//
+// Example:
+//	equal := IsEqualDefault(19.995, 20.0) // true (within 1 cent)
+func IsEqualDefault(a, b float64) bool {
+	return IsEqual(a, b, DefaultTolerance())
+}
+
 // IsZero checks if a float64 value is effectively zero within a small tolerance.
 // This function handles floating-point precision issues when checking for zero values,
 // essential for reliable financial calculations, quantity validations, and mathematical
@@ -879,11 +1062,19 @@ func LCM(a, b int) int {
 	return AbsInt(a*b) / GCD(a, b)
 }
 
+// MaxFactorialInput is the largest n for which Factorial(n) fits in an int.
+// It is 20 on 64-bit platforms (20! = 2,432,902,008,176,640,000) and 12 on
+// 32-bit platforms. Use FactorialBig for larger inputs.
+const MaxFactorialInput = 12 + 8*(bits.UintSize/64)
+
 // Factorial calculates the factorial of a non-negative integer.
 // This function computes n! = n × (n-1) × (n-2) × ... × 1, useful for
 // combinatorial calculations, permutation analysis, probability computations,
 // and mathematical modeling in ecommerce applications.
 //
+// Results that would overflow int saturate at math.MaxInt instead of wrapping
+// around. This happens for n > MaxFactorialInput (n ≥ 21 on 64-bit platforms).
+//
 // Parameters:
 //   - n: Non-negative integer to calculate factorial for
 //
@@ -891,6 +1082,7 @@ func LCM(a, b int) int {
 //   - The factorial of n (n!)
 //   - Returns 0 for negative inputs
 //   - Returns 1 for n = 0 or n = 1
+//   - Returns math.MaxInt for n > MaxFactorialInput
 //
 // Example:
 //	// Calculate permutations for product arrangements
@@ -904,6 +1096,9 @@ func Factorial(n int) int {
 	if n <= 1 {
 		return 1
 	}
+	if n > MaxFactorialInput {
+		return math.MaxInt
+	}
 
 	result := 1
 	for i := 2; i <= n; i++ {
@@ -912,6 +1107,155 @@ func Factorial(n int) int {
 	return result
 }
 
+// FactorialBig calculates the factorial of a non-negative integer using
+// arbitrary-precision arithmetic. Unlike Factorial, the result never overflows,
+// making it suitable for large inputs in probability and combinatorial analysis.
+//
+// Parameters:
+//   - n: Non-negative integer to calculate factorial for
+//
+// Returns:
+//   - The factorial of n (n!) as a new *big.Int
+//   - Returns 0 for negative inputs
+//   - Returns 1 for n = 0 or n = 1
+//
+// Example:
+//	// Number of ways to order a 30-item catalog
+//	orderings := FactorialBig(30) // 265252859812191058636308480000000
+func FactorialBig(n int) *big.Int {
+	if n < 0 {
+		return big.NewInt(0)
+	}
+	if n <= MaxFactorialInput {
+		return big.NewInt(int64(Factorial(n)))
+	}
+
+	return new(big.Int).MulRange(1, int64(n))
+}
+
+// Errors returned by the combinatorics helpers.
+var (
+	// ErrInvalidSelection is returned when n or r is negative or r exceeds n.
+	ErrInvalidSelection = errors.New("selection size must be between 0 and n")
+
+	// ErrIntegerOverflow is returned when a result does not fit in an int.
+	ErrIntegerOverflow = errors.New("result overflows int")
+)
+
+// Combinations calculates the number of ways to choose r items from n (nCr)
+// without regard to order. The value is computed iteratively, keeping every
+// intermediate product as small as possible, so moderate inputs such as
+// C(60, 30) succeed even though 60! itself overflows.
+//
+// Parameters:
+//   - n: Total number of items
+//   - r: Number of items to choose
+//
+// Returns:
+//   - The number of combinations (n choose r)
+//   - ErrInvalidSelection if n or r is negative or r > n
+//   - ErrIntegerOverflow if the result does not fit in an int
+//
+// Example:
+//	// Number of 2-product bundles from a 5-product catalog
+//	bundles, err := Combinations(5, 2) // 10
+func Combinations(n, r int) (int, error) {
+	if n < 0 || r < 0 || r > n {
+		return 0, ErrInvalidSelection
+	}
+	if r > n-r {
+		r = n - r
+	}
+
+	result := 1
+	for i := 1; i <= r; i++ {
+		// result * (n-r+i) is always divisible by i; cancel the common factor
+		// first so the multiplication stays as small as possible.
+		g := GCD(result, i)
+		result /= g
+		factor := (n - r + i) / (i / g)
+		if result > math.MaxInt/factor {
+			return 0, ErrIntegerOverflow
+		}
+		result *= factor
+	}
+	return result, nil
+}
+
+// CombinationsBig calculates nCr using arbitrary-precision arithmetic.
+// Use it when Combinations reports ErrIntegerOverflow.
+//
+// Parameters:
+//   - n: Total number of items
+//   - r: Number of items to choose
+//
+// Returns:
+//   - The number of combinations as a new *big.Int
+//   - ErrInvalidSelection if n or r is negative or r > n
+//
+// Example:
+//	ways, err := CombinationsBig(100, 50) // 100891344545564193334812497256
+func CombinationsBig(n, r int) (*big.Int, error) {
+	if n < 0 || r < 0 || r > n {
+		return nil, ErrInvalidSelection
+	}
+	return new(big.Int).Binomial(int64(n), int64(r)), nil
+}
+
+// Permutations calculates the number of ordered arrangements of r items
+// chosen from n (nPr = n! / (n-r)!). The value is computed iteratively as
+// n × (n-1) × ... × (n-r+1), so it never materializes n! itself.
+//
+// Parameters:
+//   - n: Total number of items
+//   - r: Number of items to arrange
+//
+// Returns:
+//   - The number of permutations
+//   - ErrInvalidSelection if n or r is negative or r > n
+//   - ErrIntegerOverflow if the result does not fit in an int
+//
+// Example:
+//	// Ways to fill 2 featured slots from 5 products
+//	arrangements, err := Permutations(5, 2) // 20
+func Permutations(n, r int) (int, error) {
+	if n < 0 || r < 0 || r > n {
+		return 0, ErrInvalidSelection
+	}
+
+	result := 1
+	for i := n - r + 1; i <= n; i++ {
+		if result > math.MaxInt/i {
+			return 0, ErrIntegerOverflow
+		}
+		result *= i
+	}
+	return result, nil
+}
+
+// PermutationsBig calculates nPr using arbitrary-precision arithmetic.
+// Use it when Permutations reports ErrIntegerOverflow.
+//
+// Parameters:
+//   - n: Total number of items
+//   - r: Number of items to arrange
+//
+// Returns:
+//   - The number of permutations as a new *big.Int
+//   - ErrInvalidSelection if n or r is negative or r > n
+//
+// Example:
+//	arrangements, err := PermutationsBig(30, 25)
+func PermutationsBig(n, r int) (*big.Int, error) {
+	if n < 0 || r < 0 || r > n {
+		return nil, ErrInvalidSelection
+	}
+	if r == 0 {
+		return big.NewInt(1), nil
+	}
+	return new(big.Int).MulRange(int64(n-r+1), int64(n)), nil
+}
+
 // Fibonacci calculates the nth Fibonacci number using iterative approach.
 // This function generates numbers in the Fibonacci sequence where each number
 // is the sum of the two preceding ones, useful for growth modeling, spiral
@@ -1487,4 +1831,182 @@ func LinearRegression(x, y []float64) (slope, intercept float64) {
 	intercept = (sumY - slope*sumX) / n
 
 	return slope, intercept
-}
\ No newline at end of file
+}
+
+// LinearRegressionStats performs linear regression like LinearRegression and also
+// reports how well the fitted line explains the data. Use it to decide whether a
+// trend-based forecast, such as a dynamic pricing prediction, can be trusted.
+//
+// Parameters:
+//   - x: Independent variable values (slice of floating-point values)
+//   - y: Dependent variable values (slice of floating-point values, must be same length as x)
+//
+// Returns:
+//   - slope: The slope of the regression line
+//   - intercept: The y-intercept of the regression line
+//   - r2: Coefficient of determination (1 = perfect fit, 0 = no better than the mean)
+//   - stdErr: Standard error of the estimate, sqrt(SSE / (n - 2)); 0 with fewer than 3 points
+//   - Returns all zeros for invalid inputs or when x values are all the same
+//
+// Example:
+//	months := []float64{1, 2, 3, 4, 5}
+//	sales := []float64{2, 4, 5, 4, 5}
+//	slope, intercept, r2, stdErr := LinearRegressionStats(months, sales)
+//	// slope=0.6, intercept=2.2, r2=0.6, stdErr≈0.894
+//	// A 95% prediction band is roughly forecast ± 2*stdErr
+func LinearRegressionStats(x, y []float64) (slope, intercept, r2, stdErr float64) {
+	if len(x) != len(y) || len(x) == 0 || IsZero(Variance(x)) {
+		return 0, 0, 0, 0
+	}
+
+	slope, intercept = LinearRegression(x, y)
+
+	meanY := Average(y)
+	sse := 0.0
+	sst := 0.0
+	for i := range x {
+		residual := y[i] - (slope*x[i] + intercept)
+		sse += residual * residual
+		sst += (y[i] - meanY) * (y[i] - meanY)
+	}
+
+	// A constant y is fitted exactly by a flat line
+	r2 = 1
+	if !IsZero(sst) {
+		r2 = 1 - sse/sst
+	}
+
+	if len(x) > 2 {
+		stdErr = math.Sqrt(sse / float64(len(x)-2))
+	}
+
+	return slope, intercept, r2, stdErr
+}
+
+// Detrend removes the linear trend from a time series by subtracting its
+// least-squares fit. The values are treated as evenly spaced observations
+// (x = 0, 1, 2, ...), which makes this useful for isolating fluctuations in
+// sales or demand data before feeding it into dynamic pricing.
+//
+// Parameters:
+//   - values: Time series values in chronological order
+//
+// Returns:
+//   - Residuals after removing the fitted line (same length as values)
+//   - An empty slice for empty input; a single value detrends to 0
+//
+// Example:
+//	sales := []float64{100, 112, 118, 131, 140}
+//	residuals := Detrend(sales) // fluctuations around the growth trend
+func Detrend(values []float64) []float64 {
+	result := make([]float64, len(values))
+	if len(values) == 0 {
+		return result
+	}
+
+	x := make([]float64, len(values))
+	for i := range x {
+		x[i] = float64(i)
+	}
+
+	slope, intercept := LinearRegression(x, values)
+	for i, value := range values {
+		result[i] = value - (slope*x[i] + intercept)
+	}
+	return result
+}
+
+// SeasonalAdjust removes a repeating seasonal pattern from a time series.
+// Each value is reduced by its phase's seasonal index: the mean of all values
+// sharing that position in the cycle, minus the overall mean. The overall level
+// of the series is preserved, so the result can be combined with Detrend.
+//
+// Parameters:
+//   - values: Time series values in chronological order
+//   - period: Length of the seasonal cycle (e.g., 7 for daily data with weekly seasonality)
+//
+// Returns:
+//   - Seasonally adjusted values (same length as values)
+//   - An unchanged copy if period < 2 or the series holds fewer than two full cycles
+//
+// Example:
+//	// Weekly sales with a weekend spike
+//	daily := []float64{10, 10, 10, 10, 10, 20, 20, 12, 12, 12, 12, 12, 22, 22}
+//	adjusted := SeasonalAdjust(daily, 7) // weekend spike removed
+func SeasonalAdjust(values []float64, period int) []float64 {
+	result := make([]float64, len(values))
+	copy(result, values)
+	if period < 2 || len(values) < 2*period {
+		return result
+	}
+
+	sums := make([]float64, period)
+	counts := make([]int, period)
+	for i, value := range values {
+		sums[i%period] += value
+		counts[i%period]++
+	}
+
+	mean := Average(values)
+	for i := range result {
+		phase := i % period
+		result[i] -= sums[phase]/float64(counts[phase]) - mean
+	}
+	return result
+}
+
+// Holt smoothing factors used by Forecast. The level follows recent values
+// closely while the trend adapts more slowly, so one noisy period does not
+// swing the projected slope.
+const (
+	forecastLevelSmoothing = 0.5
+	forecastTrendSmoothing = 0.3
+)
+
+// Forecast projects a time series forward using Holt's linear trend method
+// (double exponential smoothing). The series is tracked as a smoothed level plus
+// a smoothed per-period trend, and future values extend the final level along the
+// final trend. Useful for demand and inventory planning that feeds pricing.
+//
+// Assumptions:
+//   - Observations are evenly spaced and in chronological order
+//   - The trend is locally linear; seasonality is not modelled, so seasonal data
+//     should be passed through SeasonalAdjust first
+//   - Smoothing starts from the first value with the first difference as trend,
+//     so a perfectly linear series is projected exactly
+//
+// Parameters:
+//   - values: Historical values in chronological order
+//   - periodsAhead: Number of future periods to project
+//
+// Returns:
+//   - Slice of periodsAhead projected values (empty slice if invalid parameters)
+//   - A single historical value is repeated as a flat forecast
+//
+// Example:
+//	// Project weekly demand for the next three weeks
+//	demand := []float64{100, 110, 120, 130}
+//	next := Forecast(demand, 3) // [140, 150, 160]
+func Forecast(values []float64, periodsAhead int) []float64 {
+	if len(values) == 0 || periodsAhead <= 0 {
+		return []float64{}
+	}
+
+	level := values[0]
+	trend := 0.0
+	if len(values) > 1 {
+		trend = values[1] - values[0]
+	}
+
+	for i := 1; i < len(values); i++ {
+		previousLevel := level
+		level = forecastLevelSmoothing*values[i] + (1-forecastLevelSmoothing)*(level+trend)
+		trend = forecastTrendSmoothing*(level-previousLevel) + (1-forecastTrendSmoothing)*trend
+	}
+
+	result := make([]float64, periodsAhead)
+	for h := range result {
+		result[h] = level + float64(h+1)*trend
+	}
+	return result
+}
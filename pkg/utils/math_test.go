@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"math"
 	"testing"
 )
@@ -44,6 +45,8 @@ func TestRoundWithMode(t *testing.T) {
 		{"RoundHalfEven 1.225", 1.225, 2, RoundHalfEven, 1.22},
 		{"RoundUp 1.231", 1.231, 2, RoundUp, 1.24},
 		{"RoundDown 1.239", 1.239, 2, RoundDown, 1.23},
+		{"RoundDown float noise", 0.29, 2, RoundDown, 0.29},
+		{"RoundUp float noise", 0.1 + 0.2, 2, RoundUp, 0.3},
 	}
 
 	for _, tt := range tests {
@@ -491,6 +494,102 @@ func TestIsEqual(t *testing.T) {
 	}
 }
 
+func TestIsEqualDefault(t *testing.T) {
+	defer SetDefaultTolerance(DefaultEqualTolerance)
+
+	if DefaultTolerance() != DefaultEqualTolerance {
+		t.Fatalf("DefaultTolerance() = %f; want %f", DefaultTolerance(), DefaultEqualTolerance)
+	}
+
+	tests := []struct {
+		tolerance float64
+		a, b      float64
+		expected  bool
+	}{
+		{DefaultEqualTolerance, 19.995, 20.0, true},
+		{DefaultEqualTolerance, 19.98, 20.0, false},
+		{0.001, 19.995, 20.0, false},
+		{0.001, 19.9995, 20.0, true},
+		{-0.1, 19.95, 20.0, true},
+	}
+
+	for _, tt := range tests {
+		SetDefaultTolerance(tt.tolerance)
+		result := IsEqualDefault(tt.a, tt.b)
+		if result != tt.expected {
+			t.Errorf("with tolerance %f, IsEqualDefault(%f, %f) = %t; want %t", tt.tolerance, tt.a, tt.b, result, tt.expected)
+		}
+	}
+}
+
+func TestAllocateRemainder(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    float64
+		parts    []float64
+		expected []float64
+	}{
+		{"three-way split", 10.00, []float64{3.3333, 3.3333, 3.3333}, []float64{3.34, 3.33, 3.33}},
+		{"half cents", 0.03, []float64{0.015, 0.015}, []float64{0.02, 0.01}},
+		{"largest remainder wins", 1.00, []float64{0.333, 0.336, 0.331}, []float64{0.33, 0.34, 0.33}},
+		{"floors overshoot", 0.05, []float64{0.029, 0.029}, []float64{0.03, 0.02}},
+		{"float noise", 0.87, []float64{0.29, 0.29, 0.29}, []float64{0.29, 0.29, 0.29}},
+		{"seven ways", 100.00, []float64{100.0 / 7, 100.0 / 7, 100.0 / 7, 100.0 / 7, 100.0 / 7, 100.0 / 7, 100.0 / 7}, []float64{14.29, 14.29, 14.29, 14.29, 14.28, 14.28, 14.28}},
+		{"empty", 5.00, []float64{}, []float64{}},
+	}
+
+	for _, tt := range tests {
+		result := AllocateRemainder(tt.total, tt.parts)
+		if len(result) != len(tt.expected) {
+			t.Fatalf("%s: AllocateRemainder returned %d parts; want %d", tt.name, len(result), len(tt.expected))
+		}
+		sum := 0.0
+		for i := range result {
+			if !IsEqual(result[i], tt.expected[i], 1e-9) {
+				t.Errorf("%s: part %d = %f; want %f", tt.name, i, result[i], tt.expected[i])
+			}
+			sum += result[i]
+		}
+		if len(result) > 0 && !IsEqual(sum, tt.total, 1e-9) {
+			t.Errorf("%s: parts sum to %f; want %f", tt.name, sum, tt.total)
+		}
+	}
+
+	yen := AllocateRemainderWithPrecision(100, []float64{33.3, 33.3, 33.4}, 0)
+	if yen[0] != 33 || yen[1] != 33 || yen[2] != 34 {
+		t.Errorf("AllocateRemainderWithPrecision(100, ..., 0) = %v; want [33 33 34]", yen)
+	}
+}
+
+func TestAllocateProportional(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    float64
+		weights  []float64
+		decimals int
+		expected []float64
+	}{
+		{"by value", 10.00, []float64{60, 40}, 2, []float64{6.00, 4.00}},
+		{"remainder cent", 10.00, []float64{10, 10, 10}, 2, []float64{3.34, 3.33, 3.33}},
+		{"zero weight", 5.00, []float64{0, 25}, 2, []float64{0, 5.00}},
+		{"whole units", 100, []float64{1, 1, 1}, 0, []float64{34, 33, 33}},
+		{"no weight", 5.00, []float64{0, 0}, 2, []float64{0, 0}},
+		{"empty", 5.00, []float64{}, 2, []float64{}},
+	}
+
+	for _, tt := range tests {
+		result := AllocateProportional(tt.total, tt.weights, tt.decimals)
+		if len(result) != len(tt.expected) {
+			t.Fatalf("%s: AllocateProportional returned %d parts; want %d", tt.name, len(result), len(tt.expected))
+		}
+		for i := range result {
+			if !IsEqual(result[i], tt.expected[i], 1e-9) {
+				t.Errorf("%s: part %d = %f; want %f", tt.name, i, result[i], tt.expected[i])
+			}
+		}
+	}
+}
+
 func TestIsZero(t *testing.T) {
 	tests := []struct {
 		value    float64
@@ -602,6 +701,113 @@ func TestFactorial(t *testing.T) {
 	}
 }
 
+func TestFactorialOverflow(t *testing.T) {
+	if MaxFactorialInput == 20 {
+		if result := Factorial(20); result != 2432902008176640000 {
+			t.Errorf("Factorial(20) = %d; want 2432902008176640000", result)
+		}
+	}
+
+	if result := Factorial(MaxFactorialInput + 1); result != math.MaxInt {
+		t.Errorf("Factorial(%d) = %d; want saturation at math.MaxInt", MaxFactorialInput+1, result)
+	}
+}
+
+func TestFactorialBig(t *testing.T) {
+	tests := []struct {
+		n        int
+		expected string
+	}{
+		{-1, "0"},
+		{0, "1"},
+		{5, "120"},
+		{20, "2432902008176640000"},
+		{21, "51090942171709440000"},
+		{30, "265252859812191058636308480000000"},
+	}
+
+	for _, tt := range tests {
+		result := FactorialBig(tt.n)
+		if result.String() != tt.expected {
+			t.Errorf("FactorialBig(%d) = %s; want %s", tt.n, result.String(), tt.expected)
+		}
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	tests := []struct {
+		n        int
+		r        int
+		expected int
+		err      error
+	}{
+		{5, 2, 10, nil},
+		{5, 0, 1, nil},
+		{5, 5, 1, nil},
+		{60, 30, 118264581564861424, nil},
+		{2, 3, 0, ErrInvalidSelection},
+		{-1, 0, 0, ErrInvalidSelection},
+		{200, 100, 0, ErrIntegerOverflow},
+	}
+
+	for _, tt := range tests {
+		result, err := Combinations(tt.n, tt.r)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Combinations(%d, %d) error = %v; want %v", tt.n, tt.r, err, tt.err)
+		}
+		if result != tt.expected {
+			t.Errorf("Combinations(%d, %d) = %d; want %d", tt.n, tt.r, result, tt.expected)
+		}
+	}
+}
+
+func TestPermutations(t *testing.T) {
+	tests := []struct {
+		n        int
+		r        int
+		expected int
+		err      error
+	}{
+		{5, 2, 20, nil},
+		{5, 0, 1, nil},
+		{5, 5, 120, nil},
+		{2, 3, 0, ErrInvalidSelection},
+		{100, 50, 0, ErrIntegerOverflow},
+	}
+
+	for _, tt := range tests {
+		result, err := Permutations(tt.n, tt.r)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Permutations(%d, %d) error = %v; want %v", tt.n, tt.r, err, tt.err)
+		}
+		if result != tt.expected {
+			t.Errorf("Permutations(%d, %d) = %d; want %d", tt.n, tt.r, result, tt.expected)
+		}
+	}
+}
+
+func TestCombinatoricsBig(t *testing.T) {
+	combinations, err := CombinationsBig(100, 50)
+	if err != nil {
+		t.Fatalf("CombinationsBig(100, 50) unexpected error: %v", err)
+	}
+	if combinations.String() != "100891344545564193334812497256" {
+		t.Errorf("CombinationsBig(100, 50) = %s; want 100891344545564193334812497256", combinations.String())
+	}
+
+	permutations, err := PermutationsBig(5, 2)
+	if err != nil {
+		t.Fatalf("PermutationsBig(5, 2) unexpected error: %v", err)
+	}
+	if permutations.Int64() != 20 {
+		t.Errorf("PermutationsBig(5, 2) = %s; want 20", permutations.String())
+	}
+
+	if _, err := PermutationsBig(2, 3); !errors.Is(err, ErrInvalidSelection) {
+		t.Errorf("PermutationsBig(2, 3) error = %v; want %v", err, ErrInvalidSelection)
+	}
+}
+
 func TestFibonacci(t *testing.T) {
 	tests := []struct {
 		n        int
@@ -1038,4 +1244,147 @@ func TestMaxIntEdgeCases(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestLinearRegressionStats(t *testing.T) {
+	tests := []struct {
+		name              string
+		x, y              []float64
+		expectedSlope     float64
+		expectedIntercept float64
+		expectedR2        float64
+		expectedStdErr    float64
+	}{
+		{"Known R squared", []float64{1, 2, 3, 4, 5}, []float64{2, 4, 5, 4, 5}, 0.6, 2.2, 0.6, 0.894427},
+		{"Perfect fit", []float64{1, 2, 3, 4}, []float64{3, 5, 7, 9}, 2, 1, 1, 0},
+		{"Constant y", []float64{1, 2, 3}, []float64{4, 4, 4}, 0, 4, 1, 0},
+		{"Two points", []float64{1, 2}, []float64{1, 3}, 2, -1, 1, 0},
+		{"Constant x", []float64{2, 2, 2}, []float64{1, 2, 3}, 0, 0, 0, 0},
+		{"Empty", []float64{}, []float64{}, 0, 0, 0, 0},
+		{"Mismatched lengths", []float64{1, 2}, []float64{1}, 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slope, intercept, r2, stdErr := LinearRegressionStats(tt.x, tt.y)
+			if math.Abs(slope-tt.expectedSlope) > 0.0001 {
+				t.Errorf("LinearRegressionStats slope = %f; want %f", slope, tt.expectedSlope)
+			}
+			if math.Abs(intercept-tt.expectedIntercept) > 0.0001 {
+				t.Errorf("LinearRegressionStats intercept = %f; want %f", intercept, tt.expectedIntercept)
+			}
+			if math.Abs(r2-tt.expectedR2) > 0.0001 {
+				t.Errorf("LinearRegressionStats r2 = %f; want %f", r2, tt.expectedR2)
+			}
+			if math.Abs(stdErr-tt.expectedStdErr) > 0.0001 {
+				t.Errorf("LinearRegressionStats stdErr = %f; want %f", stdErr, tt.expectedStdErr)
+			}
+		})
+	}
+}
+
+func TestDetrend(t *testing.T) {
+	// Trend of 2.5 per period plus a seasonal cycle of length 4
+	seasonal := []float64{3, -1, -4, 2}
+	values := make([]float64, 24)
+	for i := range values {
+		values[i] = 50 + 2.5*float64(i) + seasonal[i%4]
+	}
+
+	residuals := Detrend(values)
+	if len(residuals) != len(values) {
+		t.Fatalf("Detrend length = %d; want %d", len(residuals), len(values))
+	}
+
+	x := make([]float64, len(residuals))
+	for i := range x {
+		x[i] = float64(i)
+	}
+	slope, _ := LinearRegression(x, residuals)
+	if math.Abs(slope) > 1e-9 {
+		t.Errorf("Detrend residual slope = %f; want ~0", slope)
+	}
+	if math.Abs(Average(residuals)) > 1e-9 {
+		t.Errorf("Detrend residual mean = %f; want ~0", Average(residuals))
+	}
+
+	// Removing the seasonality from the residuals leaves a flat series
+	adjusted := SeasonalAdjust(residuals, 4)
+	if spread := StandardDeviation(adjusted); spread > 0.5 {
+		t.Errorf("SeasonalAdjust residual spread = %f; want < 0.5", spread)
+	}
+
+	t.Run("Short series", func(t *testing.T) {
+		if result := Detrend([]float64{}); len(result) != 0 {
+			t.Errorf("Detrend(empty) = %v; want empty", result)
+		}
+		if result := Detrend([]float64{42}); len(result) != 1 || result[0] != 0 {
+			t.Errorf("Detrend([42]) = %v; want [0]", result)
+		}
+	})
+}
+
+func TestSeasonalAdjust(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		period   int
+		expected []float64
+	}{
+		{"Pure seasonal pattern", []float64{10, 20, 10, 20, 10, 20}, 2, []float64{15, 15, 15, 15, 15, 15}},
+		{"Partial last cycle", []float64{1, 5, 9, 1, 5, 9, 1}, 3, []float64{31.0 / 7, 31.0 / 7, 31.0 / 7, 31.0 / 7, 31.0 / 7, 31.0 / 7, 31.0 / 7}},
+		{"Fewer than two cycles", []float64{1, 2, 3}, 2, []float64{1, 2, 3}},
+		{"Invalid period", []float64{1, 2, 3, 4}, 1, []float64{1, 2, 3, 4}},
+		{"Empty", []float64{}, 4, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := SeasonalAdjust(tt.values, tt.period)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("SeasonalAdjust length = %d; want %d", len(result), len(tt.expected))
+			}
+			for i := range result {
+				if math.Abs(result[i]-tt.expected[i]) > 1e-9 {
+					t.Errorf("SeasonalAdjust[%d] = %f; want %f", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForecast(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       []float64
+		periodsAhead int
+		expected     []float64
+	}{
+		{"Linear growth", []float64{100, 110, 120, 130}, 3, []float64{140, 150, 160}},
+		{"Linear decline", []float64{50, 47.5, 45, 42.5, 40}, 2, []float64{37.5, 35}},
+		{"Flat series", []float64{20, 20, 20}, 2, []float64{20, 20}},
+		{"Single value", []float64{42}, 3, []float64{42, 42, 42}},
+		{"Empty series", []float64{}, 3, []float64{}},
+		{"No periods", []float64{1, 2, 3}, 0, []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Forecast(tt.values, tt.periodsAhead)
+			if len(result) != len(tt.expected) {
+				t.Fatalf("Forecast length = %d; want %d", len(result), len(tt.expected))
+			}
+			for i := range result {
+				if math.Abs(result[i]-tt.expected[i]) > 1e-9 {
+					t.Errorf("Forecast[%d] = %f; want %f", i, result[i], tt.expected[i])
+				}
+			}
+		})
+	}
+
+	// Noise around a trend still projects in the direction of the trend
+	noisy := []float64{10, 13, 14, 17, 18, 21, 22, 25}
+	next := Forecast(noisy, 2)
+	if next[0] <= noisy[len(noisy)-1]-1 || next[1] <= next[0] {
+		t.Errorf("Forecast of upward series = %v; want increasing values near %f", next, noisy[len(noisy)-1])
+	}
+}